@@ -0,0 +1,208 @@
+package gelflogger
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueueFull is the reason reported to the OnDrop callback for messages dropped because the queue of an asynchronous
+// Logger was full, see WithOverflowPolicy.
+var ErrQueueFull = errors.New("queue of the asynchronous Logger is full")
+
+// OverflowPolicy selects what Log does with a message when the queue of an asynchronous Logger is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until a worker takes a message from the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the message being logged, keeping the queued ones.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued message to make room for the message being logged.
+	OverflowDropOldest
+)
+
+const (
+	// DefaultQueueSize is the number of messages the queue of an asynchronous Logger holds if WithAsync is given a
+	// size of zero or less.
+	DefaultQueueSize = 1024
+	// DefaultWorkers is the number of workers draining the queue of an asynchronous Logger if WithAsync is given a
+	// count of zero or less.
+	DefaultWorkers = 1
+)
+
+// queuedMessage is an encoded GELF message waiting in the queue of an asynchronous Logger, together with the
+// transport it was routed to.
+type queuedMessage struct {
+	transport Transport
+	message   []byte
+}
+
+// startWorkers creates the queue of an asynchronous Logger and starts the workers draining it. The workers run until
+// the Logger is closed and its queue is drained. If batching is enabled, the workers send the messages in batches, see WithBatching.
+func (l *Logger) startWorkers(queueSize, workers int, cfg config) {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	l.queue = make(chan queuedMessage, queueSize)
+	l.queueMemoryLimit = cfg.queueMemoryLimit
+	l.room = make(chan struct{}, 1)
+	batchSize, flushInterval := cfg.batchSize, cfg.flushInterval
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	l.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		flushes := make(chan *sync.WaitGroup)
+		l.flushes = append(l.flushes, flushes)
+		go func() {
+			defer l.workers.Done()
+			if cfg.batching {
+				l.drainBatches(batchSize, flushInterval, flushes)
+			} else {
+				l.drain(flushes)
+			}
+		}()
+	}
+}
+
+// drain sends the queued messages until the queue is closed. Messages which can be sent neither through their
+// transport nor through the fallback are dropped, see Logger.Dropped, as there is no caller left to report the error
+// to. On a flush request, the messages queued at that time are sent before the request is marked done, see Flush.
+func (l *Logger) drain(flushes <-chan *sync.WaitGroup) {
+	for {
+		select {
+		case queued, ok := <-l.queue:
+			if !ok {
+				return
+			}
+			l.dequeued(queued)
+			l.dropUnsent(l.send(queued.transport, queued.message), queued.message)
+		case done := <-flushes:
+			l.takeQueued(func(queued queuedMessage) {
+				l.dropUnsent(l.send(queued.transport, queued.message), queued.message)
+			})
+			done.Done()
+		}
+	}
+}
+
+// takeQueued passes the messages in the queue to handle until the queue is empty, without waiting for new messages.
+func (l *Logger) takeQueued(handle func(queued queuedMessage)) {
+	for {
+		select {
+		case queued, ok := <-l.queue:
+			if !ok {
+				return
+			}
+			l.dequeued(queued)
+			handle(queued)
+		default:
+			return
+		}
+	}
+}
+
+// Flush sends the messages queued or batched by an asynchronous Logger before it returns, or returns the error of
+// the context if it is done first. Messages logged while flushing may be sent as well. Messages spooled because
+// Graylog is unreachable stay in the spool. Synchronous Loggers send every message within Log, so there is nothing
+// to flush.
+func (l *Logger) Flush(ctx context.Context) error {
+	l.closeLock.RLock()
+	defer l.closeLock.RUnlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+
+	var done sync.WaitGroup
+	done.Add(len(l.flushes))
+	for i, flushes := range l.flushes {
+		select {
+		case flushes <- &done:
+		case <-ctx.Done():
+			// The workers which did not receive the request will never mark it done
+			done.Add(i - len(l.flushes))
+			return ctx.Err()
+		}
+	}
+	flushed := make(chan struct{})
+	go func() {
+		done.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue adds the message to the queue. If the queue is full, or its messages would exceed the memory limit, see
+// WithQueueMemoryLimit, messages for the transport of the Logger are appended to the spool, if configured, otherwise
+// the OverflowPolicy of the Logger decides whether enqueue blocks until a worker takes a message from the queue, or a
+// message is dropped. Blocked callers return ErrLoggerClosed once the Logger is being closed.
+func (l *Logger) enqueue(queued queuedMessage) error {
+	if l.tryEnqueue(queued) {
+		return nil
+	}
+	if l.spool != nil && queued.transport == l.transport {
+		return l.spoolMessage(queued.message)
+	}
+	switch l.overflowPolicy {
+	case OverflowDropNewest:
+		l.drop(queued.message, ErrQueueFull)
+		return ErrQueueFull
+	case OverflowDropOldest:
+		for !l.tryEnqueue(queued) {
+			select {
+			case oldest := <-l.queue:
+				l.dequeued(oldest)
+				l.drop(oldest.message, ErrQueueFull)
+			default:
+			}
+		}
+		return nil
+	default:
+		for !l.tryEnqueue(queued) {
+			select {
+			case <-l.room:
+			case <-l.closing:
+				return ErrLoggerClosed
+			}
+		}
+		return nil
+	}
+}
+
+// tryEnqueue adds the message to the queue if the queue has room for it, without waiting. A message exceeding the
+// memory limit on its own is only added to an empty queue, so it is not blocked forever.
+func (l *Logger) tryEnqueue(queued queuedMessage) bool {
+	size := int64(len(queued.message))
+	if queuedBytes := l.queuedBytes.Add(size); l.queueMemoryLimit > 0 && queuedBytes > l.queueMemoryLimit && queuedBytes > size {
+		l.queuedBytes.Add(-size)
+		return false
+	}
+	select {
+	case l.queue <- queued:
+		return true
+	default:
+		l.queuedBytes.Add(-size)
+		return false
+	}
+}
+
+// dequeued releases the memory of a message taken from the queue and wakes up a caller waiting for room.
+func (l *Logger) dequeued(queued queuedMessage) {
+	l.queuedBytes.Add(-int64(len(queued.message)))
+	select {
+	case l.room <- struct{}{}:
+	default:
+	}
+}
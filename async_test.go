@@ -0,0 +1,190 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestAsyncLogger(t *testing.T) {
+	blocking := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+	logger := gelflogger.NewLoggerWithTransport(blocking, processNothing, gelflogger.WithAsync(2, 1))
+
+	// The worker blocks on the first message, the next two fill the queue
+	for i := 0; i < 3; i++ {
+		done := make(chan error, 1)
+		go func() { done <- logger.Log("async", map[string]interface{}{}) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Log() #%d blocked on the transport", i+1)
+		}
+		if i == 0 {
+			<-blocking.started
+		}
+	}
+
+	// The queue is full, so Log blocks until the worker takes the next message
+	done := make(chan error, 1)
+	go func() { done <- logger.Log("async", map[string]interface{}{}) }()
+	select {
+	case <-done:
+		t.Fatal("Log() returned although the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(blocking.release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Log() still blocked after the worker drained the queue")
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-blocking.started:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 4 messages were sent", i+1)
+		}
+	}
+}
+
+func TestAsyncLoggerFallback(t *testing.T) {
+	fallback := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(&recordingTransport{down: true}, processNothing,
+		gelflogger.WithAsync(0, 4), gelflogger.WithFallback(fallback))
+
+	for i := 0; i < 10; i++ {
+		if err := logger.Log("async", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		fallback.lock.Lock()
+		sent := len(fallback.messages)
+		fallback.lock.Unlock()
+		if sent == 10 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fallback received %d messages, want 10", sent)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      gelflogger.OverflowPolicy
+		wantDropped []string
+		wantSent    []string
+		// wantErr lists the messages for which Log returns ErrQueueFull
+		wantErr map[int]bool
+	}{
+		{
+			name:        "Drop newest",
+			policy:      gelflogger.OverflowDropNewest,
+			wantDropped: []string{"3", "4"},
+			wantSent:    []string{"0", "1", "2"},
+			wantErr:     map[int]bool{3: true, 4: true},
+		},
+		{
+			name:        "Drop oldest",
+			policy:      gelflogger.OverflowDropOldest,
+			wantDropped: []string{"1", "2"},
+			wantSent:    []string{"0", "3", "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			blocking := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+			var dropped []string
+			logger := gelflogger.NewLoggerWithTransport(&sequentialTransport{first: blocking, rest: transport}, processNothing,
+				gelflogger.WithAsync(2, 1),
+				gelflogger.WithOverflowPolicy(tt.policy),
+				gelflogger.WithOnDrop(func(message []byte, reason error) {
+					if !errors.Is(reason, gelflogger.ErrQueueFull) {
+						t.Errorf("OnDrop() reason = %v, want ErrQueueFull", reason)
+					}
+					dropped = append(dropped, shortMessage(t, message))
+				}))
+
+			// The worker blocks on message 0, messages 1 and 2 fill the queue, messages 3 and 4 overflow
+			for i := 0; i < 5; i++ {
+				if err := logger.Log(strconv.Itoa(i), map[string]interface{}{}); (err == gelflogger.ErrQueueFull) != tt.wantErr[i] {
+					t.Fatalf("Log() error = %v, want ErrQueueFull %v", err, tt.wantErr[i])
+				}
+				if i == 0 {
+					<-blocking.started
+				}
+			}
+			if !reflect.DeepEqual(dropped, tt.wantDropped) {
+				t.Errorf("dropped %v, want %v", dropped, tt.wantDropped)
+			}
+			if logger.Dropped() != uint64(len(tt.wantDropped)) {
+				t.Errorf("Dropped() = %d, want %d", logger.Dropped(), len(tt.wantDropped))
+			}
+
+			close(blocking.release)
+			deadline := time.Now().Add(time.Second)
+			for {
+				transport.lock.Lock()
+				sent := make([]string, 0, len(transport.messages))
+				for _, message := range transport.messages {
+					sent = append(sent, shortMessage(t, []byte(message)))
+				}
+				transport.lock.Unlock()
+				if len(sent) == len(tt.wantSent)-1 {
+					if !reflect.DeepEqual(append([]string{"0"}, sent...), tt.wantSent) {
+						t.Errorf("sent %v, want %v", append([]string{"0"}, sent...), tt.wantSent)
+					}
+					return
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("sent %v, want %v", sent, tt.wantSent)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+// sequentialTransport sends the first message through first and all later ones through rest.
+type sequentialTransport struct {
+	first gelflogger.Transport
+	rest  gelflogger.Transport
+	sent  bool
+}
+
+func (s *sequentialTransport) Send(message []byte) error {
+	if !s.sent {
+		s.sent = true
+		return s.first.Send(message)
+	}
+	return s.rest.Send(message)
+}
+
+func (s *sequentialTransport) Close() error { return nil }
+
+func shortMessage(t *testing.T, message []byte) string {
+	t.Helper()
+	var gelfMsg map[string]interface{}
+	if err := json.Unmarshal(message, &gelfMsg); err != nil {
+		t.Fatalf("invalid GELF message %s: %v", message, err)
+	}
+	return gelfMsg["short_message"].(string)
+}
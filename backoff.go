@@ -0,0 +1,34 @@
+package gelflogger
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultReconnectBackoff is the delay before the second reconnect attempt, the first one is made right away.
+	DefaultReconnectBackoff = 100 * time.Millisecond
+	// DefaultMaxReconnectBackoff is the maximum delay between two reconnect attempts.
+	DefaultMaxReconnectBackoff = 30 * time.Second
+	// backoffJitter is the fraction by which a backoff delay is randomized in both directions.
+	backoffJitter = 0.2
+)
+
+// backoff computes the delays between consecutive reconnect attempts: the initial delay doubles with every attempt
+// up to the maximum delay.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+// delay returns the delay after the given failed attempt, counted from zero. The delay is randomized by up to
+// backoffJitter in both directions, so many clients losing their connection at the same time do not reconnect in
+// lockstep.
+func (b backoff) delay(attempt int) time.Duration {
+	d := b.initial
+	for i := 0; i < attempt && d < b.max; i++ {
+		d *= 2
+	}
+	d = min(d, b.max)
+	return time.Duration(float64(d) * (1 + backoffJitter*(2*rand.Float64()-1)))
+}
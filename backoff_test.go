@@ -0,0 +1,72 @@
+package gelflogger_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestReconnectInBackground(t *testing.T) {
+	server := helper.StartMockServer(t)
+	address := server.Addr().String()
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	logger, err := gelflogger.NewLogger(address, gelflogger.WithProcessor(processNothing), gelflogger.WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	// Take the server down, the writes fail once the connection reset is noticed
+	_ = server.Close()
+	(<-accepted).Close()
+	var logErr error
+	for i := 0; i < 100 && logErr == nil; i++ {
+		logErr = logger.Log("down", map[string]interface{}{})
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logErr == nil {
+		t.Fatal("Log() error = nil while the server is down, want an error")
+	}
+
+	// While reconnecting, Log fails right away instead of dialing itself
+	start := time.Now()
+	if err := logger.Log("down", map[string]interface{}{}); err == nil {
+		t.Fatal("Log() error = nil while the server is down, want an error")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Log() took %v while reconnecting, want it to fail right away", elapsed)
+	}
+
+	server, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("cannot restart the server on %s: %v", address, err)
+	}
+	defer func() { _ = server.Close() }()
+	messages := helper.ReceiveMessages(t, server, 0)
+
+	// The background reconnect picks the server up within the maximum backoff
+	deadline := time.Now().Add(time.Second)
+	for logger.Log("up", map[string]interface{}{}) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Log() still fails after the server is back")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-messages:
+	case <-time.After(time.Second):
+		t.Fatal("message was not received after reconnecting")
+	}
+}
@@ -0,0 +1,112 @@
+package gelflogger
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync/atomic"
+)
+
+// LoadBalancing selects how a Logger distributes its messages across multiple Graylog addresses.
+type LoadBalancing int
+
+const (
+	// NoLoadBalancing sends all messages to a single address and only fails over to the next address if it is
+	// unreachable.
+	NoLoadBalancing LoadBalancing = iota
+	// RoundRobin sends the messages to the addresses in turn.
+	RoundRobin
+	// LeastErrors sends the messages to the address with the fewest consecutive send errors, addresses with an
+	// equal number of errors are used in turn.
+	LeastErrors
+)
+
+// balancedTransport distributes the messages across a TCP connection per address according to a LoadBalancing
+// strategy. If the send to the selected address fails, the remaining addresses are tried in turn.
+type balancedTransport struct {
+	endpoints []*balancedEndpoint
+	strategy  LoadBalancing
+	next      atomic.Uint64
+}
+
+// balancedEndpoint is a single address of a balancedTransport together with its consecutive send errors.
+type balancedEndpoint struct {
+	transport *tcpTransport
+	errors    atomic.Int64
+}
+
+// newBalancedTransport creates a balancedTransport connecting to every address. Unreachable addresses are
+// reconnected on their next send, only if none of the addresses is reachable an error is returned.
+func newBalancedTransport(addresses []string, useTLS bool, tslConfig *tls.Config, cfg config) (*balancedTransport, error) {
+	b := &balancedTransport{strategy: cfg.loadBalancing}
+	errs := make([]error, 0, len(addresses))
+	for _, address := range addresses {
+		endpoint := &balancedEndpoint{transport: newUnconnectedTCPTransport([]string{address}, useTLS, tslConfig, cfg)}
+		endpoint.transport.connLock.Lock()
+		if err := endpoint.transport.connect(); err != nil {
+			errs = append(errs, err)
+			endpoint.errors.Add(1)
+		}
+		endpoint.transport.connLock.Unlock()
+		b.endpoints = append(b.endpoints, endpoint)
+	}
+	if len(errs) == len(addresses) {
+		// Stop the supervisors of the endpoints
+		_ = b.Close()
+		return nil, errors.Join(errs...)
+	}
+	return b, nil
+}
+
+// Send sends the message to the address selected by the LoadBalancing strategy. If that fails, the remaining
+// addresses are tried in turn until the message was sent.
+func (b *balancedTransport) Send(message []byte) error {
+	return b.try(func(transport *tcpTransport) error { return transport.Send(message) })
+}
+
+// SendBatch sends the messages to the address selected by the LoadBalancing strategy like Send.
+func (b *balancedTransport) SendBatch(messages [][]byte) error {
+	return b.try(func(transport *tcpTransport) error { return transport.SendBatch(messages) })
+}
+
+// try calls send with the transport of the address selected by the LoadBalancing strategy. If it fails, send is
+// called with the transports of the remaining addresses in turn.
+func (b *balancedTransport) try(send func(transport *tcpTransport) error) error {
+	first := b.selectEndpoint()
+	errs := make([]error, 0, len(b.endpoints))
+	for i := range b.endpoints {
+		endpoint := b.endpoints[(first+i)%len(b.endpoints)]
+		if err := send(endpoint.transport); err != nil {
+			endpoint.errors.Add(1)
+			errs = append(errs, err)
+			continue
+		}
+		endpoint.errors.Store(0)
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// selectEndpoint returns the index of the endpoint the next message is sent to.
+func (b *balancedTransport) selectEndpoint() int {
+	start := int((b.next.Add(1) - 1) % uint64(len(b.endpoints)))
+	if b.strategy != LeastErrors {
+		return start
+	}
+	selected := start
+	for i := 1; i < len(b.endpoints); i++ {
+		index := (start + i) % len(b.endpoints)
+		if b.endpoints[index].errors.Load() < b.endpoints[selected].errors.Load() {
+			selected = index
+		}
+	}
+	return selected
+}
+
+// Close closes the connections to all addresses.
+func (b *balancedTransport) Close() error {
+	errs := make([]error, 0, len(b.endpoints))
+	for _, endpoint := range b.endpoints {
+		errs = append(errs, endpoint.transport.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package gelflogger_test
+
+import (
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestLoadBalancing(t *testing.T) {
+	tests := []struct {
+		name            string
+		strategy        gelflogger.LoadBalancing
+		unreachableLast bool
+		wantReceived    []int
+	}{
+		{
+			name:         "Round robin",
+			strategy:     gelflogger.RoundRobin,
+			wantReceived: []int{2, 2, 2},
+		},
+		{
+			name:            "Round robin with unreachable address",
+			strategy:        gelflogger.RoundRobin,
+			unreachableLast: true,
+			wantReceived:    []int{4, 2, 0},
+		},
+		{
+			name:            "Least errors with unreachable address",
+			strategy:        gelflogger.LeastErrors,
+			unreachableLast: true,
+			wantReceived:    []int{4, 2, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses := make([]string, 3)
+			received := make([]<-chan string, 3)
+			for i := range addresses {
+				server := helper.StartMockServer(t)
+				addresses[i] = server.Addr().String()
+				received[i] = helper.ReceiveMessages(t, server, 0)
+				if tt.unreachableLast && i == len(addresses)-1 {
+					_ = server.Close()
+				} else {
+					defer func() { _ = server.Close() }()
+				}
+			}
+
+			logger, err := gelflogger.NewLogger(addresses[0], gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			}), gelflogger.WithFailoverAddresses(addresses[1:]...), gelflogger.WithLoadBalancing(tt.strategy))
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+			for i := 0; i < 6; i++ {
+				if err := logger.Log("balanced", map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+
+			for i, want := range tt.wantReceived {
+				got := 0
+				for got < want {
+					select {
+					case <-received[i]:
+						got++
+					case <-time.After(time.Second):
+						t.Fatalf("address %d received %d messages, want %d", i, got, want)
+					}
+				}
+			}
+		})
+	}
+}
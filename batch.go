@@ -0,0 +1,103 @@
+package gelflogger
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBatchSize is the maximum number of messages of a batch if WithBatching is given a size of zero or less.
+	DefaultBatchSize = 100
+	// DefaultFlushInterval is the maximum time a message waits in a batch if WithBatching is given an interval of zero
+	// or less.
+	DefaultFlushInterval = time.Second
+)
+
+// batchSender is implemented by transports which can send multiple messages at once, e.g. the TCP transport writing
+// them with a single syscall.
+type batchSender interface {
+	SendBatch(messages [][]byte) error
+}
+
+// sendBatch sends the messages through the transport, at once if it is a batchSender, otherwise one after another.
+func sendBatch(transport Transport, messages [][]byte) error {
+	if batcher, ok := transport.(batchSender); ok {
+		return batcher.SendBatch(messages)
+	}
+	errs := make([]error, 0, len(messages))
+	for _, message := range messages {
+		errs = append(errs, transport.Send(message))
+	}
+	return errors.Join(errs...)
+}
+
+// sendBatch sends the messages through the given transport. If that fails and a fallback is configured, the messages
+// are handed to the fallback instead. Messages for the transport of the Logger go through the spool, if configured.
+// Messages which cannot be sent at all are dropped, see Logger.Dropped.
+func (l *Logger) sendBatch(transport Transport, messages [][]byte) {
+	if l.spool != nil && transport == l.transport {
+		if !l.spool.Pending() && l.sendBatchCounted(transport, messages) == nil {
+			return
+		}
+		for _, message := range messages {
+			l.dropUnsent(l.spoolMessage(message), message)
+		}
+		return
+	}
+	err := l.retry(func() error { return l.sendBatchCounted(transport, messages) })
+	if err != nil && l.fallback != nil {
+		if fallbackErr := sendBatch(l.fallback, messages); fallbackErr != nil {
+			err = errors.Join(err, fallbackErr)
+		} else {
+			err = nil
+		}
+	}
+	l.dropUnsent(err, messages...)
+}
+
+// drainBatches collects the queued messages into a batch per transport, and sends a batch once it holds batchSize
+// messages or flushInterval elapsed since the first message was queued, until the queue is closed. Messages which
+// can be sent neither through their transport nor through the fallback are dropped, see Logger.Dropped. On a flush
+// request, the messages queued at that time and all batches are sent before the request is marked done, see Flush.
+func (l *Logger) drainBatches(batchSize int, flushInterval time.Duration, flushes <-chan *sync.WaitGroup) {
+	batches := make(map[Transport][][]byte)
+	flush := func() {
+		for transport, messages := range batches {
+			l.sendBatch(transport, messages)
+			delete(batches, transport)
+		}
+	}
+	add := func(queued queuedMessage) {
+		batch := append(batches[queued.transport], queued.message)
+		if len(batch) < batchSize {
+			batches[queued.transport] = batch
+			return
+		}
+		delete(batches, queued.transport)
+		l.sendBatch(queued.transport, batch)
+	}
+	timer := time.NewTimer(flushInterval)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case queued, ok := <-l.queue:
+			if !ok {
+				flush()
+				return
+			}
+			l.dequeued(queued)
+			if len(batches) == 0 {
+				timer.Reset(flushInterval)
+			}
+			add(queued)
+		case <-timer.C:
+			flush()
+		case done := <-flushes:
+			l.takeQueued(add)
+			flush()
+			done.Done()
+		}
+	}
+}
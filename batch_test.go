@@ -0,0 +1,130 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+type batchRecordingTransport struct {
+	recordingTransport
+	batches chan int
+}
+
+func (b *batchRecordingTransport) SendBatch(messages [][]byte) error {
+	for _, message := range messages {
+		if err := b.Send(message); err != nil {
+			return err
+		}
+	}
+	b.batches <- len(messages)
+	return nil
+}
+
+func TestBatching(t *testing.T) {
+	tests := []struct {
+		name        string
+		logs        int
+		wantBatches []int
+	}{
+		{name: "Flushed when full", logs: 6, wantBatches: []int{3, 3}},
+		{name: "Flushed after the interval", logs: 2, wantBatches: []int{2}},
+		{name: "Full and partial batch", logs: 4, wantBatches: []int{3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &batchRecordingTransport{batches: make(chan int, 10)}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithBatching(3, 100*time.Millisecond))
+			for i := 0; i < tt.logs; i++ {
+				if err := logger.Log("batched", map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+			for i, want := range tt.wantBatches {
+				select {
+				case got := <-transport.batches:
+					if got != want {
+						t.Errorf("batch %d has %d messages, want %d", i+1, got, want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("batch %d was not sent", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchingTCP(t *testing.T) {
+	mockServer := helper.StartMockServer(t)
+	defer func() { _ = mockServer.Close() }()
+	messages := helper.ReceiveMessages(t, mockServer, 0)
+
+	logger, err := gelflogger.NewLogger(mockServer.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithBatching(10, 50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := logger.Log("batched", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case <-messages:
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of 5 messages", i)
+		}
+	}
+}
+
+func TestBatchingHTTP(t *testing.T) {
+	var lock sync.Mutex
+	var bodies [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var batch []map[string]interface{}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		bodies = append(bodies, batch)
+		lock.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	logger, err := gelflogger.NewLogger(server.URL, gelflogger.WithProcessor(processNothing), gelflogger.WithBatching(3, time.Minute))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := logger.Log("batched", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		lock.Lock()
+		received := len(bodies)
+		lock.Unlock()
+		if received > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not posted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(bodies) != 1 || len(bodies[0]) != 3 {
+		t.Errorf("posted %v, want a single JSON array of 3 messages", bodies)
+	}
+}
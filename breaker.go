@@ -0,0 +1,142 @@
+package gelflogger
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for messages which are not sent because the circuit breaker of the Logger is open, see
+// WithCircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit breaker open, Graylog is considered unavailable")
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	// breakerClosed lets all messages pass.
+	breakerClosed breakerState = iota
+	// breakerOpen rejects all messages until the open duration elapsed.
+	breakerOpen
+	// breakerHalfOpen lets a single probe message pass, deciding whether the breaker closes or opens again.
+	breakerHalfOpen
+)
+
+// circuitBreaker wraps the transport of a Logger and stops sending through it after a number of consecutive send
+// failures. Only temporary failures count, see isTemporary, as the permanent errors of single messages, e.g.
+// ErrMessageTooLarge, say nothing about the availability of Graylog. While the breaker is open, messages are rejected
+// with ErrCircuitOpen right away, so the Logger hands them to the fallback without waiting for the failing transport. Once the open duration elapsed, the breaker half-opens
+// and lets a single message probe the transport: if it is sent, the breaker closes, otherwise it opens again.
+type circuitBreaker struct {
+	transport    Transport
+	maxFailures  int
+	openDuration time.Duration
+
+	lock     sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// newCircuitBreaker creates a closed circuitBreaker around the transport.
+func newCircuitBreaker(transport Transport, maxFailures int, openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{transport: transport, maxFailures: maxFailures, openDuration: openDuration}
+}
+
+// allow reports whether a message may be sent through the transport, half-opening the breaker once the open
+// duration elapsed.
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// A probe is in flight, the other messages are rejected until it decided the state
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the state of the breaker with the result of a send. A permanent error closes a half-open breaker,
+// as the transport was reached, but neither resets nor increments the count of consecutive failures.
+func (b *circuitBreaker) record(err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err == nil {
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+	if !errors.Is(err, ErrTemporary) && !isTemporary(err) {
+		if b.state == breakerHalfOpen {
+			b.state = breakerClosed
+		}
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.maxFailures {
+		b.state, b.openedAt = breakerOpen, time.Now()
+	}
+}
+
+// Send sends the message through the transport, unless the breaker is open.
+func (b *circuitBreaker) Send(message []byte) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := b.transport.Send(message)
+	b.record(err)
+	return err
+}
+
+// SendBatch sends the messages through the transport, unless the breaker is open.
+func (b *circuitBreaker) SendBatch(messages [][]byte) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := sendBatch(b.transport, messages)
+	b.record(err)
+	return err
+}
+
+// ensureConnection checks the connection of the transport, unless the breaker is open. Once the open duration
+// elapsed, the check is the probe of the half-open breaker, so writers checking the connection before each message,
+// see GelfWriter, recover as well. Failed checks count like failed sends.
+func (b *circuitBreaker) ensureConnection() error {
+	checker, ok := b.transport.(connectionChecker)
+	if !ok {
+		return nil
+	}
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := checker.ensureConnection()
+	b.recordCheck(err)
+	return err
+}
+
+// recordCheck updates the state of the breaker with the result of a connection check. A successful check closes a
+// half-open breaker, but does not reset the failures of a closed one, as the sends may fail nonetheless.
+func (b *circuitBreaker) recordCheck(err error) {
+	if err != nil {
+		b.record(err)
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == breakerHalfOpen {
+		b.state, b.failures = breakerClosed, 0
+	}
+}
+
+// Close closes the transport.
+func (b *circuitBreaker) Close() error {
+	return b.transport.Close()
+}
@@ -0,0 +1,167 @@
+package gelflogger_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// countingTransport counts the sends, which fail temporarily while it is down, or with err if set, and the connection
+// checks.
+type countingTransport struct {
+	lock   sync.Mutex
+	down   bool
+	err    error
+	calls  int
+	checks int
+}
+
+func (c *countingTransport) Send([]byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls++
+	if c.err != nil {
+		return c.err
+	}
+	if c.down {
+		return fmt.Errorf("%w: transport down", gelflogger.ErrNotConnected)
+	}
+	return nil
+}
+
+func (c *countingTransport) Close() error { return nil }
+
+// check checks the connection like the TCP transport, failing while the transport is down.
+func (c *countingTransport) check() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.checks++
+	if c.down {
+		return fmt.Errorf("%w: transport down", gelflogger.ErrNotConnected)
+	}
+	return nil
+}
+
+func (c *countingTransport) set(down bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.down = down
+	return c.calls
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	transport := &countingTransport{down: true}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithCircuitBreaker(3, 50*time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		err := logger.Log("failing", map[string]interface{}{})
+		if i >= 3 && !errors.Is(err, gelflogger.ErrCircuitOpen) {
+			t.Fatalf("Log() #%d error = %v, want ErrCircuitOpen", i+1, err)
+		}
+	}
+	if calls := transport.set(true); calls != 3 {
+		t.Fatalf("transport was called %d times, want 3 before the breaker opened", calls)
+	}
+
+	// The probe after the open duration fails, so the breaker opens again
+	time.Sleep(60 * time.Millisecond)
+	if err := logger.Log("probe", map[string]interface{}{}); err == nil || errors.Is(err, gelflogger.ErrCircuitOpen) {
+		t.Fatalf("Log() error = %v, want the error of the probe", err)
+	}
+	if err := logger.Log("rejected", map[string]interface{}{}); !errors.Is(err, gelflogger.ErrCircuitOpen) {
+		t.Fatalf("Log() error = %v, want ErrCircuitOpen after the failed probe", err)
+	}
+
+	// The probe after recovery closes the breaker
+	transport.set(false)
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if err := logger.Log("recovered", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v after recovery", err)
+		}
+	}
+	if calls := transport.set(false); calls != 7 {
+		t.Errorf("transport was called %d times, want 7", calls)
+	}
+}
+
+func TestCircuitBreakerWriter(t *testing.T) {
+	transport := &countingTransport{down: true}
+	checked := gelflogger.CheckedTransport{Transport: transport, Check: transport.check}
+	writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(checked, processNothing,
+		gelflogger.WithCircuitBreaker(2, 50*time.Millisecond))}
+
+	for i := 0; i < 5; i++ {
+		_, err := writer.Write([]byte(`{"message":"failing"}`))
+		if !errors.Is(err, gelflogger.ErrTemporary) {
+			t.Fatalf("Write() #%d error = %v, want ErrTemporary", i+1, err)
+		}
+		if i >= 2 && !errors.Is(err, gelflogger.ErrCircuitOpen) {
+			t.Fatalf("Write() #%d error = %v, want ErrCircuitOpen", i+1, err)
+		}
+	}
+	transport.lock.Lock()
+	checks := transport.checks
+	transport.lock.Unlock()
+	if checks != 2 {
+		t.Fatalf("connection was checked %d times, want 2 before the breaker opened", checks)
+	}
+
+	// The check after the open duration probes the recovered transport and closes the breaker
+	transport.set(false)
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := writer.Write([]byte(`{"message":"recovered"}`)); err != nil {
+			t.Fatalf("Write() error = %v after recovery", err)
+		}
+	}
+	if calls := transport.set(false); calls != 3 {
+		t.Errorf("transport was called %d times, want 3", calls)
+	}
+}
+
+func TestCircuitBreakerPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "Message too large", err: fmt.Errorf("%w: 2 MiB", gelflogger.ErrMessageTooLarge)},
+		{name: "Invalid message", err: fmt.Errorf("%w: missing short_message", gelflogger.ErrInvalidMessage)},
+		{name: "Classified permanent", err: fmt.Errorf("%w: rejected", gelflogger.ErrPermanent)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &countingTransport{err: tt.err}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithCircuitBreaker(3, time.Minute))
+
+			for i := 0; i < 10; i++ {
+				if err := logger.Log("rejected", map[string]interface{}{}); errors.Is(err, gelflogger.ErrCircuitOpen) {
+					t.Fatalf("Log() #%d error = %v, want the breaker to stay closed", i+1, err)
+				}
+			}
+			if calls := transport.set(false); calls != 10 {
+				t.Errorf("transport was called %d times, want 10", calls)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerFallback(t *testing.T) {
+	fallback := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(&countingTransport{down: true}, processNothing,
+		gelflogger.WithCircuitBreaker(1, time.Minute), gelflogger.WithFallback(fallback))
+
+	for i := 0; i < 5; i++ {
+		if err := logger.Log("diverted", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	if len(fallback.messages) != 5 {
+		t.Errorf("fallback received %d messages, want 5", len(fallback.messages))
+	}
+}
@@ -0,0 +1,91 @@
+package gelflogger
+
+import (
+	"sync"
+)
+
+const (
+	// maxPooledBufferSize is the capacity up to which the buffers of encoding messages are reused, so a single huge
+	// message does not pin its buffer.
+	maxPooledBufferSize = 64 << 10
+	// maxPooledFields is the number of fields up to which the field maps are reused, for the same reason.
+	maxPooledFields = 256
+)
+
+// objectPool is the part of sync.Pool used by the pools below, so the benchmarks comparing the allocations with and
+// without pooling can replace them by pools allocating every object.
+type objectPool interface {
+	Get() interface{}
+	Put(x interface{})
+}
+
+var (
+	// encodeBuffers are the buffers the Loggers encode their messages in, see Logger.formatGELFMessage.
+	encodeBuffers objectPool = &sync.Pool{New: newEncodeBuffer}
+	// fieldMaps are the temporary maps of fields, e.g. the fields decoded by a GelfWriter or the flattened fields.
+	fieldMaps objectPool = &sync.Pool{New: newFieldMap}
+	// messages are the Messages built by the log calls, see Logger.releaseMessage.
+	messages objectPool = &sync.Pool{New: newMessage}
+)
+
+// newEncodeBuffer allocates a buffer of encodeBuffers.
+func newEncodeBuffer() interface{} {
+	buf := make([]byte, 0, 1024)
+	return &buf
+}
+
+// newFieldMap allocates a map of fieldMaps.
+func newFieldMap() interface{} {
+	return make(map[string]interface{}, 16)
+}
+
+// newMessage allocates a Message of messages.
+func newMessage() interface{} {
+	return new(Message)
+}
+
+// getBuffer returns an empty buffer for encoding a message, to be returned by putBuffer.
+func getBuffer() *[]byte {
+	buf := encodeBuffers.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putBuffer returns the buffer with its content encoded into the grown slice to the pool.
+func putBuffer(buf *[]byte, grown []byte) {
+	if cap(grown) > maxPooledBufferSize {
+		return
+	}
+	*buf = grown
+	encodeBuffers.Put(buf)
+}
+
+// getFieldMap returns an empty map of fields, to be returned by putFieldMap once it is not referenced anymore.
+func getFieldMap() map[string]interface{} {
+	return fieldMaps.Get().(map[string]interface{})
+}
+
+// putFieldMap clears the map of fields and returns it to the pool.
+func putFieldMap(fields map[string]interface{}) {
+	if fields == nil || len(fields) > maxPooledFields {
+		return
+	}
+	clear(fields)
+	fieldMaps.Put(fields)
+}
+
+// getMessage returns an empty Message, to be returned by Logger.releaseMessage.
+func getMessage() *Message {
+	return messages.Get().(*Message)
+}
+
+// releaseMessage returns the Message of a log call and the fields map it owns to the pools, once it was delivered.
+// Messages are kept by a deduplicating Logger, as the deduplicator holds on to the last one, see WithDeduplication.
+func (l *Logger) releaseMessage(msg *Message) {
+	if l.dedup != nil {
+		return
+	}
+	putFieldMap(msg.ownedFields)
+	*msg = Message{}
+	messages.Put(msg)
+}
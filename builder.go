@@ -0,0 +1,81 @@
+package gelflogger
+
+import "time"
+
+// MessageBuilder builds a GELF message step by step and sends it with a Logger, a typed alternative to Log and to the
+// zerolog and zap adapters:
+//
+//	err := gelflogger.NewMessage("payment failed").
+//		Level(gelflogger.Error).
+//		Field("order_id", 42).
+//		FullMessage(stack).
+//		Send(logger)
+//
+// The level and the timestamp are taken as they are, the fields are not handed to the processor of the Logger, see
+// LogAt. The static fields, the Processor chain, sampling, deduplication, routing and encoding of the Logger apply
+// like for Log.
+// A MessageBuilder is not safe for concurrent use, and must not be changed after Send, as the Logger may keep the
+// fields, e.g. to deduplicate messages.
+type MessageBuilder struct {
+	shortMessage string
+	fullMessage  string
+	level        int
+	timestamp    time.Time
+	fields       map[string]interface{}
+}
+
+// NewMessage returns a MessageBuilder of a message with the given short message, informational level and no fields.
+func NewMessage(shortMessage string) *MessageBuilder {
+	return &MessageBuilder{shortMessage: shortMessage, level: Informational}
+}
+
+// Level sets the Graylog (Syslog) level of the message, from Emergency to Debug.
+func (b *MessageBuilder) Level(level int) *MessageBuilder {
+	b.level = level
+	return b
+}
+
+// FullMessage sets the full message, e.g. a stack trace. It takes precedence over the FullMessageMode of the Logger,
+// see WithFullMessage; without, the full message is taken from the field of FullMessageFromField, or left out.
+func (b *MessageBuilder) FullMessage(fullMessage string) *MessageBuilder {
+	b.fullMessage = fullMessage
+	return b
+}
+
+// Timestamp sets the time of the message, the time of Send if not set.
+func (b *MessageBuilder) Timestamp(t time.Time) *MessageBuilder {
+	b.timestamp = t
+	return b
+}
+
+// Field sets the additional field of the given name, replacing a field of the same name.
+func (b *MessageBuilder) Field(name string, value interface{}) *MessageBuilder {
+	if b.fields == nil {
+		b.fields = make(map[string]interface{})
+	}
+	b.fields[name] = value
+	return b
+}
+
+// Fields sets the given additional fields, replacing fields of the same names.
+func (b *MessageBuilder) Fields(fields map[string]interface{}) *MessageBuilder {
+	for name, value := range fields {
+		b.Field(name, value)
+	}
+	return b
+}
+
+// Err sets the error as "error" field, sent as its message, with its details, e.g. a stack trace, appended to the full
+// message, see Log. A nil error is ignored.
+func (b *MessageBuilder) Err(err error) *MessageBuilder {
+	if err == nil {
+		return b
+	}
+	return b.Field("error", err)
+}
+
+// Send sends the message with the Logger. It returns the errors of Log, nil if the message was filtered out, sampled
+// out or suppressed as repetition.
+func (b *MessageBuilder) Send(l *Logger) error {
+	return l.logAt(b.level, b.timestamp, b.shortMessage, b.fullMessage, b.fields)
+}
@@ -0,0 +1,100 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() *gelflogger.MessageBuilder
+		opts    []gelflogger.Option
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name:    "Defaults",
+			build:   func() *gelflogger.MessageBuilder { return gelflogger.NewMessage("started") },
+			want:    map[string]interface{}{"short_message": "started", "level": float64(6)},
+			missing: []string{"full_message"},
+		},
+		{
+			name: "Level, timestamp, fields and full message",
+			build: func() *gelflogger.MessageBuilder {
+				return gelflogger.NewMessage("payment failed").
+					Level(gelflogger.Error).
+					Timestamp(time.Unix(1700000000, 500000000)).
+					Field("order_id", 42).
+					Fields(map[string]interface{}{"customer": "c-1", "order_id": 43}).
+					FullMessage("stack")
+			},
+			want: map[string]interface{}{
+				"short_message": "payment failed",
+				"level":         float64(3),
+				"timestamp":     1700000000.5,
+				"full_message":  "stack",
+				"_order_id":     float64(43),
+				"_customer":     "c-1",
+			},
+		},
+		{
+			name: "Level and time fields are additional fields",
+			build: func() *gelflogger.MessageBuilder {
+				return gelflogger.NewMessage("imported").Field("level", "debug").Field("time", "yesterday")
+			},
+			want: map[string]interface{}{"level": float64(6), "_level": "debug", "_time": "yesterday"},
+		},
+		{
+			name: "Error",
+			build: func() *gelflogger.MessageBuilder {
+				return gelflogger.NewMessage("failed").Err(errors.New("timeout")).Err(nil)
+			},
+			want: map[string]interface{}{"_error": "timeout"},
+		},
+		{
+			name:    "Full message from field",
+			build:   func() *gelflogger.MessageBuilder { return gelflogger.NewMessage("failed").Field("stack", "trace") },
+			opts:    []gelflogger.Option{gelflogger.WithFullMessageField("stack")},
+			want:    map[string]interface{}{"full_message": "trace"},
+			missing: []string{"_stack"},
+		},
+		{
+			name:  "Static fields",
+			build: func() *gelflogger.MessageBuilder { return gelflogger.NewMessage("started") },
+			opts:  []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"service": "billing"})},
+			want:  map[string]interface{}{"_service": "billing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, tt.opts...)
+			if err := tt.build().Send(logger); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(transport.messages))
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			for key, want := range tt.want {
+				if gelfMsg[key] != want {
+					t.Errorf("%s = %v, want %v in %s", key, gelfMsg[key], want, transport.messages[0])
+				}
+			}
+			for _, key := range tt.missing {
+				if _, ok := gelfMsg[key]; ok {
+					t.Errorf("sent %s, want no %s", transport.messages[0], key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestBuildInfoOf(t *testing.T) {
+	tests := []struct {
+		name string
+		info *debug.BuildInfo
+		want gelflogger.BuildInfo
+	}{
+		{
+			name: "Release build",
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "example.com/billing", Version: "v1.4.2"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs", Value: "git"},
+					{Key: "vcs.revision", Value: "3f9a2c1e"},
+					{Key: "vcs.time", Value: "2024-05-02T10:31:00Z"},
+					{Key: "vcs.modified", Value: "false"},
+				},
+			},
+			want: gelflogger.BuildInfo{AppVersion: "v1.4.2", VCSRevision: "3f9a2c1e", BuildTime: "2024-05-02T10:31:00Z"},
+		},
+		{
+			name: "Modified working tree",
+			info: &debug.BuildInfo{
+				Main:     debug.Module{Path: "example.com/billing", Version: "(devel)"},
+				Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "3f9a2c1e"}, {Key: "vcs.modified", Value: "true"}},
+			},
+			want: gelflogger.BuildInfo{VCSRevision: "3f9a2c1e-dirty"},
+		},
+		{
+			name: "Development build without VCS",
+			info: &debug.BuildInfo{Main: debug.Module{Path: "example.com/billing", Version: "(devel)"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gelflogger.BuildInfoOf(tt.info); got != tt.want {
+				t.Errorf("BuildInfoOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBuildInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []gelflogger.Option
+		want map[string]interface{}
+	}{
+		{
+			name: "Overridden build info",
+			opts: []gelflogger.Option{
+				gelflogger.WithBuildInfo(gelflogger.BuildInfo{AppVersion: "1.4.2", VCSRevision: "3f9a2c1e"}),
+				gelflogger.WithBuildInfo(gelflogger.BuildInfo{BuildTime: "2024-05-02T10:31:00Z"}),
+			},
+			want: map[string]interface{}{"_app_version": "1.4.2", "_vcs_revision": "3f9a2c1e", "_build_time": "2024-05-02T10:31:00Z"},
+		},
+		{
+			name: "Static fields take precedence",
+			opts: []gelflogger.Option{
+				gelflogger.WithStaticFields(map[string]interface{}{"app_version": "1.4.2-hotfix"}),
+				gelflogger.WithBuildInfo(gelflogger.BuildInfo{AppVersion: "1.4.2"}),
+			},
+			want: map[string]interface{}{"_app_version": "1.4.2-hotfix", "_vcs_revision": nil},
+		},
+		{
+			name: "Without build info",
+			opts: []gelflogger.Option{
+				gelflogger.WithBuildInfo(gelflogger.BuildInfo{AppVersion: "1.4.2"}),
+				gelflogger.WithoutBuildInfo(),
+			},
+			want: map[string]interface{}{"_app_version": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			if err := logger.Log("built", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			for name, want := range tt.want {
+				if gelfMsg[name] != want {
+					t.Errorf("field %s = %v, want %v", name, gelfMsg[name], want)
+				}
+			}
+		})
+	}
+}
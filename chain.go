@@ -0,0 +1,146 @@
+package gelflogger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// SinkStats holds the counters of a single sink of a FallbackChain.
+type SinkStats struct {
+	// Sent is the number of messages the sink accepted.
+	Sent uint64
+	// Failed is the number of messages the sink failed to send, they cascaded to the next sink.
+	Failed uint64
+}
+
+// FallbackChain is a Transport passing every message down an ordered chain of sinks, e.g. GELF TCP, then GELF UDP,
+// then a local file and finally stderr. A message cascades to the next sink only once the previous one failed to send
+// it, so the first sink which is available takes it. It can be used as transport of a Logger, or as its fallback,
+// see WithFallback.
+type FallbackChain struct {
+	sinks []*chainSink
+}
+
+// chainSink is a single sink of a FallbackChain together with its counters.
+type chainSink struct {
+	transport Transport
+	sent      atomic.Uint64
+	failed    atomic.Uint64
+}
+
+var _ Transport = (*FallbackChain)(nil)
+
+// NewFallbackChain creates a FallbackChain trying the sinks in the given order.
+//
+// Example usage:
+//
+//	udp, _ := gelflogger.NewTransport("udp://graylog.example.com", false, nil)
+//	file, _ := gelflogger.NewFileFallback("/var/log/app/gelf-fallback.log", 100<<20, 5)
+//	chain := gelflogger.NewFallbackChain(udp, file, gelflogger.NewWriterTransport(os.Stderr))
+//	logger, err := gelflogger.NewLogger("graylog.example.com:12201", gelflogger.WithProcessor(zerologger.ProcessZerologFields), gelflogger.WithFallback(chain))
+func NewFallbackChain(sinks ...Transport) *FallbackChain {
+	c := &FallbackChain{sinks: make([]*chainSink, 0, len(sinks))}
+	for _, transport := range sinks {
+		c.sinks = append(c.sinks, &chainSink{transport: transport})
+	}
+	return c
+}
+
+// Send sends the message through the first sink accepting it. Only if all sinks fail, their errors are returned.
+func (c *FallbackChain) Send(message []byte) error {
+	return c.cascade(func(transport Transport) error {
+		return transport.Send(message)
+	}, 1)
+}
+
+// SendBatch sends the messages through the first sink accepting all of them at once.
+func (c *FallbackChain) SendBatch(messages [][]byte) error {
+	return c.cascade(func(transport Transport) error {
+		return sendBatch(transport, messages)
+	}, uint64(len(messages)))
+}
+
+// cascade calls send with the sinks in order until it succeeds, counting the given number of messages.
+func (c *FallbackChain) cascade(send func(transport Transport) error, count uint64) error {
+	errs := make([]error, 0, len(c.sinks))
+	for _, sink := range c.sinks {
+		err := send(sink.transport)
+		if err == nil {
+			sink.sent.Add(count)
+			return nil
+		}
+		sink.failed.Add(count)
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return errors.New("fallback chain has no sinks")
+	}
+	return errors.Join(errs...)
+}
+
+// Replay replays the messages kept by the sinks which keep messages, e.g. a FileFallback, see Logger.ReplayFallback.
+// Every sink is replayed, even if the replay of an earlier one failed.
+func (c *FallbackChain) Replay(send func(message []byte) error) error {
+	return c.replay(send, nil)
+}
+
+// replay replays the sinks like Replay, and reports the messages skipped by the sinks to drop, see dropReplayer.
+func (c *FallbackChain) replay(send func(message []byte) error, drop func(message []byte, reason error)) error {
+	var errs []error
+	for _, sink := range c.sinks {
+		switch r := sink.transport.(type) {
+		case dropReplayer:
+			errs = append(errs, r.replay(send, drop))
+		case replayer:
+			errs = append(errs, r.Replay(send))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the counters of the sinks, in the order they were passed to NewFallbackChain.
+func (c *FallbackChain) Stats() []SinkStats {
+	stats := make([]SinkStats, 0, len(c.sinks))
+	for _, sink := range c.sinks {
+		stats = append(stats, SinkStats{Sent: sink.sent.Load(), Failed: sink.failed.Load()})
+	}
+	return stats
+}
+
+// Close closes all sinks.
+func (c *FallbackChain) Close() error {
+	errs := make([]error, 0, len(c.sinks))
+	for _, sink := range c.sinks {
+		errs = append(errs, sink.transport.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// WriterTransport is a Transport writing GELF messages as JSON lines to an io.Writer, e.g. os.Stderr as last sink of
+// a FallbackChain.
+type WriterTransport struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+var _ Transport = (*WriterTransport)(nil)
+
+// NewWriterTransport creates a WriterTransport writing to the given writer.
+func NewWriterTransport(writer io.Writer) *WriterTransport {
+	return &WriterTransport{writer: writer}
+}
+
+// Send writes the message as a line to the writer.
+func (w *WriterTransport) Send(message []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, err := w.writer.Write(append(message[:len(message):len(message)], '\n'))
+	return err
+}
+
+// Close does nothing, the writer is owned by the caller.
+func (w *WriterTransport) Close() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package gelflogger_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestFallbackChain(t *testing.T) {
+	primary := &recordingTransport{down: true}
+	secondary := &recordingTransport{failAfter: 1}
+	var stderr bytes.Buffer
+	chain := gelflogger.NewFallbackChain(primary, secondary, gelflogger.NewWriterTransport(&stderr))
+
+	for _, message := range []string{"1", "2", "3"} {
+		if err := chain.Send([]byte(message)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	want := []gelflogger.SinkStats{{Failed: 3}, {Sent: 1, Failed: 2}, {Sent: 2}}
+	if got := chain.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(secondary.messages, []string{"1"}) {
+		t.Errorf("secondary sink received %v, want [1]", secondary.messages)
+	}
+	if stderr.String() != "2\n3\n" {
+		t.Errorf("last sink received %q, want the messages 2 and 3 as lines", stderr.String())
+	}
+
+	failing := gelflogger.NewFallbackChain(&recordingTransport{down: true}, &recordingTransport{down: true})
+	if err := failing.Send([]byte("lost")); err == nil {
+		t.Error("Send() error = nil, want an error if all sinks fail")
+	}
+}
+
+func TestFallbackChainReplay(t *testing.T) {
+	file, err := gelflogger.NewFileFallback(filepath.Join(t.TempDir(), "fallback.log"), 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileFallback() error = %v", err)
+	}
+	transport := &recordingTransport{down: true}
+	chain := gelflogger.NewFallbackChain(&recordingTransport{down: true}, file)
+	defer func() { _ = chain.Close() }()
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithFallback(chain))
+
+	if err := logger.Log("kept", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	transport.down = false
+	if err := logger.ReplayFallback(); err != nil {
+		t.Fatalf("ReplayFallback() error = %v", err)
+	}
+	if len(transport.messages) != 1 {
+		t.Errorf("replayed %d messages, want 1", len(transport.messages))
+	}
+}
@@ -0,0 +1,105 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+type closeRecordingTransport struct {
+	recordingTransport
+	closed atomic.Bool
+}
+
+func (c *closeRecordingTransport) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []gelflogger.Option
+	}{
+		{name: "Synchronous"},
+		{name: "Asynchronous", opts: []gelflogger.Option{gelflogger.WithAsync(100, 2)}},
+		{name: "Batching", opts: []gelflogger.Option{gelflogger.WithBatching(10, time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &closeRecordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			for i := 0; i < 25; i++ {
+				if err := logger.Log("pending", map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+
+			if err := logger.Close(context.Background()); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if len(transport.messages) != 25 {
+				t.Errorf("transport received %d messages, want all 25 pending ones", len(transport.messages))
+			}
+			if !transport.closed.Load() {
+				t.Error("transport was not closed")
+			}
+			if err := logger.Log("late", map[string]interface{}{}); !errors.Is(err, gelflogger.ErrLoggerClosed) {
+				t.Errorf("Log() after Close() error = %v, want ErrLoggerClosed", err)
+			}
+			if err := logger.Close(context.Background()); err != nil {
+				t.Errorf("second Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCloseDeadline(t *testing.T) {
+	blocking := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+	defer close(blocking.release)
+	logger := gelflogger.NewLoggerWithTransport(blocking, processNothing, gelflogger.WithAsync(1, 1))
+	if err := logger.Log("stuck", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	<-blocking.started
+	if err := logger.Log("queued", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	// A caller blocked on the full queue is released by Close
+	blocked := make(chan error, 1)
+	go func() { blocked <- logger.Log("blocked", map[string]interface{}{}) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := logger.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want context.DeadlineExceeded", err)
+	}
+	select {
+	case err := <-blocked:
+		if !errors.Is(err, gelflogger.ErrLoggerClosed) {
+			t.Errorf("blocked Log() error = %v, want ErrLoggerClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked Log() was not released by Close()")
+	}
+}
+
+func TestGelfWriterClose(t *testing.T) {
+	transport := &closeRecordingTransport{}
+	writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(transport, processNothing)}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !transport.closed.Load() {
+		t.Error("transport was not closed")
+	}
+	if _, err := writer.Write([]byte(`{"message":"late"}`)); !errors.Is(err, gelflogger.ErrLoggerClosed) {
+		t.Errorf("Write() after Close() error = %v, want ErrLoggerClosed", err)
+	}
+}
@@ -0,0 +1,208 @@
+package gelflogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CloudMetadataTimeout is the maximum time WithCloudMetadata waits for the instance metadata service. Outside a
+// cloud, the requests to it usually time out, so the time is kept short.
+const CloudMetadataTimeout = time.Second
+
+// cloudMetadataEndpoint is the address of the instance metadata services of EC2, GCE and Azure.
+const cloudMetadataEndpoint = "http://169.254.169.254"
+
+// maxCloudMetadataSize is the maximum size of a response of the instance metadata service read.
+const maxCloudMetadataSize = 64 << 10
+
+// The cached cloud metadata, see WithCloudMetadata.
+var (
+	cloudMetadataOnce   sync.Once
+	cloudMetadataFields map[string]interface{}
+)
+
+// cloudProvider looks up the metadata of the instance from the instance metadata service of a cloud provider. It
+// returns an error if the service does not answer like the one of the provider.
+type cloudProvider func(ctx context.Context, client *http.Client, endpoint string) (map[string]interface{}, error)
+
+// cloudProviders are the cloud providers asked by WithCloudMetadata.
+var cloudProviders = []cloudProvider{ec2Metadata, gceMetadata, azureMetadata}
+
+// WithCloudMetadata adds the metadata of the cloud instance the process runs on to every message, read from the
+// instance metadata service of EC2 (IMDSv2), GCE or Azure:
+//
+//   - _cloud_provider: aws, gcp or azure.
+//   - _cloud_instance_id: The ID of the instance.
+//   - _cloud_instance_type: The instance or machine type, e.g. m5.large, e2-medium or Standard_D2s_v3.
+//   - _cloud_region and _cloud_zone: The region and availability zone, if the instance is placed in one.
+//
+// The providers are asked at once, waiting at most CloudMetadataTimeout, which delays the creation of the first Logger
+// using the Option if the process does not run in a cloud. The metadata is looked up once per process and cached for
+// later Loggers. If no provider answers, no fields are added. They are static fields, see WithStaticFields.
+func WithCloudMetadata() Option {
+	cloudMetadataOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), CloudMetadataTimeout)
+		defer cancel()
+		cloudMetadataFields = cloudMetadata(ctx, cloudMetadataEndpoint)
+	})
+	return WithStaticFields(cloudMetadataFields)
+}
+
+// cloudMetadata asks all cloud providers at the given endpoint at once and returns the metadata of the first one
+// answering, or nil if none answers before the context is done.
+func cloudMetadata(ctx context.Context, endpoint string) map[string]interface{} {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client := &http.Client{
+		// The metadata services must be reached directly, never through a proxy of the environment.
+		Transport: &http.Transport{Proxy: nil},
+	}
+	defer client.CloseIdleConnections()
+
+	results := make(chan map[string]interface{}, len(cloudProviders))
+	for _, provider := range cloudProviders {
+		go func() {
+			metadata, err := provider(ctx, client, endpoint)
+			if err != nil {
+				metadata = nil
+			}
+			results <- metadata
+		}()
+	}
+	for range cloudProviders {
+		if metadata := <-results; metadata != nil {
+			return metadata
+		}
+	}
+	return nil
+}
+
+// ec2Metadata returns the metadata of an EC2 instance, using a session token of IMDSv2.
+func ec2Metadata(ctx context.Context, client *http.Client, endpoint string) (map[string]interface{}, error) {
+	_, token, err := cloudMetadataRequest(ctx, client, http.MethodPut, endpoint+"/latest/api/token",
+		map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := cloudMetadataRequest(ctx, client, http.MethodGet, endpoint+"/latest/dynamic/instance-identity/document",
+		map[string]string{"X-aws-ec2-metadata-token": string(token)})
+	if err != nil {
+		return nil, err
+	}
+	var document struct {
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+	if document.InstanceID == "" {
+		return nil, fmt.Errorf("EC2 instance identity document without instance ID")
+	}
+	return cloudFields("aws", document.InstanceID, document.InstanceType, document.Region, document.AvailabilityZone), nil
+}
+
+// gceMetadata returns the metadata of a GCE instance.
+func gceMetadata(ctx context.Context, client *http.Client, endpoint string) (map[string]interface{}, error) {
+	values := make(map[string]string, 3)
+	for _, name := range []string{"id", "machine-type", "zone"} {
+		header, body, err := cloudMetadataRequest(ctx, client, http.MethodGet, endpoint+"/computeMetadata/v1/instance/"+name,
+			map[string]string{"Metadata-Flavor": "Google"})
+		if err != nil {
+			return nil, err
+		}
+		// Only the metadata server of GCE answers with the header, so other services are not mistaken for it.
+		if header.Get("Metadata-Flavor") != "Google" {
+			return nil, fmt.Errorf("GCE metadata server: unexpected Metadata-Flavor %q", header.Get("Metadata-Flavor"))
+		}
+		values[name] = strings.TrimSpace(string(body))
+	}
+	if values["id"] == "" {
+		return nil, fmt.Errorf("GCE instance metadata without instance ID")
+	}
+	// The machine type and zone are paths, e.g. projects/123/zones/europe-west1-b, the region is the zone without its
+	// suffix.
+	zone := lastPathElement(values["zone"])
+	region := zone
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+	return cloudFields("gcp", values["id"], lastPathElement(values["machine-type"]), region, zone), nil
+}
+
+// azureMetadata returns the metadata of an Azure virtual machine.
+func azureMetadata(ctx context.Context, client *http.Client, endpoint string) (map[string]interface{}, error) {
+	_, body, err := cloudMetadataRequest(ctx, client, http.MethodGet, endpoint+"/metadata/instance/compute?api-version=2021-02-01",
+		map[string]string{"Metadata": "true"})
+	if err != nil {
+		return nil, err
+	}
+	var compute struct {
+		VMID     string `json:"vmId"`
+		VMSize   string `json:"vmSize"`
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+	}
+	if err := json.Unmarshal(body, &compute); err != nil {
+		return nil, err
+	}
+	if compute.VMID == "" {
+		return nil, fmt.Errorf("Azure instance metadata without VM ID")
+	}
+	zone := compute.Zone
+	if zone != "" {
+		// The zones of Azure are numbered per region, e.g. 1, so the region is prepended to make them unique.
+		zone = compute.Location + "-" + zone
+	}
+	return cloudFields("azure", compute.VMID, compute.VMSize, compute.Location, zone), nil
+}
+
+// cloudMetadataRequest sends a request with the given headers to the instance metadata service and returns the
+// header and body of the response. An error is returned unless the status is 200 OK.
+func cloudMetadataRequest(ctx context.Context, client *http.Client, method, url string, headers map[string]string) (http.Header, []byte, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("instance metadata service: %s %s: %s", method, url, response.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxCloudMetadataSize))
+	return response.Header, body, err
+}
+
+// cloudFields returns the fields of the cloud metadata, see WithCloudMetadata, omitting the empty ones.
+func cloudFields(provider, instanceID, instanceType, region, zone string) map[string]interface{} {
+	fields := map[string]interface{}{"cloud_provider": provider, "cloud_instance_id": instanceID}
+	for name, value := range map[string]string{
+		"cloud_instance_type": instanceType,
+		"cloud_region":        region,
+		"cloud_zone":          zone,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// lastPathElement returns the part of the path after the last slash.
+func lastPathElement(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
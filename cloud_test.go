@@ -0,0 +1,122 @@
+package gelflogger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// ec2MetadataService is the handler of a fake instance metadata service of EC2, requiring an IMDSv2 token.
+func ec2MetadataService(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" && r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") != "":
+		_, _ = w.Write([]byte("token-1"))
+	case r.URL.Path == "/latest/dynamic/instance-identity/document" && r.Header.Get("X-aws-ec2-metadata-token") == "token-1":
+		_, _ = w.Write([]byte(`{"accountId":"123456789012","availabilityZone":"eu-central-1a","instanceId":"i-0abc123",` +
+			`"instanceType":"m5.large","region":"eu-central-1"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// gceMetadataService is the handler of a fake metadata server of GCE.
+func gceMetadataService(w http.ResponseWriter, r *http.Request) {
+	values := map[string]string{
+		"/computeMetadata/v1/instance/id":           "4520031799277581759",
+		"/computeMetadata/v1/instance/machine-type": "projects/123/machineTypes/e2-medium",
+		"/computeMetadata/v1/instance/zone":         "projects/123/zones/europe-west1-b",
+	}
+	value, ok := values[r.URL.Path]
+	if !ok || r.Header.Get("Metadata-Flavor") != "Google" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Metadata-Flavor", "Google")
+	_, _ = w.Write([]byte(value))
+}
+
+// azureMetadataService is the handler of a fake instance metadata service of Azure.
+func azureMetadataService(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/metadata/instance/compute" || r.Header.Get("Metadata") != "true" {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write([]byte(`{"location":"westeurope","vmId":"02aab8a4-74ef-476e-8182-f6d2ba4166a6","vmSize":"Standard_D2s_v3","zone":"2"}`))
+}
+
+func TestCloudMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    map[string]interface{}
+	}{
+		{
+			name:    "EC2",
+			handler: ec2MetadataService,
+			want: map[string]interface{}{
+				"cloud_provider": "aws", "cloud_instance_id": "i-0abc123", "cloud_instance_type": "m5.large",
+				"cloud_region": "eu-central-1", "cloud_zone": "eu-central-1a",
+			},
+		},
+		{
+			name:    "GCE",
+			handler: gceMetadataService,
+			want: map[string]interface{}{
+				"cloud_provider": "gcp", "cloud_instance_id": "4520031799277581759", "cloud_instance_type": "e2-medium",
+				"cloud_region": "europe-west1", "cloud_zone": "europe-west1-b",
+			},
+		},
+		{
+			name:    "Azure",
+			handler: azureMetadataService,
+			want: map[string]interface{}{
+				"cloud_provider": "azure", "cloud_instance_id": "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+				"cloud_instance_type": "Standard_D2s_v3", "cloud_region": "westeurope", "cloud_zone": "westeurope-2",
+			},
+		},
+		{
+			name: "GCE without Metadata-Flavor",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("4520031799277581759"))
+			},
+		},
+		{
+			name:    "No metadata service",
+			handler: http.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			got := gelflogger.CloudMetadata(context.Background(), server.URL)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CloudMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudMetadataTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release }))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if got := gelflogger.CloudMetadata(ctx, server.URL); got != nil {
+		t.Errorf("CloudMetadata() = %v, want nil", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CloudMetadata() took %v, want it to give up at the deadline", elapsed)
+	}
+}
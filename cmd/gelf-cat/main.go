@@ -0,0 +1,125 @@
+// Command gelf-cat reads log lines from stdin and ships them to Graylog as GELF messages, e.g. the output of cron jobs
+// and shell pipelines:
+//
+//	backup.sh 2>&1 | gelf-cat -address tls://graylog.example.com:12201 -field job=backup -multiline '^\S'
+//
+// Every line becomes a message, plain text as short message at the level of -level, and JSON objects like the records
+// of structured loggers with their "message" or "msg" as short message, their "level" and "time" read like by
+// gelflogger.ProcessFields and the other members as additional fields. With -multiline, lines not matching the
+// pattern are joined to the message before, e.g. the lines of a stack trace. The address may also be a DSN, see
+// gelflogger.NewLoggerFromDSN. The exit status is 1 if a message could not be sent, and 2 for invalid flags.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// AddressEnv is the environment variable of the default of the -address flag.
+const AddressEnv = "GELF_ADDRESS"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stderr))
+}
+
+// fieldsFlag collects the key=value pairs of the repeated -field flag.
+type fieldsFlag map[string]interface{}
+
+func (f fieldsFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for name, value := range f {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", name, value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldsFlag) Set(pair string) error {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("field %q is not of the form key=value", pair)
+	}
+	f[name] = value
+	return nil
+}
+
+// run parses the flags, ships the lines of stdin and returns the exit status. Errors are written to stderr.
+func run(args []string, stdin io.Reader, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gelf-cat", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	address := flags.String("address", os.Getenv(AddressEnv), "address or DSN of the Graylog GELF input, e.g. tcp://graylog:12201 (default $"+AddressEnv+")")
+	useTLS := flags.Bool("tls", false, "connect over TLS")
+	caFile := flags.String("ca", "", "PEM encoded CA bundle verifying the server certificate, enables TLS")
+	certFile := flags.String("cert", "", "PEM encoded client certificate, enables TLS")
+	keyFile := flags.String("key", "", "PEM encoded private key of the client certificate")
+	level := flags.String("level", "info", "level of the lines without level, a name like warn or a Syslog number")
+	hostname := flags.String("hostname", "", "host field of the messages (default the hostname)")
+	multiline := flags.String("multiline", "", "pattern of the first line of a message, other lines are joined to the message before, e.g. '^\\S'")
+	multilineTimeout := flags.Duration("multiline-timeout", time.Second, "time to wait for further lines of a multi-line message")
+	fields := fieldsFlag{}
+	flags.Var(fields, "field", "key=value of a field added to every message, repeatable")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *address == "" {
+		_, _ = fmt.Fprintln(stderr, "gelf-cat: -address or $"+AddressEnv+" is required")
+		return 2
+	}
+	parsedLevel, err := gelflogger.ParseLevel(*level)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "gelf-cat: -level: %v\n", err)
+		return 2
+	}
+	s := &shipper{level: parsedLevel, multilineTimeout: *multilineTimeout, stderr: stderr}
+	if *multiline != "" {
+		if s.multiline, err = regexp.Compile(*multiline); err != nil {
+			_, _ = fmt.Fprintf(stderr, "gelf-cat: -multiline: %v\n", err)
+			return 2
+		}
+	}
+
+	opts := []gelflogger.Option{gelflogger.WithStaticFields(fields)}
+	if *useTLS {
+		opts = append(opts, gelflogger.WithTLS(nil))
+	}
+	if *caFile != "" {
+		opts = append(opts, gelflogger.WithCAFile(*caFile))
+	}
+	if *certFile != "" || *keyFile != "" {
+		opts = append(opts, gelflogger.WithClientCertificateFiles(*certFile, *keyFile))
+	}
+	if *hostname != "" {
+		opts = append(opts, gelflogger.WithHostname(*hostname))
+	}
+	var logger *gelflogger.Logger
+	if strings.HasPrefix(*address, "gelf:") || strings.HasPrefix(*address, "gelf+") {
+		logger, err = gelflogger.NewLoggerFromDSN(*address, opts...)
+	} else {
+		logger, err = gelflogger.NewLogger(*address, opts...)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "gelf-cat: %v\n", err)
+		return 1
+	}
+	s.logger = logger
+
+	failed := s.ship(stdin)
+	ctx, cancel := context.WithTimeout(context.Background(), gelflogger.DefaultCloseTimeout)
+	defer cancel()
+	if err := logger.Close(ctx); err != nil {
+		_, _ = fmt.Fprintf(stderr, "gelf-cat: %v\n", err)
+		failed = true
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShip(t *testing.T) {
+	tests := []struct {
+		name      string
+		multiline string
+		input     string
+		want      []map[string]interface{}
+	}{
+		{
+			name:  "Plain text",
+			input: "backup started\n\nbackup done\n",
+			want: []map[string]interface{}{
+				{"short_message": "backup started", "level": float64(gelflogger.Notice)},
+				{"short_message": "backup done", "level": float64(gelflogger.Notice)},
+			},
+		},
+		{
+			name:  "JSON records",
+			input: `{"level":"error","msg":"disk full","disk":"/dev/sda1"}` + "\n" + `{"message":"rotated","files":3}` + "\n" + `{"event":"tick"}` + "\n",
+			want: []map[string]interface{}{
+				{"short_message": "disk full", "level": float64(gelflogger.Error), "_disk": "/dev/sda1", "_msg": nil, "_level": nil},
+				{"short_message": "rotated", "level": float64(gelflogger.Notice), "_files": float64(3)},
+				{"short_message": `{"event":"tick"}`, "_event": "tick"},
+			},
+		},
+		{
+			name:  "Invalid JSON is sent as text",
+			input: "{not json\n",
+			want:  []map[string]interface{}{{"short_message": "{not json"}},
+		},
+		{
+			name:      "Multi-line messages",
+			multiline: `^\S`,
+			input:     "panic: boom\n\tmain.go:12\n\tmain.go:7\nrecovered\n",
+			want: []map[string]interface{}{
+				{"short_message": "panic: boom", "full_message": "panic: boom\n\tmain.go:12\n\tmain.go:7"},
+				{"short_message": "recovered"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields)
+			s := &shipper{logger: logger, level: gelflogger.Notice, multilineTimeout: time.Hour, stderr: io.Discard}
+			if tt.multiline != "" {
+				s.multiline = regexp.MustCompile(tt.multiline)
+			}
+
+			assert.False(t, s.ship(strings.NewReader(tt.input)))
+			messages := transport.Messages()
+			require.Len(t, messages, len(tt.want))
+			for i, want := range tt.want {
+				var gelfMsg map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(messages[i]), &gelfMsg))
+				for name, value := range want {
+					assert.Equal(t, value, gelfMsg[name], "message %d, field %s", i, name)
+				}
+			}
+		})
+	}
+}
+
+func TestShipMultilineTimeout(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing)
+	s := &shipper{logger: logger, multiline: regexp.MustCompile(`^\S`), multilineTimeout: 20 * time.Millisecond, stderr: io.Discard}
+
+	reader, writer := io.Pipe()
+	done := make(chan bool)
+	go func() { done <- s.ship(reader) }()
+	_, err := writer.Write([]byte("first\n  continued\n"))
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, len(transport.Messages()), "the pending message is sent after the timeout")
+	require.NoError(t, writer.Close())
+	assert.False(t, <-done)
+	assert.Equal(t, 1, len(transport.Messages()))
+}
+
+func TestRun(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	received := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		for {
+			message, err := reader.ReadString(0)
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- strings.TrimSuffix(message, "\x00")
+		}
+	}()
+
+	var stderr bytes.Buffer
+	status := run([]string{"-address", "tcp://" + listener.Addr().String(), "-level", "warn", "-field", "job=backup", "-hostname", "cron-1"},
+		strings.NewReader("backup failed\n"), &stderr)
+	require.Equal(t, 0, status, stderr.String())
+
+	var gelfMsg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(<-received), &gelfMsg))
+	assert.Equal(t, "backup failed", gelfMsg["short_message"])
+	assert.Equal(t, float64(gelflogger.Warning), gelfMsg["level"])
+	assert.Equal(t, "backup", gelfMsg["_job"])
+	assert.Equal(t, "cron-1", gelfMsg["host"])
+}
+
+func TestRunInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "Missing address", args: []string{}, want: "-address or $GELF_ADDRESS is required"},
+		{name: "Invalid level", args: []string{"-address", "tcp://127.0.0.1:1", "-level", "loud"}, want: "-level"},
+		{name: "Invalid field", args: []string{"-field", "job"}, want: "not of the form key=value"},
+		{name: "Invalid pattern", args: []string{"-address", "tcp://127.0.0.1:1", "-multiline", "("}, want: "-multiline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(AddressEnv, "")
+			var stderr bytes.Buffer
+			assert.Equal(t, 2, run(tt.args, strings.NewReader(""), &stderr))
+			assert.Contains(t, stderr.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// maxLineSize is the maximum size of a line read from stdin, longer lines are rejected.
+const maxLineSize = 1 << 20
+
+// shipper ships the lines read to Graylog.
+//
+// - logger: The Logger the messages are sent with.
+// - level: The Graylog (Syslog) level of the lines without level.
+// - multiline: The pattern of the first line of a message, or nil if every line is a message.
+// - multilineTimeout: The time to wait for further lines of a multi-line message before it is sent.
+// - stderr: The writer of the errors.
+type shipper struct {
+	logger           *gelflogger.Logger
+	level            int
+	multiline        *regexp.Regexp
+	multilineTimeout time.Duration
+	stderr           io.Writer
+}
+
+// ship reads the lines until the end of the input and sends them, joining the lines of multi-line messages. The
+// pending multi-line message is sent after multilineTimeout without further lines, so the last message of a
+// long-running pipeline is not held back. It reports whether a message could not be sent.
+func (s *shipper) ship(input io.Reader) (failed bool) {
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var pending []string
+	send := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := s.send(strings.Join(pending, "\n")); err != nil {
+			_, _ = fmt.Fprintf(s.stderr, "gelf-cat: %v\n", err)
+			failed = true
+		}
+		pending = pending[:0]
+	}
+	timer := time.NewTimer(s.multilineTimeout)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				send()
+				if err := <-readErr; err != nil {
+					_, _ = fmt.Fprintf(s.stderr, "gelf-cat: reading stdin: %v\n", err)
+					failed = true
+				}
+				return failed
+			}
+			if s.multiline == nil {
+				pending = append(pending, line)
+				send()
+				continue
+			}
+			if len(pending) > 0 && !s.multiline.MatchString(line) {
+				pending = append(pending, line)
+			} else {
+				send()
+				pending = append(pending, line)
+			}
+			timer.Reset(s.multilineTimeout)
+		case <-timer.C:
+			send()
+		}
+	}
+}
+
+// send sends the text of a line or multi-line message. JSON objects are sent with their fields, see the package
+// documentation, other text as it is. Blank text is skipped.
+func (s *shipper) send(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(text), "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &fields); err == nil {
+			return s.sendRecord(text, fields)
+		}
+	}
+	return s.logger.LogAt(s.level, time.Time{}, text, nil)
+}
+
+// sendRecord sends the fields of a JSON record. The short message is taken from the "message" or "msg" field, else
+// the record itself is the short message. Records without level get the level of the shipper.
+func (s *shipper) sendRecord(text string, fields map[string]interface{}) error {
+	message, ok := fields["message"].(string)
+	if !ok {
+		if message, ok = fields["msg"].(string); ok {
+			delete(fields, "msg")
+		} else {
+			message = text
+		}
+	}
+	if _, ok := fields["level"]; !ok {
+		fields["level"] = s.level
+	}
+	return s.logger.Log(message, fields)
+}
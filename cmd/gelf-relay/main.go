@@ -0,0 +1,119 @@
+// Command gelf-relay receives GELF messages over UDP and TCP and forwards them to Graylog over TCP or TLS, so legacy
+// emitters which only speak GELF UDP benefit from reliable delivery:
+//
+//	gelf-relay -udp :12201 -upstream tls://graylog.example.com:12201 -failover tls://graylog-2.example.com:12201 -spool /var/spool/gelf-relay
+//
+// The messages are queued in memory, and spooled to disk while Graylog is unreachable if -spool is given, see
+// relay.Relay. The upstream address may also be a DSN, see gelflogger.NewLoggerFromDSN. The relay stops on SIGINT or
+// SIGTERM, waiting up to gelflogger.DefaultCloseTimeout for the queued messages to be sent. The exit status is 1 if it
+// fails, and 2 for invalid flags.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/relay"
+)
+
+// UpstreamEnv is the environment variable of the default of the -upstream flag.
+const UpstreamEnv = "GELF_ADDRESS"
+
+// spoolRetention is the time the spooled messages are kept.
+const spoolRetention = 7 * 24 * time.Hour
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stderr))
+}
+
+// run parses the flags and relays the messages until the context is done, and returns the exit status. Errors are
+// written to stderr.
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gelf-relay", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	udpAddress := flags.String("udp", ":12201", "address to receive GELF UDP on, empty to disable")
+	tcpAddress := flags.String("tcp", "", "address to receive GELF TCP on, empty to disable")
+	tcpNewline := flags.Bool("tcp-newline", false, "also split GELF TCP messages at newlines, for senders not using null bytes")
+	upstream := flags.String("upstream", os.Getenv(UpstreamEnv), "address or DSN of the Graylog GELF input, e.g. tls://graylog:12201 (default $"+UpstreamEnv+")")
+	failover := flags.String("failover", "", "comma separated addresses to fail over to")
+	useTLS := flags.Bool("tls", false, "connect upstream over TLS")
+	caFile := flags.String("ca", "", "PEM encoded CA bundle verifying the server certificate, enables TLS")
+	certFile := flags.String("cert", "", "PEM encoded client certificate, enables TLS")
+	keyFile := flags.String("key", "", "PEM encoded private key of the client certificate")
+	queueSize := flags.Int("queue", 10000, "number of messages queued in memory")
+	workers := flags.Int("workers", 2, "number of connections sending the queued messages")
+	spoolDir := flags.String("spool", "", "directory spooling the messages to disk while Graylog is unreachable")
+	spoolSize := flags.Int64("spool-size", 1<<30, "maximum size of the spool in bytes")
+	chunkTimeout := flags.Duration("chunk-timeout", relay.DefaultChunkTimeout, "time the chunks of a UDP message must be received within")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *upstream == "" {
+		_, _ = fmt.Fprintln(stderr, "gelf-relay: -upstream or $"+UpstreamEnv+" is required")
+		return 2
+	}
+	if *udpAddress == "" && *tcpAddress == "" {
+		_, _ = fmt.Fprintln(stderr, "gelf-relay: -udp or -tcp is required")
+		return 2
+	}
+
+	opts := []gelflogger.Option{gelflogger.WithAsync(*queueSize, *workers)}
+	if *failover != "" {
+		opts = append(opts, gelflogger.WithFailoverAddresses(strings.Split(*failover, ",")...))
+	}
+	if *useTLS {
+		opts = append(opts, gelflogger.WithTLS(nil))
+	}
+	if *caFile != "" {
+		opts = append(opts, gelflogger.WithCAFile(*caFile))
+	}
+	if *certFile != "" || *keyFile != "" {
+		opts = append(opts, gelflogger.WithClientCertificateFiles(*certFile, *keyFile))
+	}
+	if *spoolDir != "" {
+		spool, err := gelflogger.NewSpool(*spoolDir, *spoolSize, spoolRetention)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "gelf-relay: %v\n", err)
+			return 1
+		}
+		opts = append(opts, gelflogger.WithSpool(spool))
+	}
+	var logger *gelflogger.Logger
+	var err error
+	if strings.HasPrefix(*upstream, "gelf:") || strings.HasPrefix(*upstream, "gelf+") {
+		logger, err = gelflogger.NewLoggerFromDSN(*upstream, opts...)
+	} else {
+		logger, err = gelflogger.NewLogger(*upstream, opts...)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "gelf-relay: %v\n", err)
+		return 1
+	}
+
+	relayOpts := []relay.Option{relay.WithChunkTimeout(*chunkTimeout)}
+	if *tcpNewline {
+		relayOpts = append(relayOpts, relay.WithNewlineFraming())
+	}
+	status := 0
+	if err := relay.New(logger, relayOpts...).ListenAndServe(ctx, *udpAddress, *tcpAddress); err != nil {
+		_, _ = fmt.Fprintf(stderr, "gelf-relay: %v\n", err)
+		status = 1
+	}
+	closeCtx, cancel := context.WithTimeout(context.Background(), gelflogger.DefaultCloseTimeout)
+	defer cancel()
+	if err := logger.Close(closeCtx); err != nil {
+		_, _ = fmt.Fprintf(stderr, "gelf-relay: %v\n", err)
+		status = 1
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = upstream.Close() }()
+	received := make(chan string, 10)
+	go func() {
+		conn, err := upstream.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		for {
+			message, err := reader.ReadString(0)
+			if err != nil {
+				return
+			}
+			received <- strings.TrimSuffix(message, "\x00")
+		}
+	}()
+
+	// A free port for the relay
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	udpAddress := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stderr bytes.Buffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"-udp", udpAddress, "-upstream", "tcp://" + upstream.Addr().String()}, &stderr)
+	}()
+
+	sender, err := net.Dial("udp", udpAddress)
+	require.NoError(t, err)
+	defer func() { _ = sender.Close() }()
+	message := `{"version":"1.1","host":"legacy","short_message":"disk full"}`
+	var got string
+	require.Eventually(t, func() bool {
+		// Sent until the relay listens
+		_, _ = sender.Write([]byte(message))
+		select {
+		case got = <-received:
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, time.Millisecond)
+	assert.Equal(t, message, got)
+
+	cancel()
+	assert.Equal(t, 0, <-done, stderr.String())
+}
+
+func TestRunInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "Missing upstream", args: []string{}, want: "-upstream or $GELF_ADDRESS is required"},
+		{name: "Nothing to listen on", args: []string{"-upstream", "tcp://127.0.0.1:1", "-udp", ""}, want: "-udp or -tcp is required"},
+		{name: "Unknown flag", args: []string{"-verbose"}, want: "flag provided but not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(UpstreamEnv, "")
+			var stderr bytes.Buffer
+			assert.Equal(t, 2, run(context.Background(), tt.args, &stderr))
+			assert.Contains(t, stderr.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ErrUnsupportedFieldType is returned for fields whose values are neither strings nor numbers, nor one of the types
+// coerced to them, if the Logger checks the types strictly, see WithStrictFieldTypes.
+var ErrUnsupportedFieldType = errors.New("unsupported GELF field type")
+
+// coerceFieldValue coerces the value of an additional field to a string or a number, the only types GELF permits:
+//
+//   - Strings, integers, finite floats and json.Number are kept.
+//   - Booleans become "true" or "false".
+//   - time.Time becomes its RFC 3339 representation with nanoseconds.
+//   - Errors become their message.
+//   - fmt.Stringer, e.g. time.Duration, becomes its String representation.
+//   - Other named numeric types are kept as number, NaN and infinite floats become their string representation.
+//   - nil, nil pointers included, is omitted, reported by false.
+//   - Other values, e.g. maps, slices and structs, become their JSON encoding, or are rejected with an error wrapping
+//     ErrUnsupportedFieldType if strict is set.
+func coerceFieldValue(value interface{}, strict bool) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false, nil
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return v, true, nil
+	case float64:
+		return coerceFloat(v), true, nil
+	case float32:
+		return coerceFloat(float64(v)), true, nil
+	case bool:
+		return strconv.FormatBool(v), true, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true, nil
+	case error:
+		if isNil(v) {
+			return nil, false, nil
+		}
+		return v.Error(), true, nil
+	case fmt.Stringer:
+		if isNil(v) {
+			return nil, false, nil
+		}
+		return v.String(), true, nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), true, nil
+	case reflect.Float32, reflect.Float64:
+		return coerceFloat(rv.Float()), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), true, nil
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nil, false, nil
+		}
+	}
+	if strict {
+		return nil, false, fmt.Errorf("%w: %T", ErrUnsupportedFieldType, value)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value), true, nil
+	}
+	return string(encoded), true, nil
+}
+
+// coerceFloat keeps finite floats, and returns the string representation of NaN and infinite floats, which JSON
+// cannot encode.
+func coerceFloat(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return f
+}
+
+// isNil reports whether the interface holds a nil pointer.
+func isNil(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -0,0 +1,78 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+type priority int
+
+func TestFieldTypes(t *testing.T) {
+	var nilURL *url.URL
+	created := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name    string
+		strict  bool
+		value   interface{}
+		want    interface{}
+		omitted bool
+		wantErr bool
+	}{
+		{name: "String", value: "text", want: "text"},
+		{name: "Integer", value: 42, want: float64(42)},
+		{name: "Float", value: 1.5, want: 1.5},
+		{name: "Named integer", value: priority(3), want: float64(3)},
+		{name: "Boolean", value: true, want: "true"},
+		{name: "Time", value: created, want: "2024-05-01T12:00:00.0000005Z"},
+		{name: "Duration", value: 1500 * time.Millisecond, want: "1.5s"},
+		{name: "Stringer", value: &url.URL{Scheme: "https", Host: "example.com"}, want: "https://example.com"},
+		{name: "Error", value: errors.New("failed"), want: "failed"},
+		{name: "NaN", value: math.NaN(), want: "NaN"},
+		{name: "Nil", value: nil, omitted: true},
+		{name: "Nil pointer", value: nilURL, omitted: true},
+		{name: "Map is encoded", value: map[string]int{"a": 1}, want: `{"a":1}`},
+		{name: "Slice is encoded", value: []string{"a"}, want: `["a"]`},
+		{name: "Strict mode keeps coercible values", strict: true, value: created, want: "2024-05-01T12:00:00.0000005Z"},
+		{name: "Strict mode rejects maps", strict: true, value: map[string]int{"a": 1}, wantErr: true},
+		{name: "Strict mode rejects structs", strict: true, value: struct{ A int }{1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []gelflogger.Option
+			if tt.strict {
+				opts = append(opts, gelflogger.WithStrictFieldTypes())
+			}
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, opts...)
+			err := logger.Log("types", map[string]interface{}{"value": tt.value})
+			if tt.wantErr {
+				if !errors.Is(err, gelflogger.ErrUnsupportedFieldType) || !errors.Is(err, gelflogger.ErrPermanent) {
+					t.Errorf("Log() error = %v, want ErrUnsupportedFieldType and ErrPermanent", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			got, ok := gelfMsg["_value"]
+			if ok == tt.omitted {
+				t.Fatalf("sent %s, want the field omitted: %v", transport.messages[0], tt.omitted)
+			}
+			if !tt.omitted && got != tt.want {
+				t.Errorf("_value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
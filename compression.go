@@ -0,0 +1,82 @@
+package gelflogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// Compression selects how the UDP and HTTP transports compress the GELF messages, see WithCompression.
+type Compression int
+
+const (
+	// CompressionNone sends the messages uncompressed.
+	CompressionNone Compression = iota
+	// CompressionGzip compresses the messages with gzip.
+	CompressionGzip
+	// CompressionZlib compresses the messages with zlib, sent as deflate content encoding over HTTP.
+	CompressionZlib
+)
+
+// ParseCompression returns the Compression of the given name: "none", "gzip" or "zlib". The empty name selects
+// CompressionNone.
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip":
+		return CompressionGzip, nil
+	case "zlib", "deflate":
+		return CompressionZlib, nil
+	default:
+		return CompressionNone, fmt.Errorf("unsupported compression %q", name)
+	}
+}
+
+// String returns the name of the Compression, as accepted by ParseCompression.
+func (c Compression) String() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZlib:
+		return "zlib"
+	default:
+		return "none"
+	}
+}
+
+// contentEncoding returns the HTTP Content-Encoding of messages compressed with the Compression, empty for
+// uncompressed messages.
+func (c Compression) contentEncoding() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZlib:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compress returns the message compressed with the Compression. Uncompressed messages are returned as they are.
+func (c Compression) compress(message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch c {
+	case CompressionGzip:
+		writer = gzip.NewWriter(&buf)
+	case CompressionZlib:
+		writer = zlib.NewWriter(&buf)
+	default:
+		return message, nil
+	}
+	if _, err := writer.Write(message); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
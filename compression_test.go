@@ -0,0 +1,104 @@
+package gelflogger_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// decompress decompresses the message with the given Compression.
+func decompress(t *testing.T, compression gelflogger.Compression, message []byte) string {
+	t.Helper()
+	var reader io.Reader
+	var err error
+	switch compression {
+	case gelflogger.CompressionGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(message))
+	case gelflogger.CompressionZlib:
+		reader, err = zlib.NewReader(bytes.NewReader(message))
+	default:
+		return string(message)
+	}
+	if err != nil {
+		t.Fatalf("invalid %v message: %v", compression, err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("invalid %v message: %v", compression, err)
+	}
+	return string(decompressed)
+}
+
+func TestCompression(t *testing.T) {
+	udpServer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on UDP: %v", err)
+	}
+	defer func() { _ = udpServer.Close() }()
+	udpMessages := receiveDatagrams(udpServer)
+
+	type request struct {
+		encoding string
+		body     []byte
+	}
+	httpRequests := make(chan request, 10)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		httpRequests <- request{encoding: r.Header.Get("Content-Encoding"), body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer httpServer.Close()
+
+	tests := []struct {
+		name         string
+		address      string
+		compression  gelflogger.Compression
+		wantEncoding string
+	}{
+		{name: "UDP gzip", address: "udp://" + udpServer.LocalAddr().String(), compression: gelflogger.CompressionGzip},
+		{name: "UDP zlib", address: "udp://" + udpServer.LocalAddr().String(), compression: gelflogger.CompressionZlib},
+		{name: "HTTP gzip", address: httpServer.URL, compression: gelflogger.CompressionGzip, wantEncoding: "gzip"},
+		{name: "HTTP zlib", address: httpServer.URL, compression: gelflogger.CompressionZlib, wantEncoding: "deflate"},
+		{name: "HTTP uncompressed", address: httpServer.URL, compression: gelflogger.CompressionNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := gelflogger.NewTransport(tt.address, false, nil, gelflogger.WithCompression(tt.compression))
+			if err != nil {
+				t.Fatalf("NewTransport() error = %v", err)
+			}
+			defer func() { _ = transport.Close() }()
+			// Large enough to be chunked over UDP without compression
+			message := `{"short_message":"` + strings.Repeat("compressible ", 500) + `"}`
+			if err := transport.Send([]byte(message)); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			var got string
+			select {
+			case datagram := <-udpMessages:
+				got = decompress(t, tt.compression, []byte(datagram))
+			case req := <-httpRequests:
+				if req.encoding != tt.wantEncoding {
+					t.Errorf("Content-Encoding = %q, want %q", req.encoding, tt.wantEncoding)
+				}
+				got = decompress(t, tt.compression, req.body)
+			case <-time.After(2 * time.Second):
+				t.Fatal("message was not received")
+			}
+			if got != message {
+				t.Errorf("received %.50s..., want the sent message", got)
+			}
+		})
+	}
+}
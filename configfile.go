@@ -0,0 +1,531 @@
+package gelflogger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of a Logger read from a YAML or JSON file by LoadConfig, so a single file can be
+// shipped to all services embedding the Logger. Every field is optional, only Address is required. The processor of
+// the messages cannot be configured by a file and is passed as Option to NewLoggerFromConfig instead.
+//
+// Example YAML file:
+//
+//	address: tls://graylog.example.com:12201
+//	hostname: billing-service
+//	static_fields:
+//	  service: billing
+//	  environment: production
+//	timeout: 3s
+//	tls:
+//	  ca_file: /etc/gelf/ca.crt
+//	  cert_file: /etc/gelf/client.crt
+//	  key_file: /etc/gelf/client.key
+//	async:
+//	  queue_size: 10000
+//	  overflow: drop_oldest
+//	fallback:
+//	  - file: /var/log/app/gelf-fallback.log
+//	    max_size: 104857600
+//	  - stderr: true
+type Config struct {
+	// Address is the address of the Graylog server, see NewLogger.
+	Address string `json:"address" yaml:"address"`
+	// Hostname is the host field of the messages, see WithHostname.
+	Hostname string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	// HostnameRefresh is the interval of looking up the hostname again, see WithHostnameRefresh.
+	HostnameRefresh Duration `json:"hostname_refresh,omitempty" yaml:"hostname_refresh,omitempty"`
+	// Facility is the _facility field of the messages, see WithFacility.
+	Facility string `json:"facility,omitempty" yaml:"facility,omitempty"`
+	// ProcessMetadata adds _pid, _go_version and _executable to the messages, see WithProcessMetadata.
+	ProcessMetadata bool `json:"process_metadata,omitempty" yaml:"process_metadata,omitempty"`
+	// StrictFieldNames rejects messages with invalid field names, see WithStrictFieldNames.
+	StrictFieldNames bool `json:"strict_field_names,omitempty" yaml:"strict_field_names,omitempty"`
+	// StrictFieldTypes rejects messages with field values of unsupported types, see WithStrictFieldTypes.
+	StrictFieldTypes bool `json:"strict_field_types,omitempty" yaml:"strict_field_types,omitempty"`
+	// Validate checks the messages against the GELF specification before they are sent, see WithValidation.
+	Validate bool `json:"validate,omitempty" yaml:"validate,omitempty"`
+	// Flattening is the number of nesting levels of the fields flattened into dot notation, see WithFlattening.
+	Flattening int `json:"flattening,omitempty" yaml:"flattening,omitempty"`
+	// ShortMessageLimit is the maximum size of the short messages in bytes, see WithShortMessageLimit.
+	ShortMessageLimit int `json:"short_message_limit,omitempty" yaml:"short_message_limit,omitempty"`
+	// MultiLineShortMessages sends multi-line short messages as they are, see WithMultiLineShortMessages.
+	MultiLineShortMessages bool `json:"multi_line_short_messages,omitempty" yaml:"multi_line_short_messages,omitempty"`
+	// UnsanitizedStrings sends the strings of the messages without stripping control characters, see
+	// WithUnsanitizedStrings.
+	UnsanitizedStrings bool `json:"unsanitized_strings,omitempty" yaml:"unsanitized_strings,omitempty"`
+	// FullMessage selects the full message: processor, the default, or none, see WithFullMessage.
+	FullMessage string `json:"full_message,omitempty" yaml:"full_message,omitempty"`
+	// FullMessageField is the field used as full message, see WithFullMessageField.
+	FullMessageField string `json:"full_message_field,omitempty" yaml:"full_message_field,omitempty"`
+	// FieldNaming selects the names of the fields: gelf, the default, ecs or otel, see WithFieldNaming.
+	FieldNaming string `json:"field_naming,omitempty" yaml:"field_naming,omitempty"`
+	// ShortMessageTemplate is the text/template rendering the short message of records without message, see
+	// WithShortMessageTemplate.
+	ShortMessageTemplate string `json:"short_message_template,omitempty" yaml:"short_message_template,omitempty"`
+	// StaticFields are attached to every message, see WithStaticFields.
+	StaticFields map[string]interface{} `json:"static_fields,omitempty" yaml:"static_fields,omitempty"`
+	// Timeout is the timeout of connecting, see WithTimeout.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// WriteTimeout is the timeout of the TCP writes, see WithWriteTimeout.
+	WriteTimeout Duration `json:"write_timeout,omitempty" yaml:"write_timeout,omitempty"`
+	// TLS configures the TLS connection.
+	TLS *TLSFileConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// Failover are the failover addresses, see WithFailoverAddresses.
+	Failover []string `json:"failover,omitempty" yaml:"failover,omitempty"`
+	// LoadBalancing distributes the messages across the addresses: none, round_robin or least_errors, see
+	// WithLoadBalancing.
+	LoadBalancing string `json:"load_balancing,omitempty" yaml:"load_balancing,omitempty"`
+	// Pool is the number of connections of a connection pool, see WithConnectionPool.
+	Pool int `json:"pool,omitempty" yaml:"pool,omitempty"`
+	// Framing is the delimiter of the TCP messages: null or newline, see WithFramingMode.
+	Framing string `json:"framing,omitempty" yaml:"framing,omitempty"`
+	// Compression is the compression of the UDP and HTTP messages: none, gzip or zlib, see WithCompression.
+	Compression string `json:"compression,omitempty" yaml:"compression,omitempty"`
+	// HealthCheckInterval is the interval of the connection probes, see WithHealthCheckInterval.
+	HealthCheckInterval Duration `json:"health_check_interval,omitempty" yaml:"health_check_interval,omitempty"`
+	// Heartbeat is the interval of the heartbeat messages, see WithHeartbeat.
+	Heartbeat Duration `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty"`
+	// Async makes the Logger asynchronous, see WithAsync.
+	Async *AsyncFileConfig `json:"async,omitempty" yaml:"async,omitempty"`
+	// Batching sends the messages in batches, see WithBatching.
+	Batching *BatchingFileConfig `json:"batching,omitempty" yaml:"batching,omitempty"`
+	// Retry retries temporary failures, see WithRetry.
+	Retry *RetryFileConfig `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// FailFast reports a lost connection right away instead of reconnecting, see WithFailFast.
+	FailFast bool `json:"fail_fast,omitempty" yaml:"fail_fast,omitempty"`
+	// Sampling keeps 1 in N messages per Graylog level, see WithSampling.
+	Sampling map[int]int `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+	// Deduplication suppresses repeated messages, see WithDeduplication.
+	Deduplication *DeduplicationFileConfig `json:"deduplication,omitempty" yaml:"deduplication,omitempty"`
+	// Spool persists the messages which could not be sent, see WithSpool.
+	Spool *SpoolFileConfig `json:"spool,omitempty" yaml:"spool,omitempty"`
+	// Fallback are the sinks of the messages which could not be sent, chained in this order, see FallbackChain.
+	Fallback []FallbackFileConfig `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+}
+
+// TLSFileConfig configures the TLS connection of a Config. Setting any field enables TLS.
+type TLSFileConfig struct {
+	// CAFile is the CA bundle verifying the server certificate, see WithCAFile.
+	CAFile string `json:"ca_file,omitempty" yaml:"ca_file,omitempty"`
+	// CertFile and KeyFile are the client certificate and its key, see WithClientCertificateFiles.
+	CertFile string `json:"cert_file,omitempty" yaml:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+	// MinVersion is the minimum TLS version, 1.2 or 1.3, see WithTLSMinVersion.
+	MinVersion string `json:"min_version,omitempty" yaml:"min_version,omitempty"`
+	// Strict enforces the strict TLS policy, see WithStrictTLS.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+	// PinnedSPKI are the pinned public keys of the server, see WithPinnedSPKI.
+	PinnedSPKI []string `json:"pinned_spki,omitempty" yaml:"pinned_spki,omitempty"`
+}
+
+// AsyncFileConfig configures the queue of an asynchronous Logger, see WithAsync.
+type AsyncFileConfig struct {
+	QueueSize int `json:"queue_size,omitempty" yaml:"queue_size,omitempty"`
+	Workers   int `json:"workers,omitempty" yaml:"workers,omitempty"`
+	// Overflow is the OverflowPolicy: block, drop_newest or drop_oldest.
+	Overflow    string `json:"overflow,omitempty" yaml:"overflow,omitempty"`
+	MemoryLimit int64  `json:"memory_limit,omitempty" yaml:"memory_limit,omitempty"`
+}
+
+// BatchingFileConfig configures the batches of a Logger, see WithBatching.
+type BatchingFileConfig struct {
+	Size          int      `json:"size,omitempty" yaml:"size,omitempty"`
+	FlushInterval Duration `json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"`
+}
+
+// RetryFileConfig configures the retries of temporary failures, see WithRetry.
+type RetryFileConfig struct {
+	Budget       int      `json:"budget" yaml:"budget"`
+	InitialDelay Duration `json:"initial_delay,omitempty" yaml:"initial_delay,omitempty"`
+}
+
+// DeduplicationFileConfig configures the deduplication of repeated messages, see WithDeduplication.
+type DeduplicationFileConfig struct {
+	Window Duration `json:"window" yaml:"window"`
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// SpoolFileConfig configures the Spool of a Logger, see NewSpool.
+type SpoolFileConfig struct {
+	Dir       string   `json:"dir" yaml:"dir"`
+	MaxSize   int64    `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+	Retention Duration `json:"retention,omitempty" yaml:"retention,omitempty"`
+	// KeyFile is a file holding the hex encoded AES key encrypting the spool, see NewEncryptedSpool.
+	KeyFile string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+}
+
+// FallbackFileConfig configures a single sink of the fallback chain of a Config. Exactly one of File, Address,
+// Stderr and Stdout must be set.
+type FallbackFileConfig struct {
+	// File keeps the messages in a local file, see NewFileFallback.
+	File       string `json:"file,omitempty" yaml:"file,omitempty"`
+	MaxSize    int64  `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+	MaxBackups int    `json:"max_backups,omitempty" yaml:"max_backups,omitempty"`
+	// Address sends the messages to another GELF input, e.g. udp://graylog-backup:12201, see NewTransport.
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+	// Stderr and Stdout write the messages as lines to the standard streams, see NewWriterTransport.
+	Stderr bool `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+	Stdout bool `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+}
+
+// Duration is a time.Duration read from a string like "3s" or "1m30s" in YAML and JSON config files.
+type Duration time.Duration
+
+// UnmarshalText parses the duration, see time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration, see time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadConfig reads the Config from the YAML (.yaml, .yml) or JSON (.json) file at the given path. Unknown fields are
+// rejected, so typos do not silently fall back to the defaults.
+//
+// Example usage:
+//
+//	cfg, err := gelflogger.LoadConfig("/etc/app/gelf.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	logger, err := gelflogger.NewLoggerFromConfig(cfg, gelflogger.WithProcessor(zerologger.ProcessZerologFields))
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q of %s, want .yaml, .yml or .json", ext, path)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("invalid config file %s: address is missing", path)
+	}
+	return &cfg, nil
+}
+
+// NewLoggerFromConfig creates a new Logger configured by the Config, e.g. read by LoadConfig. The given Options, e.g.
+// WithProcessor, are applied after the ones of the Config. The spool and the fallbacks created for the Config are
+// owned by the Logger and closed by Logger.Close.
+func NewLoggerFromConfig(cfg *Config, opts ...Option) (*Logger, error) {
+	cfgOpts, closers, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	logger, err := NewLogger(cfg.Address, append(append(cfgOpts, withOwned(closers...)), opts...)...)
+	if err != nil {
+		closeAll(closers)
+		return nil, err
+	}
+	return logger, nil
+}
+
+// options returns the Options of the Config, and the spool and fallbacks created for it. If an error is returned,
+// the created resources are closed already.
+func (c *Config) options() ([]Option, []io.Closer, error) {
+	var opts []Option
+	var errs []error
+	var closers []io.Closer
+	if c.Hostname != "" {
+		opts = append(opts, WithHostname(c.Hostname))
+	}
+	if c.HostnameRefresh != 0 {
+		opts = append(opts, WithHostnameRefresh(time.Duration(c.HostnameRefresh)))
+	}
+	if c.Facility != "" {
+		opts = append(opts, WithFacility(c.Facility))
+	}
+	if c.ProcessMetadata {
+		opts = append(opts, WithProcessMetadata())
+	}
+	if c.StrictFieldNames {
+		opts = append(opts, WithStrictFieldNames())
+	}
+	if c.StrictFieldTypes {
+		opts = append(opts, WithStrictFieldTypes())
+	}
+	if c.Validate {
+		opts = append(opts, WithValidation())
+	}
+	if c.Flattening > 0 {
+		opts = append(opts, WithFlattening(c.Flattening))
+	}
+	if c.ShortMessageLimit > 0 {
+		opts = append(opts, WithShortMessageLimit(c.ShortMessageLimit))
+	}
+	if c.MultiLineShortMessages {
+		opts = append(opts, WithMultiLineShortMessages())
+	}
+	if c.UnsanitizedStrings {
+		opts = append(opts, WithUnsanitizedStrings())
+	}
+	switch c.FullMessage {
+	case "", "processor":
+	case "none":
+		opts = append(opts, WithFullMessage(FullMessageNone))
+	default:
+		errs = append(errs, fmt.Errorf("unsupported full_message %q, want processor or none", c.FullMessage))
+	}
+	if c.FullMessageField != "" {
+		opts = append(opts, WithFullMessageField(c.FullMessageField))
+	}
+	if c.FieldNaming != "" {
+		naming, err := parseFieldNaming(c.FieldNaming)
+		errs = append(errs, err)
+		opts = append(opts, WithFieldNaming(naming))
+	}
+	if c.ShortMessageTemplate != "" {
+		tmpl, err := template.New("short_message_template").Parse(c.ShortMessageTemplate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid short_message_template: %w", err))
+		} else {
+			opts = append(opts, WithShortMessageTemplate(tmpl))
+		}
+	}
+	if len(c.StaticFields) > 0 {
+		opts = append(opts, WithStaticFields(c.StaticFields))
+	}
+	if c.Timeout != 0 {
+		opts = append(opts, WithTimeout(time.Duration(c.Timeout)))
+	}
+	if c.WriteTimeout != 0 {
+		opts = append(opts, WithWriteTimeout(time.Duration(c.WriteTimeout)))
+	}
+	if c.TLS != nil {
+		opts = append(opts, WithTLS(nil))
+		if c.TLS.CAFile != "" {
+			opts = append(opts, WithCAFile(c.TLS.CAFile))
+		}
+		if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+			opts = append(opts, WithClientCertificateFiles(c.TLS.CertFile, c.TLS.KeyFile))
+		}
+		switch c.TLS.MinVersion {
+		case "":
+		case "1.2":
+			opts = append(opts, WithTLSMinVersion(tls.VersionTLS12))
+		case "1.3":
+			opts = append(opts, WithTLSMinVersion(tls.VersionTLS13))
+		default:
+			errs = append(errs, fmt.Errorf("unsupported TLS min_version %q, want 1.2 or 1.3", c.TLS.MinVersion))
+		}
+		if c.TLS.Strict {
+			opts = append(opts, WithStrictTLS())
+		}
+		if len(c.TLS.PinnedSPKI) > 0 {
+			opts = append(opts, WithPinnedSPKI(c.TLS.PinnedSPKI...))
+		}
+	}
+	if len(c.Failover) > 0 {
+		opts = append(opts, WithFailoverAddresses(c.Failover...))
+	}
+	if c.LoadBalancing != "" {
+		strategy, err := parseLoadBalancing(c.LoadBalancing)
+		errs = append(errs, err)
+		opts = append(opts, WithLoadBalancing(strategy))
+	}
+	if c.Pool > 0 {
+		opts = append(opts, WithConnectionPool(c.Pool))
+	}
+	if c.Framing != "" {
+		mode, err := parseFramingMode(c.Framing)
+		errs = append(errs, err)
+		opts = append(opts, WithFramingMode(mode))
+	}
+	if c.Compression != "" {
+		compression, err := ParseCompression(c.Compression)
+		errs = append(errs, err)
+		opts = append(opts, WithCompression(compression))
+	}
+	if c.HealthCheckInterval != 0 {
+		opts = append(opts, WithHealthCheckInterval(time.Duration(c.HealthCheckInterval)))
+	}
+	if c.Heartbeat != 0 {
+		opts = append(opts, WithHeartbeat(time.Duration(c.Heartbeat)))
+	}
+	if c.Async != nil {
+		opts = append(opts, WithAsync(c.Async.QueueSize, c.Async.Workers), WithQueueMemoryLimit(c.Async.MemoryLimit))
+		if c.Async.Overflow != "" {
+			policy, err := parseOverflowPolicy(c.Async.Overflow)
+			errs = append(errs, err)
+			opts = append(opts, WithOverflowPolicy(policy))
+		}
+	}
+	if c.Batching != nil {
+		opts = append(opts, WithBatching(c.Batching.Size, time.Duration(c.Batching.FlushInterval)))
+	}
+	if c.Retry != nil {
+		opts = append(opts, WithRetry(c.Retry.Budget, time.Duration(c.Retry.InitialDelay)))
+	}
+	if c.FailFast {
+		opts = append(opts, WithFailFast())
+	}
+	if len(c.Sampling) > 0 {
+		opts = append(opts, WithSampling(c.Sampling))
+	}
+	if c.Deduplication != nil {
+		opts = append(opts, WithDeduplication(time.Duration(c.Deduplication.Window), c.Deduplication.Fields...))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	if c.Spool != nil {
+		spool, err := c.Spool.open()
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, spool)
+		opts = append(opts, WithSpool(spool))
+	}
+	if len(c.Fallback) > 0 {
+		sinks := make([]Transport, 0, len(c.Fallback))
+		for i, fallback := range c.Fallback {
+			sink, err := fallback.open()
+			if err != nil {
+				closeAll(closers)
+				return nil, nil, fmt.Errorf("fallback %d: %w", i, err)
+			}
+			closers = append(closers, sink)
+			sinks = append(sinks, sink)
+		}
+		if len(sinks) == 1 {
+			opts = append(opts, WithFallback(sinks[0]))
+		} else {
+			opts = append(opts, WithFallback(NewFallbackChain(sinks...)))
+		}
+	}
+	return opts, closers, nil
+}
+
+// open creates the Spool of the configuration.
+func (s *SpoolFileConfig) open() (*Spool, error) {
+	if s.KeyFile == "" {
+		return NewSpool(s.Dir, s.MaxSize, time.Duration(s.Retention))
+	}
+	encoded, err := os.ReadFile(s.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid spool key file %s: %w", s.KeyFile, err)
+	}
+	return NewEncryptedSpool(s.Dir, s.MaxSize, time.Duration(s.Retention), key)
+}
+
+// open creates the sink of the fallback configuration.
+func (f *FallbackFileConfig) open() (Transport, error) {
+	set := 0
+	for _, isSet := range []bool{f.File != "", f.Address != "", f.Stderr, f.Stdout} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("exactly one of file, address, stderr and stdout must be set")
+	}
+	switch {
+	case f.File != "":
+		return NewFileFallback(f.File, f.MaxSize, f.MaxBackups)
+	case f.Address != "":
+		return NewTransport(f.Address, false, nil)
+	case f.Stderr:
+		return NewWriterTransport(os.Stderr), nil
+	default:
+		return NewWriterTransport(os.Stdout), nil
+	}
+}
+
+// closeAll closes the given resources, ignoring their errors.
+func closeAll(closers []io.Closer) {
+	for _, closer := range closers {
+		_ = closer.Close()
+	}
+}
+
+// parseLoadBalancing returns the LoadBalancing of the given name: none, round_robin or least_errors.
+func parseLoadBalancing(name string) (LoadBalancing, error) {
+	switch name {
+	case "none":
+		return NoLoadBalancing, nil
+	case "round_robin":
+		return RoundRobin, nil
+	case "least_errors":
+		return LeastErrors, nil
+	default:
+		return NoLoadBalancing, fmt.Errorf("unsupported load balancing %q", name)
+	}
+}
+
+// parseFieldNaming returns the FieldNaming of the given name: gelf, ecs or otel.
+func parseFieldNaming(name string) (FieldNaming, error) {
+	switch name {
+	case "gelf":
+		return FieldNamingGELF, nil
+	case "ecs":
+		return FieldNamingECS, nil
+	case "otel":
+		return FieldNamingOTel, nil
+	default:
+		return FieldNamingGELF, fmt.Errorf("unsupported field_naming %q, want gelf, ecs or otel", name)
+	}
+}
+
+// parseFramingMode returns the FramingMode of the given name: null or newline.
+func parseFramingMode(name string) (FramingMode, error) {
+	switch name {
+	case "null":
+		return FramingNullByte, nil
+	case "newline":
+		return FramingNewline, nil
+	default:
+		return FramingNullByte, fmt.Errorf("unsupported framing %q", name)
+	}
+}
+
+// parseOverflowPolicy returns the OverflowPolicy of the given name: block, drop_newest or drop_oldest.
+func parseOverflowPolicy(name string) (OverflowPolicy, error) {
+	switch name {
+	case "block":
+		return OverflowBlock, nil
+	case "drop_newest":
+		return OverflowDropNewest, nil
+	case "drop_oldest":
+		return OverflowDropOldest, nil
+	default:
+		return OverflowBlock, fmt.Errorf("unsupported overflow policy %q", name)
+	}
+}
@@ -0,0 +1,180 @@
+package gelflogger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+const yamlConfig = `
+address: tls://graylog.example.com:12201
+hostname: billing
+static_fields:
+  environment: production
+timeout: 3s
+tls:
+  ca_file: /etc/gelf/ca.crt
+  min_version: "1.3"
+failover: [tls://graylog-2.example.com:12201]
+load_balancing: round_robin
+async:
+  queue_size: 10000
+  overflow: drop_oldest
+sampling:
+  7: 10
+fallback:
+  - file: /var/log/gelf.log
+    max_size: 1048576
+  - stderr: true
+`
+
+const jsonConfig = `{
+  "address": "udp://graylog.example.com:12201",
+  "compression": "gzip",
+  "heartbeat": "1m",
+  "deduplication": {"window": "10s", "fields": ["error"]}
+}`
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		check   func(t *testing.T, cfg *gelflogger.Config)
+		wantErr string
+	}{
+		{
+			name:    "YAML",
+			file:    "gelf.yaml",
+			content: yamlConfig,
+			check: func(t *testing.T, cfg *gelflogger.Config) {
+				if cfg.Address != "tls://graylog.example.com:12201" || cfg.Hostname != "billing" || time.Duration(cfg.Timeout) != 3*time.Second {
+					t.Errorf("LoadConfig() = %+v, want the address, hostname and timeout of the file", cfg)
+				}
+				if cfg.StaticFields["environment"] != "production" {
+					t.Errorf("LoadConfig() StaticFields = %v, want the static fields of the file", cfg.StaticFields)
+				}
+				if cfg.TLS == nil || cfg.TLS.CAFile != "/etc/gelf/ca.crt" || cfg.TLS.MinVersion != "1.3" {
+					t.Errorf("LoadConfig() TLS = %+v, want the TLS settings of the file", cfg.TLS)
+				}
+				if cfg.Async == nil || cfg.Async.QueueSize != 10000 || cfg.Async.Overflow != "drop_oldest" || cfg.Sampling[7] != 10 {
+					t.Errorf("LoadConfig() = %+v, want the queue and sampling of the file", cfg)
+				}
+				if len(cfg.Fallback) != 2 || cfg.Fallback[0].File != "/var/log/gelf.log" || !cfg.Fallback[1].Stderr {
+					t.Errorf("LoadConfig() Fallback = %+v, want the fallback chain of the file", cfg.Fallback)
+				}
+			},
+		},
+		{
+			name:    "JSON",
+			file:    "gelf.json",
+			content: jsonConfig,
+			check: func(t *testing.T, cfg *gelflogger.Config) {
+				if cfg.Compression != "gzip" || time.Duration(cfg.Heartbeat) != time.Minute {
+					t.Errorf("LoadConfig() = %+v, want the compression and heartbeat of the file", cfg)
+				}
+				if cfg.Deduplication == nil || time.Duration(cfg.Deduplication.Window) != 10*time.Second || len(cfg.Deduplication.Fields) != 1 {
+					t.Errorf("LoadConfig() Deduplication = %+v, want the deduplication of the file", cfg.Deduplication)
+				}
+			},
+		},
+		{name: "Unknown YAML field", file: "gelf.yml", content: "address: graylog:12201\ncompress: gzip\n", wantErr: "compress"},
+		{name: "Unknown JSON field", file: "gelf.json", content: `{"address": "graylog:12201", "compress": "gzip"}`, wantErr: "compress"},
+		{name: "Invalid duration", file: "gelf.yaml", content: "address: graylog:12201\ntimeout: 3\n", wantErr: "duration"},
+		{name: "Missing address", file: "gelf.yaml", content: "hostname: billing\n", wantErr: "address is missing"},
+		{name: "Unsupported extension", file: "gelf.toml", content: `address = "graylog:12201"`, wantErr: "unsupported config file extension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := gelflogger.LoadConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestNewLoggerFromConfig(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	messages := helper.ReceiveMessages(t, server, '\n')
+	fallbackPath := filepath.Join(t.TempDir(), "fallback.log")
+
+	tests := []struct {
+		name    string
+		cfg     gelflogger.Config
+		wantErr string
+	}{
+		{
+			name: "Framing, hostname and fallback",
+			cfg: gelflogger.Config{
+				Address:  server.Addr().String(),
+				Hostname: "billing",
+				Framing:  "newline",
+				Fallback: []gelflogger.FallbackFileConfig{{File: fallbackPath}, {Stderr: true}},
+			},
+		},
+		{
+			name:    "Invalid values",
+			cfg:     gelflogger.Config{Address: server.Addr().String(), LoadBalancing: "random", Framing: "crlf"},
+			wantErr: `unsupported framing "crlf"`,
+		},
+		{
+			name:    "Invalid short message template",
+			cfg:     gelflogger.Config{Address: server.Addr().String(), ShortMessageTemplate: "{{.method"},
+			wantErr: "invalid short_message_template",
+		},
+		{
+			name:    "Fallback without sink",
+			cfg:     gelflogger.Config{Address: server.Addr().String(), Fallback: []gelflogger.FallbackFileConfig{{MaxSize: 10}}},
+			wantErr: "fallback 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := gelflogger.NewLoggerFromConfig(&tt.cfg, gelflogger.WithProcessor(processNothing))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewLoggerFromConfig() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewLoggerFromConfig() error = %v", err)
+			}
+			if err := logger.Log("configured by file", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			if err := logger.Close(context.Background()); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			select {
+			case message := <-messages:
+				if !strings.Contains(message, `"host":"billing"`) || !strings.Contains(message, "configured by file") {
+					t.Errorf("received %s, want the message with the host of the config", message)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no newline delimited message received")
+			}
+		})
+	}
+}
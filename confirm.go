@@ -0,0 +1,67 @@
+package gelflogger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrConfirmationUnsupported is returned by LogAndConfirm if the transport cannot confirm that Graylog accepted a
+// message, e.g. the TCP and UDP transports, as GELF over TCP and UDP has no acknowledgements.
+var ErrConfirmationUnsupported = errors.New("transport cannot confirm the delivery of messages")
+
+// confirmer is implemented by transports which learn whether Graylog accepted a message, e.g. the HTTP transport.
+type confirmer interface {
+	SendConfirmed(ctx context.Context, message []byte) error
+}
+
+// LogAndConfirm formats the message and its fields as GELF message and sends it right away, returning only once
+// Graylog acknowledged it or the context is done, for audit-grade messages which must not be lost silently. Unlike
+// Log, it bypasses sampling, deduplication, the queue of asynchronous Loggers, the spool and the fallback, so a nil
+// error means Graylog accepted the message. It requires a transport which can confirm the delivery, e.g. the HTTP
+// transport, otherwise ErrConfirmationUnsupported is returned. Once the Logger is closed, ErrLoggerClosed is returned.
+// The fields of the context are added, see WithContextExtractor.
+func (l *Logger) LogAndConfirm(ctx context.Context, message string, fields map[string]interface{}) error {
+	msg, err := l.newGELFMessage(message, l.contextFields(ctx, fields), nil, readLevel)
+	if err != nil || msg == nil {
+		return err
+	}
+	defer l.releaseMessage(msg)
+	gelfMessage, err := l.formatGELFMessage(msg)
+	if err != nil {
+		return l.dropUnencoded(nil, err)
+	}
+	transport, ok := l.route(msg)
+	if !ok {
+		return nil
+	}
+
+	l.closeLock.RLock()
+	defer l.closeLock.RUnlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+	c, ok := transport.(confirmer)
+	if !ok {
+		return ErrConfirmationUnsupported
+	}
+	start := time.Now()
+	err = classify(l.count(start, c.SendConfirmed(ctx, gelfMessage), gelfMessage))
+	l.dropUnsent(err, gelfMessage)
+	return err
+}
+
+// SendConfirmed sends the message through the transport, unless the breaker is open or the transport cannot confirm
+// the delivery.
+func (b *circuitBreaker) SendConfirmed(ctx context.Context, message []byte) error {
+	c, ok := b.transport.(confirmer)
+	if !ok {
+		return ErrConfirmationUnsupported
+	}
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := c.SendConfirmed(ctx, message)
+	b.record(err)
+	return err
+}
@@ -0,0 +1,73 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestLogAndConfirm(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		options      []gelflogger.Option
+		wantErr      error
+		wantRequests int
+	}{
+		{name: "accepted", statuses: []int{http.StatusAccepted}, wantRequests: 1},
+		{name: "OK is not an acknowledgement", statuses: []int{http.StatusOK}, wantErr: gelflogger.ErrPermanent, wantRequests: 1},
+		{
+			name:         "accepted after retries",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusAccepted},
+			wantRequests: 3,
+		},
+		{
+			name:         "retries exhausted",
+			statuses:     []int{http.StatusServiceUnavailable},
+			options:      []gelflogger.Option{gelflogger.WithHTTPRetries(1)},
+			wantErr:      gelflogger.ErrTemporary,
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lock sync.Mutex
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				// The last status answers all further requests
+				w.WriteHeader(tt.statuses[min(requests, len(tt.statuses)-1)])
+				requests++
+			}))
+			defer server.Close()
+
+			logger, err := gelflogger.NewLogger(server.URL, append(tt.options, gelflogger.WithProcessor(processNothing))...)
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+			err = logger.LogAndConfirm(context.Background(), "audit", map[string]interface{}{})
+			if (tt.wantErr == nil && err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("LogAndConfirm() error = %v, want %v", err, tt.wantErr)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		logger := gelflogger.NewLoggerWithTransport(&recordingTransport{}, processNothing)
+		if err := logger.LogAndConfirm(context.Background(), "audit", map[string]interface{}{}); !errors.Is(err, gelflogger.ErrConfirmationUnsupported) {
+			t.Errorf("LogAndConfirm() error = %v, want ErrConfirmationUnsupported", err)
+		}
+	})
+}
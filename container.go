@@ -0,0 +1,108 @@
+package gelflogger
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// cgroupContainerIDPattern matches the 64 hex digits of a container ID in a line of /proc/self/cgroup, e.g. of
+// 0::/system.slice/docker-<id>.scope or 11:memory:/kubepods/burstable/pod<uid>/<id>.
+var cgroupContainerIDPattern = regexp.MustCompile(`[/\-:]([0-9a-f]{64})(?:\.scope)?(?:/|$)`)
+
+// mountContainerIDPattern matches the 64 hex digits of a container ID in a mount point of /proc/self/mountinfo, e.g.
+// /var/lib/docker/containers/<id>/hostname. The IDs of the overlay layers, which are 64 hex digits as well, are not
+// matched.
+var mountContainerIDPattern = regexp.MustCompile(`containers/([0-9a-f]{64})/`)
+
+// shortContainerIDPattern matches the 12 hex digits of the short container ID Docker sets as hostname.
+var shortContainerIDPattern = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// The cached container metadata, see WithContainerMetadata.
+var (
+	containerMetadataOnce   sync.Once
+	containerMetadataFields map[string]interface{}
+)
+
+// WithContainerMetadata adds the metadata of the container the process runs in to every message, so the messages of
+// the replicas of a service can be told apart and traced back to their image:
+//
+//   - _container_id: The ID of the container, read from /proc/self/cgroup, from /proc/self/mountinfo if the cgroup
+//     namespace of the container hides it, or else the short ID Docker sets as hostname in /etc/hostname.
+//   - _container_name and _container_image: The name and image of a Podman container, read from /run/.containerenv.
+//     Docker and Kubernetes do not expose the image to the container.
+//
+// The metadata is looked up once per process and cached for later Loggers. Fields which cannot be detected are
+// omitted, so outside a container, no fields are added. They are static fields, see WithStaticFields.
+func WithContainerMetadata() Option {
+	containerMetadataOnce.Do(func() {
+		containerMetadataFields = containerMetadata(os.DirFS("/"))
+	})
+	return WithStaticFields(containerMetadataFields)
+}
+
+// containerMetadata returns the metadata of the container, see WithContainerMetadata, read from the given root file
+// system.
+func containerMetadata(root fs.FS) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	containerEnv, _ := fs.ReadFile(root, "run/.containerenv")
+	for _, line := range strings.Split(string(containerEnv), "\n") {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if value == "" {
+			continue
+		}
+		switch name {
+		case "id":
+			metadata["container_id"] = value
+		case "name":
+			metadata["container_name"] = value
+		case "image":
+			metadata["container_image"] = value
+		}
+	}
+	if _, ok := metadata["container_id"]; ok {
+		return metadata
+	}
+
+	if id := findContainerID(root, "proc/self/cgroup", cgroupContainerIDPattern); id != "" {
+		metadata["container_id"] = id
+		return metadata
+	}
+	if id := findContainerID(root, "proc/self/mountinfo", mountContainerIDPattern); id != "" {
+		metadata["container_id"] = id
+		return metadata
+	}
+	if _, err := fs.Stat(root, ".dockerenv"); err == nil {
+		hostname, _ := fs.ReadFile(root, "etc/hostname")
+		if id := string(bytes.TrimSpace(hostname)); shortContainerIDPattern.MatchString(id) {
+			metadata["container_id"] = id
+		}
+	}
+	return metadata
+}
+
+// findContainerID returns the first container ID matched by the pattern in the file, or an empty string.
+func findContainerID(root fs.FS, name string, pattern *regexp.Regexp) string {
+	file, err := root.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		for _, field := range strings.Fields(scanner.Text()) {
+			if match := pattern.FindStringSubmatch(field); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
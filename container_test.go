@@ -0,0 +1,78 @@
+package gelflogger_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestContainerMetadata(t *testing.T) {
+	id := strings.Repeat("3f9a2c", 10) + "0b1d"
+	layer := strings.Repeat("e7", 32)
+
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  map[string]interface{}
+	}{
+		{
+			name: "Docker with cgroup v1",
+			files: fstest.MapFS{
+				"proc/self/cgroup": {Data: []byte("12:memory:/docker/" + id + "\n1:name=systemd:/docker/" + id + "\n")},
+			},
+			want: map[string]interface{}{"container_id": id},
+		},
+		{
+			name: "Kubernetes with systemd cgroup driver",
+			files: fstest.MapFS{
+				"proc/self/cgroup": {Data: []byte("0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1a2b.slice/cri-containerd-" + id + ".scope\n")},
+			},
+			want: map[string]interface{}{"container_id": id},
+		},
+		{
+			name: "Docker with cgroup namespace",
+			files: fstest.MapFS{
+				"proc/self/cgroup": {Data: []byte("0::/\n")},
+				"proc/self/mountinfo": {Data: []byte(
+					"612 515 0:62 / / rw,relatime - overlay overlay rw,upperdir=/var/lib/docker/overlay2/" + layer + "/diff\n" +
+						"640 612 254:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n")},
+			},
+			want: map[string]interface{}{"container_id": id},
+		},
+		{
+			name: "Docker hostname",
+			files: fstest.MapFS{
+				".dockerenv":       {},
+				"proc/self/cgroup": {Data: []byte("0::/\n")},
+				"etc/hostname":     {Data: []byte(id[:12] + "\n")},
+			},
+			want: map[string]interface{}{"container_id": id[:12]},
+		},
+		{
+			name: "Podman",
+			files: fstest.MapFS{
+				"run/.containerenv": {Data: []byte("engine=\"podman-4.9.3\"\nname=\"billing\"\nid=\"" + id + "\"\nimage=\"quay.io/acme/billing:1.2\"\nrootless=0\n")},
+			},
+			want: map[string]interface{}{"container_id": id, "container_name": "billing", "container_image": "quay.io/acme/billing:1.2"},
+		},
+		{
+			name: "No container",
+			files: fstest.MapFS{
+				"proc/self/cgroup": {Data: []byte("0::/user.slice/user-1000.slice/session-2.scope\n")},
+				"etc/hostname":     {Data: []byte("workstation\n")},
+			},
+			want: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gelflogger.ContainerMetadata(tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ContainerMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package gelflogger
+
+import (
+	"context"
+	"time"
+)
+
+// ContextExtractor returns the fields a context carries, e.g. the trace and span ID of the active span or the ID of
+// the request being handled, see WithContextExtractor. It returns nil if the context carries none.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// WithContextExtractor adds the fields of the given ContextExtractors to every message logged with a context, i.e. by
+// LogContext, LogAtContext and LogAndConfirm, and by the adapters passing the context of a log call on, e.g. the slog
+// Handler and the gRPC interceptors. The fields passed to the log call take precedence, and the fields of later
+// extractors take precedence over the ones of earlier extractors.
+//
+// Example usage:
+//
+//	logger, err := gelflogger.NewLogger(address, gelflogger.WithContextExtractor(tracecontext.Extract))
+func WithContextExtractor(extractors ...ContextExtractor) Option {
+	return func(c *config) {
+		c.contextExtractors = append(c.contextExtractors, extractors...)
+	}
+}
+
+// LogContext logs the message like Log, with the fields of the context added, see WithContextExtractor.
+func (l *Logger) LogContext(ctx context.Context, message string, fields map[string]interface{}) error {
+	return l.Log(message, l.contextFields(ctx, fields))
+}
+
+// LogAtContext logs the message like LogAt, with the fields of the context added, see WithContextExtractor.
+func (l *Logger) LogAtContext(ctx context.Context, level int, timestamp time.Time, message string, fields map[string]interface{}) error {
+	return l.LogAt(level, timestamp, message, l.contextFields(ctx, fields))
+}
+
+// contextFields returns the fields with the fields of the context extractors added. The given fields are copied
+// rather than modified, as the caller may reuse them, and returned as they are if the context carries no fields.
+func (l *Logger) contextFields(ctx context.Context, fields map[string]interface{}) map[string]interface{} {
+	var extracted map[string]interface{}
+	for _, extractor := range l.contextExtractors {
+		for name, value := range extractor(ctx) {
+			if _, ok := fields[name]; ok {
+				continue
+			}
+			if extracted == nil {
+				extracted = make(map[string]interface{}, len(fields)+2)
+			}
+			extracted[name] = value
+		}
+	}
+	if extracted == nil {
+		return fields
+	}
+	for name, value := range fields {
+		extracted[name] = value
+	}
+	return extracted
+}
@@ -0,0 +1,115 @@
+package gelflogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// requestIDKey is the context key of the request ID of the tests.
+type requestIDKey struct{}
+
+// extractRequestID is a ContextExtractor returning the request ID of the context.
+func extractRequestID(ctx context.Context) map[string]interface{} {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return map[string]interface{}{"request_id": id, "trace_id": "from-request"}
+	}
+	return nil
+}
+
+// extractTraceID is a ContextExtractor returning a fixed trace ID.
+func extractTraceID(context.Context) map[string]interface{} {
+	return map[string]interface{}{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}
+}
+
+func TestLogContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		extractors []gelflogger.ContextExtractor
+		ctx        context.Context
+		fields     map[string]interface{}
+		want       []string
+		unwanted   []string
+	}{
+		{
+			name:       "Fields of the context are added",
+			extractors: []gelflogger.ContextExtractor{extractRequestID},
+			ctx:        context.WithValue(context.Background(), requestIDKey{}, "r-1"),
+			fields:     map[string]interface{}{"order_id": "o-7"},
+			want:       []string{`"_request_id":"r-1"`, `"_order_id":"o-7"`},
+		},
+		{
+			name:       "Fields of the log call take precedence",
+			extractors: []gelflogger.ContextExtractor{extractRequestID},
+			ctx:        context.WithValue(context.Background(), requestIDKey{}, "r-1"),
+			fields:     map[string]interface{}{"request_id": "r-2"},
+			want:       []string{`"_request_id":"r-2"`},
+			unwanted:   []string{`"_request_id":"r-1"`},
+		},
+		{
+			name:       "Later extractors take precedence",
+			extractors: []gelflogger.ContextExtractor{extractRequestID, extractTraceID},
+			ctx:        context.WithValue(context.Background(), requestIDKey{}, "r-1"),
+			want:       []string{`"_request_id":"r-1"`, `"_trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`},
+			unwanted:   []string{"from-request"},
+		},
+		{
+			name:       "Context without fields",
+			extractors: []gelflogger.ContextExtractor{extractRequestID},
+			ctx:        context.Background(),
+			fields:     map[string]interface{}{"order_id": "o-7"},
+			want:       []string{`"_order_id":"o-7"`},
+			unwanted:   []string{"request_id"},
+		},
+		{
+			name:     "No extractors",
+			ctx:      context.WithValue(context.Background(), requestIDKey{}, "r-1"),
+			unwanted: []string{"request_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, gelflogger.WithContextExtractor(tt.extractors...))
+			var fields map[string]interface{}
+			if tt.fields != nil {
+				fields = make(map[string]interface{}, len(tt.fields))
+				for name, value := range tt.fields {
+					fields[name] = value
+				}
+			}
+			if err := logger.LogContext(tt.ctx, "order placed", fields); err != nil {
+				t.Fatalf("LogContext() error = %v", err)
+			}
+			if err := logger.LogAtContext(tt.ctx, gelflogger.Warning, time.Unix(1700000000, 0), "order delayed", fields); err != nil {
+				t.Fatalf("LogAtContext() error = %v", err)
+			}
+
+			if len(transport.messages) != 2 {
+				t.Fatalf("sent %d messages, want 2", len(transport.messages))
+			}
+			for _, message := range transport.messages {
+				for _, want := range tt.want {
+					if !strings.Contains(message, want) {
+						t.Errorf("sent %s, want %s", message, want)
+					}
+				}
+				for _, unwanted := range tt.unwanted {
+					if strings.Contains(message, unwanted) {
+						t.Errorf("sent %s, want no %s", message, unwanted)
+					}
+				}
+			}
+			if !strings.Contains(transport.messages[1], `"level":4`) || !strings.Contains(transport.messages[1], `"timestamp":1700000000`) {
+				t.Errorf("sent %s, want the level and timestamp of LogAtContext", transport.messages[1])
+			}
+			if len(fields) != len(tt.fields) {
+				t.Errorf("fields of the log call = %v, want them unmodified", fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package gelflogger
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// repeats is a message which was repeated within the deduplication window, see WithDeduplication.
+type repeats struct {
+	msg    *Message
+	static []staticField
+	count  int
+}
+
+// summary turns the last repetition into the "message repeated N times" record, carrying the number of suppressed
+// repetitions in the additional field _repeat_count. The additional fields of the repetition are a copy made by
+// deduplicator.check, so they are modified in place.
+func (r repeats) summary() *Message {
+	summary := *r.msg
+	summary.ShortMessage = fmt.Sprintf("message repeated %d times: %s", r.count, r.msg.ShortMessage)
+	if summary.Additional == nil {
+		summary.Additional = make(map[string]interface{}, 1)
+	}
+	summary.Additional["repeat_count"] = r.count
+	return &summary
+}
+
+// deduplicator suppresses identical consecutive messages within a time window, like classic syslog daemons do. The
+// first message is sent, its repetitions are counted and reported by a single summary record, once a different
+// message is logged, the window elapses or the Logger is closed.
+type deduplicator struct {
+	window time.Duration
+	fields []string
+	emit   func(repeats)
+
+	lock       sync.Mutex
+	key        string
+	started    time.Time
+	generation uint64
+	pending    repeats
+	timer      *time.Timer
+}
+
+// newDeduplicator creates a deduplicator comparing the short message, the level and the given fields of the messages
+// and passing the repetitions to emit, or returns nil if window is zero or less.
+func newDeduplicator(window time.Duration, fields []string, emit func(repeats)) *deduplicator {
+	if window <= 0 {
+		return nil
+	}
+	return &deduplicator{window: window, fields: fields, emit: emit}
+}
+
+// identify builds the key identifying identical messages. Messages of Loggers with different static fields, e.g.
+// derived by Logger.With, are never identical.
+func (d *deduplicator) identify(msg *Message, static []staticField) string {
+	var key strings.Builder
+	fmt.Fprintf(&key, "%d\x00%s", msg.Level, msg.ShortMessage)
+	for _, name := range d.fields {
+		fmt.Fprintf(&key, "\x00%v", msg.Additional[name])
+	}
+	for _, field := range static {
+		key.WriteByte(0)
+		key.Write(field.encoded)
+	}
+	return key.String()
+}
+
+// check reports whether the message repeats the previous one within the window and is suppressed. Otherwise, the
+// repetitions of the previous message are emitted before the message is sent.
+func (d *deduplicator) check(msg *Message, static []staticField) bool {
+	key := d.identify(msg, static)
+	now := time.Now()
+
+	d.lock.Lock()
+	if key == d.key && now.Sub(d.started) < d.window {
+		// The additional fields are copied right away, as they may be the map of the caller, who may reuse it once the
+		// log call returned
+		repeated := *msg
+		repeated.Additional = maps.Clone(msg.Additional)
+		d.pending = repeats{msg: &repeated, static: static, count: d.pending.count + 1}
+		if d.pending.count == 1 {
+			generation := d.generation
+			d.timer = time.AfterFunc(d.window-now.Sub(d.started), func() { d.expire(generation) })
+		}
+		d.lock.Unlock()
+		return true
+	}
+	pending := d.reset()
+	d.key = key
+	d.started = now
+	d.lock.Unlock()
+
+	if pending.count > 0 {
+		d.emit(pending)
+	}
+	return false
+}
+
+// expire emits the repetitions once the window of the message elapsed, unless a different message was logged since.
+func (d *deduplicator) expire(generation uint64) {
+	d.lock.Lock()
+	if generation != d.generation {
+		d.lock.Unlock()
+		return
+	}
+	pending := d.reset()
+	d.lock.Unlock()
+
+	if pending.count > 0 {
+		d.emit(pending)
+	}
+}
+
+// flush emits the pending repetitions, so they are not lost when the Logger is closed.
+func (d *deduplicator) flush() {
+	if d == nil {
+		return
+	}
+	d.lock.Lock()
+	pending := d.reset()
+	d.lock.Unlock()
+
+	if pending.count > 0 {
+		d.emit(pending)
+	}
+}
+
+// reset forgets the previous message and returns its pending repetitions. The lock must be held.
+func (d *deduplicator) reset() repeats {
+	pending := d.pending
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.key = ""
+	d.pending = repeats{}
+	d.generation++
+	return pending
+}
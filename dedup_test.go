@@ -0,0 +1,158 @@
+package gelflogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// sentMessages returns the short messages sent through the transport, with the repeat count of the summaries.
+func sentMessages(t *testing.T, transport *recordingTransport) []string {
+	t.Helper()
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	var sent []string
+	for _, message := range transport.messages {
+		var gelfMsg map[string]interface{}
+		if err := json.Unmarshal([]byte(message), &gelfMsg); err != nil {
+			t.Fatalf("invalid GELF message %s: %v", message, err)
+		}
+		shortMessage := gelfMsg["short_message"].(string)
+		if count, ok := gelfMsg["_repeat_count"]; ok {
+			shortMessage = shortMessage + " #" + jsonString(t, count)
+		}
+		sent = append(sent, shortMessage)
+	}
+	return sent
+}
+
+func jsonString(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestDeduplication(t *testing.T) {
+	type entry struct {
+		message string
+		request int
+	}
+	tests := []struct {
+		name    string
+		fields  []string
+		entries []entry
+		want    []string
+	}{
+		{
+			name:    "repeated message",
+			entries: []entry{{"a", 1}, {"a", 1}, {"a", 1}, {"b", 1}},
+			want:    []string{"a", "message repeated 2 times: a #2", "b"},
+		},
+		{
+			name:    "fields which are not compared",
+			entries: []entry{{"a", 1}, {"a", 2}},
+			want:    []string{"a", "message repeated 1 times: a #1"},
+		},
+		{
+			name:    "compared fields differ",
+			fields:  []string{"request"},
+			entries: []entry{{"a", 1}, {"a", 2}, {"a", 2}},
+			want:    []string{"a", "a", "message repeated 1 times: a #1"},
+		},
+		{
+			name:    "no repetition",
+			entries: []entry{{"a", 1}, {"b", 1}, {"a", 1}},
+			want:    []string{"a", "b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing,
+				gelflogger.WithDeduplication(time.Hour, tt.fields...))
+			for _, e := range tt.entries {
+				if err := logger.Log(e.message, map[string]interface{}{"request": e.request}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+			// Closing sends the pending repetitions
+			if err := logger.Close(context.Background()); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if got := sentMessages(t, transport); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sent %q, want %q", got, tt.want)
+			}
+			if err := logger.Log("a", map[string]interface{}{}); err != gelflogger.ErrLoggerClosed {
+				t.Errorf("Log() after Close() error = %v, want ErrLoggerClosed", err)
+			}
+		})
+	}
+}
+
+func TestDeduplicationWindow(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing,
+		gelflogger.WithDeduplication(50*time.Millisecond))
+	defer logger.Close(context.Background())
+
+	for i := 0; i < 3; i++ {
+		if err := logger.Log("a", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	// The summary is sent once the window elapsed, without waiting for another message
+	want := []string{"a", "message repeated 2 times: a #2"}
+	for deadline := time.Now().Add(5 * time.Second); !reflect.DeepEqual(sentMessages(t, transport), want); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("sent %q, want %q", sentMessages(t, transport), want)
+		}
+	}
+
+	// A repetition after the window is sent again
+	if err := logger.Log("a", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	want = append(want, "a")
+	if got := sentMessages(t, transport); !reflect.DeepEqual(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestDeduplicationReusedFields(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithDeduplication(time.Minute))
+
+	// The caller reuses its fields map after every log call
+	fields := map[string]interface{}{"request": 1}
+	for i := 0; i < 3; i++ {
+		if err := logger.Log("a", fields); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+		fields["request"] = i + 2
+	}
+	fields["request"] = "reused"
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	if len(transport.messages) != 2 {
+		t.Fatalf("sent %d messages, want the message and its summary", len(transport.messages))
+	}
+	var summary map[string]interface{}
+	if err := json.Unmarshal([]byte(transport.messages[1]), &summary); err != nil {
+		t.Fatalf("invalid GELF message %s: %v", transport.messages[1], err)
+	}
+	if got := summary["_request"]; got != float64(3) {
+		t.Errorf("_request of the summary = %v, want 3, the value of the last repetition", got)
+	}
+}
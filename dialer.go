@@ -0,0 +1,120 @@
+package gelflogger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// interleaveFamilies reorders the resolved targets so IPv6 and IPv4 addresses alternate, starting with the family of
+// the first target, as recommended by RFC 8305. The order within each family is kept.
+func interleaveFamilies(targets []string) []string {
+	var first, second []string
+	for _, target := range targets {
+		if isIPv6(target) == isIPv6(targets[0]) {
+			first = append(first, target)
+		} else {
+			second = append(second, target)
+		}
+	}
+	if len(second) == 0 {
+		return targets
+	}
+	interleaved := make([]string, 0, len(targets))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			interleaved = append(interleaved, first[i])
+		}
+		if i < len(second) {
+			interleaved = append(interleaved, second[i])
+		}
+	}
+	return interleaved
+}
+
+// isIPv6 reports whether the host of the given target, an IP address joined with a port, is an IPv6 address.
+func isIPv6(target string) bool {
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.To4() == nil
+}
+
+// dialResult is the outcome of a single connection attempt of dialParallel.
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// dialParallel connects to the first reachable of the targets, racing the connection attempts in the style of
+// RFC 8305 (Happy Eyeballs): the targets are dialed in order, and whenever an attempt neither succeeded nor failed
+// within the attempt delay, the next target is dialed concurrently. An attempt failing starts the next one right
+// away. The first established connection wins, all other attempts are canceled and their connections closed.
+// So a host whose IPv6 route silently drops packets is reached over IPv4 after the attempt delay instead of only
+// after the dial timeout. A delay of zero or less dials the targets one after another.
+func dialParallel(ctx context.Context, dialer *net.Dialer, network string, targets []string, delay time.Duration) (net.Conn, error) {
+	if delay <= 0 || len(targets) == 1 {
+		var errs []error
+		for _, target := range targets {
+			conn, err := dialer.DialContext(ctx, network, target)
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan dialResult, len(targets))
+	next, pending := 0, 0
+	start := func() {
+		target := targets[next]
+		next++
+		pending++
+		go func() {
+			conn, err := dialer.DialContext(ctx, network, target)
+			results <- dialResult{conn: conn, err: err}
+		}()
+	}
+
+	start()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	errs := make([]error, 0, len(targets))
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if next < len(targets) {
+				start()
+				timer.Reset(delay)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				go closeDialed(results, pending)
+				return result.conn, nil
+			}
+			errs = append(errs, result.err)
+			if next < len(targets) {
+				start()
+				timer.Reset(delay)
+			}
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// closeDialed closes the connections of the given number of pending attempts which lost the race of dialParallel
+// but still succeeded before they were canceled.
+func closeDialed(results <-chan dialResult, pending int) {
+	for ; pending > 0; pending-- {
+		if result := <-results; result.conn != nil {
+			_ = result.conn.Close()
+		}
+	}
+}
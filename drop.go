@@ -0,0 +1,54 @@
+package gelflogger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrEncode is wrapped by the errors of messages which could not be encoded as GELF message, e.g. because the
+// processor returned an error, both the errors returned by Log and the reasons reported to the OnDrop callback. The
+// errors wrap the underlying error as well.
+var ErrEncode = errors.New("message could not be encoded")
+
+// ErrEncodeFailed is the former name of ErrEncode.
+//
+// Deprecated: Use ErrEncode.
+var ErrEncodeFailed = ErrEncode
+
+// ErrSendFailed is the reason reported to the OnDrop callback for messages dropped because they could be sent neither
+// through their transport nor handed to the fallback or spool. The reason wraps the underlying error.
+var ErrSendFailed = errors.New("message could not be sent")
+
+// drop counts the message as dropped and reports it to the OnDrop callback, if configured.
+func (l *Logger) drop(message []byte, reason error) {
+	l.dropped.Add(1)
+	if l.onDrop != nil {
+		l.onDrop(message, reason)
+	}
+}
+
+// dropUnencoded drops a message which could not be encoded, message is the raw input if there is one, or nil. It
+// returns the error wrapping ErrEncode, classified as permanent.
+func (l *Logger) dropUnencoded(message []byte, err error) error {
+	err = fmt.Errorf("%w: %w", ErrEncode, err)
+	l.drop(message, err)
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// dropUnsent drops the messages if err reports that they could not be sent.
+func (l *Logger) dropUnsent(err error, messages ...[]byte) {
+	if err == nil {
+		return
+	}
+	for _, message := range messages {
+		l.drop(message, fmt.Errorf("%w: %w", ErrSendFailed, err))
+	}
+}
+
+// Dropped returns the number of messages the Logger dropped since it was created: messages dropped because the
+// queue of an asynchronous Logger was full, see WithOverflowPolicy, messages which could not be encoded and messages
+// which could not be sent, not even to the fallback or spool. For synchronous Loggers, the latter two are reported by
+// Log as well. See WithOnDrop for a callback receiving the dropped messages.
+func (l *Logger) Dropped() uint64 {
+	return l.dropped.Load()
+}
@@ -0,0 +1,131 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestDroppedMessages(t *testing.T) {
+	failingProcessor := func(fields map[string]interface{}) (int, float64, []byte, error) {
+		return 0, 0, nil, errors.New("invalid fields")
+	}
+	tests := []struct {
+		name       string
+		processor  func(fields map[string]interface{}) (int, float64, []byte, error)
+		transport  *recordingTransport
+		options    []gelflogger.Option
+		write      []byte
+		wantReason error
+		wantErr    bool
+	}{
+		{
+			name:       "processor error",
+			processor:  failingProcessor,
+			transport:  &recordingTransport{},
+			wantReason: gelflogger.ErrEncodeFailed,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid JSON written to the GelfWriter",
+			processor:  processNothing,
+			transport:  &recordingTransport{},
+			write:      []byte("not JSON"),
+			wantReason: gelflogger.ErrEncodeFailed,
+			wantErr:    true,
+		},
+		{
+			name:       "send failure",
+			processor:  processNothing,
+			transport:  &recordingTransport{down: true},
+			wantReason: gelflogger.ErrSendFailed,
+			wantErr:    true,
+		},
+		{
+			name:       "send failure of an asynchronous Logger",
+			processor:  processNothing,
+			transport:  &recordingTransport{down: true},
+			options:    []gelflogger.Option{gelflogger.WithAsync(10, 1)},
+			wantReason: gelflogger.ErrSendFailed,
+		},
+		{
+			name:       "send failure of a batching Logger",
+			processor:  processNothing,
+			transport:  &recordingTransport{down: true},
+			options:    []gelflogger.Option{gelflogger.WithBatching(10, 0)},
+			wantReason: gelflogger.ErrSendFailed,
+		},
+		{
+			name:      "send failure taken by the fallback",
+			processor: processNothing,
+			transport: &recordingTransport{down: true},
+			options:   []gelflogger.Option{gelflogger.WithFallback(&recordingTransport{})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lock sync.Mutex
+			var reasons []error
+			options := append([]gelflogger.Option{gelflogger.WithOnDrop(func(message []byte, reason error) {
+				lock.Lock()
+				defer lock.Unlock()
+				reasons = append(reasons, reason)
+			})}, tt.options...)
+			logger := gelflogger.NewLoggerWithTransport(tt.transport, tt.processor, options...)
+
+			var err error
+			if tt.write != nil {
+				writer := gelflogger.GelfWriter{Logger: logger}
+				_, err = writer.Write(tt.write)
+			} else {
+				err = logger.Log("dropped", map[string]interface{}{})
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Log() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := logger.Close(context.Background()); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if tt.wantReason == nil {
+				if len(reasons) != 0 || logger.Dropped() != 0 {
+					t.Errorf("dropped %d messages for %v, want none", logger.Dropped(), reasons)
+				}
+				return
+			}
+			if len(reasons) != 1 || logger.Dropped() != 1 {
+				t.Fatalf("dropped %d messages for %v, want 1", logger.Dropped(), reasons)
+			}
+			if !errors.Is(reasons[0], tt.wantReason) {
+				t.Errorf("OnDrop() reason = %v, want %v", reasons[0], tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDroppedWriterMessages(t *testing.T) {
+	var reasons []error
+	transport := gelflogger.CheckedTransport{Transport: &recordingTransport{}, Check: func() error {
+		return gelflogger.ErrNotConnected
+	}}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithOnDrop(func(message []byte, reason error) {
+		reasons = append(reasons, reason)
+	}))
+	writer := gelflogger.GelfWriter{Logger: logger}
+
+	if _, err := writer.Write([]byte(`{"message":"lost"}`)); !errors.Is(err, gelflogger.ErrNotConnected) {
+		t.Fatalf("Write() error = %v, want ErrNotConnected", err)
+	}
+	if len(reasons) != 1 || !errors.Is(reasons[0], gelflogger.ErrSendFailed) {
+		t.Fatalf("OnDrop() reasons = %v, want one wrapping ErrSendFailed", reasons)
+	}
+	if stats := logger.Stats(); stats.Dropped != 1 || stats.SendErrors != 1 {
+		t.Errorf("Stats() = %+v, want 1 dropped message and 1 send error", stats)
+	}
+}
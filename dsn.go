@@ -0,0 +1,192 @@
+package gelflogger
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewLoggerFromDSN creates a new Logger configured by a single DSN, e.g. from an environment variable or a flag:
+//
+//	gelf+tls://graylog.example.com:12201?compress=gzip&queue=10000&timeout=3s
+//
+// The scheme selects the transport like the scheme of the address of NewLogger, optionally prefixed with "gelf+":
+// gelf+tcp, gelf+tls, gelf+udp, gelf+http, gelf+https and gelf+unix. The scheme gelf alone selects TCP. The query
+// parameters configure the Logger, see ParseDSN. The given Options are applied after the ones of the DSN.
+//
+// Example usage:
+//
+//	logger, err := gelflogger.NewLoggerFromDSN(os.Getenv("GELF_DSN"), gelflogger.WithProcessor(zerologger.ProcessZerologFields))
+func NewLoggerFromDSN(dsn string, opts ...Option) (*Logger, error) {
+	address, dsnOpts, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogger(address, append(dsnOpts, opts...)...)
+}
+
+// ParseDSN parses the DSN into the address and the Options passed to NewLogger, see NewLoggerFromDSN. The following
+// query parameters are supported, unknown parameters and invalid values are rejected:
+//
+//   - compress: the Compression of the UDP and HTTP transports, none, gzip or zlib, see WithCompression.
+//   - queue, workers: the queue size and the number of workers of an asynchronous Logger, see WithAsync.
+//   - overflow: the OverflowPolicy of the queue, block, drop_newest or drop_oldest, see WithOverflowPolicy.
+//   - batch, flush: the batch size and the flush interval, see WithBatching.
+//   - timeout: the timeout of connecting, e.g. 3s, see WithTimeout.
+//   - write_timeout: the timeout of the TCP writes, see WithWriteTimeout.
+//   - retries: the retry budget of temporary failures, see WithRetry.
+//   - hostname: the host field of the messages, see WithHostname.
+//   - facility: the _facility field of the messages, see WithFacility.
+//   - hostname_refresh: the interval of looking up the hostname again, see WithHostnameRefresh.
+//   - failover: the comma separated failover addresses, see WithFailoverAddresses.
+//   - pool: the number of connections of a connection pool, see WithConnectionPool.
+//   - framing: the delimiter of the TCP messages, null or newline, see WithFramingMode.
+//   - heartbeat: the interval of the heartbeat messages, see WithHeartbeat.
+//   - ca, cert, key: the CA bundle and client certificate files for TLS, see WithCAFile and WithClientCertificateFiles.
+func ParseDSN(dsn string) (string, []Option, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	scheme := strings.TrimPrefix(strings.ToLower(u.Scheme), "gelf+")
+	var address string
+	switch scheme {
+	case "gelf", "tcp", "tls", "udp":
+		if scheme == "gelf" {
+			scheme = "tcp"
+		}
+		address = scheme + "://" + u.Host
+	case "http", "https":
+		address = (&url.URL{Scheme: scheme, User: u.User, Host: u.Host, Path: u.Path}).String()
+	case "unix":
+		address = "unix://" + u.Path
+	default:
+		return "", nil, fmt.Errorf("unsupported scheme %q in DSN", u.Scheme)
+	}
+
+	p := dsnParams{query: u.Query()}
+	var opts []Option
+	if compress, ok := p.take("compress"); ok {
+		compression, err := ParseCompression(compress)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid DSN parameter compress: %w", err))
+		}
+		opts = append(opts, WithCompression(compression))
+	}
+	queue, hasQueue := p.int("queue")
+	workers, hasWorkers := p.int("workers")
+	if hasQueue || hasWorkers {
+		opts = append(opts, WithAsync(queue, workers))
+	}
+	if overflow, ok := p.take("overflow"); ok {
+		policy, err := parseOverflowPolicy(overflow)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid DSN parameter overflow: %w", err))
+		}
+		opts = append(opts, WithOverflowPolicy(policy))
+	}
+	batch, hasBatch := p.int("batch")
+	flush, hasFlush := p.duration("flush")
+	if hasBatch || hasFlush {
+		opts = append(opts, WithBatching(batch, flush))
+	}
+	if timeout, ok := p.duration("timeout"); ok {
+		opts = append(opts, WithTimeout(timeout))
+	}
+	if timeout, ok := p.duration("write_timeout"); ok {
+		opts = append(opts, WithWriteTimeout(timeout))
+	}
+	if retries, ok := p.int("retries"); ok {
+		opts = append(opts, WithRetry(retries, 0))
+	}
+	if hostname, ok := p.take("hostname"); ok {
+		opts = append(opts, WithHostname(hostname))
+	}
+	if facility, ok := p.take("facility"); ok {
+		opts = append(opts, WithFacility(facility))
+	}
+	if interval, ok := p.duration("hostname_refresh"); ok {
+		opts = append(opts, WithHostnameRefresh(interval))
+	}
+	if failover, ok := p.take("failover"); ok {
+		opts = append(opts, WithFailoverAddresses(strings.Split(failover, ",")...))
+	}
+	if pool, ok := p.int("pool"); ok {
+		opts = append(opts, WithConnectionPool(pool))
+	}
+	if framing, ok := p.take("framing"); ok {
+		mode, err := parseFramingMode(framing)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid DSN parameter framing: %w", err))
+		}
+		opts = append(opts, WithFramingMode(mode))
+	}
+	if interval, ok := p.duration("heartbeat"); ok {
+		opts = append(opts, WithHeartbeat(interval))
+	}
+	if ca, ok := p.take("ca"); ok {
+		opts = append(opts, WithCAFile(ca))
+	}
+	cert, hasCert := p.take("cert")
+	key, hasKey := p.take("key")
+	if hasCert != hasKey {
+		p.errs = append(p.errs, fmt.Errorf("invalid DSN: the parameters cert and key must be given together"))
+	} else if hasCert {
+		opts = append(opts, WithClientCertificateFiles(cert, key))
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.query)) {
+		p.errs = append(p.errs, fmt.Errorf("unknown DSN parameter %s", name))
+	}
+	if err := errors.Join(p.errs...); err != nil {
+		return "", nil, err
+	}
+	return address, opts, nil
+}
+
+// dsnParams takes the query parameters of a DSN one after another, so the remaining ones are unknown, and collects
+// the errors of invalid values.
+type dsnParams struct {
+	query url.Values
+	errs  []error
+}
+
+// take removes the parameter from the query and returns its value, and whether it was given.
+func (p *dsnParams) take(name string) (string, bool) {
+	if !p.query.Has(name) {
+		return "", false
+	}
+	value := p.query.Get(name)
+	p.query.Del(name)
+	return value, true
+}
+
+// int takes the parameter as integer.
+func (p *dsnParams) int(name string) (int, bool) {
+	value, ok := p.take(name)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("invalid DSN parameter %s: %w", name, err))
+	}
+	return n, true
+}
+
+// duration takes the parameter as time.Duration, e.g. 3s.
+func (p *dsnParams) duration(name string) (time.Duration, bool) {
+	value, ok := p.take(name)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("invalid DSN parameter %s: %w", name, err))
+	}
+	return d, true
+}
@@ -0,0 +1,81 @@
+package gelflogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		name        string
+		dsn         string
+		wantAddress string
+		wantOptions int
+		wantErr     string
+	}{
+		{name: "TLS with options", dsn: "gelf+tls://graylog.example.com:12201?compress=gzip&queue=10000&timeout=3s", wantAddress: "tls://graylog.example.com:12201", wantOptions: 3},
+		{name: "Plain gelf scheme", dsn: "gelf://graylog.example.com:12201", wantAddress: "tcp://graylog.example.com:12201"},
+		{name: "Scheme without prefix", dsn: "udp://graylog.example.com?compress=zlib", wantAddress: "udp://graylog.example.com", wantOptions: 1},
+		{name: "HTTP keeps the path", dsn: "gelf+https://graylog.example.com/custom?retries=3&hostname=billing", wantAddress: "https://graylog.example.com/custom", wantOptions: 2},
+		{name: "Unix socket", dsn: "gelf+unix:///run/gelf.sock?framing=newline", wantAddress: "unix:///run/gelf.sock", wantOptions: 1},
+		{name: "Queue and workers", dsn: "gelf://graylog:12201?queue=10&workers=2&overflow=drop_oldest&batch=5&flush=1s", wantAddress: "tcp://graylog:12201", wantOptions: 3},
+		{name: "Unsupported scheme", dsn: "gelf+ftp://graylog:12201", wantErr: "unsupported scheme"},
+		{name: "Unknown parameter", dsn: "gelf://graylog:12201?compression=gzip", wantErr: "unknown DSN parameter compression"},
+		{name: "Invalid duration", dsn: "gelf://graylog:12201?timeout=3", wantErr: "invalid DSN parameter timeout"},
+		{name: "Invalid number", dsn: "gelf://graylog:12201?queue=many", wantErr: "invalid DSN parameter queue"},
+		{name: "Invalid compression", dsn: "gelf://graylog:12201?compress=brotli", wantErr: "invalid DSN parameter compress"},
+		{name: "Certificate without key", dsn: "gelf+tls://graylog:12201?cert=client.crt", wantErr: "cert and key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, opts, err := gelflogger.ParseDSN(tt.dsn)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseDSN() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDSN() error = %v", err)
+			}
+			if address != tt.wantAddress {
+				t.Errorf("ParseDSN() address = %s, want %s", address, tt.wantAddress)
+			}
+			if len(opts) != tt.wantOptions {
+				t.Errorf("ParseDSN() returned %d options, want %d", len(opts), tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestNewLoggerFromDSN(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	messages := helper.ReceiveMessages(t, server, '\n')
+
+	logger, err := gelflogger.NewLoggerFromDSN("gelf+tcp://"+server.Addr().String()+"?framing=newline&hostname=billing&queue=10",
+		gelflogger.WithProcessor(processNothing))
+	if err != nil {
+		t.Fatalf("NewLoggerFromDSN() error = %v", err)
+	}
+	if err := logger.Log("configured by DSN", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case message := <-messages:
+		if !strings.Contains(message, `"host":"billing"`) || !strings.Contains(message, "configured by DSN") {
+			t.Errorf("received %s, want the message with the host of the DSN", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no newline delimited message received")
+	}
+}
@@ -0,0 +1,316 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// hexDigits are the digits of the \u escapes of JSON strings.
+const hexDigits = "0123456789abcdef"
+
+// AppendGELF appends the GELF JSON encoding of the message to dst and returns the extended buffer, like MarshalGELF,
+// but without allocating for the common case of string and number fields if dst has enough capacity, e.g. a buffer
+// reused across messages.
+func (m *Message) AppendGELF(dst []byte) ([]byte, error) {
+	return m.appendGELF(dst, messageEncoding{}, nil)
+}
+
+// appendGELF appends the GELF JSON encoding of the message with the given settings and static fields to dst. The
+// JSON is written directly, without building the members in a map first, see gelfFields for the same encoding as
+// map. A static field is left out if the message has an additional field of the same name, so the fields of a log call
+// take precedence. On error, the content appended to dst is undefined.
+func (m *Message) appendGELF(dst []byte, enc messageEncoding, static []staticField) ([]byte, error) {
+	msg := *m
+	if enc.splitMultiLine {
+		splitMultiLine(&msg)
+	}
+	if enc.shortMessageLimit > 0 {
+		truncateShortMessage(&msg, enc.shortMessageLimit)
+	}
+	fields := msg.Additional
+	if enc.flattenDepth > 0 {
+		fields = getFieldMap()
+		defer putFieldMap(fields)
+		flattenFieldsInto(fields, msg.Additional, enc.flattenDepth)
+	}
+	for k, v := range fields {
+		if errVal, ok := v.(error); ok && !isNil(errVal) {
+			appendErrorDetails(&msg, k, errVal)
+		}
+	}
+
+	dst = append(dst, `{"version":`...)
+	dst = appendJSONString(dst, msg.Version)
+	dst = append(dst, `,"host":`...)
+	dst = appendJSONString(dst, msg.Host)
+	dst = append(dst, `,"short_message":`...)
+	dst = appendString(dst, msg.ShortMessage, enc.sanitize)
+	// The full message is optional, omit it rather than sending it empty
+	if msg.FullMessage != "" {
+		dst = append(dst, `,"full_message":`...)
+		dst = appendString(dst, msg.FullMessage, enc.sanitize)
+	}
+	if math.IsNaN(msg.Timestamp) || math.IsInf(msg.Timestamp, 0) {
+		return dst, fmt.Errorf("unsupported timestamp %v", msg.Timestamp)
+	}
+	dst = append(dst, `,"timestamp":`...)
+	dst = appendJSONFloat(dst, msg.Timestamp)
+	dst = append(dst, `,"level":`...)
+	dst = strconv.AppendInt(dst, int64(msg.Level), 10)
+
+	sampled := msg.sampleRate > 1
+	for k, v := range fields {
+		if sampled && (k == "sampled" || k == "sample_rate") {
+			continue
+		}
+		mark := len(dst)
+		dst = append(dst, ',')
+		var err error
+		dst, err = appendFieldName(dst, k, enc.strictFieldNames)
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, ':')
+		var ok bool
+		dst, ok, err = appendFieldValue(dst, v, enc.strictFieldTypes, enc.sanitize)
+		if err != nil {
+			return dst, fmt.Errorf("field %s: %w", k, err)
+		}
+		if !ok {
+			dst = dst[:mark]
+		}
+	}
+	if sampled {
+		dst = append(dst, `,"_sampled":"true","_sample_rate":`...)
+		dst = strconv.AppendUint(dst, msg.sampleRate, 10)
+	}
+	for _, field := range static {
+		if overridesStaticField(fields, field.key) {
+			continue
+		}
+		dst = append(dst, ',')
+		dst = append(dst, field.encoded...)
+	}
+	return append(dst, '}'), nil
+}
+
+// overridesStaticField reports whether the fields of a message set the static field of the given key. Fields whose
+// sanitized name merely collides with the key are not detected, see gelfFieldName.
+func overridesStaticField(fields map[string]interface{}, key string) bool {
+	value, ok := fields[key[1:]]
+	if !ok && key == "_id_" {
+		value, ok = fields["id"]
+	}
+	return ok && value != nil && !isNil(value)
+}
+
+// appendFieldName appends the JSON encoded name of the additional field of the given field name to dst, see
+// gelfFieldName. Valid names are appended without allocating.
+func appendFieldName(dst []byte, name string, strict bool) ([]byte, error) {
+	if name != "id" && strings.IndexFunc(name, invalidFieldNameRune) < 0 {
+		// Valid names need no escaping
+		dst = append(dst, '"', '_')
+		dst = append(dst, name...)
+		return append(dst, '"'), nil
+	}
+	key, err := gelfFieldName(name, strict)
+	if err != nil {
+		return dst, err
+	}
+	return appendJSONString(dst, key), nil
+}
+
+// appendFieldValue appends the value of an additional field coerced to a JSON string or number to dst, see
+// coerceFieldValue, and reports whether the value was appended, false if it is omitted. Strings are sanitized if
+// sanitize is set, see appendString. Strings, numbers and booleans are appended without allocating.
+func appendFieldValue(dst []byte, value interface{}, strict, sanitize bool) ([]byte, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return dst, false, nil
+	case string:
+		return appendString(dst, v, sanitize), true, nil
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int8:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int16:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int32:
+		return strconv.AppendInt(dst, int64(v), 10), true, nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), true, nil
+	case uint:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint8:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint16:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint32:
+		return strconv.AppendUint(dst, uint64(v), 10), true, nil
+	case uint64:
+		return strconv.AppendUint(dst, v, 10), true, nil
+	case float64:
+		return appendFieldFloat(dst, v), true, nil
+	case float32:
+		return appendFieldFloat(dst, float64(v)), true, nil
+	case bool:
+		dst = append(dst, '"')
+		dst = strconv.AppendBool(dst, v)
+		return append(dst, '"'), true, nil
+	case json.Number:
+		if !validJSONNumber(string(v)) {
+			return dst, false, fmt.Errorf("invalid number literal %q", string(v))
+		}
+		return append(dst, v...), true, nil
+	}
+	coerced, ok, err := coerceFieldValue(value, strict)
+	if err != nil || !ok {
+		return dst, ok, err
+	}
+	// The coerced value is a string or a number, handled above
+	return appendFieldValue(dst, coerced, strict, sanitize)
+}
+
+// appendFieldFloat appends a finite float as JSON number, and NaN and infinite floats as string, see coerceFloat.
+func appendFieldFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		dst = append(dst, '"')
+		dst = strconv.AppendFloat(dst, f, 'g', -1, 64)
+		return append(dst, '"')
+	}
+	return appendJSONFloat(dst, f)
+}
+
+// appendJSONFloat appends the finite float as JSON number to dst, formatted like encoding/json does.
+func appendJSONFloat(dst []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9, like encoding/json
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// appendJSONString appends the string as JSON string to dst, escaped like encoding/json does: control characters,
+// quotes, backslashes, the HTML characters <, > and &, and the line and paragraph separators are escaped, invalid
+// UTF-8 is replaced by the replacement character.
+func appendJSONString(dst []byte, s string) []byte {
+	return appendString(dst, s, false)
+}
+
+// appendString appends the string as JSON string to dst like appendJSONString. If sanitize is set, the control
+// characters are stripped instead of escaped, except for tabs, line feeds and carriage returns, see strippedControl,
+// and every run of invalid UTF-8 bytes is replaced by a single replacement character, see WithUnsanitizedStrings.
+func appendString(dst []byte, s string, sanitize bool) []byte {
+	dst = append(dst, '"')
+	start := 0
+	invalid := false
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			invalid = false
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' && (b != 0x7f || !sanitize) {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			i++
+			start = i
+			if sanitize && strippedControl(rune(b)) {
+				continue
+			}
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+			}
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			if !sanitize || !invalid {
+				dst = append(dst, "\ufffd"...)
+			}
+			invalid = true
+			i += size
+			start = i
+			continue
+		}
+		invalid = false
+		if sanitize && strippedControl(r) {
+			dst = append(dst, s[start:i]...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// validJSONNumber reports whether s is a valid JSON number literal, e.g. the content of a json.Number.
+func validJSONNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	switch n := digits(); {
+	case n == 0:
+		return false
+	case n > 1 && s[i-n] == '0':
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
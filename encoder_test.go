@@ -0,0 +1,184 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// discardTransport drops the messages, so the benchmarks measure the Logger alone.
+type discardTransport struct{}
+
+func (discardTransport) Send([]byte) error { return nil }
+
+func (discardTransport) Close() error { return nil }
+
+// benchmarkMessage returns a Message with typical additional fields.
+func benchmarkMessage() *gelflogger.Message {
+	return &gelflogger.Message{
+		Version:      "1.1",
+		Host:         "billing-7f9c",
+		ShortMessage: "order placed",
+		Timestamp:    1700000000.123,
+		Level:        6,
+		Additional: map[string]interface{}{
+			"request_id": "9b2c41e0-6a8f-4c55-b1c0-2f7d3e9a1b64",
+			"user_id":    4711,
+			"amount":     99.95,
+			"path":       "/api/v1/orders",
+			"status":     201,
+			"paid":       true,
+		},
+	}
+}
+
+func TestMessageAppendGELFMatchesJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "Plain string", value: "order placed"},
+		{name: "Quotes and backslashes", value: `say "hi" \ bye`},
+		{name: "Control characters", value: "line\nbreak\ttab\rreturn\x00null\x1fend\b\f"},
+		{name: "HTML characters", value: "<script>alert('x')</script> & more"},
+		{name: "Line separators", value: "a b c"},
+		{name: "Invalid UTF-8", value: "bad \xff\xfe byte"},
+		{name: "Multi-byte runes", value: "Grüße 日本 🚀"},
+		{name: "Integer", value: -42},
+		{name: "Large unsigned integer", value: uint64(18446744073709551615)},
+		{name: "Float", value: 123.456},
+		{name: "Small float", value: 1e-7},
+		{name: "Large float", value: 1.5e21},
+		{name: "Negative zero", value: -0.0},
+		{name: "JSON number", value: json.Number("12.5e3")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := gelflogger.Message{Version: "1.1", Host: "h", ShortMessage: "s", Additional: map[string]interface{}{"v": tt.value}}
+			encoded, err := msg.AppendGELF(nil)
+			if err != nil {
+				t.Fatalf("AppendGELF() error = %v", err)
+			}
+			want, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(encoded), `"_v":`+string(want)) {
+				t.Errorf("AppendGELF() = %s, want _v encoded like encoding/json as %s", encoded, want)
+			}
+			if !json.Valid(encoded) {
+				t.Errorf("AppendGELF() = %s, invalid JSON", encoded)
+			}
+		})
+	}
+}
+
+func TestMessageAppendGELFInvalid(t *testing.T) {
+	msg := gelflogger.Message{Additional: map[string]interface{}{"v": json.Number("0x1F")}}
+	if _, err := msg.AppendGELF(nil); err == nil {
+		t.Error("AppendGELF() error = nil, want an error for an invalid json.Number")
+	}
+}
+
+func TestMessageAppendGELFAllocations(t *testing.T) {
+	msg := benchmarkMessage()
+	buf := make([]byte, 0, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		var err error
+		buf, err = msg.AppendGELF(buf[:0])
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("AppendGELF() allocates %v times per message, want none", allocs)
+	}
+}
+
+func BenchmarkMessageAppendGELF(b *testing.B) {
+	msg := benchmarkMessage()
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = msg.AppendGELF(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSONMarshalMap encodes the message of BenchmarkMessageAppendGELF the way the Logger did before the append
+// based encoder, as map marshalled by encoding/json, for comparison.
+func BenchmarkJSONMarshalMap(b *testing.B) {
+	msg := benchmarkMessage()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gelfMsg := map[string]interface{}{
+			"version":       msg.Version,
+			"host":          msg.Host,
+			"short_message": msg.ShortMessage,
+			"timestamp":     msg.Timestamp,
+			"level":         msg.Level,
+		}
+		for name, value := range msg.Additional {
+			gelfMsg["_"+name] = value
+		}
+		if _, err := json.Marshal(gelfMsg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLog(b *testing.B) {
+	logger := gelflogger.NewLoggerWithTransport(discardTransport{}, processNothing,
+		gelflogger.WithFullMessage(gelflogger.FullMessageNone),
+		gelflogger.WithStaticFields(map[string]interface{}{"service": "billing", "environment": "production"}),
+	)
+	fields := benchmarkMessage().Additional
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := logger.Log("order placed", fields); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPooling compares the allocations of logging and of writing to a GelfWriter with and without the reuse of
+// buffers, field maps and Messages.
+func BenchmarkPooling(b *testing.B) {
+	line := []byte(`{"level":"info","time":1700000000500,"message":"order placed","request_id":"9b2c41e0","user_id":4711,"amount":99.95}` + "\n")
+	for _, pooled := range []bool{true, false} {
+		name := "Pooled"
+		if !pooled {
+			name = "Unpooled"
+		}
+		b.Run(name+"/Log", func(b *testing.B) {
+			defer gelflogger.SetPooling(pooled)()
+			logger := gelflogger.NewLoggerWithTransport(discardTransport{}, processNothing,
+				gelflogger.WithFlattening(2),
+				gelflogger.WithProcessorChain(gelflogger.NewDefaultRedactor()),
+			)
+			fields := benchmarkMessage().Additional
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := logger.Log("order placed", fields); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/GelfWriter", func(b *testing.B) {
+			defer gelflogger.SetPooling(pooled)()
+			writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(discardTransport{}, gelflogger.ProcessFields,
+				gelflogger.WithFieldReader(gelflogger.ReadFields))}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := writer.Write(line); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
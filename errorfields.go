@@ -0,0 +1,55 @@
+package gelflogger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errorDetails returns the detailed representation of the error, e.g. the stack trace of errors created by
+// github.com/pkg/errors, which print it for the %+v verb, or an empty string if the error has no more details than
+// its message.
+func errorDetails(err error) string {
+	details := fmt.Sprintf("%+v", err)
+	if details == err.Error() {
+		return ""
+	}
+	return details
+}
+
+// encodeErrors returns the fields with the error values replaced by their messages, as errors are encoded as empty
+// JSON objects otherwise. The fields are only copied if they hold an error.
+func encodeErrors(fields map[string]interface{}) map[string]interface{} {
+	var encoded map[string]interface{}
+	for name, value := range fields {
+		if err, ok := value.(error); ok && !isNil(err) {
+			if encoded == nil {
+				encoded = make(map[string]interface{}, len(fields))
+				for name, value := range fields {
+					encoded[name] = value
+				}
+			}
+			encoded[name] = err.Error()
+		}
+	}
+	if encoded == nil {
+		return fields
+	}
+	return encoded
+}
+
+// appendErrorDetails appends the details of the error field to the full message of the message, see errorDetails.
+func appendErrorDetails(msg *Message, name string, err error) {
+	details := errorDetails(err)
+	if details == "" {
+		return
+	}
+	var fullMessage strings.Builder
+	if msg.FullMessage != "" {
+		fullMessage.WriteString(msg.FullMessage)
+		fullMessage.WriteString("\n\n")
+	}
+	fullMessage.WriteString(name)
+	fullMessage.WriteString(": ")
+	fullMessage.WriteString(details)
+	msg.FullMessage = fullMessage.String()
+}
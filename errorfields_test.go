@@ -0,0 +1,96 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// stackError mimics the errors of github.com/pkg/errors, which print their stack trace for the %+v verb.
+type stackError struct {
+	msg string
+}
+
+func (e *stackError) Error() string {
+	return e.msg
+}
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprintf(s, "%s\nmain.handler\n\t/app/main.go:42", e.msg)
+		return
+	}
+	_, _ = fmt.Fprint(s, e.msg)
+}
+
+func TestErrorFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		processor       func(fields map[string]interface{}) (int, float64, []byte, error)
+		fields          map[string]interface{}
+		wantFields      map[string]interface{}
+		wantFullMessage []string
+		wantNoStack     bool
+	}{
+		{
+			name:        "Plain error",
+			processor:   processNothing,
+			fields:      map[string]interface{}{"error": fmt.Errorf("query failed: %w", errors.New("timeout"))},
+			wantFields:  map[string]interface{}{"_error": "query failed: timeout"},
+			wantNoStack: true,
+		},
+		{
+			name:            "Error with stack trace",
+			processor:       processNothing,
+			fields:          map[string]interface{}{"cause": &stackError{msg: "disk full"}},
+			wantFields:      map[string]interface{}{"_cause": "disk full"},
+			wantFullMessage: []string{"cause: disk full\nmain.handler\n\t/app/main.go:42"},
+		},
+		{
+			name:            "Stack trace appended to the full message of the processor",
+			processor:       gelflogger.ProcessFields,
+			fields:          map[string]interface{}{"error": &stackError{msg: "disk full"}},
+			wantFields:      map[string]interface{}{"_error": "disk full"},
+			wantFullMessage: []string{`{"error":"disk full"}`, "\n\nerror: disk full\nmain.handler"},
+		},
+		{
+			name:        "Nil error",
+			processor:   processNothing,
+			fields:      map[string]interface{}{"error": error(nil)},
+			wantFields:  map[string]interface{}{"_error": nil},
+			wantNoStack: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, tt.processor, gelflogger.WithFlattening(3))
+			if err := logger.Log("failed", tt.fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			for name, want := range tt.wantFields {
+				if gelfMsg[name] != want {
+					t.Errorf("field %s = %v, want %v", name, gelfMsg[name], want)
+				}
+			}
+			fullMessage, _ := gelfMsg["full_message"].(string)
+			for _, want := range tt.wantFullMessage {
+				if !strings.Contains(fullMessage, want) {
+					t.Errorf("full_message = %q, want it to contain %q", fullMessage, want)
+				}
+			}
+			if tt.wantNoStack && fullMessage != "" {
+				t.Errorf("full_message = %q, want none", fullMessage)
+			}
+		})
+	}
+}
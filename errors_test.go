@@ -0,0 +1,106 @@
+package gelflogger_test
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestErrors(t *testing.T) {
+	failingProcessor := func(map[string]interface{}) (int, float64, []byte, error) {
+		return 0, 0, nil, errors.New("unexpected record")
+	}
+
+	tests := []struct {
+		name  string
+		err   func(t *testing.T) error
+		wants []error
+	}{
+		{
+			name: "Not connected",
+			err: func(t *testing.T) error {
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				address := listener.Addr().String()
+				_ = listener.Close()
+				_, err = gelflogger.NewLogger(address)
+				return err
+			},
+			wants: []error{gelflogger.ErrNotConnected},
+		},
+		{
+			name: "Encoding failed",
+			err: func(t *testing.T) error {
+				return gelflogger.NewLoggerWithTransport(&recordingTransport{}, failingProcessor).Log("event", map[string]interface{}{})
+			},
+			wants: []error{gelflogger.ErrEncode, gelflogger.ErrPermanent},
+		},
+		{
+			name: "Message too large for UDP",
+			err: func(t *testing.T) error {
+				transport, err := gelflogger.NewTransport("udp://127.0.0.1:12201", false, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer func() { _ = transport.Close() }()
+				message := make([]byte, gelflogger.MaxMessageSize+1)
+				_, _ = rand.Read(message)
+				return transport.Send(message)
+			},
+			wants: []error{gelflogger.ErrMessageTooLarge, gelflogger.ErrPermanent},
+		},
+		{
+			name: "Message too large for HTTP",
+			err: func(t *testing.T) error {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+				}))
+				defer server.Close()
+				transport, err := gelflogger.NewTransport(server.URL+"/gelf", false, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer func() { _ = transport.Close() }()
+				return transport.Send([]byte(`{"version":"1.1"}`))
+			},
+			wants: []error{gelflogger.ErrMessageTooLarge, gelflogger.ErrPermanent},
+		},
+		{
+			name: "Invalid raw message",
+			err: func(t *testing.T) error {
+				return gelflogger.NewLoggerWithTransport(&recordingTransport{}, processNothing).LogRaw(nil)
+			},
+			wants: []error{gelflogger.ErrInvalidMessage, gelflogger.ErrEncode},
+		},
+		{
+			name: "Closed",
+			err: func(t *testing.T) error {
+				logger := gelflogger.NewLoggerWithTransport(&recordingTransport{}, processNothing)
+				if err := logger.Close(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				return logger.Log("event", map[string]interface{}{})
+			},
+			wants: []error{gelflogger.ErrClosed, gelflogger.ErrLoggerClosed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err(t)
+			for _, want := range tt.wants {
+				if !errors.Is(err, want) {
+					t.Errorf("error = %v, want it to wrap %v", err, want)
+				}
+			}
+		})
+	}
+}
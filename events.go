@@ -0,0 +1,146 @@
+package gelflogger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEventBuffer is the number of events buffered for every subscriber, see Logger.Events. Events for a
+// subscriber whose buffer is full are discarded, so a slow subscriber never blocks logging.
+const DefaultEventBuffer = 64
+
+// EventType is the kind of change of the connection to Graylog reported by an Event.
+type EventType int
+
+const (
+	// EventConnected reports that a connection to the primary address was established.
+	EventConnected EventType = iota
+	// EventDisconnected reports that the connection was lost, the reason is the error which revealed the loss.
+	EventDisconnected
+	// EventReconnecting reports an attempt to re-establish the connection, the reason is the error of the previous
+	// attempt, if any.
+	EventReconnecting
+	// EventFailedOver reports that a connection to a failover address was established, see WithFailoverAddresses.
+	EventFailedOver
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventConnected:
+		return "connected"
+	case EventDisconnected:
+		return "disconnected"
+	case EventReconnecting:
+		return "reconnecting"
+	case EventFailedOver:
+		return "failed over"
+	default:
+		return "unknown"
+	}
+}
+
+// Event reports a change of the connection to Graylog, see Logger.Events.
+type Event struct {
+	// Type is the kind of change.
+	Type EventType
+	// Time is the time the change happened.
+	Time time.Time
+	// Address is the address of the Graylog server the event refers to.
+	Address string
+	// Reason is the error which caused the change, nil if there is none.
+	Reason error
+}
+
+// eventHub passes the events of the transports to the subscribers.
+type eventHub struct {
+	lock        sync.Mutex
+	subscribers []chan Event
+	closed      bool
+	// reconnects counts the connections re-established in the background, see Stats.
+	reconnects atomic.Uint64
+}
+
+// subscribe returns a new channel receiving the events emitted from now on. Once the hub is closed, the channel is
+// closed as well.
+func (h *eventHub) subscribe() <-chan Event {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	events := make(chan Event, DefaultEventBuffer)
+	if h.closed {
+		close(events)
+		return events
+	}
+	h.subscribers = append(h.subscribers, events)
+	return events
+}
+
+// emit passes the event to every subscriber with room in its buffer. Emitting on a nil hub does nothing.
+func (h *eventHub) emit(eventType EventType, address string, reason error) {
+	if h == nil {
+		return
+	}
+	event := Event{Type: eventType, Time: time.Now(), Address: address, Reason: reason}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, events := range h.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// reconnected counts a connection re-established in the background. Counting on a nil hub does nothing.
+func (h *eventHub) reconnected() {
+	if h != nil {
+		h.reconnects.Add(1)
+	}
+}
+
+// close closes the channels of the subscribers, later events are discarded.
+func (h *eventHub) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, events := range h.subscribers {
+		close(events)
+	}
+	h.subscribers = nil
+}
+
+// eventSource is implemented by transports which emit connection events.
+type eventSource interface {
+	eventHub() *eventHub
+}
+
+// Events returns a new channel receiving the changes of the connection to Graylog from now on: connections
+// established to the primary or to a failover address, lost connections and reconnect attempts. Every call returns
+// a separate subscription. Events for a subscriber which does not keep up are discarded, see DefaultEventBuffer. The
+// channel is closed when the Logger is closed. Transports without a long-lived connection, e.g. UDP and HTTP, emit no
+// events.
+func (l *Logger) Events() <-chan Event {
+	return l.events.subscribe()
+}
+
+// eventHub returns the hub the events of the transport are emitted to.
+func (t *tcpTransport) eventHub() *eventHub {
+	return t.events
+}
+
+// eventHub returns the hub the events of the pooled connections are emitted to, they all share the same hub.
+func (p *pooledTransport) eventHub() *eventHub {
+	if source, ok := p.members[0].transport.(eventSource); ok {
+		return source.eventHub()
+	}
+	return nil
+}
+
+// eventHub returns the hub the events of the endpoints are emitted to, they all share the same hub.
+func (b *balancedTransport) eventHub() *eventHub {
+	return b.endpoints[0].transport.events
+}
@@ -0,0 +1,103 @@
+package gelflogger_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+// acceptConnections passes the connections accepted by the server to the returned channel.
+func acceptConnections(server net.Listener) <-chan net.Conn {
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	return accepted
+}
+
+// expectEvents waits for the events of the given types and addresses, in that order.
+func expectEvents(t *testing.T, events <-chan gelflogger.Event, want ...gelflogger.Event) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-events:
+			if got.Type != w.Type || got.Address != w.Address {
+				t.Fatalf("event = %v %s, want %v %s", got.Type, got.Address, w.Type, w.Address)
+			}
+			if got.Time.IsZero() {
+				t.Errorf("%v event has no time", got.Type)
+			}
+			if got.Type == gelflogger.EventDisconnected && got.Reason == nil {
+				t.Errorf("%v event has no reason", got.Type)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %v event for %s", w.Type, w.Address)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	t.Run("reconnect", func(t *testing.T) {
+		server := helper.StartMockServer(t)
+		defer func() { _ = server.Close() }()
+		accepted := acceptConnections(server)
+		address := server.Addr().String()
+
+		logger, err := gelflogger.NewLogger(address, gelflogger.WithProcessor(processNothing), gelflogger.WithHealthCheckInterval(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("NewLogger() error = %v", err)
+		}
+		events := logger.Events()
+
+		// The server closes the connection, the supervisor notices it and reconnects
+		_ = (<-accepted).Close()
+		expectEvents(t, events,
+			gelflogger.Event{Type: gelflogger.EventDisconnected, Address: address},
+			gelflogger.Event{Type: gelflogger.EventReconnecting, Address: address},
+			gelflogger.Event{Type: gelflogger.EventConnected, Address: address},
+		)
+
+		if err := logger.Close(context.Background()); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		for range events {
+			// Drain the events until the channel is closed by Close
+		}
+	})
+
+	t.Run("fail over", func(t *testing.T) {
+		primary := helper.StartMockServer(t)
+		primaryAccepted := acceptConnections(primary)
+		failover := helper.StartMockServer(t)
+		defer func() { _ = failover.Close() }()
+		acceptConnections(failover)
+
+		logger, err := gelflogger.NewLogger(primary.Addr().String(), gelflogger.WithProcessor(processNothing),
+			gelflogger.WithFailoverAddresses(failover.Addr().String()),
+			gelflogger.WithHealthCheckInterval(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("NewLogger() error = %v", err)
+		}
+		defer func() { _ = logger.Close(context.Background()) }()
+		events := logger.Events()
+
+		// The primary goes away, the transport fails over to the failover address
+		_ = primary.Close()
+		_ = (<-primaryAccepted).Close()
+		expectEvents(t, events,
+			gelflogger.Event{Type: gelflogger.EventDisconnected, Address: primary.Addr().String()},
+			gelflogger.Event{Type: gelflogger.EventReconnecting, Address: primary.Addr().String()},
+			gelflogger.Event{Type: gelflogger.EventFailedOver, Address: failover.Addr().String()},
+		)
+	})
+}
@@ -0,0 +1,62 @@
+package gelflogger
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"runtime/debug"
+)
+
+// unpooled is an objectPool allocating a new object on every Get and dropping the objects put back.
+type unpooled struct {
+	new func() interface{}
+}
+
+func (p unpooled) Get() interface{} { return p.new() }
+
+func (p unpooled) Put(interface{}) {}
+
+// SetPooling enables or disables the reuse of buffers, field maps and Messages, and returns a function restoring the
+// previous pools, so the benchmarks can compare the allocations with and without pooling. It must not be called while
+// Loggers are in use.
+func SetPooling(enabled bool) (restore func()) {
+	previousBuffers, previousFieldMaps, previousMessages := encodeBuffers, fieldMaps, messages
+	if !enabled {
+		encodeBuffers, fieldMaps, messages = unpooled{newEncodeBuffer}, unpooled{newFieldMap}, unpooled{newMessage}
+	}
+	return func() {
+		encodeBuffers, fieldMaps, messages = previousBuffers, previousFieldMaps, previousMessages
+	}
+}
+
+// WriteFull writes p to the connection like the buffered writer of the TCP transport does, retrying short writes.
+func WriteFull(conn net.Conn, p []byte) (int, error) {
+	return fullWriter{conn}.Write(p)
+}
+
+// ContainerMetadata returns the container metadata read from the given root file system, see WithContainerMetadata.
+func ContainerMetadata(root fs.FS) map[string]interface{} {
+	return containerMetadata(root)
+}
+
+// CloudMetadata returns the cloud metadata of the instance metadata service at the given endpoint, see
+// WithCloudMetadata.
+func CloudMetadata(ctx context.Context, endpoint string) map[string]interface{} {
+	return cloudMetadata(ctx, endpoint)
+}
+
+// BuildInfoOf returns the BuildInfo recorded in the given build information, see WithBuildInfo.
+func BuildInfoOf(info *debug.BuildInfo) BuildInfo {
+	return buildInfoOf(info)
+}
+
+// CheckedTransport is a Transport whose connection is checked before the messages of a GelfWriter are sent, like the
+// one of the TCP transport. Check is called for each check.
+type CheckedTransport struct {
+	Transport
+	Check func() error
+}
+
+func (c CheckedTransport) ensureConnection() error {
+	return c.Check()
+}
@@ -0,0 +1,29 @@
+package gelflogger
+
+import (
+	"expvar"
+	"fmt"
+)
+
+// PublishExpvar publishes the counters of the Logger, see Stats, as expvar variable with the given name, e.g.
+// "gelflogger", for services exposing their internals through expvar instead of Prometheus. The variable is a map
+// holding messages_sent, bytes_sent, errors, dropped, reconnects and queue_depth, read whenever the variable is
+// requested. As expvar variables cannot be removed, the Logger stays reachable once published. An error is returned
+// if a variable with the name exists already.
+func (l *Logger) PublishExpvar(name string) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar variable %q exists already", name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		stats := l.Stats()
+		return map[string]interface{}{
+			"messages_sent": stats.MessagesSent,
+			"bytes_sent":    stats.BytesSent,
+			"errors":        stats.SendErrors,
+			"dropped":       stats.Dropped,
+			"reconnects":    stats.Reconnects,
+			"queue_depth":   stats.QueueDepth,
+		}
+	}))
+	return nil
+}
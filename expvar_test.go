@@ -0,0 +1,39 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// expvarRuns numbers the runs of TestPublishExpvar, as expvar variables cannot be unpublished and -count reruns it.
+var expvarRuns atomic.Int64
+
+func TestPublishExpvar(t *testing.T) {
+	name := fmt.Sprintf("gelflogger_test_%d", expvarRuns.Add(1))
+	logger := gelflogger.NewLoggerWithTransport(&recordingTransport{failAfter: 1}, processNothing)
+	if err := logger.PublishExpvar(name); err != nil {
+		t.Fatalf("PublishExpvar() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_ = logger.Log("published", map[string]interface{}{})
+	}
+
+	var counters map[string]float64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &counters); err != nil {
+		t.Fatalf("invalid expvar variable: %v", err)
+	}
+	for name, want := range map[string]float64{"messages_sent": 1, "errors": 1, "dropped": 1, "queue_depth": 0} {
+		if counters[name] != want {
+			t.Errorf("%s = %v, want %v", name, counters[name], want)
+		}
+	}
+
+	if err := logger.PublishExpvar(name); err == nil {
+		t.Error("PublishExpvar() error = nil, want an error for a name published already")
+	}
+}
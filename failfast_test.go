@@ -0,0 +1,62 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestWithFailFast(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			// Drop every connection shortly after it was established
+			time.AfterFunc(50*time.Millisecond, func() {
+				_ = conn.(*net.TCPConn).SetLinger(0)
+				_ = conn.Close()
+			})
+		}
+	}()
+
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing),
+		gelflogger.WithFailFast(), gelflogger.WithRetry(3, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	var first error
+	for i := 0; i < 100 && first == nil; i++ {
+		first = logger.Log("event", map[string]interface{}{})
+		time.Sleep(5 * time.Millisecond)
+	}
+	if first == nil {
+		t.Fatal("Log() error = nil, want the lost connection reported")
+	}
+	if strings.Contains(first.Error(), "reconnecting") {
+		t.Errorf("Log() error = %v, want no reconnect", first)
+	}
+
+	err = logger.Log("event", map[string]interface{}{})
+	if !errors.Is(err, gelflogger.ErrNotConnected) || strings.Contains(err.Error(), "reconnecting") {
+		t.Errorf("Log() error = %v, want ErrNotConnected without reconnect", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := accepted.Load(); n != 1 {
+		t.Errorf("server accepted %d connections, want 1, no reconnect", n)
+	}
+}
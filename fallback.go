@@ -0,0 +1,278 @@
+package gelflogger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// replayer is implemented by fallback transports which keep the messages they received, so the messages can be
+// shipped again once the primary transport is available.
+type replayer interface {
+	Replay(send func(message []byte) error) error
+}
+
+// dropReplayer is implemented by replayers which skip the messages they can never send, e.g. the undecryptable
+// messages of a Spool, reporting them to drop, so a Logger counts them as dropped.
+type dropReplayer interface {
+	replay(send func(message []byte) error, drop func(message []byte, reason error)) error
+}
+
+// FileFallback is a Transport writing GELF messages as JSON lines to a local file. It is meant as fallback of a
+// Logger (see WithFallback), keeping the messages which could not be sent during Graylog outages, and shipping them
+// again with Logger.ReplayFallback once the Graylog server is reachable again.
+//
+// When the file would exceed maxSize bytes, it is rotated: the file is renamed to <path>.1, the existing backups are
+// shifted to <path>.2 and so on, and backups beyond maxBackups are removed. Replay moves the files aside as
+// <path>.replay.1 and so on while it sends their messages.
+type FileFallback struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+
+	// replayLock serializes the replays, which send the messages of the replay files without holding lock.
+	replayLock sync.Mutex
+}
+
+var _ Transport = (*FileFallback)(nil)
+
+// NewFileFallback creates a FileFallback appending to the file at the given path, which is created if it does not
+// exist. A maxSize of zero or less disables the rotation.
+//
+// Example usage:
+//
+//	fallback, err := gelflogger.NewFileFallback("/var/log/app/gelf-fallback.log", 100<<20, 5)
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger, err := gelflogger.NewLogger("graylog.example.com:12201", gelflogger.WithProcessor(zerologger.ProcessZerologFields), gelflogger.WithFallback(fallback))
+func NewFileFallback(path string, maxSize int64, maxBackups int) (*FileFallback, error) {
+	f := &FileFallback{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file for appending. The caller must hold the lock, unless the FileFallback is being created.
+func (f *FileFallback) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Send appends the message as a line to the file, rotating the file first if it would exceed its maximum size.
+func (f *FileFallback) Send(message []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return fs.ErrClosed
+	}
+	line := append(message[:len(message):len(message)], '\n')
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+// rotate renames the file to the first backup, shifting the existing backups, and opens a new file.
+// The caller must hold the lock.
+func (f *FileFallback) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil {
+			return err
+		}
+		return f.open()
+	}
+	_ = os.Remove(f.backupPath(f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(f.backupPath(i), f.backupPath(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.backupPath(1)); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// backupPath returns the path of the backup with the given number.
+func (f *FileFallback) backupPath(number int) string {
+	return fmt.Sprintf("%s.%d", f.path, number)
+}
+
+// Replay sends the stored messages, oldest first, with the given function and removes them once sent.
+// If sending a message fails, replaying stops and the message, together with all messages after it, is kept for
+// the next replay. The files are moved aside as replay files first, so Send is not blocked while the messages are
+// replayed over the network; the messages it receives meanwhile are kept for the next replay.
+func (f *FileFallback) Replay(send func(message []byte) error) error {
+	f.replayLock.Lock()
+	defer f.replayLock.Unlock()
+
+	paths, err := f.swap()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := replayFile(path, send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// swap moves the backups and the file aside as replay files, numbered after the ones a failed replay left, and opens
+// a new file. It returns the paths of all replay files, oldest first.
+func (f *FileFallback) swap() ([]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return nil, fs.ErrClosed
+	}
+	paths, err := f.replayPaths()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.file.Close(); err != nil {
+		return nil, err
+	}
+	f.file = nil
+	defer func() {
+		if f.file == nil {
+			_ = f.open()
+		}
+	}()
+
+	sources := make([]string, 0, f.maxBackups+1)
+	for i := f.maxBackups; i >= 1; i-- {
+		sources = append(sources, f.backupPath(i))
+	}
+	sources = append(sources, f.path)
+	for _, source := range sources {
+		target := fmt.Sprintf("%s%d", f.replayPrefix(), len(paths)+1)
+		if err := os.Rename(source, target); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		paths = append(paths, target)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// replayPrefix returns the path of the replay files without their number.
+func (f *FileFallback) replayPrefix() string {
+	return f.path + ".replay."
+}
+
+// replayPaths returns the paths of the replay files a failed replay left, oldest first, renumbering them from 1, so
+// the files moved aside next are numbered after them.
+func (f *FileFallback) replayPaths() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(f.replayPrefix())
+	numbers := make([]int, 0)
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		if number, err := strconv.Atoi(suffix); err == nil && number > 0 {
+			numbers = append(numbers, number)
+		}
+	}
+	slices.Sort(numbers)
+
+	paths := make([]string, 0, len(numbers))
+	for i, number := range numbers {
+		path := fmt.Sprintf("%s%d", f.replayPrefix(), i+1)
+		if number != i+1 {
+			if err := os.Rename(fmt.Sprintf("%s%d", f.replayPrefix(), number), path); err != nil {
+				return nil, err
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// replayFile sends the lines of the file with the given function and removes the file once all lines were sent.
+// If sending a line fails, the file is rewritten with the lines not sent yet.
+func replayFile(path string, send func(message []byte) error) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	sent := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) > 0 {
+			if err := send(bytes.Clone(line)); err != nil {
+				if writeErr := os.WriteFile(path, content[sent:], 0o600); writeErr != nil {
+					return errors.Join(err, writeErr)
+				}
+				return err
+			}
+		}
+		sent += len(line) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Close closes the file.
+func (f *FileFallback) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,176 @@
+package gelflogger_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// recordingTransport records the sent messages, or fails while it is down or its failAfter budget is used up.
+type recordingTransport struct {
+	lock      sync.Mutex
+	down      bool
+	failAfter int
+	messages  []string
+}
+
+func (r *recordingTransport) Send(message []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.down || (r.failAfter > 0 && len(r.messages) >= r.failAfter) {
+		return errors.New("transport down")
+	}
+	r.messages = append(r.messages, string(message))
+	return nil
+}
+
+func (r *recordingTransport) Close() error { return nil }
+
+func TestFileFallbackRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallback.log")
+	fallback, err := gelflogger.NewFileFallback(path, 100, 2)
+	if err != nil {
+		t.Fatalf("NewFileFallback() error = %v", err)
+	}
+	defer func() { _ = fallback.Close() }()
+
+	message := []byte(`{"short_message":"` + strings.Repeat("x", 30) + `"}`)
+	for i := 0; i < 10; i++ {
+		if err := fallback.Send(message); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	for _, name := range []string{"fallback.log", "fallback.log.1", "fallback.log.2"} {
+		info, err := os.Stat(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s has %d bytes, want at most 100", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists, want at most 2 backups", path)
+	}
+}
+
+func TestFallbackAndReplay(t *testing.T) {
+	tests := []struct {
+		name          string
+		failAfter     int
+		wantReplayErr bool
+		wantReplayed  int
+	}{
+		{
+			name:         "Replay all messages",
+			wantReplayed: 5,
+		},
+		{
+			name:          "Replay stops at the first failure",
+			failAfter:     2,
+			wantReplayErr: true,
+			wantReplayed:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fallback.log")
+			fallback, err := gelflogger.NewFileFallback(path, 200, 3)
+			if err != nil {
+				t.Fatalf("NewFileFallback() error = %v", err)
+			}
+			defer func() { _ = fallback.Close() }()
+
+			transport := &recordingTransport{down: true}
+			logger := gelflogger.NewLoggerWithTransport(transport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			}, gelflogger.WithFallback(fallback))
+
+			for _, message := range []string{"m1", "m2", "m3", "m4", "m5"} {
+				if err := logger.Log(message, map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v, want the message to be taken by the fallback", err)
+				}
+			}
+
+			transport.lock.Lock()
+			transport.down = false
+			transport.failAfter = tt.failAfter
+			transport.lock.Unlock()
+
+			err = logger.ReplayFallback()
+			if (err != nil) != tt.wantReplayErr {
+				t.Fatalf("ReplayFallback() error = %v, wantErr %v", err, tt.wantReplayErr)
+			}
+			if len(transport.messages) != tt.wantReplayed {
+				t.Fatalf("replayed %d messages, want %d", len(transport.messages), tt.wantReplayed)
+			}
+			for i, message := range transport.messages {
+				if want := `"short_message":"m` + string(rune('1'+i)) + `"`; !strings.Contains(message, want) {
+					t.Errorf("replayed message %d = %s, want it to contain %s", i, message, want)
+				}
+			}
+
+			// Messages which were not replayed are kept for the next replay.
+			transport.lock.Lock()
+			transport.failAfter = 0
+			transport.lock.Unlock()
+			if err := logger.ReplayFallback(); err != nil {
+				t.Fatalf("second ReplayFallback() error = %v", err)
+			}
+			if len(transport.messages) != 5 {
+				t.Errorf("replayed %d messages in total, want 5", len(transport.messages))
+			}
+		})
+	}
+}
+
+func TestFileFallbackSendDuringReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallback.log")
+	fallback, err := gelflogger.NewFileFallback(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileFallback() error = %v", err)
+	}
+	defer func() { _ = fallback.Close() }()
+	if err := fallback.Send([]byte("stored")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var replayed []string
+	err = fallback.Replay(func(message []byte) error {
+		replayed = append(replayed, string(message))
+		// The file is not locked while the messages are replayed
+		sent := make(chan error, 1)
+		go func() { sent <- fallback.Send([]byte("during replay")) }()
+		select {
+		case err := <-sent:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("Send() blocked during Replay()")
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	// The message sent during the replay is kept for the next one
+	if err := fallback.Replay(func(message []byte) error {
+		replayed = append(replayed, string(message))
+		return nil
+	}); err != nil {
+		t.Fatalf("second Replay() error = %v", err)
+	}
+	if want := []string{"stored", "during replay"}; !slices.Equal(replayed, want) {
+		t.Errorf("replayed %v, want %v", replayed, want)
+	}
+}
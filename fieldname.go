@@ -0,0 +1,42 @@
+package gelflogger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidFieldName is returned for fields whose names are not allowed by the GELF specification if the Logger
+// validates the names strictly, see WithStrictFieldNames.
+var ErrInvalidFieldName = errors.New("invalid GELF field name")
+
+// gelfFieldName returns the name of the additional field of the given field name: the name prefixed with an
+// underscore. The GELF specification only allows the characters of ^[\w\.\-]*$ and forbids _id, which Graylog
+// reserves. Unless strict is set, other characters are replaced by an underscore and _id is renamed to _id_.
+// Otherwise, an error wrapping ErrInvalidFieldName is returned.
+func gelfFieldName(name string, strict bool) (string, error) {
+	key := "_" + name
+	if key == "_id" {
+		if strict {
+			return "", fmt.Errorf("%w: %s is reserved", ErrInvalidFieldName, key)
+		}
+		return "_id_", nil
+	}
+	if strings.IndexFunc(name, invalidFieldNameRune) < 0 {
+		return key, nil
+	}
+	if strict {
+		return "", fmt.Errorf("%w: %q may only contain letters, digits, underscores, dots and dashes", ErrInvalidFieldName, key)
+	}
+	return strings.Map(func(r rune) rune {
+		if invalidFieldNameRune(r) {
+			return '_'
+		}
+		return r
+	}, key), nil
+}
+
+// invalidFieldNameRune reports whether r is not allowed in GELF field names.
+func invalidFieldNameRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-')
+}
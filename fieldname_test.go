@@ -0,0 +1,54 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestFieldNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []gelflogger.Option
+		fields    map[string]interface{}
+		wantField string
+		wantErr   bool
+	}{
+		{name: "Valid name", fields: map[string]interface{}{"user.id-2_x": "1"}, wantField: "_user.id-2_x"},
+		{name: "Invalid characters are replaced", fields: map[string]interface{}{"user id/ä": "1"}, wantField: "_user_id__"},
+		{name: "id is renamed", fields: map[string]interface{}{"id": "1"}, wantField: "_id_"},
+		{name: "Static field names are sanitized", opts: []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"k8s pod": "1"})}, fields: map[string]interface{}{}, wantField: "_k8s_pod"},
+		{name: "Strict mode accepts valid names", opts: []gelflogger.Option{gelflogger.WithStrictFieldNames()}, fields: map[string]interface{}{"user.id": "1"}, wantField: "_user.id"},
+		{name: "Strict mode rejects invalid characters", opts: []gelflogger.Option{gelflogger.WithStrictFieldNames()}, fields: map[string]interface{}{"user id": "1"}, wantErr: true},
+		{name: "Strict mode rejects id", opts: []gelflogger.Option{gelflogger.WithStrictFieldNames()}, fields: map[string]interface{}{"id": "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			err := logger.Log("field names", tt.fields)
+			if tt.wantErr {
+				if !errors.Is(err, gelflogger.ErrInvalidFieldName) || !errors.Is(err, gelflogger.ErrPermanent) {
+					t.Errorf("Log() error = %v, want ErrInvalidFieldName and ErrPermanent", err)
+				}
+				if len(transport.messages) != 0 {
+					t.Errorf("sent %v, want no message", transport.messages)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			if gelfMsg[tt.wantField] != "1" {
+				t.Errorf("sent %s, want the field %s", transport.messages[0], tt.wantField)
+			}
+		})
+	}
+}
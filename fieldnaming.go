@@ -0,0 +1,259 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FieldNaming selects the names of the additional fields, see WithFieldNaming.
+type FieldNaming int
+
+const (
+	// FieldNamingGELF sends the fields by the names they were logged with. It is the default.
+	FieldNamingGELF FieldNaming = iota
+	// FieldNamingECS renames well-known fields to the names of the Elastic Common Schema (ECS), for Graylog data
+	// indexed into Elasticsearch with ECS mappings:
+	//
+	//   - service and service_name to service.name, environment and env to service.environment, version to
+	//     service.version.
+	//   - trace_id, traceId and traceID to trace.id, span_id, spanId and spanID to span.id, transaction_id to
+	//     transaction.id.
+	//   - error and err to error.message, stack, stacktrace and stack_trace to error.stack_trace.
+	//   - logger and logger_name to log.logger, caller to log.origin.file.name and log.origin.file.line, split at the
+	//     colon of e.g. handler.go:42.
+	//   - hostname to host.name, pid to process.pid, executable to process.name.
+	//   - user_id to user.id, request_id to http.request.id, method to http.request.method, status and status_code
+	//     to http.response.status_code, path to url.path, user_agent to user_agent.original, client_ip and
+	//     remote_addr to client.ip.
+	//
+	// The name of the level is added as log.level, e.g. error or warning, and the details of an error, e.g. its
+	// stack trace, as error.stack_trace.
+	FieldNamingECS
+	// FieldNamingOTel renames well-known fields to the names of the OpenTelemetry semantic conventions, for
+	// correlation with the logs and traces of OpenTelemetry:
+	//
+	//   - service and service_name to service.name, environment and env to deployment.environment, version to
+	//     service.version.
+	//   - traceId and traceID to trace_id, spanId and spanID to span_id.
+	//   - error and err to exception.message, stack, stacktrace and stack_trace to exception.stacktrace.
+	//   - caller to code.filepath and code.lineno, split at the colon of e.g. handler.go:42, function and func to
+	//     code.function.
+	//   - hostname to host.name, pid to process.pid, executable to process.executable.name.
+	//   - user_id to user.id, method to http.request.method, status and status_code to http.response.status_code,
+	//     path to url.path, user_agent to user_agent.original, client_ip and remote_addr to client.address.
+	//
+	// The details of an error, e.g. its stack trace, are added as exception.stacktrace, its type as exception.type.
+	FieldNamingOTel
+)
+
+// fieldRename renames the field from to the field to, see fieldNamingScheme.
+type fieldRename struct {
+	from, to string
+}
+
+// fieldNamingScheme is a Processor renaming the well-known fields of the messages to the names of a FieldNaming, see
+// WithFieldNaming. It runs after the Processors of WithProcessorChain, which see the fields by their logged names.
+//
+// - renames: The renamed fields. Of several fields renamed to the same name, the first one present wins, a field
+// logged by the new name already is kept.
+// - levelField: The field the name of the level is added as, or empty.
+// - errorField: The field holding the error of the message after renaming.
+// - stackField: The field the details of the error are added as, see errorDetails.
+// - typeField: The field the type of the error is added as, or empty.
+// - fileField: The field holding the caller after renaming, split into the file and the line, or empty.
+// - lineField: The field the line of the caller is added as.
+type fieldNamingScheme struct {
+	renames    []fieldRename
+	levelField string
+	errorField string
+	stackField string
+	typeField  string
+	fileField  string
+	lineField  string
+}
+
+// fieldNamingSchemes are the schemes of the FieldNamings other than FieldNamingGELF.
+var fieldNamingSchemes = map[FieldNaming]*fieldNamingScheme{
+	FieldNamingECS: {
+		renames: []fieldRename{
+			{"service", "service.name"},
+			{"service_name", "service.name"},
+			{"environment", "service.environment"},
+			{"env", "service.environment"},
+			{"version", "service.version"},
+			{"trace_id", "trace.id"},
+			{"traceId", "trace.id"},
+			{"traceID", "trace.id"},
+			{"span_id", "span.id"},
+			{"spanId", "span.id"},
+			{"spanID", "span.id"},
+			{"transaction_id", "transaction.id"},
+			{"error", "error.message"},
+			{"err", "error.message"},
+			{"stack", "error.stack_trace"},
+			{"stacktrace", "error.stack_trace"},
+			{"stack_trace", "error.stack_trace"},
+			{"logger", "log.logger"},
+			{"logger_name", "log.logger"},
+			{"caller", "log.origin.file.name"},
+			{"hostname", "host.name"},
+			{"pid", "process.pid"},
+			{"executable", "process.name"},
+			{"user_id", "user.id"},
+			{"request_id", "http.request.id"},
+			{"method", "http.request.method"},
+			{"status", "http.response.status_code"},
+			{"status_code", "http.response.status_code"},
+			{"path", "url.path"},
+			{"user_agent", "user_agent.original"},
+			{"client_ip", "client.ip"},
+			{"remote_addr", "client.ip"},
+		},
+		levelField: "log.level",
+		errorField: "error.message",
+		stackField: "error.stack_trace",
+		fileField:  "log.origin.file.name",
+		lineField:  "log.origin.file.line",
+	},
+	FieldNamingOTel: {
+		renames: []fieldRename{
+			{"service", "service.name"},
+			{"service_name", "service.name"},
+			{"environment", "deployment.environment"},
+			{"env", "deployment.environment"},
+			{"version", "service.version"},
+			{"traceId", "trace_id"},
+			{"traceID", "trace_id"},
+			{"spanId", "span_id"},
+			{"spanID", "span_id"},
+			{"error", "exception.message"},
+			{"err", "exception.message"},
+			{"stack", "exception.stacktrace"},
+			{"stacktrace", "exception.stacktrace"},
+			{"stack_trace", "exception.stacktrace"},
+			{"caller", "code.filepath"},
+			{"function", "code.function"},
+			{"func", "code.function"},
+			{"hostname", "host.name"},
+			{"pid", "process.pid"},
+			{"executable", "process.executable.name"},
+			{"user_id", "user.id"},
+			{"method", "http.request.method"},
+			{"status", "http.response.status_code"},
+			{"status_code", "http.response.status_code"},
+			{"path", "url.path"},
+			{"user_agent", "user_agent.original"},
+			{"client_ip", "client.address"},
+			{"remote_addr", "client.address"},
+		},
+		errorField: "exception.message",
+		stackField: "exception.stacktrace",
+		typeField:  "exception.type",
+		fileField:  "code.filepath",
+		lineField:  "code.lineno",
+	},
+}
+
+// syslogLevelNames are the names of the Graylog (Syslog) levels, indexed by level.
+var syslogLevelNames = [...]string{"emergency", "alert", "critical", "error", "warning", "notice", "informational", "debug"}
+
+// Process renames the fields of the message and adds the level and the error details.
+func (s *fieldNamingScheme) Process(msg *Message) error {
+	fields := msg.Additional
+	for _, rename := range s.renames {
+		value, ok := fields[rename.from]
+		if !ok {
+			continue
+		}
+		delete(fields, rename.from)
+		if _, exists := fields[rename.to]; !exists {
+			fields[rename.to] = value
+		}
+	}
+	if s.levelField != "" {
+		if _, exists := fields[s.levelField]; !exists {
+			fields[s.levelField] = levelName(msg.Level)
+		}
+	}
+	if err, ok := fields[s.errorField].(error); ok && !isNil(err) {
+		if _, exists := fields[s.stackField]; !exists {
+			if details := errorDetails(err); details != "" {
+				fields[s.stackField] = details
+			}
+		}
+		if _, exists := fields[s.typeField]; s.typeField != "" && !exists {
+			fields[s.typeField] = fmt.Sprintf("%T", err)
+		}
+	}
+	if s.fileField != "" {
+		s.splitCaller(fields)
+	}
+	return nil
+}
+
+// splitCaller splits the caller of the form file:line, e.g. handler.go:42 as logged by zap and zerolog, into the file
+// and the line field. Callers without line are left as they are, as is a line field logged already.
+func (s *fieldNamingScheme) splitCaller(fields map[string]interface{}) {
+	caller, ok := fields[s.fileField].(string)
+	if !ok {
+		return
+	}
+	colon := strings.LastIndexByte(caller, ':')
+	if colon < 0 {
+		return
+	}
+	line, err := strconv.Atoi(caller[colon+1:])
+	if err != nil {
+		return
+	}
+	if _, exists := fields[s.lineField]; exists {
+		return
+	}
+	fields[s.fileField] = caller[:colon]
+	fields[s.lineField] = line
+}
+
+// renameStaticFields returns the static fields with the well-known fields renamed like the fields of the messages. A
+// static field is dropped if another one has its new name already, or was renamed to it before. static is not
+// modified, as it may be shared with other Loggers.
+func (s *fieldNamingScheme) renameStaticFields(static []staticField) []staticField {
+	renamed := make([]staticField, 0, len(static))
+	for _, field := range static {
+		name, ok := s.rename(field.key[1:])
+		if !ok {
+			renamed = append(renamed, field)
+			continue
+		}
+		key := "_" + name
+		hasKey := func(other staticField) bool { return other.key == key }
+		if slices.ContainsFunc(static, hasKey) || slices.ContainsFunc(renamed, hasKey) {
+			continue
+		}
+		encodedKey, _ := json.Marshal(key)
+		encodedOldKey, _ := json.Marshal(field.key)
+		encoded := append(encodedKey, field.encoded[len(encodedOldKey):]...)
+		renamed = append(renamed, staticField{key: key, encoded: encoded})
+	}
+	return renamed
+}
+
+// rename returns the new name of the field of the given name, and whether it is renamed.
+func (s *fieldNamingScheme) rename(name string) (string, bool) {
+	for _, rename := range s.renames {
+		if rename.from == name {
+			return rename.to, true
+		}
+	}
+	return "", false
+}
+
+// levelName returns the name of the Graylog (Syslog) level, or the level as number if it is out of range.
+func levelName(level int) string {
+	if level >= 0 && level < len(syslogLevelNames) {
+		return syslogLevelNames[level]
+	}
+	return strconv.Itoa(level)
+}
@@ -0,0 +1,191 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestWithFieldNamingECS(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []gelflogger.Option
+		level   string
+		fields  map[string]interface{}
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name:  "Well-known fields renamed",
+			level: "warn",
+			fields: map[string]interface{}{
+				"trace_id": "4bf92f3577b34da6",
+				"user_id":  "42",
+				"status":   503,
+				"order_id": "o-1",
+				"caller":   "handler.go:42",
+			},
+			want: map[string]interface{}{
+				"_trace.id":                  "4bf92f3577b34da6",
+				"_user.id":                   "42",
+				"_http.response.status_code": float64(503),
+				"_order_id":                  "o-1",
+				"_log.origin.file.name":      "handler.go",
+				"_log.origin.file.line":      float64(42),
+				"_log.level":                 "warning",
+			},
+			missing: []string{"_trace_id", "_user_id", "_status"},
+		},
+		{
+			name:    "Error message and stack trace",
+			level:   "error",
+			fields:  map[string]interface{}{"error": &stackError{msg: "connection refused"}},
+			want:    map[string]interface{}{"_error.message": "connection refused", "_error.stack_trace": "connection refused\nmain.handler\n\t/app/main.go:42", "_log.level": "error"},
+			missing: []string{"_error"},
+		},
+		{
+			name:    "Field logged by the new name kept",
+			fields:  map[string]interface{}{"service": "old", "service.name": "billing"},
+			want:    map[string]interface{}{"_service.name": "billing", "_log.level": "informational"},
+			missing: []string{"_service"},
+		},
+		{
+			name: "Static fields renamed",
+			opts: []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"service": "billing", "environment": "production", "region": "eu"})},
+			want: map[string]interface{}{
+				"_service.name":        "billing",
+				"_service.environment": "production",
+				"_region":              "eu",
+			},
+			missing: []string{"_service", "_environment"},
+		},
+		{
+			name:   "Static field overridden by a renamed field",
+			opts:   []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"service": "billing"})},
+			fields: map[string]interface{}{"service": "payments"},
+			want:   map[string]interface{}{"_service.name": "payments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			opts := append([]gelflogger.Option{gelflogger.WithFieldNaming(gelflogger.FieldNamingECS)}, tt.opts...)
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, opts...)
+			fields := map[string]interface{}{}
+			for name, value := range tt.fields {
+				fields[name] = value
+			}
+			if tt.level != "" {
+				fields["level"] = tt.level
+			}
+			if err := logger.Log("event", fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			gelfMsg := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			for key, want := range tt.want {
+				if gelfMsg[key] != want {
+					t.Errorf("%s = %v, want %v in %s", key, gelfMsg[key], want, transport.messages[0])
+				}
+			}
+			for _, key := range tt.missing {
+				if _, ok := gelfMsg[key]; ok {
+					t.Errorf("sent %s, want no %s", transport.messages[0], key)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFieldNamingWith(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithFieldNaming(gelflogger.FieldNamingECS))
+	if err := logger.With(map[string]interface{}{"request_id": "r-1"}).Log("event", map[string]interface{}{"err": errors.New("timeout")}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	gelfMsg := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+		t.Fatalf("invalid GELF message: %v", err)
+	}
+	if gelfMsg["_http.request.id"] != "r-1" || gelfMsg["_error.message"] != "timeout" {
+		t.Errorf("sent %s, want the fields of With and of the log call renamed", transport.messages[0])
+	}
+	if _, ok := gelfMsg["_error.stack_trace"]; ok {
+		t.Errorf("sent %s, want no stack trace for an error without details", transport.messages[0])
+	}
+}
+
+func TestWithFieldNamingOTel(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]interface{}
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name: "Well-known fields renamed",
+			fields: map[string]interface{}{
+				"service":     "billing",
+				"environment": "production",
+				"traceId":     "4bf92f3577b34da6",
+				"client_ip":   "10.0.0.1",
+			},
+			want: map[string]interface{}{
+				"_service.name":           "billing",
+				"_deployment.environment": "production",
+				"_trace_id":               "4bf92f3577b34da6",
+				"_client.address":         "10.0.0.1",
+			},
+			missing: []string{"_service", "_environment", "_traceId", "_log.level"},
+		},
+		{
+			name:    "Caller split",
+			fields:  map[string]interface{}{"caller": "billing/handler.go:42", "function": "billing.Handle"},
+			want:    map[string]interface{}{"_code.filepath": "billing/handler.go", "_code.lineno": float64(42), "_code.function": "billing.Handle"},
+			missing: []string{"_caller"},
+		},
+		{
+			name:   "Caller without line",
+			fields: map[string]interface{}{"caller": "handler.go"},
+			want:   map[string]interface{}{"_code.filepath": "handler.go"},
+		},
+		{
+			name:   "Exception",
+			fields: map[string]interface{}{"error": &stackError{msg: "connection refused"}},
+			want: map[string]interface{}{
+				"_exception.message":    "connection refused",
+				"_exception.stacktrace": "connection refused\nmain.handler\n\t/app/main.go:42",
+				"_exception.type":       "*gelflogger_test.stackError",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithFieldNaming(gelflogger.FieldNamingOTel))
+			if err := logger.Log("event", tt.fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			gelfMsg := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			for key, want := range tt.want {
+				if gelfMsg[key] != want {
+					t.Errorf("%s = %v, want %v in %s", key, gelfMsg[key], want, transport.messages[0])
+				}
+			}
+			for _, key := range tt.missing {
+				if _, ok := gelfMsg[key]; ok {
+					t.Errorf("sent %s, want no %s", transport.messages[0], key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// flattenFields returns the fields with the nested maps, slices and structs flattened into fields named by their path
+// in dot notation, e.g. the field user holding {"address": {"city": "Berlin"}} becomes user.address.city, and the
+// elements of slices are named by their index, e.g. tags.0. Structures nested deeper than maxDepth levels are
+// encoded as JSON string, see WithFlattening.
+func flattenFields(fields map[string]interface{}, maxDepth int) map[string]interface{} {
+	flat := make(map[string]interface{}, len(fields))
+	flattenFieldsInto(flat, fields, maxDepth)
+	return flat
+}
+
+// flattenFieldsInto adds the flattened fields to flat, see flattenFields.
+func flattenFieldsInto(flat, fields map[string]interface{}, maxDepth int) {
+	for name, value := range fields {
+		flattenValue(flat, name, value, 1, maxDepth)
+	}
+}
+
+// flattenValue adds the value at the given path and depth to the flat fields.
+func flattenValue(flat map[string]interface{}, path string, value interface{}, depth, maxDepth int) {
+	nested, ok := nestedValue(value)
+	if !ok {
+		flat[path] = value
+		return
+	}
+	switch v := nested.(type) {
+	case map[string]interface{}:
+		if depth > maxDepth || len(v) == 0 {
+			flat[path] = encodeJSONString(v)
+			return
+		}
+		for name, element := range v {
+			flattenValue(flat, path+"."+name, element, depth+1, maxDepth)
+		}
+	case []interface{}:
+		if depth > maxDepth || len(v) == 0 {
+			flat[path] = encodeJSONString(v)
+			return
+		}
+		for i, element := range v {
+			flattenValue(flat, path+"."+strconv.Itoa(i), element, depth+1, maxDepth)
+		}
+	default:
+		flat[path] = nested
+	}
+}
+
+// nestedValue returns the value as map[string]interface{} or []interface{} if it is a structure which is flattened:
+// a map, a slice, an array or a struct, or a pointer to one of them, unless it is an error or fmt.Stringer. Other structures are converted by their JSON
+// encoding, so the field names of structs follow their json tags, and types encoding themselves as JSON scalars, like
+// time.Time, are not flattened. It reports false for scalar values.
+func nestedValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, []byte, json.Number:
+		return value, false
+	case error, fmt.Stringer:
+		return value, false
+	case map[string]interface{}, []interface{}:
+		return v, true
+	}
+	kind := reflect.TypeOf(value).Kind()
+	if kind == reflect.Pointer {
+		kind = reflect.TypeOf(value).Elem().Kind()
+	}
+	if kind != reflect.Map && kind != reflect.Slice && kind != reflect.Array && kind != reflect.Struct {
+		return value, false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value, false
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return value, false
+	}
+	switch decoded.(type) {
+	case map[string]interface{}, []interface{}:
+		return decoded, true
+	default:
+		return decoded, false
+	}
+}
+
+// encodeJSONString returns the JSON encoding of the value as string.
+func encodeJSONString(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,86 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type user struct {
+	Name    string   `json:"name"`
+	Address *address `json:"address"`
+}
+
+func TestWithFlattening(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		fields   map[string]interface{}
+		want     map[string]interface{}
+		wantNot  []string
+	}{
+		{
+			name:     "Nested maps",
+			maxDepth: 5,
+			fields:   map[string]interface{}{"user": map[string]interface{}{"address": map[string]interface{}{"city": "Berlin"}, "admin": true}},
+			want:     map[string]interface{}{"_user.address.city": "Berlin", "_user.admin": "true"},
+			wantNot:  []string{"_user"},
+		},
+		{
+			name:     "Slices are indexed",
+			maxDepth: 5,
+			fields:   map[string]interface{}{"tags": []string{"a", "b"}, "empty": []int{}},
+			want:     map[string]interface{}{"_tags.0": "a", "_tags.1": "b", "_empty": "[]"},
+		},
+		{
+			name:     "Structs follow their JSON encoding",
+			maxDepth: 5,
+			fields:   map[string]interface{}{"user": &user{Name: "jane", Address: &address{City: "Berlin", Zip: "10115"}}, "created": created},
+			want:     map[string]interface{}{"_user.name": "jane", "_user.address.city": "Berlin", "_user.address.zip": "10115", "_created": "2024-05-01T12:00:00Z"},
+		},
+		{
+			name:     "Deeper structures are JSON encoded",
+			maxDepth: 1,
+			fields:   map[string]interface{}{"user": map[string]interface{}{"address": map[string]interface{}{"city": "Berlin"}, "name": "jane"}},
+			want:     map[string]interface{}{"_user.address": `{"city":"Berlin"}`, "_user.name": "jane"},
+		},
+		{
+			name:   "Flattening disabled",
+			fields: map[string]interface{}{"user": map[string]interface{}{"name": "jane"}},
+			want:   map[string]interface{}{"_user": `{"name":"jane"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithFlattening(tt.maxDepth))
+			if err := logger.Log("flattened", tt.fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			for name, want := range tt.want {
+				if jsonString(t, gelfMsg[name]) != jsonString(t, want) {
+					t.Errorf("field %s = %v, want %v in %s", name, gelfMsg[name], want, transport.messages[0])
+				}
+			}
+			for _, name := range tt.wantNot {
+				if _, ok := gelfMsg[name]; ok {
+					t.Errorf("sent field %s, want it flattened", name)
+				}
+			}
+		})
+	}
+}
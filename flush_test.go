@@ -0,0 +1,74 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []gelflogger.Option
+	}{
+		{name: "Synchronous"},
+		{name: "Asynchronous", opts: []gelflogger.Option{gelflogger.WithAsync(100, 3)}},
+		{name: "Batching", opts: []gelflogger.Option{gelflogger.WithBatching(100, time.Hour), gelflogger.WithAsync(100, 2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			for i := 0; i < 30; i++ {
+				if err := logger.Log("flushed", map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+			if err := logger.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			transport.lock.Lock()
+			sent := len(transport.messages)
+			transport.lock.Unlock()
+			if sent != 30 {
+				t.Errorf("transport received %d messages after Flush(), want 30", sent)
+			}
+		})
+	}
+}
+
+func TestFlushDeadline(t *testing.T) {
+	blocking := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+	defer close(blocking.release)
+	logger := gelflogger.NewLoggerWithTransport(blocking, processNothing, gelflogger.WithAsync(10, 1))
+	if err := logger.Log("stuck", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	<-blocking.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := logger.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestGelfWriterSync(t *testing.T) {
+	transport := &recordingTransport{}
+	writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithBatching(10, time.Hour))}
+	if _, err := writer.Write([]byte(`{"message":"synced"}`)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	if len(transport.messages) != 1 {
+		t.Errorf("transport received %d messages after Sync(), want 1", len(transport.messages))
+	}
+}
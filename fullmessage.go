@@ -0,0 +1,54 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FullMessageMode selects the content of the full_message of the GELF messages.
+type FullMessageMode int
+
+const (
+	// FullMessageFromProcessor uses the full message returned by the processor of the fields, see WithProcessor, e.g.
+	// the JSON encoding of all fields for ProcessFields. This is the default.
+	FullMessageFromProcessor FullMessageMode = iota
+	// FullMessageNone sends no full message, which roughly halves the size of messages whose processor duplicates all
+	// fields into the full message.
+	FullMessageNone
+	// FullMessageFromField uses the value of a designated field, e.g. a stack trace or a multi-line body, as full
+	// message, see WithFullMessageField.
+	FullMessageFromField
+)
+
+// fullMessageFromField returns the full message taken from the named field and the fields without it. The fields are
+// copied, so the map of the caller is not modified. Strings are used as they are, errors with their details, e.g. a
+// stack trace, and other values by their JSON encoding. Without the field, the full message is empty.
+func fullMessageFromField(fields map[string]interface{}, name string) (string, map[string]interface{}) {
+	value, ok := fields[name]
+	if !ok {
+		return "", fields
+	}
+	remaining := make(map[string]interface{}, len(fields)-1)
+	for field, v := range fields {
+		if field != name {
+			remaining[field] = v
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, remaining
+	case error:
+		if details := errorDetails(v); details != "" {
+			return details, remaining
+		}
+		return v.Error(), remaining
+	case nil:
+		return "", remaining
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v), remaining
+		}
+		return string(encoded), remaining
+	}
+}
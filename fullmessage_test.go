@@ -0,0 +1,82 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestFullMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            []gelflogger.Option
+		fields          map[string]interface{}
+		wantFullMessage interface{}
+		wantFields      map[string]interface{}
+	}{
+		{
+			name:            "Full message of the processor",
+			fields:          map[string]interface{}{"user": "42"},
+			wantFullMessage: `{"user":"42"}`,
+			wantFields:      map[string]interface{}{"_user": "42"},
+		},
+		{
+			name:            "No full message",
+			opts:            []gelflogger.Option{gelflogger.WithFullMessage(gelflogger.FullMessageNone)},
+			fields:          map[string]interface{}{"user": "42"},
+			wantFullMessage: nil,
+			wantFields:      map[string]interface{}{"_user": "42"},
+		},
+		{
+			name:            "Full message from a field",
+			opts:            []gelflogger.Option{gelflogger.WithFullMessageField("stack")},
+			fields:          map[string]interface{}{"user": "42", "stack": "goroutine 1 [running]:\nmain.main()"},
+			wantFullMessage: "goroutine 1 [running]:\nmain.main()",
+			wantFields:      map[string]interface{}{"_user": "42", "_stack": nil},
+		},
+		{
+			name:            "Full message from a structured field",
+			opts:            []gelflogger.Option{gelflogger.WithFullMessageField("body")},
+			fields:          map[string]interface{}{"body": map[string]interface{}{"id": 1}},
+			wantFullMessage: `{"id":1}`,
+			wantFields:      map[string]interface{}{"_body": nil},
+		},
+		{
+			name:            "Designated field missing",
+			opts:            []gelflogger.Option{gelflogger.WithFullMessageField("stack")},
+			fields:          map[string]interface{}{"user": "42"},
+			wantFullMessage: nil,
+			wantFields:      map[string]interface{}{"_user": "42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, tt.opts...)
+			fields := map[string]interface{}{}
+			for name, value := range tt.fields {
+				fields[name] = value
+			}
+			if err := logger.Log("full message", fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			if gelfMsg["full_message"] != tt.wantFullMessage {
+				t.Errorf("full_message = %v, want %v", gelfMsg["full_message"], tt.wantFullMessage)
+			}
+			for name, want := range tt.wantFields {
+				if gelfMsg[name] != want {
+					t.Errorf("field %s = %v, want %v", name, gelfMsg[name], want)
+				}
+			}
+			if len(fields) != len(tt.fields) {
+				t.Errorf("the fields of the caller were modified: %v", fields)
+			}
+		})
+	}
+}
@@ -1,190 +1,694 @@
 package gelflogger
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
-	"os"
-	"strconv"
+	"io"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 )
 
-// Logger represents a logging client that connects to a Graylog server using TCP.
+// DefaultCloseTimeout is the time GelfWriter.Close and GelfWriter.Sync wait for the pending messages to be sent.
+const DefaultCloseTimeout = 5 * time.Second
+
+// ErrClosed is wrapped by the errors of Loggers and transports used after they were closed, e.g. ErrLoggerClosed.
+var ErrClosed = errors.New("closed")
+
+// ErrLoggerClosed is returned by Log once the Logger was closed. It wraps ErrClosed.
+var ErrLoggerClosed = fmt.Errorf("logger is %w", ErrClosed)
+
+// Logger represents a logging client that ships GELF messages to a Graylog server.
 //
 // The Logger struct has the following fields:
-// - conn: The network connection to the Graylog server.
-// - connLock: A mutex used to ensure thread-safe access to the conn field.
-// - address: The address of the Graylog server to connect to.
-// - useTLS: A boolean value indicating whether to use TLS for the connection.
-// - tslConfig: The TLS configuration to use if useTLS is true.
-// - host: The hostname of the client machine.
+// - loggerCore: The connection, queue and state shared by the Logger and the Loggers derived from it, see With.
+// - staticFields: The encoded fields attached to every message, see WithStaticFields and With.
 //
 // The Logger struct provides the following methods:
-// - connect: Establishes a connection to the Graylog server.
-// - ensureConnection: Ensures that a connection to the Graylog server is established, reconnecting if necessary.
+// - ensureConnection: Ensures that the transport's connection is established, reconnecting if necessary.
 // - Log: Sends a log message to the Graylog server.
+// - With: Derives a Logger carrying additional fields.
+// - ReplayFallback: Ships the messages kept by the fallback to the Graylog server.
+// - Flush: Sends the messages queued or batched by an asynchronous Logger.
+// - Close: Sends the pending messages and closes the transport.
 type Logger struct {
-	conn             net.Conn
-	connLock         sync.Mutex
-	address          string
-	useTLS           bool
-	tslConfig        *tls.Config
-	host             string
-	baseLogProcessor func(fields map[string]interface{}) (int, float64, []byte, error)
+	*loggerCore
+	staticFields []staticField
 }
 
-// NewLogger creates a new Logger.
+// loggerCore is the state of a Logger shared with the Loggers derived from it by With.
 //
-// Example with TLS:
+// The loggerCore struct has the following fields:
+// - transport: The Transport used to deliver the encoded GELF messages, TCP by default.
+// - host: The hostname of the client machine, looked up again periodically if configured, see WithHostnameRefresh.
+// - baseLogProcessor: The function extracting level, timestamp and full message from the log fields.
+// - fieldReader: The function extracting level and timestamp from the log fields, used where the full message of
+// baseLogProcessor is not needed, or nil, see WithFieldReader.
+// - fallback: The Transport receiving the messages which could not be sent through the transport, if configured.
+// - routes: The Routes sending matching messages through other transports, see WithRoutes.
+// - sampler: The sampler keeping 1 in N messages of the sampled levels, nil unless WithSampling is used.
+// - dedup: The deduplicator suppressing repeated messages, nil unless WithDeduplication is used.
+// - spool: The Spool keeping the messages which could not be sent through the transport, if configured.
+// - replaying: A boolean value indicating whether the spool is being replayed in the background.
+// - queue: The queue of messages sent by the background workers, nil unless the Logger is asynchronous, see WithAsync.
+// - overflowPolicy: The OverflowPolicy applied when the queue is full.
+// - queueMemoryLimit: The maximum number of bytes of the queued messages, zero for no limit, see WithQueueMemoryLimit.
+// - queuedBytes: The number of bytes of the queued messages.
+// - room: A channel signaled whenever a message is taken from the queue, waking up a caller blocked by a full queue.
+// - onDrop: The callback receiving the dropped messages, if configured.
+// - dropped: The number of dropped messages.
+// - sent, bytesSent, sendErrors: The counters of the sends through the transports, see Stats.
+// - sendObserver: The observer called after every send, see ObserveSends.
+// - retryBudget: The number of retries of a send which failed temporarily, see WithRetry.
+// - retryBackoff: The backoff between the retries.
+// - events: The hub passing the connection events of the transport to the subscribers, see Events.
+// - workers: The background workers draining the queue.
+// - flushes: The channels passing the flush requests to the workers, one per worker.
+// - background: The background goroutines besides the workers, e.g. the spool replay, waited for by Close.
+// - backgroundLock: A mutex guarding the start of background goroutines against Close waiting for them.
+// - backgroundDone: A boolean value indicating whether Close waits for the background goroutines, no more start.
+// - closing: A channel closed as soon as Close is called, stopping the background goroutines.
+// - closeOnce: Makes sure closing is closed once.
+// - closeLock: A read-write mutex held for reading while a message is logged, and for writing while closing.
+// - closed: A boolean value indicating whether the Logger was closed.
+// - owned: The resources created for the Logger, e.g. by NewLoggerFromConfig, closed by Close.
+// - processors: The Processors run on every message before it is encoded, see WithProcessorChain.
+// - contextExtractors: The ContextExtractors adding the fields of the contexts of log calls, see WithContextExtractor.
+// - strictFieldNames: A boolean value indicating whether invalid field names are rejected, see WithStrictFieldNames.
+// - fullMessageMode: The FullMessageMode selecting the full message, see WithFullMessage.
+// - fullMessageField: The field used as full message by FullMessageFromField, see WithFullMessageField.
+// - shortMessageTemplate: The template rendering the short message of records without message, see
+// WithShortMessageTemplate.
+// - naming: The scheme renaming the fields, the last of the processors, or nil, see WithFieldNaming.
+// - shortMessageLimit: The maximum size of the short message in bytes, zero for no limit, see WithShortMessageLimit.
+// - keepMultiLine: A boolean value indicating whether multi-line short messages are sent as they are, see
+// WithMultiLineShortMessages.
+// - unsanitized: A boolean value indicating whether the strings of the messages are sent without stripping control
+// characters, see WithUnsanitizedStrings.
+// - strictFieldTypes: A boolean value indicating whether field values of unsupported types are rejected, see
+// WithStrictFieldTypes.
+// - validate: A boolean value indicating whether the encoded messages are checked against the GELF specification, see
+// WithValidation.
+// - flattenDepth: The number of nesting levels of the fields flattened into dot notation, zero if disabled, see WithFlattening.
+type loggerCore struct {
+	transport            Transport
+	host                 atomic.Pointer[string]
+	baseLogProcessor     func(fields map[string]interface{}) (int, float64, []byte, error)
+	fieldReader          func(fields map[string]interface{}) (int, float64, error)
+	fallback             Transport
+	routes               []Route
+	sampler              *sampler
+	dedup                *deduplicator
+	spool                *Spool
+	replaying            atomic.Bool
+	queue                chan queuedMessage
+	overflowPolicy       OverflowPolicy
+	queueMemoryLimit     int64
+	queuedBytes          atomic.Int64
+	room                 chan struct{}
+	onDrop               func(message []byte, reason error)
+	dropped              atomic.Uint64
+	sent                 atomic.Uint64
+	bytesSent            atomic.Uint64
+	sendErrors           atomic.Uint64
+	sendObserver         atomic.Pointer[SendObserver]
+	retryBudget          int
+	retryBackoff         backoff
+	events               *eventHub
+	workers              sync.WaitGroup
+	flushes              []chan *sync.WaitGroup
+	background           sync.WaitGroup
+	backgroundLock       sync.Mutex
+	backgroundDone       bool
+	closing              chan struct{}
+	closeOnce            sync.Once
+	closeLock            sync.RWMutex
+	closed               bool
+	owned                []io.Closer
+	processors           []Processor
+	contextExtractors    []ContextExtractor
+	strictFieldNames     bool
+	flattenDepth         int
+	strictFieldTypes     bool
+	validate             bool
+	shortMessageLimit    int
+	keepMultiLine        bool
+	unsanitized          bool
+	fullMessageMode      FullMessageMode
+	fullMessageField     string
+	shortMessageTemplate *template.Template
+	naming               *fieldNamingScheme
+}
+
+// NewLogger creates a new Logger shipping its messages to the Graylog server at the given address.
 //
-//	    // Load our Root CA certificate
-//		caCert, err := os.ReadFile("/path/to/ca.crt")
-//		if err != nil {
-//			log.Fatal(err)
-//		}
+// The transport is selected by the scheme of the address, e.g. "tls://graylog:12201", "udp://graylog:12201" or
+// "https://graylog/gelf", see NewTransport. Addresses without scheme are connected to over TCP.
 //
-//		caCertPool := x509.NewCertPool()
-//		caCertPool.AppendCertsFromPEM(caCert)
+// Everything else is configured with Options, e.g. WithTLS to connect over TLS, WithProcessor to read the messages of
+// a logging library, WithAsync to send the messages in the background, or WithFailoverAddresses("graylog-2:12201")
+// to fail over to another Graylog node if the address is unreachable.
 //
-//		// Create the credentials and return it
-//		config := &tls.Config{
-//			RootCAs:            caCertPool,
-//			InsecureSkipVerify: true,
-//			// Other fields can be filled in as necessary
-//		}
+// Example with TLS:
 //
-//		writer, err := NewLogger("localhost:1234", true, config)
+//	// Load our Root CA certificate
+//	caCert, err := os.ReadFile("/path/to/ca.crt")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	caCertPool := x509.NewCertPool()
+//	caCertPool.AppendCertsFromPEM(caCert)
 //
-// This creates a new Logger that will use TLS when connecting
-// to the specified address.
-func NewLogger(address string, useTSL bool, tslConfig *tls.Config, baseLogProcessor func(fields map[string]interface{}) (int, float64, []byte, error)) (*Logger, error) {
-	host, _ := os.Hostname()
-	logger := &Logger{address: address, useTLS: useTSL, tslConfig: tslConfig, host: host, baseLogProcessor: baseLogProcessor}
-	err := logger.connect()
+//	logger, err := NewLogger("graylog.example.com:12201",
+//		WithTLS(&tls.Config{RootCAs: caCertPool}),
+//		WithProcessor(zerologger.ProcessZerologFields),
+//		WithHostname("billing-service"),
+//		WithTimeout(10*time.Second),
+//	)
+func NewLogger(address string, opts ...Option) (*Logger, error) {
+	cfg := newConfig(opts)
+	transport, err := newConfiguredTransport(address, cfg.useTLS, cfg.tlsConfig, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return logger, nil
+	if cfg.fieldReader == nil && !cfg.customProcessor {
+		cfg.fieldReader = ReadFields
+	}
+	return newLogger(transport, cfg.processor, cfg), nil
 }
 
-// connect establishes a connection to the specified address using either TCP or TLS, depending on the value of the useTLS flag. If the connection is successful, it is stored in the
-func (l *Logger) connect() error {
-	dialer := net.Dialer{
-		Timeout:   5 * time.Second,  // 5 seconds timeout for the connection attempt
-		KeepAlive: 30 * time.Second, // 30 seconds keep-alive interval
+// NewLoggerWithProcessor creates a new Logger like NewLogger, with the TLS settings and the processor given as
+// arguments instead of Options.
+//
+// Deprecated: Use NewLogger with WithTLS and WithProcessor instead, e.g.
+// NewLogger(address, WithTLS(tlsConfig), WithProcessor(processor)).
+func NewLoggerWithProcessor(address string, useTSL bool, tslConfig *tls.Config, baseLogProcessor func(fields map[string]interface{}) (int, float64, []byte, error), opts ...Option) (*Logger, error) {
+	legacy := func(c *config) {
+		c.useTLS = useTSL
+		c.tlsConfig = tslConfig
+		c.processor = baseLogProcessor
+		c.customProcessor = true
 	}
-	var conn net.Conn
-	var err error
+	return NewLogger(address, append([]Option{legacy}, opts...)...)
+}
 
-	if l.useTLS {
-		conn, err = dialer.Dial("tcp", l.address)
-		if conn != nil {
-			conn = tls.Client(conn, l.tslConfig) // Wrap the connection with TLS
-		}
-	} else {
-		conn, err = dialer.Dial("tcp", l.address)
-	}
+// NewLoggerWithTransport creates a new Logger that ships its messages through the given Transport instead of
+// the default TCP connection. Options which configure the TCP connection have no effect.
+//
+// Example with the NATS transport:
+//
+//	nc, err := nats.Connect(nats.DefaultURL)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	logger := NewLoggerWithTransport(natstransport.New(nc, "logs.gelf"), zerologger.ProcessZerologFields)
+func NewLoggerWithTransport(transport Transport, baseLogProcessor func(fields map[string]interface{}) (int, float64, []byte, error), opts ...Option) *Logger {
+	return newLogger(transport, baseLogProcessor, newConfig(opts))
+}
 
-	if err != nil {
-		//log.Printf("Failed to connect to Graylog: %v", err)
-		return err
+// newLogger creates a new Logger shipping its messages through the given Transport.
+func newLogger(transport Transport, baseLogProcessor func(fields map[string]interface{}) (int, float64, []byte, error), cfg config) *Logger {
+	host, ok := lookupHostname(cfg.hostname)
+	if !ok {
+		host = unknownHostname
 	}
-
-	l.connLock.Lock()
-	l.conn = conn
-	l.connLock.Unlock()
-	return nil
+	events := &eventHub{}
+	if source, ok := transport.(eventSource); ok && source.eventHub() != nil {
+		events = source.eventHub()
+	}
+	if cfg.breakerFailures > 0 {
+		transport = newCircuitBreaker(transport, cfg.breakerFailures, cfg.breakerOpenDuration)
+	}
+	core := &loggerCore{
+		transport:            transport,
+		baseLogProcessor:     baseLogProcessor,
+		fieldReader:          cfg.fieldReader,
+		fallback:             cfg.fallback,
+		routes:               cfg.routes,
+		sampler:              newSampler(cfg.sampleRates),
+		spool:                cfg.spool,
+		overflowPolicy:       cfg.overflowPolicy,
+		onDrop:               cfg.onDrop,
+		retryBudget:          cfg.retryBudget,
+		retryBackoff:         cfg.retryBackoff,
+		events:               events,
+		closing:              make(chan struct{}),
+		owned:                cfg.owned,
+		processors:           cfg.processors,
+		contextExtractors:    cfg.contextExtractors,
+		strictFieldNames:     cfg.strictFieldNames,
+		flattenDepth:         cfg.flattenDepth,
+		strictFieldTypes:     cfg.strictFieldTypes,
+		validate:             cfg.validate,
+		shortMessageLimit:    cfg.shortMessageLimit,
+		keepMultiLine:        cfg.keepMultiLine,
+		unsanitized:          cfg.unsanitized,
+		fullMessageMode:      cfg.fullMessageMode,
+		fullMessageField:     cfg.fullMessageField,
+		shortMessageTemplate: cfg.shortMessageTemplate,
+	}
+	if cfg.failFast {
+		core.retryBudget = 0
+	}
+	staticFields := cfg.staticFields
+	if naming := fieldNamingSchemes[cfg.fieldNaming]; naming != nil {
+		core.naming = naming
+		core.processors = append(slices.Clip(core.processors), naming)
+		staticFields = naming.renameStaticFields(staticFields)
+	}
+	l := &Logger{loggerCore: core, staticFields: staticFields}
+	l.host.Store(&host)
+	l.dedup = newDeduplicator(cfg.dedupWindow, cfg.dedupFields, l.logRepeats)
+	if l.spool != nil && l.spool.Pending() {
+		// Ship the messages spooled by a previous run
+		l.replaySpool()
+	}
+	if cfg.async || cfg.batching {
+		l.startWorkers(cfg.queueSize, cfg.workers, cfg)
+	}
+	if cfg.heartbeatInterval > 0 {
+		l.goBackground(func() { l.heartbeat(cfg.heartbeatInterval) })
+	}
+	if cfg.hostnameRefresh > 0 && cfg.hostname == "" {
+		l.goBackground(func() { l.refreshHostname(cfg.hostnameRefresh) })
+	}
+	return l
 }
 
-// ensureConnection checks if the Logger has an active connection. If not, it tries to establish a new connection.
-// If the connection is already established, it sends a zero-byte message to the server to check if the connection is alive.
-// If the connection is not alive, it tries to reconnect.
-// It is called by the Log method to make sure that there is an active connection before sending log messages.
+// ensureConnection makes sure the transport has an active connection before log messages are sent.
+// Transports without a long-lived connection are always considered connected.
+// It is called by the GelfWriter before sending log messages. Asynchronous Loggers skip the check, their workers
+// reconnect when sending, so the log call does not wait for the network. If a fallback or spool is configured, a
+// missing connection is not reported, as Log hands the message to them.
 func (l *Logger) ensureConnection() error {
-	l.connLock.Lock()
-	defer l.connLock.Unlock()
-
-	if l.conn == nil {
-		err := l.connect()
-		if err != nil {
+	if l.queue != nil {
+		return nil
+	}
+	if checker, ok := l.transport.(connectionChecker); ok {
+		if err := checker.ensureConnection(); err != nil && l.fallback == nil && l.spool == nil {
 			return err
 		}
-	} else {
-		// Simple way to check if the connection is alive
-		_, err := l.conn.Write(nil)
-		if err != nil {
-			err := l.connect()
-			if err != nil {
-				return err
-			}
-		}
-		return err
 	}
 	return nil
 }
 
-// Log Ensure the connection is alive before logging
+// Log formats the message and its fields as GELF message and sends it through the Logger's transport.
+// Asynchronous Loggers enqueue the message instead, see WithAsync. Once the Logger is closed, ErrLoggerClosed is
+// returned. Send and encoding errors wrap ErrTemporary or ErrPermanent, so callers can tell whether trying again
+// later makes sense, see WithRetry. ErrQueueFull is returned if the message was dropped as the queue was full.
 func (l *Logger) Log(message string, fields map[string]interface{}) error {
-	graylogLevel, glTimeStamp, fullMessage, err := l.baseLogProcessor(fields)
+	return l.log(message, fields, nil, readLevel)
+}
+
+// LogAt logs the message with the given Graylog (Syslog) level, from Emergency to Debug, and timestamp, e.g. to ship
+// historical events of a batch import or replay with their original severity and time. The current time is used if
+// the timestamp is zero. Unlike Log, the fields are not handed to the processor of the Logger, so a "level" or "time"
+// field is sent as additional field, and there is no full message unless taken from a field, see
+// FullMessageFromField. Errors are returned like by Log.
+func (l *Logger) LogAt(level int, timestamp time.Time, message string, fields map[string]interface{}) error {
+	return l.logAt(level, timestamp, message, "", fields)
+}
+
+// LogAtNow logs the message like LogAt, but sends it right away, even if the Logger is asynchronous, see WithAsync,
+// e.g. for the fatal messages of logging libraries, which exit the process right after logging. It bypasses sampling,
+// deduplication and the queue, while the retries, the spool and the fallback apply like for a synchronous Logger.
+// Messages queued before are not flushed, see Flush. Errors are returned like by Log.
+func (l *Logger) LogAtNow(level int, timestamp time.Time, message string, fields map[string]interface{}) error {
+	return l.logAtNow(level, timestamp, message, "", fields)
+}
+
+// logAtNow logs the message like LogAtNow, with the given full message, see CapturePanic.
+func (l *Logger) logAtNow(level int, timestamp time.Time, message, full string, fields map[string]interface{}) error {
+	msg, err := l.newMessageAt(level, timestamp, message, full, fields)
+	if err != nil || msg == nil {
+		return err
+	}
+	defer l.releaseMessage(msg)
+	gelfMessage, err := l.formatGELFMessage(msg)
 	if err != nil {
+		return l.dropUnencoded(nil, err)
+	}
+	transport, ok := l.route(msg)
+	if !ok {
+		return nil
+	}
+	return l.dispatchNow(transport, gelfMessage)
+}
+
+// logAt logs the message like LogAt, with the given full message, see MessageBuilder. An empty full message is taken
+// from the field of FullMessageFromField, if configured.
+func (l *Logger) logAt(level int, timestamp time.Time, message, full string, fields map[string]interface{}) error {
+	msg, err := l.newMessageAt(level, timestamp, message, full, fields)
+	if err != nil || msg == nil {
 		return err
 	}
-	gelfMsg := map[string]interface{}{
-		"version":       "1.1",
-		"host":          l.host,
-		"short_message": message,
-		"full_message":  string(fullMessage),
-		"timestamp":     glTimeStamp,
-		"level":         graylogLevel,
+	return l.logMessage(msg)
+}
+
+// newMessageAt builds the Message of logAt and LogAtNow, see newMessage.
+func (l *Logger) newMessageAt(level int, timestamp time.Time, message, full string, fields map[string]interface{}) (*Message, error) {
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
 	}
-	gelfMessage, err := formatGELFMessage(gelfMsg, fields)
+	message, err := l.renderShortMessage(message, fields)
 	if err != nil {
+		return nil, l.dropUnencoded(nil, err)
+	}
+	if full == "" && l.fullMessageMode == FullMessageFromField {
+		full, fields = fullMessageFromField(fields, l.fullMessageField)
+	}
+	return l.newMessage(level, unixTimestamp(timestamp), message, full, fields)
+}
+
+// LogRaw sends an already encoded GELF message, e.g. one relayed from another sender, as it is, without parsing and
+// encoding it again: only the framing and compression of the transport are applied. The message is neither sampled,
+// deduplicated nor validated, carries no static fields and is not processed, and it is sent through the transport of
+// the Logger, as Routes match the fields of parsed messages. Leading and trailing whitespace, e.g. the newline of a
+// line read from a file, is trimmed. The message is copied, so the caller may reuse it. Errors are returned like by
+// Log, an empty message is rejected as permanent error.
+func (l *Logger) LogRaw(gelfMessage []byte) error {
+	gelfMessage = bytes.TrimSpace(gelfMessage)
+	if len(gelfMessage) == 0 {
+		return l.dropUnencoded(nil, fmt.Errorf("%w: empty message", ErrInvalidMessage))
+	}
+	return l.dispatch(l.transport, bytes.Clone(gelfMessage))
+}
+
+// readLevel is the level passed to log and newGELFMessage to read the level from the fields by the field reader or the
+// processor of the Logger.
+const readLevel = -1
+
+// log logs the message and its fields like Log. raw is the JSON the fields were decoded from, see GelfWriter, or nil.
+// level is the Graylog (Syslog) level of the message, see GelfWriter.WriteWithLevel, or readLevel.
+func (l *Logger) log(message string, fields map[string]interface{}, raw []byte, level int) error {
+	msg, err := l.newGELFMessage(message, fields, raw, level)
+	if err != nil || msg == nil {
 		return err
 	}
-	l.connLock.Lock()
-	defer l.connLock.Unlock()
+	return l.logMessage(msg)
+}
 
-	_, err = l.conn.Write(gelfMessage)
+// logMessage samples, deduplicates and delivers the Message built by newGELFMessage or newMessage, and releases it
+// unless the deduplicator keeps it.
+func (l *Logger) logMessage(msg *Message) error {
+	sampleRate, keep := l.sampler.sample(msg.Level)
+	if !keep {
+		l.releaseMessage(msg)
+		return nil
+	}
+	msg.sampleRate = sampleRate
+	if l.dedup != nil && l.dedup.check(msg, l.staticFields) {
+		select {
+		case <-l.closing:
+			return ErrLoggerClosed
+		default:
+			return nil
+		}
+	}
+	err := l.deliver(msg)
+	l.releaseMessage(msg)
+	return err
+}
+
+// newGELFMessage builds the Message of the message and its fields, which are handed to the processor of the Logger
+// first. The full message is selected by the FullMessageMode of the Logger, see WithFullMessage. If the fields were
+// decoded from JSON, raw is the JSON, which becomes the full message of FullMessageFromProcessor instead of the one of
+// the processor. Where the full message of the processor is not needed, the fields are handed to the field reader
+// instead, if configured, see WithFieldReader. A level other than readLevel overrides the level read from the fields.
+// The remaining fields are the additional fields of the Message. If a Processor chain is configured, the Message is
+// processed by it. If a Processor filtered the message out, nil and no error are returned.
+func (l *Logger) newGELFMessage(message string, fields map[string]interface{}, raw []byte, level int) (*Message, error) {
+	message, err := l.renderShortMessage(message, fields)
 	if err != nil {
-		err := l.connect()
-		if err != nil {
-			return err
-		} // Attempt to reconnect
-		_, err = l.conn.Write(gelfMessage) // Retry the log
-		if err != nil {
-			return err
+		return nil, l.dropUnencoded(raw, err)
+	}
+	var graylogLevel int
+	var glTimeStamp float64
+	var fullMessage []byte
+	if l.fieldReader != nil && (raw != nil || l.fullMessageMode != FullMessageFromProcessor) {
+		if level != readLevel {
+			// The field reader need not parse the level field, which is not sent as additional field either
+			delete(fields, "level")
 		}
+		graylogLevel, glTimeStamp, err = l.fieldReader(fields)
+		fullMessage = bytes.TrimSpace(raw)
+	} else {
+		graylogLevel, glTimeStamp, fullMessage, err = l.baseLogProcessor(fields)
+	}
+	if err != nil {
+		return nil, l.dropUnencoded(raw, err)
+	}
+	if level != readLevel {
+		graylogLevel = level
+	}
+	full := string(fullMessage)
+	switch l.fullMessageMode {
+	case FullMessageNone:
+		full = ""
+	case FullMessageFromField:
+		full, fields = fullMessageFromField(fields, l.fullMessageField)
+	}
+	return l.newMessage(graylogLevel, glTimeStamp, message, full, fields)
+}
+
+// newMessage builds the Message of the given level, timestamp, short and full message and additional fields, with the
+// host of the Logger, and processes it by the Processor chain, if configured. If a Processor filtered the message out,
+// nil and no error are returned.
+func (l *Logger) newMessage(level int, timestamp float64, message, full string, fields map[string]interface{}) (*Message, error) {
+	msg := getMessage()
+	*msg = Message{
+		Version:      gelfVersion,
+		Host:         l.hostname(),
+		ShortMessage: message,
+		FullMessage:  full,
+		Timestamp:    timestamp,
+		Level:        level,
+		Additional:   fields,
+	}
+	if len(l.processors) == 0 {
+		return msg, nil
+	}
+	return l.processMessage(msg)
+}
+
+// logRepeats sends the "message repeated N times" record of the repetitions suppressed by the deduplicator. Send
+// errors are not reported, as the record is not sent on behalf of a log call, but counted as drops. The record carries
+// the static fields of the Logger the repetitions were logged with.
+func (l *Logger) logRepeats(r repeats) {
+	logger := &Logger{loggerCore: l.loggerCore, staticFields: r.static}
+	_ = logger.deliver(r.summary())
+}
+
+// With returns a Logger deriving from l, which attaches the given fields to every message in addition to the static
+// fields of l, e.g. the request ID of a request handler or the name of a component. Like the fields of WithStaticFields,
+// they are encoded once, and fields of the same name passed to a log call take precedence. The derived Logger shares
+// the transport, the queue and all other state of l, so deriving Loggers is cheap and does not open connections.
+// Flushing or closing any of them flushes or closes all of them, so the derived Loggers are usually not closed
+// themselves.
+//
+// Example usage:
+//
+//	requestLogger := logger.With(map[string]interface{}{"request_id": requestID})
+//	requestLogger.Log("order placed", map[string]interface{}{"order_id": orderID})
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	static := newStaticFields(fields)
+	if l.naming != nil {
+		static = l.naming.renameStaticFields(static)
+	}
+	return &Logger{loggerCore: l.loggerCore, staticFields: mergeStaticFields(l.staticFields, static)}
+}
+
+// deliver encodes the GELF message and sends it through the transport selected by the routes, or enqueues it for the
+// workers of an asynchronous Logger.
+func (l *Logger) deliver(msg *Message) error {
+	gelfMessage, err := l.formatGELFMessage(msg)
+	if err != nil {
+		return l.dropUnencoded(nil, err)
+	}
+	transport, ok := l.route(msg)
+	if !ok {
+		return nil
+	}
+	return l.dispatch(transport, gelfMessage)
+}
+
+// dispatch sends the encoded GELF message through the transport, or enqueues it for the workers of an asynchronous
+// Logger.
+func (l *Logger) dispatch(transport Transport, gelfMessage []byte) error {
+	l.closeLock.RLock()
+	defer l.closeLock.RUnlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+	if l.queue != nil {
+		return l.enqueue(queuedMessage{transport: transport, message: gelfMessage})
+	}
+	return l.sendNow(transport, gelfMessage)
+}
+
+// dispatchNow sends the encoded GELF message through the transport like dispatch, without enqueueing it for the
+// workers of an asynchronous Logger, see LogAtNow.
+func (l *Logger) dispatchNow(transport Transport, gelfMessage []byte) error {
+	l.closeLock.RLock()
+	defer l.closeLock.RUnlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+	return l.sendNow(transport, gelfMessage)
+}
+
+// sendNow sends the encoded GELF message through the transport, see send, and counts it as dropped if that fails.
+func (l *Logger) sendNow(transport Transport, gelfMessage []byte) error {
+	err := classify(l.send(transport, gelfMessage))
+	l.dropUnsent(err, gelfMessage)
+	return err
+}
+
+// send sends the encoded GELF message through the given transport. If that fails and a fallback is configured, the
+// message is handed to the fallback instead, and only if the fallback fails as well, an error is returned.
+// Messages for the transport of the Logger go through the spool, if configured, see sendSpooled.
+func (l *Logger) send(transport Transport, gelfMessage []byte) error {
+	if l.spool != nil && transport == l.transport {
+		return l.sendSpooled(gelfMessage)
+	}
+	err := l.retry(func() error { return l.sendCounted(transport, gelfMessage) })
+	if err == nil || l.fallback == nil {
+		return err
+	}
+	if fallbackErr := l.fallback.Send(gelfMessage); fallbackErr != nil {
+		return errors.Join(err, fallbackErr)
 	}
 	return nil
 }
 
-// formatGELFMessage formats a GELF (Graylog Extended Log Format) message with the given message, fields, and host information.
-// It converts the level field to the equivalent Graylog level using the ConvertZerologLevelToGraylog function.
-// The timestamp is divided by 1000 to convert it from milliseconds to seconds.
-// The "level", "time", and "message" fields are deleted from the fields map.
-// The GELF message is created by constructing a map with the required fields and adding the remaining fields prefixed with an underscore.
-// The GELF message is then marshaled into a byte slice.
-// If an error occurs during marshaling, it is logged and returned.
-// Finally, the GELF message byte slice is returned along with any error that occurred.
-func formatGELFMessage(gelfMsg, fields map[string]interface{}) ([]byte, error) {
+// ReplayFallback ships the messages kept by the fallback, e.g. a FileFallback, through the transport once the
+// Graylog server is reachable again. Replaying stops at the first message which cannot be sent, it and all later
+// messages stay with the fallback for the next replay. Fallbacks which do not keep messages are ignored.
+func (l *Logger) ReplayFallback() error {
+	switch r := l.fallback.(type) {
+	case dropReplayer:
+		return r.replay(l.sendToTransport, l.drop)
+	case replayer:
+		return r.Replay(l.sendToTransport)
+	}
+	return nil
+}
+
+// Close sends the pending repetitions of a deduplicating Logger, see WithDeduplication, stops accepting new
+// messages, waits until the pending messages of an asynchronous Logger were sent, at most until the context is done,
+// and closes the transport of the Logger. Messages which are still pending when the context is done are handed to the
+// fallback, if configured, as the transport is closed. Unless the context is done first, every goroutine started by
+// the Logger and its transport has terminated and their connections are closed once Close returns, so Loggers can be
+// created and closed repeatedly. The fallback, the spool and the transports of the Routes are not closed, as they are
+// owned by the caller. Closing a closed Logger does nothing.
+func (l *Logger) Close(ctx context.Context) error {
+	l.dedup.flush()
+	// Unblock the callers waiting for room in the queue before waiting for them
+	l.closeOnce.Do(func() { close(l.closing) })
+	l.closeLock.Lock()
+	if l.closed {
+		l.closeLock.Unlock()
+		return nil
+	}
+	l.closed = true
+	if l.queue != nil {
+		close(l.queue)
+	}
+	l.closeLock.Unlock()
 
-	for k, v := range fields {
-		if boolVal, ok := v.(bool); ok {
-			gelfMsg["_"+k] = strconv.FormatBool(boolVal)
-		} else {
-			gelfMsg["_"+k] = v
+	drained := make(chan struct{})
+	go func() {
+		l.workers.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	err = errors.Join(err, l.transport.Close())
+	l.backgroundLock.Lock()
+	l.backgroundDone = true
+	l.backgroundLock.Unlock()
+	l.background.Wait()
+	for _, owned := range l.owned {
+		err = errors.Join(err, owned.Close())
+	}
+	l.events.close()
+	return err
+}
 
-		}
+// goBackground runs f in a background goroutine Close waits for, and reports whether it was started. Once Close
+// waits for the background goroutines, f is not started anymore. f must return soon after closing is closed.
+func (l *Logger) goBackground(f func()) bool {
+	l.backgroundLock.Lock()
+	defer l.backgroundLock.Unlock()
+	if l.backgroundDone {
+		return false
 	}
+	l.background.Add(1)
+	go func() {
+		defer l.background.Done()
+		f()
+	}()
+	return true
+}
 
-	msgBytes, err := json.Marshal(gelfMsg)
+// formatGELFMessage encodes the Message with the settings of the Logger into a GELF message. The JSON is appended to a
+// pooled buffer, see Message.appendGELF, and copied into a slice of its exact size, so encoding a message of string and
+// number fields allocates nothing but the returned slice:
+// Oversized short messages are truncated, if configured, see WithShortMessageLimit.
+// Nested fields are flattened, if configured, see WithFlattening.
+// The values are coerced to strings and numbers, see coerceFieldValue and WithStrictFieldTypes. Errors are encoded as
+// their message, their details, e.g. a stack trace, are appended to the full message.
+// The field names are sanitized or validated, see gelfFieldName and WithStrictFieldNames.
+// The static fields of the Logger are appended, unless the fields set them, see WithStaticFields.
+// The encoded message is checked against the GELF specification, if configured, see WithValidation.
+// The Message itself is not modified.
+func (l *Logger) formatGELFMessage(msg *Message) ([]byte, error) {
+	buf := getBuffer()
+	encoded, err := msg.appendGELF(*buf, l.encoding(), l.staticFields)
+	var gelfMessage []byte
+	if err == nil {
+		gelfMessage = make([]byte, len(encoded))
+		copy(gelfMessage, encoded)
+	}
+	putBuffer(buf, encoded)
 	if err != nil {
 		return nil, err
 	}
+	if l.validate {
+		gelfMsg, err := msg.gelfFields(l.encoding())
+		if err != nil {
+			return nil, err
+		}
+		if violations := validateFields(gelfMsg, len(gelfMessage)); len(violations) > 0 {
+			return nil, &ValidationError{Violations: violations}
+		}
+	}
+	return gelfMessage, nil
+}
 
-	return msgBytes, nil
+// encoding returns the settings of encoding the messages of the Logger.
+func (l *loggerCore) encoding() messageEncoding {
+	return messageEncoding{
+		strictFieldNames:  l.strictFieldNames,
+		strictFieldTypes:  l.strictFieldTypes,
+		flattenDepth:      l.flattenDepth,
+		shortMessageLimit: l.shortMessageLimit,
+		splitMultiLine:    !l.keepMultiLine,
+		sanitize:          !l.unsanitized,
+	}
 }
 
 // GelfWriter Use the logger to write log messages
@@ -192,28 +696,69 @@ type GelfWriter struct {
 	Logger *Logger
 }
 
-// Write writes the log message to Graylog. It first unmarshals the log message into a map, and then retrieves the "message" key from the map.
+var _ io.WriteCloser = (*GelfWriter)(nil)
+
+// Write writes the log message to Graylog. The JSON written by the logging library is decoded once, the "message"
+// field becomes the short message and the others the additional fields. The JSON itself becomes the full message, and
+// the level and the timestamp are read by the field reader of the Logger, so the fields are not encoded again, see
+// WithFieldReader. Without field reader, the processor of the Logger builds the full message instead.
 // It ensures that the connection to Graylog is alive before writing the log message. If the connection is not alive, it calls the ensureConnection method to establish a new connection
 func (gw *GelfWriter) Write(p []byte) (n int, err error) {
-	var logMsg map[string]interface{}
+	return gw.write(p, readLevel)
+}
+
+// WriteWithLevel writes the log message like Write, with the given Graylog (Syslog) level instead of the level read
+// from the JSON, e.g. the level a logging library passes to its writers, see zerologger.LevelWriter. With a field
+// reader, the level field is neither parsed nor sent as additional field; the processor of a Logger without field
+// reader still reads it, while its level is overridden.
+func (gw *GelfWriter) WriteWithLevel(level int, p []byte) (n int, err error) {
+	return gw.write(p, level)
+}
+
+// write writes the log message of Write and WriteWithLevel, with the given level or readLevel.
+func (gw *GelfWriter) write(p []byte, level int) (n int, err error) {
+	// The map is released on every path, the deduplicator keeps a copy of the fields it holds on to
+	logMsg := getFieldMap()
+	defer putFieldMap(logMsg)
 	if err := json.Unmarshal(p, &logMsg); err != nil {
-		return 0, err
+		return 0, gw.Logger.dropUnencoded(p, err)
 	}
 
 	message, ok := logMsg["message"].(string)
+	if _, present := logMsg["message"]; !present && gw.Logger.shortMessageTemplate != nil {
+		// The short message is rendered from the fields
+		ok = true
+	}
 	if !ok {
-		return 0, fmt.Errorf("log message is not a string")
+		return 0, gw.Logger.dropUnencoded(p, fmt.Errorf("log message is not a string"))
 	}
 
-	// Ensure the connection is alive before logging
+	// Ensure the connection is alive before logging. The message is lost if it is not, so it counts as dropped
 	err = gw.Logger.ensureConnection()
 	if err != nil {
+		err = classify(err)
+		gw.Logger.sendErrors.Add(1)
+		gw.Logger.dropUnsent(err, p)
 		return 0, err
 	}
 
-	err = gw.Logger.Log(message, logMsg)
-	if err != nil {
+	if err := gw.Logger.log(message, logMsg, p, level); err != nil {
 		return 0, err
 	}
 	return len(p), nil
 }
+
+// Close closes the Logger, waiting at most DefaultCloseTimeout for the pending messages to be sent, see Logger.Close.
+func (gw *GelfWriter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+	defer cancel()
+	return gw.Logger.Close(ctx)
+}
+
+// Sync flushes the Logger, waiting at most DefaultCloseTimeout for the pending messages to be sent, see Logger.Flush.
+// Loggers like zap call it before the process exits on fatal messages.
+func (gw *GelfWriter) Sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+	defer cancel()
+	return gw.Logger.Flush(ctx)
+}
@@ -1,10 +1,16 @@
 package gelflogger_test
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	gelflogger "github.com/jame-developer/gelf-logger"
 	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"net"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -17,45 +23,38 @@ func TestNewLogger(t *testing.T) {
 		_ = mockTLSServer.Close()
 	})
 	var tests = []struct {
-		name      string
-		address   string
-		useTLS    bool
-		tlsConfig *tls.Config
-		wantErr   bool
+		name    string
+		address string
+		opts    []gelflogger.Option
+		wantErr bool
 	}{
 		{
 			name:    "Valid TCP Address Without TLS",
 			address: mockServer.Addr().String(),
-			useTLS:  false,
 			wantErr: false,
 		},
 		{
 			name:    "Invalid TCP Address Without TLS",
 			address: "invalid:address",
-			useTLS:  false,
 			wantErr: true,
 		},
 		{
-			name:      "Valid TCP Address With TLS",
-			address:   mockTLSServer.Addr().String(),
-			useTLS:    true,
-			tlsConfig: &tls.Config{InsecureSkipVerify: true},
-			wantErr:   false,
+			name:    "Valid TCP Address With TLS",
+			address: mockTLSServer.Addr().String(),
+			opts:    []gelflogger.Option{gelflogger.WithTLS(&tls.Config{InsecureSkipVerify: true})},
+			wantErr: false,
 		},
 		{
-			name:      "Invalid TCP Address With TLS",
-			address:   "invalid:address",
-			useTLS:    true,
-			tlsConfig: &tls.Config{},
-			wantErr:   true,
+			name:    "Invalid TCP Address With TLS",
+			address: "invalid:address",
+			opts:    []gelflogger.Option{gelflogger.WithTLS(&tls.Config{})},
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := gelflogger.NewLogger(tt.address, tt.useTLS, tt.tlsConfig, func(fields map[string]interface{}) (int, float64, []byte, error) {
-				return 0, 0, nil, nil
-			})
+			_, err := gelflogger.NewLogger(tt.address, tt.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -63,6 +62,32 @@ func TestNewLogger(t *testing.T) {
 	}
 }
 
+func TestNewLoggerWithProcessor(t *testing.T) {
+	mockServer := helper.StartMockServer(t)
+	defer func() { _ = mockServer.Close() }()
+	messages := helper.ReceiveMessages(t, mockServer, 0)
+
+	// The deprecated constructor still takes the TLS settings and the processor as arguments
+	logger, err := gelflogger.NewLoggerWithProcessor(mockServer.Addr().String(), false, nil, processNothing,
+		gelflogger.WithHostname("billing-service"),
+	)
+	if err != nil {
+		t.Fatalf("NewLoggerWithProcessor() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+	if err := logger.Log("legacy", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	select {
+	case message := <-messages:
+		if !strings.Contains(message, `"host":"billing-service"`) || !strings.Contains(message, `"level":6`) {
+			t.Errorf("received %s, want the host of WithHostname and the level of the processor", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
 func TestWriteWithMockServer(t *testing.T) {
 	// Set up the mock server here
 	mockServer := helper.StartMockServer(t)
@@ -120,9 +145,9 @@ func TestWriteWithMockServer(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			// Initialize our logger with the mock server's address
-			logger, _ := gelflogger.NewLogger(mockServer.Addr().String(), false, nil, func(fields map[string]interface{}) (int, float64, []byte, error) {
+			logger, _ := gelflogger.NewLogger(mockServer.Addr().String(), gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
 				return 0, 0, nil, nil
-			})
+			}))
 
 			gw := &gelflogger.GelfWriter{
 				Logger: logger,
@@ -147,3 +172,350 @@ func TestWriteWithMockServer(t *testing.T) {
 		})
 	}
 }
+
+func TestFramingMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []gelflogger.Option
+		delimiter byte
+	}{
+		{
+			name:      "Default null byte framing",
+			delimiter: 0,
+		},
+		{
+			name:      "Null byte framing",
+			opts:      []gelflogger.Option{gelflogger.WithFramingMode(gelflogger.FramingNullByte)},
+			delimiter: 0,
+		},
+		{
+			name:      "Newline framing",
+			opts:      []gelflogger.Option{gelflogger.WithFramingMode(gelflogger.FramingNewline)},
+			delimiter: '\n',
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := helper.StartMockServer(t)
+			defer func() { _ = mockServer.Close() }()
+			messages := helper.ReceiveMessages(t, mockServer, tt.delimiter)
+
+			processor := gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			})
+			logger, err := gelflogger.NewLogger(mockServer.Addr().String(), append(tt.opts, processor)...)
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+
+			for _, message := range []string{"first", "second"} {
+				if err := logger.Log(message, map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+			for _, want := range []string{"first", "second"} {
+				select {
+				case got := <-messages:
+					if !strings.Contains(got, `"short_message":"`+want+`"`) || !strings.HasPrefix(got, "{") {
+						t.Errorf("received message = %q, want the GELF message %q as a single frame", got, want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("message %q was not received", want)
+				}
+			}
+		})
+	}
+}
+
+func TestFailover(t *testing.T) {
+	primary := helper.StartMockServer(t)
+	primaryAddress := primary.Addr().String()
+	_ = primary.Close()
+	secondary := helper.StartMockServer(t)
+	defer func() { _ = secondary.Close() }()
+	secondaryMessages := helper.ReceiveMessages(t, secondary, 0)
+
+	logger, err := gelflogger.NewLogger(primaryAddress, gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+		return 6, 0, nil, nil
+	}), gelflogger.WithFailoverAddresses(secondary.Addr().String()), gelflogger.WithFailbackInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	if err := logger.Log("failed over", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	select {
+	case got := <-secondaryMessages:
+		if !strings.Contains(got, `"short_message":"failed over"`) {
+			t.Errorf("secondary received %q, want the failed over message", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was not received by the failover address")
+	}
+
+	// Bring the primary back, the next message after the failback interval has to be sent to it.
+	primary, err = net.Listen("tcp", primaryAddress)
+	if err != nil {
+		t.Skipf("cannot restart the primary on %s: %v", primaryAddress, err)
+	}
+	defer func() { _ = primary.Close() }()
+	primaryMessages := helper.ReceiveMessages(t, primary, 0)
+
+	// The primary is re-checked in the background, keep logging until a message arrives there.
+	deadline := time.After(time.Second)
+	for {
+		if err := logger.Log("failed back", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+		select {
+		case got := <-primaryMessages:
+			if !strings.Contains(got, `"short_message":"failed back"`) {
+				t.Errorf("primary received %q, want the failed back message", got)
+			}
+			return
+		case <-deadline:
+			t.Fatal("message was not received by the primary address")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestFailoverAllUnreachable(t *testing.T) {
+	_, err := gelflogger.NewLogger("invalid:address", gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+		return 0, 0, nil, nil
+	}), gelflogger.WithFailoverAddresses("invalid:address2"))
+	if err == nil {
+		t.Error("NewLogger() error = nil, want an error when no address is reachable")
+	}
+}
+
+func TestResolveHostname(t *testing.T) {
+	mockServer := helper.StartMockServer(t)
+	defer func() { _ = mockServer.Close() }()
+	messages := helper.ReceiveMessages(t, mockServer, 0)
+	_, port, _ := net.SplitHostPort(mockServer.Addr().String())
+
+	tests := []struct {
+		name    string
+		address string
+		opts    []gelflogger.Option
+		wantErr bool
+	}{
+		{
+			name:    "Hostname",
+			address: net.JoinHostPort("localhost", port),
+			wantErr: false,
+		},
+		{
+			name:    "Hostname with rotation among resolved addresses",
+			address: net.JoinHostPort("localhost", port),
+			opts:    []gelflogger.Option{gelflogger.WithResolvedAddressRotation(), gelflogger.WithResolver(&net.Resolver{PreferGo: true})},
+			wantErr: false,
+		},
+		{
+			name:    "Unresolvable hostname",
+			address: "graylog.invalid:12201",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := gelflogger.NewLogger(tt.address, append(tt.opts, gelflogger.WithProcessor(processNothing))...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := logger.Log("resolved", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			select {
+			case <-messages:
+			case <-time.After(time.Second):
+				t.Fatal("message was not received")
+			}
+		})
+	}
+}
+
+func TestHappyEyeballs(t *testing.T) {
+	mockServer := helper.StartMockServer(t)
+	defer func() { _ = mockServer.Close() }()
+	messages := helper.ReceiveMessages(t, mockServer, 0)
+	_, port, _ := net.SplitHostPort(mockServer.Addr().String())
+
+	resolver := helper.StartMockDNSServer(t, map[string][]net.IP{
+		// 2001:db8::/32 is reserved for documentation, connection attempts to it either stall or fail
+		"unroutable-ipv6.test": {net.ParseIP("2001:db8::1"), net.ParseIP("127.0.0.1")},
+		"refused-ipv6.test":    {net.ParseIP("::1"), net.ParseIP("127.0.0.1")},
+		"ipv4-only.test":       {net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.1")},
+	})
+
+	tests := []struct {
+		name string
+		host string
+		opts []gelflogger.Option
+	}{
+		{name: "Stalling IPv6 route", host: "unroutable-ipv6.test"},
+		{name: "Refused IPv6 connection", host: "refused-ipv6.test"},
+		{name: "Refused IPv6 connection without racing", host: "refused-ipv6.test", opts: []gelflogger.Option{gelflogger.WithConnectionAttemptDelay(0)}},
+		{name: "Single address family", host: "ipv4-only.test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]gelflogger.Option{gelflogger.WithResolver(resolver)}, tt.opts...)
+			start := time.Now()
+			logger, err := gelflogger.NewLogger(net.JoinHostPort(tt.host, port), append(opts, gelflogger.WithProcessor(processNothing))...)
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+			// The dial timeout of a single attempt is 5 seconds
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("NewLogger() took %v, want the IPv4 address to be dialed after the attempt delay", elapsed)
+			}
+			if err := logger.Log("dual-stack", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			select {
+			case <-messages:
+			case <-time.After(time.Second):
+				t.Fatal("message was not received")
+			}
+		})
+	}
+}
+
+func TestLogAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     int
+		timestamp time.Time
+		fields    map[string]interface{}
+		want      map[string]interface{}
+	}{
+		{
+			name:      "Historical event",
+			level:     gelflogger.Warning,
+			timestamp: time.Date(2021, 3, 4, 5, 6, 7, 250000000, time.UTC),
+			fields:    map[string]interface{}{"order_id": 42},
+			want:      map[string]interface{}{"level": float64(4), "timestamp": 1614834367.25, "_order_id": float64(42)},
+		},
+		{
+			name:      "Level and time fields are additional fields",
+			level:     gelflogger.Debug,
+			timestamp: time.Unix(1700000000, 0),
+			fields:    map[string]interface{}{"level": "error", "time": 1600000000},
+			want:      map[string]interface{}{"level": float64(7), "timestamp": float64(1700000000), "_level": "error", "_time": float64(1600000000)},
+		},
+		{
+			name:  "Without fields",
+			level: gelflogger.Critical,
+			want:  map[string]interface{}{"level": float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields)
+			before := time.Now()
+			if err := logger.LogAt(tt.level, tt.timestamp, "imported", tt.fields); err != nil {
+				t.Fatalf("LogAt() error = %v", err)
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(transport.messages))
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			for key, want := range tt.want {
+				if gelfMsg[key] != want {
+					t.Errorf("%s = %v, want %v in %s", key, gelfMsg[key], want, transport.messages[0])
+				}
+			}
+			if _, ok := gelfMsg["full_message"]; ok {
+				t.Errorf("sent %s, want no full message", transport.messages[0])
+			}
+			if ts, _ := gelfMsg["timestamp"].(float64); tt.timestamp.IsZero() && ts < float64(before.Unix()) {
+				t.Errorf("timestamp = %v, want the current time", ts)
+			}
+		})
+	}
+}
+
+func TestLogAtNow(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields,
+		gelflogger.WithAsync(10, 1), gelflogger.WithDeduplication(time.Minute))
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	for i := 0; i < 2; i++ {
+		if err := logger.LogAtNow(gelflogger.Emergency, time.Time{}, "shutting down", map[string]interface{}{"reason": "fatal"}); err != nil {
+			t.Fatalf("LogAtNow() error = %v", err)
+		}
+	}
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	if len(transport.messages) != 2 {
+		t.Fatalf("sent %d messages when LogAtNow returned, want both sent without queue and deduplication", len(transport.messages))
+	}
+	if !strings.Contains(transport.messages[0], `"level":0`) || !strings.Contains(transport.messages[0], `"_reason":"fatal"`) {
+		t.Errorf("sent %s, want the level and fields of LogAtNow", transport.messages[0])
+	}
+}
+
+func TestLogRaw(t *testing.T) {
+	const gelfMessage = `{"version":"1.1","host":"relay","short_message":"forwarded","level":3,"_origin":"edge-1"}`
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+		wantErr error
+	}{
+		{name: "Passed through", message: gelfMessage, want: gelfMessage},
+		{name: "Whitespace trimmed", message: "  " + gelfMessage + "\n", want: gelfMessage},
+		{name: "Empty message", message: " \n", wantErr: gelflogger.ErrPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields,
+				gelflogger.WithStaticFields(map[string]interface{}{"service": "billing"}),
+				gelflogger.WithSampling(map[int]int{3: 1000}),
+			)
+			raw := []byte(tt.message)
+			err := logger.LogRaw(raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || len(transport.messages) != 0 {
+					t.Errorf("LogRaw() error = %v, sent %d messages, want %v", err, len(transport.messages), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LogRaw() error = %v", err)
+			}
+			copy(raw, strings.Repeat("x", len(raw)))
+			if len(transport.messages) != 1 || transport.messages[0] != tt.want {
+				t.Errorf("sent %q, want %q", transport.messages, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogRawClosed(t *testing.T) {
+	logger := gelflogger.NewLoggerWithTransport(&recordingTransport{}, gelflogger.ProcessFields)
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.LogRaw([]byte(`{"version":"1.1"}`)); !errors.Is(err, gelflogger.ErrLoggerClosed) {
+		t.Errorf("LogRaw() error = %v, want ErrLoggerClosed", err)
+	}
+}
@@ -0,0 +1,48 @@
+package gelflogger
+
+import (
+	"time"
+)
+
+// HeartbeatMessage is the short_message of the heartbeat messages of a Logger, see WithHeartbeat.
+const HeartbeatMessage = "gelf-logger heartbeat"
+
+// heartbeatLevel is the Graylog level of the heartbeat messages, informational.
+const heartbeatLevel = 6
+
+// heartbeat sends a heartbeat message every interval until the Logger is closed.
+func (l *Logger) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.closing:
+			return
+		case <-ticker.C:
+			l.logHeartbeat()
+		}
+	}
+}
+
+// logHeartbeat sends a heartbeat message carrying the Stats of the Logger as additional fields. The message bypasses
+// sampling and deduplication, so every heartbeat reaches Graylog. Send errors are not reported, as the message is not
+// sent on behalf of a log call, but counted as drops.
+func (l *Logger) logHeartbeat() {
+	stats := l.Stats()
+	_ = l.deliver(&Message{
+		Version:      gelfVersion,
+		Host:         l.hostname(),
+		ShortMessage: HeartbeatMessage,
+		Timestamp:    float64(time.Now().UnixMilli()) / 1000,
+		Level:        heartbeatLevel,
+		Additional: map[string]interface{}{
+			"heartbeat":     true,
+			"messages_sent": stats.MessagesSent,
+			"bytes_sent":    stats.BytesSent,
+			"send_errors":   stats.SendErrors,
+			"reconnects":    stats.Reconnects,
+			"queue_depth":   stats.QueueDepth,
+			"dropped":       stats.Dropped,
+		},
+	})
+}
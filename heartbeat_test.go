@@ -0,0 +1,56 @@
+package gelflogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestHeartbeat(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing,
+		gelflogger.WithHeartbeat(10*time.Millisecond),
+		gelflogger.WithSampling(map[int]int{0: 1000}),
+	)
+	if err := logger.Log("application message", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	// The first message is the application message, the heartbeats follow
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sentMessages(t, transport)) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("no heartbeats sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	transport.lock.Lock()
+	messages := append([]string(nil), transport.messages...)
+	transport.lock.Unlock()
+	for i, message := range messages[1:] {
+		var heartbeat map[string]interface{}
+		if err := json.Unmarshal([]byte(message), &heartbeat); err != nil {
+			t.Fatalf("heartbeat is no JSON: %v", err)
+		}
+		if heartbeat["short_message"] != gelflogger.HeartbeatMessage || heartbeat["_heartbeat"] != "true" {
+			t.Errorf("heartbeat %d = %s, want a heartbeat message", i, message)
+		}
+		// Every heartbeat counts the messages sent before it, the application message and the previous heartbeats
+		if heartbeat["_messages_sent"] != float64(i+1) {
+			t.Errorf("heartbeat %d _messages_sent = %v, want %d", i, heartbeat["_messages_sent"], i+1)
+		}
+	}
+
+	// No heartbeat is sent once the Logger is closed
+	time.Sleep(30 * time.Millisecond)
+	if got := len(sentMessages(t, transport)); got != len(messages) {
+		t.Errorf("%d messages sent after Close, want none", got-len(messages))
+	}
+}
@@ -0,0 +1,51 @@
+package gelflogger
+
+import (
+	"os"
+	"time"
+)
+
+// HostnameEnv is the environment variable overriding the hostname reported by the kernel as host field of the
+// messages, e.g. set to the name of the pod. WithHostname takes precedence over it.
+const HostnameEnv = "GELF_HOSTNAME"
+
+// unknownHostname is the host field of the messages if the hostname can neither be read from HostnameEnv nor from the
+// kernel, as GELF requires a host.
+const unknownHostname = "unknown"
+
+// lookupHostname returns the host field of the messages: the hostname set by WithHostname, else the one of
+// HostnameEnv, else the one reported by the kernel. It reports false if none of them is available.
+func lookupHostname(override string) (string, bool) {
+	if override != "" {
+		return override, true
+	}
+	if hostname := os.Getenv(HostnameEnv); hostname != "" {
+		return hostname, true
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname, true
+	}
+	return "", false
+}
+
+// hostname returns the current host field of the messages.
+func (l *Logger) hostname() string {
+	return *l.host.Load()
+}
+
+// refreshHostname looks up the hostname every interval until the Logger is closed, see WithHostnameRefresh. If the
+// lookup fails, the previous hostname is kept.
+func (l *Logger) refreshHostname(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.closing:
+			return
+		case <-ticker.C:
+			if hostname, ok := lookupHostname(""); ok && hostname != l.hostname() {
+				l.host.Store(&hostname)
+			}
+		}
+	}
+}
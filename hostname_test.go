@@ -0,0 +1,75 @@
+package gelflogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// lastHost logs a message and returns its host field.
+func lastHost(t *testing.T, logger *gelflogger.Logger, transport *recordingTransport) string {
+	t.Helper()
+	if err := logger.Log("host", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	var gelfMsg map[string]interface{}
+	if err := json.Unmarshal([]byte(transport.messages[len(transport.messages)-1]), &gelfMsg); err != nil {
+		t.Fatalf("invalid GELF message: %v", err)
+	}
+	return gelfMsg["host"].(string)
+}
+
+func TestHostname(t *testing.T) {
+	kernelHostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		opts []gelflogger.Option
+		want string
+	}{
+		{name: "Hostname of the kernel", want: kernelHostname},
+		{name: "Environment overrides the kernel", env: "pod-1", want: "pod-1"},
+		{name: "Option overrides the environment", env: "pod-1", opts: []gelflogger.Option{gelflogger.WithHostname("billing")}, want: "billing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(gelflogger.HostnameEnv, tt.env)
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			defer func() { _ = logger.Close(context.Background()) }()
+			if got := lastHost(t, logger, transport); got != tt.want {
+				t.Errorf("host = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithHostnameRefresh(t *testing.T) {
+	t.Setenv(gelflogger.HostnameEnv, "before")
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithHostnameRefresh(10*time.Millisecond))
+	defer func() { _ = logger.Close(context.Background()) }()
+	if got := lastHost(t, logger, transport); got != "before" {
+		t.Fatalf("host = %s, want before", got)
+	}
+
+	t.Setenv(gelflogger.HostnameEnv, "after")
+	deadline := time.Now().Add(2 * time.Second)
+	for lastHost(t, logger, transport) != "after" {
+		if time.Now().After(deadline) {
+			t.Fatal("the hostname was not refreshed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,136 @@
+package gelflogger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultHTTPRetries is the number of times the HTTP transport retries a message which Graylog did not accept due to
+// a temporary failure, see WithHTTPRetries.
+const DefaultHTTPRetries = 3
+
+// httpTransport posts every GELF message to the GELF HTTP input of a Graylog server. Graylog acknowledges accepted
+// messages with 202 Accepted, every other response is a failure. The context is canceled by Close, aborting the
+// requests and retries in flight.
+type httpTransport struct {
+	url         string
+	client      *http.Client
+	retries     int
+	backoff     backoff
+	compression Compression
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// newHTTPTransport creates an httpTransport posting to the given http:// or https:// URL, whose path defaults to
+// /gelf. For https:// URLs, the TLS configuration, the client certificate and the TLS policy of the configuration are
+// used.
+func newHTTPTransport(rawURL string, tlsConfig *tls.Config, cfg config) (*httpTransport, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/gelf"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if u.Scheme == "https" {
+		if err := cfg.prepareTLS(tlsConfig); err != nil {
+			return nil, err
+		}
+		if transport.TLSClientConfig, err = clientTLSConfig(u.Host, tlsConfig, cfg.tlsMaterial, cfg.tlsPolicy); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &httpTransport{
+		url:         u.String(),
+		client:      &http.Client{Transport: transport, Timeout: cfg.timeout},
+		retries:     cfg.httpRetries,
+		backoff:     backoff{initial: DefaultRetryDelay, max: DefaultMaxRetryDelay},
+		compression: cfg.compression,
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+// Send posts the message to the GELF HTTP input, see SendConfirmed.
+func (t *httpTransport) Send(message []byte) error {
+	return t.post(t.ctx, message)
+}
+
+// SendConfirmed posts the message to the GELF HTTP input and returns once Graylog accepted it, or the retries are
+// exhausted, or the context is done.
+func (t *httpTransport) SendConfirmed(ctx context.Context, message []byte) error {
+	return t.post(ctx, message)
+}
+
+// SendBatch posts the messages as JSON array with a single request.
+func (t *httpTransport) SendBatch(messages [][]byte) error {
+	return t.post(t.ctx, append(append([]byte{'['}, bytes.Join(messages, []byte{','})...), ']'))
+}
+
+// post posts the body, compressed with the configured Compression, to the GELF HTTP input, retrying temporary failures
+// with backoff up to the configured number of retries.
+func (t *httpTransport) post(ctx context.Context, body []byte) error {
+	body, err := t.compression.compress(body)
+	if err != nil {
+		return err
+	}
+	err = t.postOnce(ctx, body)
+	for attempt := 0; attempt < t.retries && errors.Is(classify(err), ErrTemporary); attempt++ {
+		select {
+		case <-time.After(t.backoff.delay(attempt)):
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+		err = t.postOnce(ctx, body)
+	}
+	return err
+}
+
+// postOnce posts the body to the GELF HTTP input once. Responses other than 202 Accepted are reported as error.
+func (t *httpTransport) postOnce(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if encoding := t.compression.contentEncoding(); encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// Drain the body, so the connection can be reused for the next message
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusAccepted {
+		// Overload and server errors may go away, other responses repeat for the same message
+		class := ErrPermanent
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			class = ErrTemporary
+		}
+		if resp.StatusCode == http.StatusRequestEntityTooLarge {
+			return fmt.Errorf("%w: %w: GELF HTTP input at %s responded with %s", class, ErrMessageTooLarge, t.url, resp.Status)
+		}
+		return fmt.Errorf("%w: GELF HTTP input at %s responded with %s instead of 202 Accepted", class, t.url, resp.Status)
+	}
+	return nil
+}
+
+// Close aborts the requests in flight and closes the idle connections to the GELF HTTP input.
+func (t *httpTransport) Close() error {
+	t.cancel()
+	t.client.CloseIdleConnections()
+	return nil
+}
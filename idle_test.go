@@ -0,0 +1,61 @@
+package gelflogger_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestKeepalive(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	accepted := acceptConnections(server)
+
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithKeepalive(30*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	conn := <-accepted
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frame := make([]byte, 1)
+	if _, err := io.ReadFull(conn, frame); err != nil {
+		t.Fatalf("no keepalive frame received: %v", err)
+	}
+	if frame[0] != 0 {
+		t.Errorf("keepalive frame = %q, want an empty frame terminated by a null byte", frame)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	accepted := acceptConnections(server)
+
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithIdleTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	idle := <-accepted
+	defer func() { _ = idle.Close() }()
+	select {
+	case conn := <-accepted:
+		defer func() { _ = conn.Close() }()
+	case <-time.After(2 * time.Second):
+		t.Fatal("the idle connection was not replaced")
+	}
+
+	// The idle connection is closed by the transport once the new one is established
+	_ = idle.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := idle.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Read() on the idle connection error = %v, want io.EOF", err)
+	}
+}
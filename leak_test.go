@@ -0,0 +1,164 @@
+package gelflogger_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"go.uber.org/goleak"
+)
+
+// TestCloseStopsGoroutines creates and closes Loggers with every kind of background goroutine several times, and
+// verifies that no goroutine outlives Close.
+func TestCloseStopsGoroutines(t *testing.T) {
+	tests := []struct {
+		name string
+		// newLogger creates the Logger, the returned function releases the resources owned by the test
+		newLogger func(t *testing.T) (*gelflogger.Logger, func())
+	}{
+		{
+			name: "TCP with supervisor",
+			newLogger: func(t *testing.T) (*gelflogger.Logger, func()) {
+				server := helper.StartMockServer(t)
+				logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing),
+					gelflogger.WithHealthCheckInterval(time.Millisecond),
+					gelflogger.WithIdleTimeout(10*time.Millisecond),
+					gelflogger.WithKeepalive(5*time.Millisecond),
+				)
+				if err != nil {
+					t.Fatalf("NewLogger() error = %v", err)
+				}
+				return logger, func() { _ = server.Close() }
+			},
+		},
+		{
+			name: "TCP with failover and pool",
+			newLogger: func(t *testing.T) (*gelflogger.Logger, func()) {
+				primary := helper.StartMockServer(t)
+				secondary := helper.StartMockServer(t)
+				logger, err := gelflogger.NewLogger(primary.Addr().String(), gelflogger.WithProcessor(processNothing),
+					gelflogger.WithFailoverAddresses(secondary.Addr().String()),
+					gelflogger.WithFailbackInterval(time.Millisecond),
+					gelflogger.WithConnectionPool(3),
+				)
+				if err != nil {
+					t.Fatalf("NewLogger() error = %v", err)
+				}
+				return logger, func() {
+					_ = primary.Close()
+					_ = secondary.Close()
+				}
+			},
+		},
+		{
+			name: "Closed while reconnecting",
+			newLogger: func(t *testing.T) (*gelflogger.Logger, func()) {
+				server := helper.StartMockServer(t)
+				accepted := acceptConnections(server)
+				logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing),
+					gelflogger.WithHealthCheckInterval(time.Millisecond),
+					gelflogger.WithReconnectBackoff(time.Hour, time.Hour),
+				)
+				if err != nil {
+					t.Fatalf("NewLogger() error = %v", err)
+				}
+				events := logger.Events()
+				_ = server.Close()
+				_ = (<-accepted).Close()
+				for event := range events {
+					if event.Type == gelflogger.EventReconnecting {
+						break
+					}
+				}
+				return logger, func() {}
+			},
+		},
+		{
+			name: "Asynchronous with batching, deduplication and heartbeat",
+			newLogger: func(*testing.T) (*gelflogger.Logger, func()) {
+				logger := gelflogger.NewLoggerWithTransport(&recordingTransport{}, processNothing,
+					gelflogger.WithAsync(10, 3),
+					gelflogger.WithBatching(5, time.Hour),
+					gelflogger.WithDeduplication(time.Hour),
+					gelflogger.WithHeartbeat(time.Millisecond),
+				)
+				return logger, func() {}
+			},
+		},
+		{
+			name: "Spool replay",
+			newLogger: func(t *testing.T) (*gelflogger.Logger, func()) {
+				spool, err := gelflogger.NewSpool(t.TempDir(), 0, 0)
+				if err != nil {
+					t.Fatalf("NewSpool() error = %v", err)
+				}
+				logger := gelflogger.NewLoggerWithTransport(&recordingTransport{down: true}, processNothing, gelflogger.WithSpool(spool))
+				return logger, func() { _ = spool.Close() }
+			},
+		},
+		{
+			name: "HTTP",
+			newLogger: func(t *testing.T) (*gelflogger.Logger, func()) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusAccepted)
+				}))
+				logger, err := gelflogger.NewLogger(server.URL, gelflogger.WithProcessor(processNothing))
+				if err != nil {
+					t.Fatalf("NewLogger() error = %v", err)
+				}
+				return logger, server.Close
+			},
+		},
+		{
+			name: "UDP",
+			newLogger: func(t *testing.T) (*gelflogger.Logger, func()) {
+				conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatalf("ListenPacket() error = %v", err)
+				}
+				logger, err := gelflogger.NewLogger("udp://"+conn.LocalAddr().String(), gelflogger.WithProcessor(processNothing))
+				if err != nil {
+					t.Fatalf("NewLogger() error = %v", err)
+				}
+				return logger, func() { _ = conn.Close() }
+			},
+		},
+	}
+
+	t.Run("Failed to connect", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+		server := helper.StartMockServer(t)
+		address := server.Addr().String()
+		_ = server.Close()
+		for _, opts := range [][]gelflogger.Option{
+			{gelflogger.WithHealthCheckInterval(time.Millisecond)},
+			{gelflogger.WithConnectionPool(3)},
+			{gelflogger.WithLoadBalancing(gelflogger.RoundRobin), gelflogger.WithFailoverAddresses(address)},
+		} {
+			if _, err := gelflogger.NewLogger(address, append(opts, gelflogger.WithProcessor(processNothing))...); err == nil {
+				t.Fatal("NewLogger() error = nil, want the connect error")
+			}
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+			for i := 0; i < 3; i++ {
+				logger, release := tt.newLogger(t)
+				for j := 0; j < 10; j++ {
+					_ = logger.Log("cycle", map[string]interface{}{})
+				}
+				if err := logger.Close(context.Background()); err != nil {
+					t.Errorf("Close() error = %v", err)
+				}
+				release()
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package gelflogger_test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestQueueMemoryLimit(t *testing.T) {
+	// Determine the size of an encoded message, all messages below have the same size
+	sample := &recordingTransport{}
+	_ = gelflogger.NewLoggerWithTransport(sample, processNothing).Log("0", map[string]interface{}{})
+	size := int64(len(sample.messages[0]))
+
+	transport := &recordingTransport{}
+	blocking := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+	var dropped []string
+	logger := gelflogger.NewLoggerWithTransport(&sequentialTransport{first: blocking, rest: transport}, processNothing,
+		gelflogger.WithAsync(10, 1),
+		gelflogger.WithQueueMemoryLimit(2*size+size/2),
+		gelflogger.WithOverflowPolicy(gelflogger.OverflowDropNewest),
+		gelflogger.WithOnDrop(func(message []byte, reason error) {
+			dropped = append(dropped, shortMessage(t, message))
+		}))
+
+	// The worker blocks on message 0, messages 1 and 2 take the memory of the queue, messages 3 and 4 exceed it
+	for i := 0; i < 5; i++ {
+		err := logger.Log(strconv.Itoa(i), map[string]interface{}{})
+		if wantErr := i >= 3; (err == gelflogger.ErrQueueFull) != wantErr {
+			t.Fatalf("Log(%d) error = %v, want ErrQueueFull %v", i, err, wantErr)
+		}
+		if i == 0 {
+			<-blocking.started
+		}
+	}
+	if stats := logger.Stats(); stats.QueueBytes != 2*size || stats.QueueDepth != 2 {
+		t.Errorf("Stats() = %+v, want 2 queued messages of %d bytes", stats, 2*size)
+	}
+	close(blocking.release)
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(dropped, []string{"3", "4"}) {
+		t.Errorf("dropped %v, want [3 4]", dropped)
+	}
+	var sent []string
+	for _, message := range transport.messages {
+		sent = append(sent, shortMessage(t, []byte(message)))
+	}
+	if !reflect.DeepEqual(sent, []string{"1", "2"}) {
+		t.Errorf("sent %v after the blocked message, want [1 2]", sent)
+	}
+	if queued := logger.Stats().QueueBytes; queued != 0 {
+		t.Errorf("Stats().QueueBytes = %d after Close, want 0", queued)
+	}
+}
+
+func TestQueueMemoryLimitLargeMessage(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing,
+		gelflogger.WithAsync(10, 1),
+		gelflogger.WithQueueMemoryLimit(1))
+
+	// A message exceeding the limit on its own is queued once the queue is empty instead of blocking forever
+	if err := logger.Log("large", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(transport.messages) != 1 {
+		t.Errorf("sent %d messages, want 1", len(transport.messages))
+	}
+}
@@ -0,0 +1,161 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// gelfVersion is the version of the GELF messages of a Logger.
+const gelfVersion = "1.1"
+
+// Message is a GELF message before it is encoded, as built by the log calls of a Logger and seen by its Processors.
+// Messages can also be constructed, e.g. by tools relaying GELF messages, and encoded by MarshalGELF, or decoded from
+// their encoding by UnmarshalGELF.
+type Message struct {
+	// Version is the GELF version, 1.1.
+	Version string
+	// Host is the host field, see WithHostname.
+	Host string
+	// ShortMessage is the message passed to the log call.
+	ShortMessage string
+	// FullMessage is the full message returned by the processor of the fields, see WithProcessor. It is omitted from
+	// the encoding if empty.
+	FullMessage string
+	// Timestamp is the UNIX timestamp in seconds.
+	Timestamp float64
+	// Level is the Graylog (Syslog) level.
+	Level int
+	// Additional are the additional fields, named without the leading underscore added when encoding. The static
+	// fields of the Logger are not included, see WithStaticFields.
+	Additional map[string]interface{}
+
+	// sampleRate is the rate the message was sampled with, see WithSampling, added as the _sampled and _sample_rate
+	// fields if greater than one.
+	sampleRate uint64
+	// ownedFields is the map of fields the Logger created for the message, e.g. the copy handed to the Processors,
+	// returned to the pool along with the message, see Logger.releaseMessage.
+	ownedFields map[string]interface{}
+}
+
+// messageEncoding are the settings of encoding a Message, see Message.MarshalGELF and Logger.formatGELFMessage.
+//
+// - strictFieldNames: Rejecting invalid field names rather than sanitizing them, see WithStrictFieldNames.
+// - strictFieldTypes: Rejecting field values of unsupported types rather than coercing them, see WithStrictFieldTypes.
+// - flattenDepth: The maximum depth of flattening nested fields, zero if disabled, see WithFlattening.
+// - shortMessageLimit: The maximum size of the short message in bytes, zero if unlimited, see WithShortMessageLimit.
+// - splitMultiLine: Reducing multi-line short messages to their first line, see WithMultiLineShortMessages.
+// - sanitize: Replacing invalid UTF-8 and stripping control characters from the strings, see WithUnsanitizedStrings.
+type messageEncoding struct {
+	strictFieldNames  bool
+	strictFieldTypes  bool
+	flattenDepth      int
+	shortMessageLimit int
+	splitMultiLine    bool
+	sanitize          bool
+}
+
+// MarshalGELF encodes the message as GELF JSON, like a Logger with the default settings does: the additional fields are
+// prefixed with an underscore, invalid field names are sanitized, see WithStrictFieldNames, and the values are coerced
+// to strings and numbers, see WithStrictFieldTypes. An empty full message is omitted. The message is not modified.
+func (m *Message) MarshalGELF() ([]byte, error) {
+	return m.appendGELF(nil, messageEncoding{}, nil)
+}
+
+// UnmarshalGELF decodes the GELF JSON encoding of a message, replacing the content of m. The additional fields are
+// stored without their leading underscore. Fields without the underscore which are not standard GELF fields, e.g. the
+// facility of GELF 1.0, are kept in Additional as they are. Numbers are decoded as float64, the level must be a Syslog
+// level, see ParseLevel. Missing fields are left at their zero value.
+func (m *Message) UnmarshalGELF(data []byte) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+	msg := Message{Additional: make(map[string]interface{}, len(fields))}
+	for name, value := range fields {
+		var ok bool
+		switch name {
+		case "version":
+			msg.Version, ok = value.(string)
+		case "host":
+			msg.Host, ok = value.(string)
+		case "short_message":
+			msg.ShortMessage, ok = value.(string)
+		case "full_message":
+			msg.FullMessage, ok = value.(string)
+		case "timestamp":
+			msg.Timestamp, ok = value.(float64)
+		case "level":
+			level, err := ParseLevel(value)
+			if err != nil {
+				return fmt.Errorf("%w: field level: %w", ErrInvalidMessage, err)
+			}
+			msg.Level, ok = level, true
+		default:
+			msg.Additional[strings.TrimPrefix(name, "_")], ok = value, true
+		}
+		if !ok {
+			return fmt.Errorf("%w: field %s of type %T", ErrInvalidMessage, name, value)
+		}
+	}
+	*m = msg
+	return nil
+}
+
+// gelfFields returns the members of the GELF encoding of the message with the given settings, as matched by the Routes
+// and checked by WithValidation, see appendGELF for the encoding itself. Multi-line short messages are reduced to
+// their first line, oversized short messages are truncated, nested fields flattened, the details of errors appended
+// to the full message, the values of the additional fields coerced and the strings sanitized, without modifying the
+// message.
+func (m *Message) gelfFields(enc messageEncoding) (map[string]interface{}, error) {
+	msg := *m
+	if enc.splitMultiLine {
+		splitMultiLine(&msg)
+	}
+	if enc.shortMessageLimit > 0 {
+		truncateShortMessage(&msg, enc.shortMessageLimit)
+	}
+	fields := msg.Additional
+	if enc.flattenDepth > 0 {
+		fields = flattenFields(fields, enc.flattenDepth)
+	}
+
+	gelfMsg := make(map[string]interface{}, len(fields)+8)
+	for k, v := range fields {
+		key, err := gelfFieldName(k, enc.strictFieldNames)
+		if err != nil {
+			return nil, err
+		}
+		if errVal, ok := v.(error); ok && !isNil(errVal) {
+			appendErrorDetails(&msg, k, errVal)
+		}
+		value, ok, err := coerceFieldValue(v, enc.strictFieldTypes)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", k, err)
+		}
+		if s, isString := value.(string); isString && enc.sanitize {
+			value = sanitizeString(s)
+		}
+		if ok {
+			gelfMsg[key] = value
+		}
+	}
+	if msg.sampleRate > 1 {
+		gelfMsg["_sampled"] = "true"
+		gelfMsg["_sample_rate"] = msg.sampleRate
+	}
+
+	gelfMsg["version"] = msg.Version
+	gelfMsg["host"] = msg.Host
+	if enc.sanitize {
+		msg.ShortMessage, msg.FullMessage = sanitizeString(msg.ShortMessage), sanitizeString(msg.FullMessage)
+	}
+	gelfMsg["short_message"] = msg.ShortMessage
+	gelfMsg["timestamp"] = msg.Timestamp
+	gelfMsg["level"] = msg.Level
+	// The full message is optional, omit it rather than sending it empty
+	if msg.FullMessage != "" {
+		gelfMsg["full_message"] = msg.FullMessage
+	}
+	return gelfMsg, nil
+}
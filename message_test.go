@@ -0,0 +1,144 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestMessageMarshalGELF(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     gelflogger.Message
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "Standard and additional fields",
+			msg: gelflogger.Message{
+				Version:      "1.1",
+				Host:         "billing",
+				ShortMessage: "order placed",
+				FullMessage:  "order 42 placed",
+				Timestamp:    1700000000.5,
+				Level:        6,
+				Additional:   map[string]interface{}{"order_id": 42, "paid": true},
+			},
+			want: map[string]interface{}{
+				"version": "1.1", "host": "billing", "short_message": "order placed", "full_message": "order 42 placed",
+				"timestamp": 1700000000.5, "level": float64(6), "_order_id": float64(42), "_paid": "true",
+			},
+		},
+		{
+			name: "Empty full message and sanitized name",
+			msg:  gelflogger.Message{Version: "1.1", Host: "billing", ShortMessage: "hello", Additional: map[string]interface{}{"id": "7"}},
+			want: map[string]interface{}{
+				"version": "1.1", "host": "billing", "short_message": "hello", "timestamp": float64(0), "level": float64(0),
+				"_id_": "7",
+			},
+		},
+		{
+			name: "Error with details",
+			msg:  gelflogger.Message{Version: "1.1", ShortMessage: "failed", Additional: map[string]interface{}{"error": &stackError{msg: "failed"}}},
+			want: map[string]interface{}{
+				"version": "1.1", "host": "", "short_message": "failed", "full_message": "error: failed\nmain.handler\n\t/app/main.go:42",
+				"timestamp": float64(0), "level": float64(0), "_error": "failed",
+			},
+		},
+		{
+			name:    "Invalid timestamp",
+			msg:     gelflogger.Message{Timestamp: math.NaN()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.msg.MarshalGELF()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MarshalGELF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(encoded, &got); err != nil {
+				t.Fatalf("MarshalGELF() = %s, invalid JSON: %v", encoded, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarshalGELF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageUnmarshalGELF(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    gelflogger.Message
+		wantErr string
+	}{
+		{
+			name: "Standard and additional fields",
+			data: `{"version":"1.1","host":"billing","short_message":"order placed","full_message":"details","timestamp":1700000000.5,"level":3,"_order_id":42,"facility":"shop"}`,
+			want: gelflogger.Message{
+				Version:      "1.1",
+				Host:         "billing",
+				ShortMessage: "order placed",
+				FullMessage:  "details",
+				Timestamp:    1700000000.5,
+				Level:        3,
+				Additional:   map[string]interface{}{"order_id": float64(42), "facility": "shop"},
+			},
+		},
+		{name: "Invalid JSON", data: `{"version":`, wantErr: "invalid GELF message"},
+		{name: "Invalid level", data: `{"level":9}`, wantErr: "field level"},
+		{name: "Invalid type", data: `{"short_message":42}`, wantErr: "field short_message of type float64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got gelflogger.Message
+			err := got.UnmarshalGELF([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UnmarshalGELF() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalGELF() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalGELF() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	msg := gelflogger.Message{
+		Version:      "1.1",
+		Host:         "billing",
+		ShortMessage: "order placed",
+		Timestamp:    1700000000.25,
+		Level:        4,
+		Additional:   map[string]interface{}{"customer": "c-1", "amount": 9.5},
+	}
+	encoded, err := msg.MarshalGELF()
+	if err != nil {
+		t.Fatalf("MarshalGELF() error = %v", err)
+	}
+	var decoded gelflogger.Message
+	if err := decoded.UnmarshalGELF(encoded); err != nil {
+		t.Fatalf("UnmarshalGELF() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, msg) {
+		t.Errorf("UnmarshalGELF(MarshalGELF()) = %+v, want %+v", decoded, msg)
+	}
+}
@@ -0,0 +1,34 @@
+package gelflogger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WithFacility sets the _facility field of every message, e.g. to the name of the application or subsystem, like
+// the facility of the GELF libraries of other languages. It is a static field, see WithStaticFields.
+func WithFacility(facility string) Option {
+	return WithStaticFields(map[string]interface{}{"facility": facility})
+}
+
+// WithProcessMetadata adds the standard metadata of the process to every message, as the GELF libraries of other
+// languages do: _pid, the process ID, _go_version, the version of the Go runtime, and _executable, the file name of
+// the executable. They are static fields, see WithStaticFields.
+func WithProcessMetadata() Option {
+	return WithStaticFields(processMetadata())
+}
+
+// processMetadata returns the standard metadata of the process, see WithProcessMetadata.
+func processMetadata() map[string]interface{} {
+	metadata := map[string]interface{}{
+		"pid":        os.Getpid(),
+		"go_version": runtime.Version(),
+	}
+	if executable, err := os.Executable(); err == nil {
+		metadata["executable"] = filepath.Base(executable)
+	} else {
+		metadata["executable"] = filepath.Base(os.Args[0])
+	}
+	return metadata
+}
@@ -0,0 +1,64 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestMetadata(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts []gelflogger.Option
+		want map[string]interface{}
+	}{
+		{
+			name: "Facility",
+			opts: []gelflogger.Option{gelflogger.WithFacility("billing")},
+			want: map[string]interface{}{"_facility": "billing", "_pid": nil},
+		},
+		{
+			name: "Process metadata",
+			opts: []gelflogger.Option{gelflogger.WithProcessMetadata()},
+			want: map[string]interface{}{
+				"_pid":        float64(os.Getpid()),
+				"_go_version": runtime.Version(),
+				"_executable": filepath.Base(executable),
+				"_facility":   nil,
+			},
+		},
+		{
+			name: "Facility and process metadata",
+			opts: []gelflogger.Option{gelflogger.WithFacility("billing"), gelflogger.WithProcessMetadata()},
+			want: map[string]interface{}{"_facility": "billing", "_pid": float64(os.Getpid())},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			if err := logger.Log("metadata", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			for name, want := range tt.want {
+				if gelfMsg[name] != want {
+					t.Errorf("field %s = %v, want %v", name, gelfMsg[name], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gelflogger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// DestinationStats holds the counters of a single destination of a MirrorTransport.
+type DestinationStats struct {
+	// Sent is the number of messages sent to the destination.
+	Sent uint64
+	// Failed is the number of messages the destination failed to send.
+	Failed uint64
+	// Dropped is the number of messages dropped because the buffer of the destination was full.
+	Dropped uint64
+	// Buffered is the number of messages waiting in the buffer of the destination.
+	Buffered int
+}
+
+// MirrorTransport sends every message to all of its destinations, e.g. to the old and the new Graylog cluster
+// during a migration.
+//
+// Every destination has its own buffer and worker goroutine, so a slow or unreachable destination neither delays
+// nor affects the delivery to the others. If the buffer of a destination is full, the message is dropped for that
+// destination only. Sent, failed and dropped messages are counted per destination, see Stats.
+type MirrorTransport struct {
+	destinations []*mirrorDestination
+	lock         sync.RWMutex
+	closed       bool
+	workers      sync.WaitGroup
+}
+
+// mirrorDestination is a single destination of a MirrorTransport with its buffer and counters.
+type mirrorDestination struct {
+	transport Transport
+	buffer    chan []byte
+	sent      atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+var _ Transport = (*MirrorTransport)(nil)
+
+// NewMirrorTransport creates a MirrorTransport sending to the given destinations, buffering up to bufferSize
+// messages per destination.
+//
+// Example usage:
+//
+//	oldCluster, err := gelflogger.NewTCPTransport("graylog-old.example.com:12201", false, nil)
+//	...
+//	newCluster, err := gelflogger.NewTCPTransport("graylog-new.example.com:12201", true, tlsConfig)
+//	...
+//	logger := gelflogger.NewLoggerWithTransport(gelflogger.NewMirrorTransport(1000, oldCluster, newCluster), zerologger.ProcessZerologFields)
+func NewMirrorTransport(bufferSize int, destinations ...Transport) *MirrorTransport {
+	m := &MirrorTransport{destinations: make([]*mirrorDestination, 0, len(destinations))}
+	for _, transport := range destinations {
+		destination := &mirrorDestination{transport: transport, buffer: make(chan []byte, bufferSize)}
+		m.destinations = append(m.destinations, destination)
+		m.workers.Add(1)
+		go func() {
+			defer m.workers.Done()
+			for message := range destination.buffer {
+				if err := destination.transport.Send(message); err != nil {
+					destination.failed.Add(1)
+					continue
+				}
+				destination.sent.Add(1)
+			}
+		}()
+	}
+	return m
+}
+
+// Send adds the message to the buffers of all destinations. It only returns an error if no destination accepted
+// the message because all buffers were full, or the MirrorTransport is closed.
+func (m *MirrorTransport) Send(message []byte) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.closed {
+		return fmt.Errorf("mirror transport is %w", ErrClosed)
+	}
+	accepted := false
+	for _, destination := range m.destinations {
+		select {
+		case destination.buffer <- message:
+			accepted = true
+		default:
+			destination.dropped.Add(1)
+		}
+	}
+	if !accepted && len(m.destinations) > 0 {
+		return fmt.Errorf("buffers of all %d mirror destinations are full", len(m.destinations))
+	}
+	return nil
+}
+
+// Stats returns the counters of the destinations, in the order they were passed to NewMirrorTransport.
+func (m *MirrorTransport) Stats() []DestinationStats {
+	stats := make([]DestinationStats, 0, len(m.destinations))
+	for _, destination := range m.destinations {
+		stats = append(stats, DestinationStats{
+			Sent:     destination.sent.Load(),
+			Failed:   destination.failed.Load(),
+			Dropped:  destination.dropped.Load(),
+			Buffered: len(destination.buffer),
+		})
+	}
+	return stats
+}
+
+// Close stops accepting messages, waits until the buffered messages were sent and closes all destinations.
+func (m *MirrorTransport) Close() error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return nil
+	}
+	m.closed = true
+	for _, destination := range m.destinations {
+		close(destination.buffer)
+	}
+	m.lock.Unlock()
+
+	m.workers.Wait()
+	errs := make([]error, 0, len(m.destinations))
+	for _, destination := range m.destinations {
+		errs = append(errs, destination.transport.Close())
+	}
+	return errors.Join(errs...)
+}
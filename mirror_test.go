@@ -0,0 +1,92 @@
+package gelflogger_test
+
+import (
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// blockingTransport signals every message it starts to send and blocks until it is released.
+type blockingTransport struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTransport) Send([]byte) error {
+	b.started <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func (b *blockingTransport) Close() error { return nil }
+
+func TestMirrorTransport(t *testing.T) {
+	healthy := &recordingTransport{}
+	down := &recordingTransport{down: true}
+	mirror := gelflogger.NewMirrorTransport(10, healthy, down)
+	logger := gelflogger.NewLoggerWithTransport(mirror, func(fields map[string]interface{}) (int, float64, []byte, error) {
+		return 6, 0, nil, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := logger.Log("mirrored", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	if err := mirror.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := []gelflogger.DestinationStats{{Sent: 3}, {Failed: 3}}
+	got := mirror.Stats()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Stats()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if len(healthy.messages) != 3 {
+		t.Errorf("healthy destination received %d messages, want 3", len(healthy.messages))
+	}
+	if err := mirror.Send([]byte("{}")); err == nil {
+		t.Error("Send() after Close() error = nil, want an error")
+	}
+}
+
+func TestMirrorTransportIndependentBuffers(t *testing.T) {
+	slow := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+	fast := &recordingTransport{}
+	mirror := gelflogger.NewMirrorTransport(1, slow, fast)
+
+	// The slow destination takes the first message and blocks, its buffer takes the second one and drops the third.
+	if err := mirror.Send([]byte("1")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	<-slow.started
+	for i, message := range []string{"2", "3"} {
+		// Wait for the fast destination to take the previous message, so only the buffer of the slow one is full
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+			fast.lock.Lock()
+			taken := len(fast.messages)
+			fast.lock.Unlock()
+			if taken == i+1 || time.Now().After(deadline) {
+				break
+			}
+		}
+		if err := mirror.Send([]byte(message)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	close(slow.release)
+	if err := mirror.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	stats := mirror.Stats()
+	if stats[0].Sent != 2 || stats[0].Dropped != 1 {
+		t.Errorf("slow destination stats = %+v, want 2 sent and 1 dropped", stats[0])
+	}
+	if stats[1].Sent != 3 {
+		t.Errorf("fast destination stats = %+v, want 3 sent", stats[1])
+	}
+}
@@ -0,0 +1,20 @@
+package gelflogger
+
+import "strings"
+
+// splitMultiLine reduces a multi-line short message, e.g. a panic, an SQL statement or a stack trace, to its first
+// non-blank line and puts the complete text in front of the full message, see WithMultiLineShortMessages. Single-line
+// short messages are left as they are.
+func splitMultiLine(msg *Message) {
+	text := strings.TrimSpace(msg.ShortMessage)
+	firstLine, _, multiLine := strings.Cut(text, "\n")
+	if !multiLine {
+		return
+	}
+	msg.ShortMessage = strings.TrimSpace(firstLine)
+	if msg.FullMessage != "" {
+		msg.FullMessage = text + "\n\n" + msg.FullMessage
+	} else {
+		msg.FullMessage = text
+	}
+}
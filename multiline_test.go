@@ -0,0 +1,79 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestMultiLineShortMessages(t *testing.T) {
+	const panicMessage = "panic: runtime error: index out of range [3] with length 3\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:8 +0x1d"
+
+	tests := []struct {
+		name             string
+		opts             []gelflogger.Option
+		processor        func(fields map[string]interface{}) (int, float64, []byte, error)
+		message          string
+		wantShortMessage string
+		wantFullMessage  string
+	}{
+		{name: "Single line", processor: processNothing, message: "order placed", wantShortMessage: "order placed"},
+		{
+			name:             "Stack trace",
+			processor:        processNothing,
+			message:          panicMessage,
+			wantShortMessage: "panic: runtime error: index out of range [3] with length 3",
+			wantFullMessage:  panicMessage,
+		},
+		{
+			name:             "Leading blank lines and CRLF",
+			processor:        processNothing,
+			message:          "\r\n  SELECT *\r\n  FROM orders\r\n",
+			wantShortMessage: "SELECT *",
+			wantFullMessage:  "SELECT *\r\n  FROM orders",
+		},
+		{
+			name:             "Complete text in front of the full message",
+			processor:        gelflogger.ProcessFields,
+			message:          "first\nsecond",
+			wantShortMessage: "first",
+			wantFullMessage:  "first\nsecond\n\n{}",
+		},
+		{
+			name:             "Split before truncated",
+			opts:             []gelflogger.Option{gelflogger.WithShortMessageLimit(8)},
+			processor:        processNothing,
+			message:          "0123456789\nsecond",
+			wantShortMessage: "01234…",
+			wantFullMessage:  "0123456789\nsecond",
+		},
+		{
+			name:             "Kept",
+			opts:             []gelflogger.Option{gelflogger.WithMultiLineShortMessages()},
+			processor:        processNothing,
+			message:          panicMessage,
+			wantShortMessage: panicMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, tt.processor, tt.opts...)
+			if err := logger.Log(tt.message, map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			if gelfMsg["short_message"] != tt.wantShortMessage {
+				t.Errorf("short_message = %q, want %q", gelfMsg["short_message"], tt.wantShortMessage)
+			}
+			if fullMessage, _ := gelfMsg["full_message"].(string); fullMessage != tt.wantFullMessage {
+				t.Errorf("full_message = %q, want %q", fullMessage, tt.wantFullMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,723 @@
+package gelflogger
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"text/template"
+	"time"
+)
+
+const (
+	// DefaultFailbackInterval is the interval in which the primary address is re-checked after a failover.
+	DefaultFailbackInterval = 30 * time.Second
+	// DefaultTLSHandshakeTimeout is the maximum duration of the TLS handshake performed when connecting.
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	// DefaultConnectionAttemptDelay is the delay after which the next IP address of a Graylog host is dialed
+	// concurrently, as recommended by RFC 8305.
+	DefaultConnectionAttemptDelay = 250 * time.Millisecond
+	// DefaultTimeout is the maximum duration of establishing a connection, and of a request of the HTTP transport.
+	DefaultTimeout = 5 * time.Second
+	// DefaultWriteTimeout is the maximum duration of a write to the TCP connection before the watchdog aborts it.
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+// Option configures optional behaviour of a Logger created by NewLogger.
+type Option func(*config)
+
+// config holds the settings which can be changed by the Options passed to NewLogger.
+type config struct {
+	useTLS                 bool
+	tlsConfig              *tls.Config
+	processor              func(fields map[string]interface{}) (int, float64, []byte, error)
+	hostname               string
+	hostnameRefresh        time.Duration
+	timeout                time.Duration
+	framing                FramingMode
+	compression            Compression
+	failoverAddresses      []string
+	failbackInterval       time.Duration
+	loadBalancing          LoadBalancing
+	resolver               *net.Resolver
+	rotateResolved         bool
+	poolSize               int
+	tlsMaterial            *tlsMaterial
+	tlsPolicy              tlsPolicy
+	strictTLS              bool
+	spkiPins               []string
+	tlsHandshakeTimeout    time.Duration
+	writeTimeout           time.Duration
+	fallback               Transport
+	spool                  *Spool
+	routes                 []Route
+	sampleRates            map[int]int
+	dedupWindow            time.Duration
+	dedupFields            []string
+	retryBudget            int
+	failFast               bool
+	retryBackoff           backoff
+	httpRetries            int
+	events                 *eventHub
+	idleTimeout            time.Duration
+	keepaliveInterval      time.Duration
+	heartbeatInterval      time.Duration
+	connectionAttemptDelay time.Duration
+	queueSize              int
+	workers                int
+	async                  bool
+	overflowPolicy         OverflowPolicy
+	queueMemoryLimit       int64
+	onDrop                 func(message []byte, reason error)
+	batching               bool
+	batchSize              int
+	flushInterval          time.Duration
+	reconnectBackoff       backoff
+	owned                  []io.Closer
+	staticFields           []staticField
+	buildInfo              BuildInfo
+	noBuildInfo            bool
+	processors             []Processor
+	contextExtractors      []ContextExtractor
+	fieldReader            func(fields map[string]interface{}) (int, float64, error)
+	customProcessor        bool
+	strictFieldNames       bool
+	strictFieldTypes       bool
+	validate               bool
+	flattenDepth           int
+	shortMessageLimit      int
+	keepMultiLine          bool
+	unsanitized            bool
+	fieldNaming            FieldNaming
+	fullMessageMode        FullMessageMode
+	fullMessageField       string
+	shortMessageTemplate   *template.Template
+	healthCheckInterval    time.Duration
+	breakerFailures        int
+	breakerOpenDuration    time.Duration
+	network                string // Set by the scheme of the address instead of an Option, see NewTransport
+}
+
+// newConfig returns the default configuration with the given Options applied.
+func newConfig(opts []Option) config {
+	cfg := config{
+		processor:              ProcessFields,
+		timeout:                DefaultTimeout,
+		framing:                FramingNullByte,
+		failbackInterval:       DefaultFailbackInterval,
+		tlsHandshakeTimeout:    DefaultTLSHandshakeTimeout,
+		writeTimeout:           DefaultWriteTimeout,
+		connectionAttemptDelay: DefaultConnectionAttemptDelay,
+		reconnectBackoff:       backoff{initial: DefaultReconnectBackoff, max: DefaultMaxReconnectBackoff},
+		healthCheckInterval:    DefaultHealthCheckInterval,
+		httpRetries:            DefaultHTTPRetries,
+		events:                 &eventHub{},
+		network:                "tcp",
+		buildInfo:              readBuildInfo(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.staticFields = cfg.staticFieldsWithBuildInfo()
+	return cfg
+}
+
+// WithTLS makes NewLogger connect over TLS using the given configuration, which may be nil to verify the server
+// certificate against the system roots. It has the same effect as the tls:// scheme of the address, and is combined
+// with the TLS Options, e.g. WithCAFile or WithClientCertificateFiles.
+//
+// Example usage:
+//
+//	logger, err := gelflogger.NewLogger("graylog.example.com:12201", gelflogger.WithTLS(&tls.Config{RootCAs: pool}))
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) {
+		c.useTLS = true
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithProcessor sets the function extracting the Graylog level, the timestamp and the full message from the fields of
+// every message, e.g. zerologger.ProcessZerologFields for messages written by zerolog. Defaults to ProcessFields.
+func WithProcessor(processor func(fields map[string]interface{}) (int, float64, []byte, error)) Option {
+	return func(c *config) {
+		c.processor = processor
+		c.customProcessor = true
+	}
+}
+
+// WithFieldReader sets the counterpart of the processor of WithProcessor, which extracts the Graylog level and the
+// timestamp from the fields of a message and removes the consumed fields like the processor, but leaves the full
+// message out, e.g. zerologger.ReadZerologFields along with zerologger.ProcessZerologFields. It is used instead of the
+// processor where the full message is not needed: for the JSON written to a GelfWriter, whose original bytes become
+// the full message, so every message is parsed once and never encoded again, and with FullMessageNone or
+// FullMessageFromField. Defaults to ReadFields unless WithProcessor is given.
+func WithFieldReader(reader func(fields map[string]interface{}) (int, float64, error)) Option {
+	return func(c *config) {
+		c.fieldReader = reader
+	}
+}
+
+// WithHostname sets the host field of the messages, e.g. to the name of the service or the pod. It defaults to the
+// value of the environment variable HostnameEnv, else to the hostname reported by the kernel.
+func WithHostname(hostname string) Option {
+	return func(c *config) {
+		c.hostname = hostname
+	}
+}
+
+// WithHostnameRefresh makes the Logger look up the hostname every interval, so containers whose hostname is set late
+// or renamed report the current one. The environment variable HostnameEnv is re-read as well. It has no effect if the
+// hostname is set by WithHostname. Zero, the default, looks up the hostname once, when the Logger is created.
+func WithHostnameRefresh(interval time.Duration) Option {
+	return func(c *config) {
+		c.hostnameRefresh = interval
+	}
+}
+
+// WithTimeout sets the maximum duration of establishing a connection to the Graylog server, and of a request of the
+// HTTP transport. The TLS handshake and the writes are limited separately, see WithTLSHandshakeTimeout and
+// WithWriteTimeout. Zero disables the timeout. Defaults to DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithCompression compresses the messages sent by the UDP and HTTP transports, which Graylog decompresses
+// transparently. Compression reduces the bandwidth, and the number of UDP chunks of large messages, at the cost of CPU
+// time. It has no effect on TCP, as the GELF TCP input does not support compressed messages. Defaults to
+// CompressionNone.
+func WithCompression(compression Compression) Option {
+	return func(c *config) {
+		c.compression = compression
+	}
+}
+
+// WithFramingMode sets the delimiter appended to every message sent over TCP, see FramingMode.
+func WithFramingMode(mode FramingMode) Option {
+	return func(c *config) {
+		c.framing = mode
+	}
+}
+
+// WithFailoverAddresses adds Graylog addresses the Logger fails over to, in the given order, when the address passed
+// to NewLogger (the primary) is unreachable. While connected to a failover address, the primary is re-checked
+// every failback interval and the Logger switches back as soon as it is reachable again.
+func WithFailoverAddresses(addresses ...string) Option {
+	return func(c *config) {
+		c.failoverAddresses = append(c.failoverAddresses, addresses...)
+	}
+}
+
+// WithFailbackInterval sets the interval in which the primary address is re-checked in the background after a
+// failover, see WithFailoverAddresses. An interval of zero disables the failback, the Logger stays on the failover address
+// until it becomes unreachable. Defaults to DefaultFailbackInterval.
+func WithFailbackInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.failbackInterval = interval
+	}
+}
+
+// WithLoadBalancing distributes the messages across the address passed to NewLogger and the addresses added with
+// WithFailoverAddresses according to the given strategy, keeping a connection to each of them. If the send to the
+// selected address fails, the message is sent to the next one. Defaults to NoLoadBalancing.
+func WithLoadBalancing(strategy LoadBalancing) Option {
+	return func(c *config) {
+		c.loadBalancing = strategy
+	}
+}
+
+// WithResolver sets the resolver used to look up the IP addresses of the Graylog hosts. The hosts are resolved again
+// on every reconnect, so the Logger follows DNS changes. Defaults to net.DefaultResolver.
+func WithResolver(resolver *net.Resolver) Option {
+	return func(c *config) {
+		c.resolver = resolver
+	}
+}
+
+// WithResolvedAddressRotation makes every reconnect start with the next of the IP addresses (A/AAAA records) a Graylog
+// host resolves to, instead of always preferring the first one, to spread the connections across all of them.
+func WithResolvedAddressRotation() Option {
+	return func(c *config) {
+		c.rotateResolved = true
+	}
+}
+
+// WithConnectionAttemptDelay sets the delay after which the next IP address of a Graylog host resolving to several
+// addresses is dialed, while the previous attempts are still pending (Happy Eyeballs, RFC 8305). The IPv6 and IPv4
+// addresses of dual-stack hosts are dialed alternately, so a broken route of one family only delays the connection by
+// the delay. A delay of zero dials the addresses one after another, each until the dial timeout.
+// Defaults to DefaultConnectionAttemptDelay.
+func WithConnectionAttemptDelay(delay time.Duration) Option {
+	return func(c *config) {
+		c.connectionAttemptDelay = delay
+	}
+}
+
+// WithReconnectBackoff sets the delays between the attempts to re-establish a lost TCP connection, which are made in
+// the background while Log reports the messages as failed, or hands them to the fallback, right away. The first
+// attempt is made immediately, then the delay starts at initial and doubles with every failed attempt up to max. Every
+// delay is randomized by up to 20%, so many clients do not reconnect to a recovering Graylog server in lockstep.
+// Defaults to DefaultReconnectBackoff and DefaultMaxReconnectBackoff.
+func WithReconnectBackoff(initial, max time.Duration) Option {
+	return func(c *config) {
+		c.reconnectBackoff = backoff{initial: initial, max: max}
+	}
+}
+
+// WithHealthCheckInterval sets the interval in which a background supervisor probes the TCP connection, and
+// re-establishes it before the next message is sent if the Graylog server closed it. An interval of zero disables
+// the probes. Defaults to DefaultHealthCheckInterval.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.healthCheckInterval = interval
+	}
+}
+
+// WithWriteTimeout sets the maximum duration of a write to the TCP connection. A watchdog aborts writes blocked for
+// longer, e.g. because Graylog accepted the connection but stopped reading from it, tears down the connection and
+// reconnects in the background, so the message fails with ErrWriteTimeout and is retried or handed to the fallback
+// instead of blocking the caller forever. A timeout of zero disables the watchdog. Defaults to DefaultWriteTimeout.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.writeTimeout = timeout
+	}
+}
+
+// WithCircuitBreaker opens a circuit breaker around the transport of the Logger after the given number of consecutive
+// send failures. While it is open, Log does not use the transport: the messages are handed to the fallback or spool,
+// if configured, or fail with ErrCircuitOpen right away, so a failing Graylog server does not slow down the
+// application. After openDuration, a single message probes the transport, closing the breaker if it is sent and
+// opening it for another openDuration otherwise. A maxFailures of zero or less disables the circuit breaker.
+func WithCircuitBreaker(maxFailures int, openDuration time.Duration) Option {
+	return func(c *config) {
+		c.breakerFailures = maxFailures
+		c.breakerOpenDuration = openDuration
+	}
+}
+
+// WithConnectionPool makes the Logger keep size connections and distribute the messages across them, so a single
+// connection does not become the bottleneck of high-throughput services. Every connection of the pool connects and
+// fails over like a single connection would, failed connections are skipped and replaced. Sizes below 2 disable
+// the pool.
+func WithConnectionPool(size int) Option {
+	return func(c *config) {
+		c.poolSize = size
+	}
+}
+
+// WithClientCertificateFiles sets the PEM encoded certificate and private key files the Logger authenticates itself
+// with to the Graylog server (mutual TLS). The files are reloaded on the next connect after they changed, so
+// rotated certificates are picked up without restarting. Setting a client certificate enables TLS.
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	return func(c *config) {
+		c.mutualTLS().certFile, c.mutualTLS().keyFile = certFile, keyFile
+	}
+}
+
+// WithClientCertificatePEM sets the PEM encoded certificate and private key the Logger authenticates itself with to
+// the Graylog server (mutual TLS). Setting a client certificate enables TLS.
+func WithClientCertificatePEM(certPEM, keyPEM []byte) Option {
+	return func(c *config) {
+		c.mutualTLS().certPEM, c.mutualTLS().keyPEM = certPEM, keyPEM
+	}
+}
+
+// WithCAFile sets the PEM encoded CA bundle file used to verify the certificate of the Graylog server instead of the
+// system roots. The file is reloaded on the next connect after it changed. Setting a CA bundle enables TLS.
+func WithCAFile(caFile string) Option {
+	return func(c *config) {
+		c.mutualTLS().caFile = caFile
+	}
+}
+
+// WithCAPEM sets the PEM encoded CA bundle used to verify the certificate of the Graylog server instead of the
+// system roots. Setting a CA bundle enables TLS.
+func WithCAPEM(caPEM []byte) Option {
+	return func(c *config) {
+		c.mutualTLS().caPEM = caPEM
+	}
+}
+
+// WithTLSMinVersion enforces the given minimum TLS version, e.g. tls.VersionTLS12, on every connection, regardless
+// of the MinVersion of the supplied TLS configuration. A higher MinVersion of the supplied configuration is kept.
+func WithTLSMinVersion(version uint16) Option {
+	return func(c *config) {
+		c.tlsPolicy.minVersion = version
+	}
+}
+
+// WithCipherSuites enforces the given cipher suites on every connection, replacing the CipherSuites of the supplied
+// TLS configuration. As Go does not allow configuring the TLS 1.3 cipher suites, they only restrict TLS 1.0-1.2.
+func WithCipherSuites(suites ...uint16) Option {
+	return func(c *config) {
+		c.tlsPolicy.cipherSuites = suites
+	}
+}
+
+// WithStrictTLS makes NewLogger refuse TLS configurations with InsecureSkipVerify set, so the certificate of the
+// Graylog server is always verified.
+func WithStrictTLS() Option {
+	return func(c *config) {
+		c.strictTLS = true
+	}
+}
+
+// WithPinnedSPKI pins the public key of the Graylog server by the SHA-256 fingerprints of its SubjectPublicKeyInfo,
+// base64 encoded (like "pin-sha256" values) or hex encoded. The connection is only accepted if the server presents a
+// certificate with one of the pinned public keys, which replaces the verification against a CA pool and of the host
+// name. Pinning enables TLS. The fingerprint of a certificate can be computed with:
+//
+//	openssl x509 -in server.crt -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func WithPinnedSPKI(fingerprints ...string) Option {
+	return func(c *config) {
+		c.spkiPins = append(c.spkiPins, fingerprints...)
+	}
+}
+
+// WithTLSHandshakeTimeout sets the maximum duration of the TLS handshake, which is performed right after connecting,
+// so NewLogger already fails on certificate or protocol errors. Defaults to DefaultTLSHandshakeTimeout.
+func WithTLSHandshakeTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.tlsHandshakeTimeout = timeout
+	}
+}
+
+// mutualTLS returns the tlsMaterial of the config, creating it on first use.
+func (c *config) mutualTLS() *tlsMaterial {
+	if c.tlsMaterial == nil {
+		c.tlsMaterial = &tlsMaterial{}
+	}
+	return c.tlsMaterial
+}
+
+// WithFallback sets a Transport receiving the messages which could not be sent to the Graylog server, e.g. a
+// FileFallback keeping them on disk during outages. Messages taken by the fallback are not reported as errors by Log.
+func WithFallback(fallback Transport) Option {
+	return func(c *config) {
+		c.fallback = fallback
+	}
+}
+
+// WithSpool sets a Spool persisting the messages which could not be sent to the Graylog server, or did not fit into
+// the queue of an asynchronous Logger (see WithAsync). The spooled messages are replayed in order in the background
+// once the Graylog server is reachable again, and new messages are spooled while the replay is pending, so they do not
+// overtake the spooled ones. If the spool fails, the message is handed to the fallback, if configured.
+func WithSpool(spool *Spool) Option {
+	return func(c *config) {
+		c.spool = spool
+	}
+}
+
+// WithRoutes routes the messages matching one of the Routes through the transport of the first matching Route,
+// or drops them if that Route has no transport. Messages matching no Route are sent through the transport of the
+// Logger. For example, errors go to a dedicated input while debug messages are dropped:
+//
+//	gelflogger.WithRoutes(
+//		gelflogger.Route{Match: gelflogger.LevelAtMost(3), Transport: errorInput},
+//		gelflogger.Route{Match: gelflogger.LevelAbove(6)},
+//	)
+func WithRoutes(routes ...Route) Option {
+	return func(c *config) {
+		c.routes = append(c.routes, routes...)
+	}
+}
+
+// WithSampling keeps only 1 in N messages of the given Graylog (Syslog) levels, e.g. map[int]int{7: 100} keeps one
+// in a hundred debug messages and all messages of other levels. Messages are sampled before they are encoded, so the
+// sampled out ones cost next to nothing. They are not counted as dropped. The kept messages of a sampled level carry
+// the additional fields _sampled and _sample_rate, so Graylog dashboards can extrapolate the actual counts.
+func WithSampling(rates map[int]int) Option {
+	return func(c *config) {
+		c.sampleRates = rates
+	}
+}
+
+// WithDeduplication suppresses identical consecutive messages logged within the window after the first one, like
+// classic syslog daemons do. Messages are identical if their short message, their level and the given fields are
+// equal. The first message is sent, its repetitions are replaced by a single "message repeated N times" record with
+// the number of repetitions in the additional field _repeat_count, sent once a different message is logged, the
+// window elapses or the Logger is closed.
+func WithDeduplication(window time.Duration, fields ...string) Option {
+	return func(c *config) {
+		c.dedupWindow = window
+		c.dedupFields = fields
+	}
+}
+
+// WithFailFast makes the Logger report a lost connection right away instead of recovering from it, e.g. for CLIs and
+// batch jobs which rather exit with an error: the connection is not re-established in the background, and sends are
+// not retried, even with WithRetry. The send which lost the connection returns the original network error, the
+// following sends ErrNotConnected wrapping it. Only the TCP based transports are affected.
+func WithFailFast() Option {
+	return func(c *config) {
+		c.failFast = true
+	}
+}
+
+// WithRetry retries sends which failed temporarily up to budget times per message, see ErrTemporary, waiting an
+// exponentially growing delay starting at initialDelay between the tries, at most DefaultMaxRetryDelay. Permanent
+// failures are not retried. Only once the budget is exhausted, the message is handed to the fallback, if configured.
+// Synchronous Loggers retry within Log, so the log call is blocked meanwhile. An initialDelay of zero or less selects
+// DefaultRetryDelay.
+func WithRetry(budget int, initialDelay time.Duration) Option {
+	return func(c *config) {
+		if initialDelay <= 0 {
+			initialDelay = DefaultRetryDelay
+		}
+		c.retryBudget = budget
+		c.retryBackoff = backoff{initial: initialDelay, max: DefaultMaxRetryDelay}
+	}
+}
+
+// WithHTTPRetries sets how often the HTTP transport retries a message which Graylog did not accept with 202 Accepted
+// due to a temporary failure, e.g. a network error or a 503 response, with exponential backoff between the tries.
+// It defaults to DefaultHTTPRetries, zero disables the retries. The retries happen within the transport, before the
+// retries configured with WithRetry.
+func WithHTTPRetries(retries int) Option {
+	return func(c *config) {
+		c.httpRetries = retries
+	}
+}
+
+// WithIdleTimeout makes the TCP transport replace its connection by a new one once nothing was written to it for the
+// given period, so connections silently dropped by firewalls after an idle period are never used. The new connection
+// is established before the idle one is closed. Zero, the default, keeps idle connections.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.idleTimeout = timeout
+	}
+}
+
+// WithKeepalive makes the TCP transport write an empty frame, only the delimiter of the FramingMode, once nothing was
+// written to the connection for the given interval, so firewalls see traffic on idle connections. Graylog skips empty
+// frames. Zero, the default, disables the keepalive frames.
+func WithKeepalive(interval time.Duration) Option {
+	return func(c *config) {
+		c.keepaliveInterval = interval
+	}
+}
+
+// WithHeartbeat makes the Logger send a heartbeat message every interval, e.g. every minute, whose short_message is
+// HeartbeatMessage and whose additional fields carry the Stats of the Logger: _heartbeat, _messages_sent,
+// _bytes_sent, _send_errors, _reconnects, _queue_depth and _dropped. An alert on missing heartbeats in Graylog
+// detects a broken pipeline, which the Logger cannot report itself. Zero, the default, disables the heartbeat.
+func WithHeartbeat(interval time.Duration) Option {
+	return func(c *config) {
+		c.heartbeatInterval = interval
+	}
+}
+
+// WithStaticFields attaches the given fields, e.g. service, environment and region, as additional fields to every
+// message of the Logger, so the call sites do not have to repeat them. Like the fields of the log calls, the names are
+// prefixed with an underscore. The fields are encoded once, when the Logger is created, so later changes of the map
+// have no effect. Fields of the same name passed to a log call take precedence. As the static fields are only added
+// to the encoded message, they are not seen by Routes. Repeated Options add to the fields, replacing the ones of the same name.
+func WithStaticFields(fields map[string]interface{}) Option {
+	static := newStaticFields(fields)
+	return func(c *config) {
+		c.staticFields = mergeStaticFields(c.staticFields, static)
+	}
+}
+
+// WithProcessorChain appends the given Processors to the chain run on every message of a log call before it is
+// encoded, in the given order, after the processor of the fields, see WithProcessor, and before sampling,
+// deduplication and routing. It is the extension point for redacting, enriching, filtering and renaming fields.
+// Messages sent by the Logger itself, e.g. heartbeats, are not processed.
+//
+// Example usage:
+//
+//	dropHealthChecks := gelflogger.ProcessorFunc(func(msg *gelflogger.Message) error {
+//		if msg.Additional["path"] == "/healthz" {
+//			return gelflogger.ErrSkipMessage
+//		}
+//		return nil
+//	})
+//	logger, err := gelflogger.NewLogger(address, gelflogger.WithProcessorChain(dropHealthChecks))
+func WithProcessorChain(processors ...Processor) Option {
+	return func(c *config) {
+		c.processors = append(c.processors, processors...)
+	}
+}
+
+// WithStrictFieldNames makes the log calls reject messages with fields whose names are not allowed by the GELF
+// specification, i.e. names with other characters than letters, digits, underscores, dots and dashes, and the field
+// id, as _id is reserved by Graylog. The error wraps ErrInvalidFieldName and ErrPermanent. By default, the invalid
+// characters are replaced by underscores and id is renamed to _id_, so Graylog does not drop or mangle the message.
+func WithStrictFieldNames() Option {
+	return func(c *config) {
+		c.strictFieldNames = true
+	}
+}
+
+// WithStrictFieldTypes makes the log calls reject messages with field values which cannot be coerced to strings or
+// numbers, the only types GELF permits, e.g. maps, slices and structs, unless they are flattened, see WithFlattening.
+// The error wraps ErrUnsupportedFieldType and ErrPermanent. By default, booleans, time.Time, errors and fmt.Stringer
+// are coerced to strings, nil values are omitted, and values of other types are sent as their JSON encoding.
+func WithStrictFieldTypes() Option {
+	return func(c *config) {
+		c.strictFieldTypes = true
+	}
+}
+
+// WithValidation makes the log calls check every encoded message against the GELF specification before it is sent,
+// see Validate, and reject violating messages with a ValidationError wrapping ErrInvalidMessage and ErrPermanent,
+// e.g. to catch invalid messages in tests or CI. The encoding is checked after the field names were sanitized and the
+// values coerced, so only messages Graylog would still reject or mangle fail, e.g. oversized ones.
+func WithValidation() Option {
+	return func(c *config) {
+		c.validate = true
+	}
+}
+
+// WithFlattening flattens the nested maps, slices and structs of the fields into fields named in dot notation, so
+// the nested context becomes searchable in Graylog, which only supports strings and numbers as field values: the field
+// user holding {"address": {"city": "Berlin"}} is sent as _user.address.city, and the elements of slices are named
+// by their index, e.g. _tags.0. Structures nested deeper than maxDepth levels, and empty ones, are encoded as JSON
+// string instead, e.g. _user.address holding {"city":"Berlin"} for a maxDepth of one. Structs are flattened by their
+// JSON encoding. Zero, the default, disables flattening, nested values are then sent as JSON string, which
+// Graylog does not index by its parts.
+func WithFlattening(maxDepth int) Option {
+	return func(c *config) {
+		c.flattenDepth = maxDepth
+	}
+}
+
+// WithShortMessageLimit truncates short messages longer than limit bytes, which Graylog displays awkwardly, at a
+// rune boundary and marks them with an ellipsis. The complete short message is put in front of the full message, so
+// it is not lost. Zero, the default, does not limit the short messages.
+func WithShortMessageLimit(limit int) Option {
+	return func(c *config) {
+		c.shortMessageLimit = limit
+	}
+}
+
+// WithMultiLineShortMessages sends multi-line short messages as they are. By default, a short message spanning
+// several lines, e.g. a panic, an SQL statement or a stack trace, is reduced to its first non-blank line, which
+// Graylog displays well in its message lists, and the complete text is put in front of the full message, so it is not
+// lost. Multi-line messages are split before they are truncated, see WithShortMessageLimit.
+func WithMultiLineShortMessages() Option {
+	return func(c *config) {
+		c.keepMultiLine = true
+	}
+}
+
+// WithUnsanitizedStrings sends the short messages, full messages and string field values as they are. By default, they
+// are sanitized while encoding, as Graylog rejects or garbles some of them: control characters other than tabs, line
+// feeds and carriage returns are stripped, and every run of invalid UTF-8 bytes is replaced by a single replacement
+// character (U+FFFD). Without sanitizing, control characters are escaped, and every invalid byte is still replaced, as
+// JSON must be valid UTF-8.
+func WithUnsanitizedStrings() Option {
+	return func(c *config) {
+		c.unsanitized = true
+	}
+}
+
+// WithFieldNaming renames well-known additional fields, e.g. service or trace_id, to the names of a common schema, so
+// the fields are named consistently across sources, see FieldNamingECS and FieldNamingOTel. The static fields are renamed as well. The
+// Processors of WithProcessorChain see the fields by the names they were logged with. FieldNamingGELF, the default,
+// keeps the names.
+func WithFieldNaming(naming FieldNaming) Option {
+	return func(c *config) {
+		c.fieldNaming = naming
+	}
+}
+
+// WithShortMessageTemplate renders the short message of log records without message from their fields, e.g. of
+// structured access logs, with the template, executed on the map of the fields:
+//
+//	gelflogger.WithShortMessageTemplate(template.Must(template.New("access").Parse("{{.method}} {{.path}} -> {{.status}}")))
+//
+// The fields include "level" and "time", as the template is rendered before they are read. Missing fields are
+// rendered as "<no value>", unless the template sets the missingkey option. Records with a message keep it. If the
+// template fails, the record is dropped with a permanent error, like a record which cannot be encoded.
+func WithShortMessageTemplate(tmpl *template.Template) Option {
+	return func(c *config) {
+		c.shortMessageTemplate = tmpl
+	}
+}
+
+// WithFullMessage selects the content of the full_message of the GELF messages: FullMessageFromProcessor, the
+// default, keeps the full message of the processor of the fields, FullMessageNone sends none. Empty full messages are
+// omitted. See WithFullMessageField for FullMessageFromField.
+func WithFullMessage(mode FullMessageMode) Option {
+	return func(c *config) {
+		c.fullMessageMode = mode
+	}
+}
+
+// WithFullMessageField uses the value of the named field, e.g. "stack" or "body", as full message instead of the one
+// of the processor of the fields, see FullMessageFromField. The field is not sent as additional field. Messages
+// without the field have no full message.
+func WithFullMessageField(name string) Option {
+	return func(c *config) {
+		c.fullMessageMode = FullMessageFromField
+		c.fullMessageField = name
+	}
+}
+
+// WithAsync makes Log enqueue the messages into a bounded in-memory queue of queueSize messages instead of sending
+// them itself, so the latency of Graylog is kept out of the log calls. The given number of workers drain the queue
+// in the background. Log blocks while the queue is full. As Log returns before the message is sent, send errors are
+// not reported to the caller, messages which cannot be sent are only kept if a fallback is configured, see
+// WithFallback. A queueSize or workers of zero or less selects DefaultQueueSize or DefaultWorkers.
+func WithAsync(queueSize, workers int) Option {
+	return func(c *config) {
+		c.async = true
+		c.queueSize = queueSize
+		c.workers = workers
+	}
+}
+
+// WithOverflowPolicy selects what Log does with a message when the queue of an asynchronous Logger is full, see
+// WithAsync: block until there is room (OverflowBlock, the default), drop the message (OverflowDropNewest) or drop the
+// oldest queued message (OverflowDropOldest). Dropped messages are counted, see Logger.Dropped, and reported to the
+// OnDrop callback with ErrQueueFull as reason. Log returns ErrQueueFull if the message being logged was dropped. Messages for the transport of
+// a Logger with a spool are spooled instead, see WithSpool.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(c *config) {
+		c.overflowPolicy = policy
+	}
+}
+
+// WithQueueMemoryLimit limits the memory held by the messages in the queue of an asynchronous Logger to the given
+// number of bytes, in addition to the number of messages given to WithAsync, so large messages cannot exhaust the
+// memory during an outage. Once the limit would be exceeded, the queue is considered full and the OverflowPolicy
+// applies, see WithOverflowPolicy. A single message larger than the limit is only queued when the queue is empty.
+// Zero, the default, disables the limit.
+func WithQueueMemoryLimit(bytes int64) Option {
+	return func(c *config) {
+		c.queueMemoryLimit = bytes
+	}
+}
+
+// WithOnDrop sets a callback receiving every message the Logger drops, together with the reason: ErrQueueFull,
+// or an error wrapping ErrEncode or ErrSendFailed, so the loss of log messages can be alerted on. The message is
+// nil if it was dropped before it could be encoded. The callback is called synchronously, it must not block or log
+// through the same Logger.
+func WithOnDrop(onDrop func(message []byte, reason error)) Option {
+	return func(c *config) {
+		c.onDrop = onDrop
+	}
+}
+
+// WithBatching makes the Logger send its messages in batches of up to batchSize messages, each sent once it is full
+// or flushInterval elapsed since its first message was logged. The TCP transport writes a batch with a single syscall,
+// the messages separated by the delimiter of the FramingMode, the HTTP transport posts a batch as JSON array, other
+// transports send the messages of a batch one after another. Batching implies the asynchronous mode, see WithAsync,
+// which configures the queue and the number of workers, each collecting its own batches. A batchSize or
+// flushInterval of zero or less selects DefaultBatchSize or DefaultFlushInterval.
+func WithBatching(batchSize int, flushInterval time.Duration) Option {
+	return func(c *config) {
+		c.batching = true
+		c.batchSize = batchSize
+		c.flushInterval = flushInterval
+	}
+}
+
+// withOwned hands the given resources, e.g. the spool and the fallbacks created by NewLoggerFromConfig, over to the
+// Logger, which closes them in Close after its transport.
+func withOwned(closers ...io.Closer) Option {
+	return func(c *config) {
+		c.owned = append(c.owned, closers...)
+	}
+}
@@ -0,0 +1,154 @@
+package gelflogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// The fields of the messages of recovered panics, see CapturePanic.
+const (
+	// PanicField is the field of the value the panic was raised with, as text.
+	PanicField = "panic"
+	// GoroutinesField is the field of the stack traces of all goroutines at the time of the panic, truncated to
+	// maxGoroutineDump bytes.
+	GoroutinesField = "goroutines"
+)
+
+// maxGoroutineDump is the maximum size of the GoroutinesField in bytes, so the dump of a process with many goroutines
+// still fits into a message Graylog accepts.
+const maxGoroutineDump = 64 << 10
+
+// PanicOption configures the handling of panics by CapturePanic and RecoverMiddleware.
+type PanicOption func(*panicConfig)
+
+// panicConfig holds the settings of the handling of panics.
+//
+// - repanic: Whether the panic is raised again once it is logged, see WithRepanic.
+// - fields: The additional fields of the panic messages, see WithPanicFields.
+type panicConfig struct {
+	repanic bool
+	fields  map[string]interface{}
+}
+
+// newPanicConfig returns the panicConfig of the given PanicOptions.
+func newPanicConfig(opts []PanicOption) panicConfig {
+	var cfg panicConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithRepanic raises the recovered panic again once it is logged, so the process still crashes, e.g. to be restarted
+// by its supervisor, or the panic reaches another handler. The panic is raised again with its original value, but the
+// stack trace printed by the runtime starts at the handler then, the original one is in the logged message.
+func WithRepanic() PanicOption {
+	return func(c *panicConfig) {
+		c.repanic = true
+	}
+}
+
+// WithPanicFields adds the given fields to the messages of the panics, e.g. the name of the goroutine or job.
+func WithPanicFields(fields map[string]interface{}) PanicOption {
+	return func(c *panicConfig) {
+		c.fields = fields
+	}
+}
+
+// CapturePanic recovers a panic of the calling goroutine and logs it, as it is the moment the most important messages
+// are usually lost. It must be deferred directly, as recover only stops a panic if called by the deferred function:
+//
+//	func worker(logger *gelflogger.Logger) {
+//		defer gelflogger.CapturePanic(logger, gelflogger.WithPanicFields(map[string]interface{}{"job": "billing"}))
+//		...
+//	}
+//
+// The panic is logged at the level Alert as message like `panic: index out of range`, with the stack trace of the
+// goroutine as full message, the value of the panic as PanicField and the stack traces of all goroutines as
+// GoroutinesField. The message is sent right away, even if the Logger is asynchronous, see Logger.LogAtNow, and the
+// messages queued before are flushed, waiting at most DefaultCloseTimeout, so they are not lost if the process ends.
+// The panic is stopped unless WithRepanic is used. Without a panic, CapturePanic does nothing.
+func CapturePanic(logger *Logger, opts ...PanicOption) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	cfg := newPanicConfig(opts)
+	logger.logPanic(context.Background(), value, cfg.fields)
+	if cfg.repanic {
+		panic(value)
+	}
+}
+
+// RecoverMiddleware returns an HTTP middleware recovering the panics of the handlers it wraps, logging them like
+// CapturePanic with the method and path of the request, and the fields of its context, see WithContextExtractor.
+// Unless WithRepanic is used, the client receives the status 500 Internal Server Error, if the handler has not written
+// the header yet. http.ErrAbortHandler, which handlers panic with to abort a response, is raised again without being
+// logged, so net/http handles it as usual.
+//
+// Example usage:
+//
+//	http.ListenAndServe(":8080", gelflogger.RecoverMiddleware(logger)(mux))
+func RecoverMiddleware(logger *Logger, opts ...PanicOption) func(http.Handler) http.Handler {
+	cfg := newPanicConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				if err, ok := value.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(value)
+				}
+				fields := make(map[string]interface{}, len(cfg.fields)+2)
+				for name, value := range cfg.fields {
+					fields[name] = value
+				}
+				fields["http_method"] = r.Method
+				fields["http_path"] = r.URL.Path
+				logger.logPanic(r.Context(), value, fields)
+				if cfg.repanic {
+					panic(value)
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// logPanic logs the recovered panic with the given fields and the fields of the context, and flushes the Logger. It
+// must be called by the deferred function recovering the panic, so the stack trace shows where the panic was raised.
+// Errors of the Logger are dropped, as there is no caller to return them to; they are counted by Stats.
+func (l *Logger) logPanic(ctx context.Context, value interface{}, fields map[string]interface{}) {
+	panicFields := make(map[string]interface{}, len(fields)+2)
+	for name, value := range fields {
+		panicFields[name] = value
+	}
+	panicFields[PanicField] = fmt.Sprint(value)
+	panicFields[GoroutinesField] = goroutineDump()
+	message := fmt.Sprintf("panic: %v", value)
+	_ = l.logAtNow(Alert, time.Time{}, message, string(debug.Stack()), l.contextFields(ctx, panicFields))
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+	defer cancel()
+	_ = l.Flush(flushCtx)
+}
+
+// goroutineDump returns the stack traces of all goroutines, truncated to maxGoroutineDump bytes.
+func goroutineDump() string {
+	buf := make([]byte, 16<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDump {
+			return string(buf[:n])
+		}
+		buf = make([]byte, min(2*len(buf), maxGoroutineDump))
+	}
+}
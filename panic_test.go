@@ -0,0 +1,161 @@
+package gelflogger_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestCapturePanic(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []gelflogger.PanicOption
+		panicValue  interface{}
+		want        []string
+		wantRepanic bool
+	}{
+		{
+			name:       "Panic is logged and stopped",
+			panicValue: "boom",
+			want:       []string{`"short_message":"panic: boom"`, `"level":1`, `"_panic":"boom"`, `"_goroutines":"goroutine `, "panic_test.go"},
+		},
+		{
+			name:       "Error value with fields",
+			opts:       []gelflogger.PanicOption{gelflogger.WithPanicFields(map[string]interface{}{"job": "billing"})},
+			panicValue: fmt.Errorf("index %d out of range", 3),
+			want:       []string{`"short_message":"panic: index 3 out of range"`, `"_job":"billing"`},
+		},
+		{
+			name:        "Panic is raised again",
+			opts:        []gelflogger.PanicOption{gelflogger.WithRepanic()},
+			panicValue:  "boom",
+			want:        []string{`"short_message":"panic: boom"`},
+			wantRepanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, gelflogger.WithAsync(100, 1))
+			defer func() { _ = logger.Close(context.Background()) }()
+			if err := logger.Log("queued before", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+
+			repanicked := func() (repanicked bool) {
+				defer func() { repanicked = recover() != nil }()
+				func() {
+					defer gelflogger.CapturePanic(logger, tt.opts...)
+					panic(tt.panicValue)
+				}()
+				return false
+			}()
+			if repanicked != tt.wantRepanic {
+				t.Errorf("raised again = %v, want %v", repanicked, tt.wantRepanic)
+			}
+
+			transport.lock.Lock()
+			messages := append([]string(nil), transport.messages...)
+			transport.lock.Unlock()
+			if len(messages) != 2 {
+				t.Fatalf("sent %d messages without waiting, want the queued and the panic message", len(messages))
+			}
+			message := messages[0]
+			if strings.Contains(message, "queued before") {
+				message = messages[1]
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(message, want) {
+					t.Errorf("sent %s, want %s", message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCapturePanicWithoutPanic(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields)
+	func() {
+		defer gelflogger.CapturePanic(logger)
+	}()
+	if len(transport.messages) != 0 {
+		t.Errorf("sent %v, want no messages", transport.messages)
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		want       []string
+	}{
+		{
+			name:       "Panic is logged with the request",
+			handler:    func(http.ResponseWriter, *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			want:       []string{`"short_message":"panic: boom"`, `"_http_method":"POST"`, `"_http_path":"/orders"`, `"_request_id":"r-1"`},
+		},
+		{
+			name:       "No panic",
+			handler:    func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusCreated) },
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, gelflogger.WithContextExtractor(extractRequestID))
+			handler := gelflogger.RecoverMiddleware(logger)(tt.handler)
+
+			request := httptest.NewRequest(http.MethodPost, "/orders", nil)
+			request = request.WithContext(context.WithValue(request.Context(), requestIDKey{}, "r-1"))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.want == nil {
+				if len(transport.messages) != 0 {
+					t.Errorf("sent %v, want no messages", transport.messages)
+				}
+				return
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(transport.messages))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(transport.messages[0], want) {
+					t.Errorf("sent %s, want %s", transport.messages[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverMiddlewareAbortHandler(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields)
+	handler := gelflogger.RecoverMiddleware(logger)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if value := recover(); value != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", value)
+		}
+		if len(transport.messages) != 0 {
+			t.Errorf("sent %v, want no messages", transport.messages)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,114 @@
+package gelflogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// pinger is implemented by transports which can verify that they are currently able to deliver messages.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping verifies that the transport of the Logger is currently usable, e.g. for readiness probes of the application:
+// the TCP transport probes its connection, or dials the Graylog server if it is not connected, and the HTTP transport
+// sends a HEAD request to the GELF HTTP input. Transports which cannot be verified, e.g. UDP, are considered usable.
+// Once the Logger is closed, ErrLoggerClosed is returned.
+func (l *Logger) Ping(ctx context.Context) error {
+	l.closeLock.RLock()
+	defer l.closeLock.RUnlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+	return ping(ctx, l.transport)
+}
+
+// ping verifies that the transport is usable, if it supports the verification.
+func ping(ctx context.Context, transport Transport) error {
+	if p, ok := transport.(pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// Ping probes the connection of the transport, re-establishing it in the background if the probe fails. If it is not
+// connected, the addresses are dialed, and the first established connection becomes the connection of the transport.
+func (t *tcpTransport) Ping(ctx context.Context) error {
+	t.connLock.Lock()
+	connected, start := t.conn != nil, t.current
+	t.connLock.Unlock()
+	if connected {
+		return t.checkHealth()
+	}
+
+	conn, index, err := t.dialFrom(ctx, start)
+	if err != nil {
+		return err
+	}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	select {
+	case <-t.closed:
+		_ = conn.Close()
+		return fmt.Errorf("transport is %w", ErrClosed)
+	default:
+	}
+	if t.conn == nil {
+		t.connected(conn, index)
+	} else {
+		_ = conn.Close()
+	}
+	return nil
+}
+
+// Ping sends a HEAD request to the GELF HTTP input. Any response but a server error counts as usable, as the input
+// only accepts posted messages.
+func (t *httpTransport) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, t.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("GELF HTTP input at %s responded with %s", t.url, resp.Status)
+	}
+	return nil
+}
+
+// Ping verifies the connections of the pool, the pool is usable if one of them is.
+func (p *pooledTransport) Ping(ctx context.Context) error {
+	errs := make([]error, 0, len(p.members))
+	for _, member := range p.members {
+		err := ping(ctx, member.transport)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Ping verifies the connections to the addresses, the transport is usable if one of them is.
+func (b *balancedTransport) Ping(ctx context.Context) error {
+	errs := make([]error, 0, len(b.endpoints))
+	for _, endpoint := range b.endpoints {
+		err := endpoint.transport.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Ping verifies the wrapped transport regardless of the state of the breaker, so readiness probes see a recovered
+// Graylog server before the breaker closes.
+func (b *circuitBreaker) Ping(ctx context.Context) error {
+	return ping(ctx, b.transport)
+}
@@ -0,0 +1,76 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestPing(t *testing.T) {
+	tcpServer := helper.StartMockServer(t)
+	defer func() { _ = tcpServer.Close() }()
+	helper.ReceiveMessages(t, tcpServer, 0)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer httpServer.Close()
+	failingHTTPServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingHTTPServer.Close()
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "TCP", address: tcpServer.Addr().String()},
+		{name: "HTTP", address: httpServer.URL},
+		{name: "HTTP server error", address: failingHTTPServer.URL, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := gelflogger.NewLogger(tt.address, gelflogger.WithProcessor(processNothing))
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+			if err := logger.Ping(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_ = logger.Close(context.Background())
+			if err := logger.Ping(context.Background()); !errors.Is(err, gelflogger.ErrLoggerClosed) {
+				t.Errorf("Ping() after Close() error = %v, want ErrLoggerClosed", err)
+			}
+		})
+	}
+}
+
+func TestPingServerDown(t *testing.T) {
+	server := helper.StartMockServer(t)
+	address := server.Addr().String()
+	accepted := make(chan struct{}, 1)
+	go func() {
+		if conn, err := server.Accept(); err == nil {
+			_ = conn.Close()
+			accepted <- struct{}{}
+		}
+	}()
+	logger, err := gelflogger.NewLogger(address, gelflogger.WithProcessor(processNothing), gelflogger.WithHealthCheckInterval(0))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+	<-accepted
+	_ = server.Close()
+
+	if err := logger.Ping(context.Background()); err == nil {
+		t.Error("Ping() error = nil after the server closed the connection, want an error")
+	}
+}
@@ -0,0 +1,46 @@
+package gelflogger
+
+import (
+	"errors"
+)
+
+// ErrSkipMessage is returned by a Processor to filter the message out. The message is neither sent nor counted as
+// dropped, and Log returns nil.
+var ErrSkipMessage = errors.New("message skipped by processor")
+
+// Processor processes the messages of a Logger before they are encoded, e.g. to redact, enrich, filter or rename
+// fields, see WithProcessorChain. It may modify the message in place. Returning ErrSkipMessage filters the message
+// out, any other error drops it as unencodable and is returned by the log call. The Message and its fields are reused
+// for later messages, so the Processor must not keep them once Process returned.
+type Processor interface {
+	Process(msg *Message) error
+}
+
+// ProcessorFunc is a function implementing Processor.
+type ProcessorFunc func(msg *Message) error
+
+// Process calls f.
+func (f ProcessorFunc) Process(msg *Message) error {
+	return f(msg)
+}
+
+// processMessage runs the Processors of the Logger on the message. The additional fields are copied first, so the
+// Processors never modify the map of the caller. If a Processor filtered the message out, nil and no error are
+// returned.
+func (l *Logger) processMessage(msg *Message) (*Message, error) {
+	additional := getFieldMap()
+	for name, value := range msg.Additional {
+		additional[name] = value
+	}
+	msg.Additional = additional
+	msg.ownedFields = additional
+	for _, processor := range l.processors {
+		if err := processor.Process(msg); err != nil {
+			if errors.Is(err, ErrSkipMessage) {
+				return nil, nil
+			}
+			return nil, l.dropUnencoded(nil, err)
+		}
+	}
+	return msg, nil
+}
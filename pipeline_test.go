@@ -0,0 +1,111 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestWithProcessorChain(t *testing.T) {
+	enrich := gelflogger.ProcessorFunc(func(msg *gelflogger.Message) error {
+		msg.Additional["team"] = "payments"
+		return nil
+	})
+	rename := gelflogger.ProcessorFunc(func(msg *gelflogger.Message) error {
+		if value, ok := msg.Additional["team"]; ok {
+			delete(msg.Additional, "team")
+			msg.Additional["owner"] = value
+		}
+		msg.ShortMessage = strings.ToUpper(msg.ShortMessage)
+		return nil
+	})
+	skipDebug := gelflogger.ProcessorFunc(func(msg *gelflogger.Message) error {
+		if msg.Level == 7 {
+			return gelflogger.ErrSkipMessage
+		}
+		return nil
+	})
+	reject := gelflogger.ProcessorFunc(func(msg *gelflogger.Message) error {
+		return errors.New("rejected")
+	})
+
+	tests := []struct {
+		name        string
+		processors  []gelflogger.Processor
+		fields      map[string]interface{}
+		wantErr     error
+		wantSent    string
+		wantDropped uint64
+	}{
+		{
+			name:       "Processors run in order",
+			processors: []gelflogger.Processor{enrich, rename},
+			fields:     map[string]interface{}{"level": "info"},
+			wantSent:   `"_owner":"payments"`,
+		},
+		{
+			name:       "Order matters",
+			processors: []gelflogger.Processor{rename, enrich},
+			fields:     map[string]interface{}{"level": "info"},
+			wantSent:   `"_team":"payments"`,
+		},
+		{
+			name:       "Skipped message",
+			processors: []gelflogger.Processor{skipDebug, enrich},
+			fields:     map[string]interface{}{"level": "debug"},
+		},
+		{
+			name:       "Not skipped message",
+			processors: []gelflogger.Processor{skipDebug, rename},
+			fields:     map[string]interface{}{"level": "warn"},
+			wantSent:   `"short_message":"PROCESSED"`,
+		},
+		{
+			name:        "Rejected message",
+			processors:  []gelflogger.Processor{reject, enrich},
+			fields:      map[string]interface{}{"level": "info"},
+			wantErr:     gelflogger.ErrPermanent,
+			wantDropped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, gelflogger.WithProcessorChain(tt.processors...))
+			fields := map[string]interface{}{"user": "42"}
+			for name, value := range tt.fields {
+				fields[name] = value
+			}
+			err := logger.Log("processed", fields)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("Log() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, ok := fields["team"]; ok {
+				t.Error("the processors modified the fields of the caller")
+			}
+			if got := logger.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+			if tt.wantSent == "" {
+				if len(transport.messages) != 0 {
+					t.Errorf("sent %v, want no message", transport.messages)
+				}
+				return
+			}
+			if len(transport.messages) != 1 || !strings.Contains(transport.messages[0], tt.wantSent) {
+				t.Fatalf("sent %v, want a message containing %s", transport.messages, tt.wantSent)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			if gelfMsg["_user"] != "42" {
+				t.Errorf("_user = %v, want the field of the log call", gelfMsg["_user"])
+			}
+		})
+	}
+}
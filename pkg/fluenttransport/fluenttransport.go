@@ -0,0 +1,214 @@
+package fluenttransport
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// EventTime is the Fluentd EventTime msgpack extension (type 0) carrying a timestamp with nanosecond precision.
+type EventTime time.Time
+
+func init() {
+	msgpack.RegisterExt(0, (*EventTime)(nil))
+}
+
+// MarshalMsgpack encodes the EventTime as big-endian seconds followed by big-endian nanoseconds.
+func (et *EventTime) MarshalMsgpack() ([]byte, error) {
+	t := time.Time(*et)
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b, uint32(t.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(t.Nanosecond()))
+	return b, nil
+}
+
+// UnmarshalMsgpack decodes the EventTime from big-endian seconds followed by big-endian nanoseconds.
+func (et *EventTime) UnmarshalMsgpack(b []byte) error {
+	if len(b) != 8 {
+		return fmt.Errorf("invalid EventTime length %d", len(b))
+	}
+	*et = EventTime(time.Unix(int64(binary.BigEndian.Uint32(b)), int64(binary.BigEndian.Uint32(b[4:]))))
+	return nil
+}
+
+// Transport sends GELF messages to a Fluentd or Fluent Bit aggregator using the Fluentd forward protocol in message mode.
+//
+// Every GELF message becomes one event with the configured tag. The record keeps the GELF field names and values
+// (version, host, short_message, level, the underscore prefixed additional fields, ...), and the event time is taken
+// from the GELF timestamp. If RequireAck is set, every event carries a chunk option and Send waits for the
+// acknowledgement of the aggregator.
+type Transport struct {
+	conn       net.Conn
+	connLock   sync.Mutex
+	address    string
+	tag        string
+	RequireAck bool
+	AckTimeout time.Duration
+}
+
+var _ gelflogger.Transport = (*Transport)(nil)
+
+// New creates a Transport and connects to the forward input of the aggregator at the given address.
+//
+// Example usage:
+//
+//	transport, err := fluenttransport.New("fluent-bit.example.com:24224", "app.gelf")
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger := gelflogger.NewLoggerWithTransport(transport, zerologger.ProcessZerologFields)
+func New(address, tag string) (*Transport, error) {
+	t := &Transport{address: address, tag: tag, AckTimeout: 5 * time.Second}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	if err := t.connect(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// connect dials the aggregator and replaces the current connection. The caller must hold connLock.
+func (t *Transport) connect() error {
+	dialer := net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	conn, err := dialer.Dial("tcp", t.address)
+	if err != nil {
+		return err
+	}
+	if t.conn != nil {
+		_ = t.conn.Close()
+	}
+	t.conn = conn
+	return nil
+}
+
+// Send converts the GELF message into a forward protocol event and writes it to the aggregator.
+// If the write fails, it reconnects once and retries the write.
+func (t *Transport) Send(message []byte) error {
+	record, err := decodeRecord(message)
+	if err != nil {
+		return err
+	}
+	eventTime := EventTime(time.Now())
+	switch timestamp := record["timestamp"].(type) {
+	case int64:
+		if timestamp > 0 {
+			eventTime = EventTime(time.Unix(timestamp, 0))
+		}
+	case float64:
+		if timestamp > 0 {
+			seconds, fraction := math.Modf(timestamp)
+			eventTime = EventTime(time.Unix(int64(seconds), int64(fraction*1e9)))
+		}
+	}
+
+	event := []interface{}{t.tag, &eventTime, record}
+	var chunk string
+	if t.RequireAck {
+		if chunk, err = newChunkID(); err != nil {
+			return err
+		}
+		event = append(event, map[string]interface{}{"chunk": chunk})
+	}
+	payload, err := msgpack.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if err = t.write(payload, chunk); err != nil {
+		// Attempt to reconnect and retry the write
+		if err = t.connect(); err != nil {
+			return err
+		}
+		err = t.write(payload, chunk)
+	}
+	return err
+}
+
+// write writes the payload and, if a chunk id is given, waits for its acknowledgement. The caller must hold connLock.
+func (t *Transport) write(payload []byte, chunk string) error {
+	if t.conn == nil {
+		return net.ErrClosed
+	}
+	if _, err := t.conn.Write(payload); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	if err := t.conn.SetReadDeadline(time.Now().Add(t.AckTimeout)); err != nil {
+		return err
+	}
+	defer func() { _ = t.conn.SetReadDeadline(time.Time{}) }()
+	var response map[string]interface{}
+	if err := msgpack.NewDecoder(t.conn).Decode(&response); err != nil {
+		return err
+	}
+	if response["ack"] != chunk {
+		return fmt.Errorf("unexpected acknowledgement %v for chunk %s", response["ack"], chunk)
+	}
+	return nil
+}
+
+// Close closes the connection to the aggregator.
+func (t *Transport) Close() error {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// decodeRecord decodes the GELF JSON document into the event record. Integral numbers are kept as integers so
+// fields like the level are not turned into msgpack floats.
+func decodeRecord(message []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	for k, v := range record {
+		number, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := number.Int64(); err == nil {
+			record[k] = i
+		} else if f, err := number.Float64(); err == nil {
+			record[k] = f
+		} else {
+			record[k] = number.String()
+		}
+	}
+	return record, nil
+}
+
+// newChunkID returns a random, base64 encoded chunk id for the acknowledgement of an event.
+func newChunkID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
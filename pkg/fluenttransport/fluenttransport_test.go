@@ -0,0 +1,96 @@
+package fluenttransport_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/fluenttransport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// startMockForwardServer starts a forward protocol server decoding every event into the returned channel.
+// Events carrying a chunk option are acknowledged.
+func startMockForwardServer(t *testing.T) (string, <-chan []interface{}) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	events := make(chan []interface{}, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				decoder := msgpack.NewDecoder(conn)
+				for {
+					var event []interface{}
+					if err := decoder.Decode(&event); err != nil {
+						return
+					}
+					if len(event) == 4 {
+						option := event[3].(map[string]interface{})
+						_ = msgpack.NewEncoder(conn).Encode(map[string]interface{}{"ack": option["chunk"]})
+					}
+					events <- event
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), events
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		requireAck bool
+	}{
+		{
+			name:       "Without acknowledgement",
+			requireAck: false,
+		},
+		{
+			name:       "With acknowledgement",
+			requireAck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, events := startMockForwardServer(t)
+			transport, err := fluenttransport.New(address, "app.gelf")
+			require.NoError(t, err)
+			defer func() { _ = transport.Close() }()
+			transport.RequireAck = tt.requireAck
+
+			logger := gelflogger.NewLoggerWithTransport(transport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 3, 1700000000.25, nil, nil
+			})
+			assert.NoError(t, logger.Log("hello fluent", map[string]interface{}{"request_id": "abc"}))
+
+			select {
+			case event := <-events:
+				assert.Equal(t, "app.gelf", event[0])
+				eventTime := event[1].(*fluenttransport.EventTime)
+				assert.Equal(t, time.Unix(1700000000, 250000000), time.Time(*eventTime))
+				record := event[2].(map[string]interface{})
+				assert.Equal(t, "hello fluent", record["short_message"])
+				assert.Equal(t, "abc", record["_request_id"])
+				assert.EqualValues(t, 3, record["level"])
+			case <-time.After(time.Second):
+				t.Fatal("event was not received")
+			}
+		})
+	}
+}
+
+func TestNewUnreachable(t *testing.T) {
+	_, err := fluenttransport.New("invalid:address", "app.gelf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package gokitlogger
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-kit/log"
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// The conventional keys of go-kit, see log.DefaultTimestamp and level.Key.
+const (
+	// LevelKey is the key of the level, e.g. level.InfoValue() or "warn".
+	LevelKey = "level"
+	// MessageKey is the key of the message.
+	MessageKey = "msg"
+	// TimestampKey is the key of the timestamp, e.g. of log.DefaultTimestampUTC.
+	TimestampKey = "ts"
+)
+
+// Logger is a go-kit log.Logger sending the records with a gelflogger.Logger. The value of MessageKey is sent as short
+// message, the value of LevelKey as Graylog (Syslog) level and the value of TimestampKey as timestamp, the other
+// keyvals as additional fields. Without MessageKey, the short message is the record as written by the logfmt logger of
+// go-kit, without level and timestamp, e.g. `event=started port=8080`.
+//
+// The level may be a level.Value of go-kit or any level understood by gelflogger.ParseLevel, e.g. "warn" or a Syslog
+// number; records without level are informational. The timestamp may be the value of log.DefaultTimestamp or
+// log.DefaultTimestampUTC, or any timestamp understood by gelflogger.ParseTimestamp; records without timestamp are sent
+// with the current time.
+//
+// Keys other than strings are converted by fmt.Sprint, and a key without value gets log.ErrMissingValue. Error values
+// are sent as their message, with their stack trace appended to the full message.
+//
+// A Logger is safe for concurrent use, like the gelflogger.Logger, so it needs no log.NewSyncLogger.
+type Logger struct {
+	Logger *gelflogger.Logger
+}
+
+// NewGoKitLogger initializes a gelflogger.Logger with the given address and Options, see gelflogger.NewLogger, and
+// returns a go-kit Logger sending the records with it. Close the gelflogger.Logger of the Logger on shutdown, so the
+// pending messages are sent.
+//
+// Example usage:
+//
+//	logger, err := gokitlogger.NewGoKitLogger("graylog.example.com:12201", gelflogger.WithTLS(nil))
+//	if err != nil {
+//	  // handle error
+//	}
+//	defer logger.Logger.Close(context.Background())
+//	kitLogger := log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
+//	level.Info(kitLogger).Log("msg", "Hello, World!", "port", 8080)
+func NewGoKitLogger(address string, opts ...gelflogger.Option) (*Logger, error) {
+	graylogLogger, err := gelflogger.NewLogger(address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{Logger: graylogLogger}, nil
+}
+
+// Log sends the record of the given keyvals. A key without value gets the value log.ErrMissingValue, like in the
+// loggers of go-kit, keys other than strings are converted by fmt.Sprint. It returns an error for an invalid level or
+// timestamp, and the errors of gelflogger.Logger.LogAt.
+func (l *Logger) Log(keyvals ...interface{}) error {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, log.ErrMissingValue)
+	}
+	graylogLevel := gelflogger.Informational
+	var timestamp time.Time
+	message, hasMessage := "", false
+	fields := make(map[string]interface{}, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, value := keyString(keyvals[i]), keyvals[i+1]
+		switch key {
+		case LevelKey:
+			parsed, err := parseLevel(value)
+			if err != nil {
+				return fmt.Errorf("key `%s`: %w", LevelKey, err)
+			}
+			graylogLevel = parsed
+		case TimestampKey:
+			parsed, err := parseTimestamp(value)
+			if err != nil {
+				return fmt.Errorf("key `%s`: %w", TimestampKey, err)
+			}
+			timestamp = parsed
+		case MessageKey:
+			message, hasMessage = fmt.Sprint(value), true
+		default:
+			fields[key] = value
+		}
+	}
+	if !hasMessage {
+		message = logfmtMessage(keyvals)
+	}
+	return l.Logger.LogAt(graylogLevel, timestamp, message, fields)
+}
+
+// keyString returns the key as string.
+func keyString(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// parseLevel returns the Graylog (Syslog) level of the value of LevelKey.
+func parseLevel(value interface{}) (int, error) {
+	if stringer, ok := value.(fmt.Stringer); ok {
+		// level.Value of go-kit, e.g. level.WarnValue()
+		value = stringer.String()
+	}
+	return gelflogger.ParseLevel(value)
+}
+
+// parseTimestamp returns the time of the value of TimestampKey.
+func parseTimestamp(value interface{}) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	if stringer, ok := value.(fmt.Stringer); ok {
+		// The timestamp of log.DefaultTimestamp, formatted as RFC 3339 by String
+		value = stringer.String()
+	}
+	seconds, err := gelflogger.ParseTimestamp(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMicro(int64(math.Round(seconds * 1e6))), nil
+}
+
+// logfmtMessage returns the keyvals other than level and timestamp as written by the logfmt logger of go-kit.
+func logfmtMessage(keyvals []interface{}) string {
+	rest := make([]interface{}, 0, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		if key := keyString(keyvals[i]); key != LevelKey && key != TimestampKey {
+			rest = append(rest, keyvals[i], keyvals[i+1])
+		}
+	}
+	var buf bytes.Buffer
+	_ = log.NewLogfmtLogger(&buf).Log(rest...)
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
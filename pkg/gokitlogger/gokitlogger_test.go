@@ -0,0 +1,115 @@
+package gokitlogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/gokitlogger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	fixed := time.Unix(1700000000, 500000000).UTC()
+
+	tests := []struct {
+		name    string
+		log     func(logger log.Logger) error
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name: "Message, level and fields",
+			log: func(logger log.Logger) error {
+				return level.Warn(logger).Log("msg", "disk almost full", "free", "5%", "mounts", 3)
+			},
+			want: map[string]interface{}{
+				"short_message": "disk almost full",
+				"level":         float64(4),
+				"_free":         "5%",
+				"_mounts":       float64(3),
+			},
+			missing: []string{"_msg", "_level"},
+		},
+		{
+			name: "Timestamp of go-kit",
+			log: func(logger log.Logger) error {
+				return log.With(logger, "ts", log.TimestampFormat(func() time.Time { return fixed }, time.RFC3339Nano)).Log("msg", "imported")
+			},
+			want:    map[string]interface{}{"timestamp": 1700000000.5, "level": float64(6)},
+			missing: []string{"_ts"},
+		},
+		{
+			name: "Timestamp and level as values",
+			log: func(logger log.Logger) error {
+				return logger.Log("ts", fixed, "level", "error", "msg", "failed")
+			},
+			want: map[string]interface{}{"timestamp": 1700000000.5, "level": float64(3)},
+		},
+		{
+			name: "Without message",
+			log: func(logger log.Logger) error {
+				return level.Info(logger).Log("event", "started", "port", 8080)
+			},
+			want: map[string]interface{}{"short_message": "event=started port=8080", "_event": "started", "_port": float64(8080)},
+		},
+		{
+			name: "Missing value and key of other type",
+			log: func(logger log.Logger) error {
+				return logger.Log("msg", "odd", 42, "answer", "dangling")
+			},
+			want: map[string]interface{}{"_42": "answer", "_dangling": log.ErrMissingValue.Error()},
+		},
+		{
+			name: "Error",
+			log: func(logger log.Logger) error {
+				return level.Error(logger).Log("msg", "payment failed", "err", errors.New("timeout"))
+			},
+			want: map[string]interface{}{"level": float64(3), "_err": "timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			logger := &gokitlogger.Logger{Logger: gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing)}
+			require.NoError(t, tt.log(logger))
+
+			require.Len(t, transport.Messages(), 1)
+			var gelfMsg map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(transport.Messages()[0]), &gelfMsg))
+			for key, want := range tt.want {
+				assert.Equal(t, want, gelfMsg[key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, gelfMsg, key)
+			}
+		})
+	}
+}
+
+func TestLoggerInvalid(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := &gokitlogger.Logger{Logger: gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing)}
+	assert.Error(t, logger.Log("level", "loud", "msg", "invalid level"))
+	assert.Error(t, logger.Log("ts", "yesterday", "msg", "invalid timestamp"))
+	assert.Empty(t, transport.Messages())
+}
+
+func TestNewGoKitLogger(t *testing.T) {
+	mockServer := helper.StartMockServer(t)
+	t.Cleanup(func() { _ = mockServer.Close() })
+
+	logger, err := gokitlogger.NewGoKitLogger(mockServer.Addr().String())
+	require.NoError(t, err)
+	assert.NotNil(t, logger.Logger)
+
+	_, err = gokitlogger.NewGoKitLogger("invalid:address")
+	assert.Error(t, err)
+}
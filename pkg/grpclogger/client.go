@@ -0,0 +1,154 @@
+package grpclogger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// TargetField is the field of the target of the client connection of a call, e.g. dns:///billing:443.
+const TargetField = "grpc_target"
+
+// UnaryClientInterceptor returns an interceptor logging the unary calls of a gRPC client with the Logger, so the
+// failing calls to other services are logged even if the logs of these services are not available. Every call is
+// logged once it returned, like by UnaryServerInterceptor, with the target of the connection and the address of the
+// server instead of the client, and the request and trace IDs of the outgoing metadata.
+//
+// Example usage:
+//
+//	conn, err := grpc.NewClient("dns:///billing:443",
+//		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+//		grpc.WithChainUnaryInterceptor(grpclogger.UnaryClientInterceptor(graylogLogger)),
+//		grpc.WithChainStreamInterceptor(grpclogger.StreamClientInterceptor(graylogLogger)),
+//	)
+func UnaryClientInterceptor(logger *gelflogger.Logger, opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		var server peer.Peer
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Peer(&server))...)
+		if cfg.skip(err) {
+			return err
+		}
+		fields := cfg.clientFields(ctx, cc, method, "unary")
+		if server.Addr != nil {
+			fields[PeerField] = server.Addr.String()
+		}
+		if cfg.payloads {
+			fields[RequestField] = payload(req)
+			if err == nil {
+				fields[ResponseField] = payload(reply)
+			}
+		}
+		cfg.log(ctx, logger, fields, method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor logging the streaming calls of a gRPC client with the Logger, like
+// UnaryClientInterceptor, with the numbers of messages sent and received on the stream. A call is logged once the
+// stream failed to open or ended, which the client sees by RecvMsg, so calls the client does not receive the end of,
+// e.g. streams abandoned by canceling their context, are not logged.
+func StreamClientInterceptor(logger *gelflogger.Logger, opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		fields := cfg.clientFields(ctx, cc, method, kind(desc.ClientStreams, desc.ServerStreams))
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			if !cfg.skip(err) {
+				cfg.log(ctx, logger, fields, method, start, err)
+			}
+			return cs, err
+		}
+		stream := &loggingClientStream{ClientStream: cs, serverStreams: desc.ServerStreams}
+		stream.finish = func(err error) {
+			if cfg.skip(err) {
+				return
+			}
+			// The fields are cloned, as messages may still be sent and logged while the stream ends
+			fields := maps.Clone(fields)
+			fields[SentField] = stream.sent.Load()
+			fields[ReceivedField] = stream.received.Load()
+			cfg.log(ctx, logger, fields, method, start, err)
+		}
+		if cfg.payloads && !cfg.errorsOnly {
+			stream.ctx, stream.logger, stream.fields, stream.fullMethod = ctx, logger, fields, method
+		}
+		return stream, nil
+	}
+}
+
+// clientFields returns the fields of the call of a client, with the target of the connection and the fields of the
+// outgoing metadata.
+func (cfg *config) clientFields(ctx context.Context, cc *grpc.ClientConn, fullMethod, kind string) map[string]interface{} {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	fields := cfg.callFields(fullMethod, kind, md)
+	if cc != nil {
+		fields[TargetField] = cc.Target()
+	}
+	return fields
+}
+
+// loggingClientStream counts the messages of a stream, logs them if a Logger is set, see WithPayloads, and logs the
+// call once the stream ended.
+type loggingClientStream struct {
+	grpc.ClientStream
+	// serverStreams is whether the server sends a stream of messages. Otherwise the stream ends with the first message
+	// received.
+	serverStreams  bool
+	sent, received atomic.Int64
+	finish         func(err error)
+	once           sync.Once
+	// ctx is the context the stream was opened with, e.g. carrying the span of the call.
+	ctx    context.Context
+	logger *gelflogger.Logger
+	// fields are the fields of the call, not modified while the stream is open.
+	fields     map[string]interface{}
+	fullMethod string
+}
+
+// SendMsg sends a message and counts it.
+func (s *loggingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+		if s.logger != nil {
+			logPayload(s.ctx, s.logger, s.fields, s.fullMethod, "sent", m)
+		}
+	}
+	return err
+}
+
+// RecvMsg receives a message and counts it. The call is logged if the stream ended, with io.EOF for streams ending
+// with the status code OK.
+func (s *loggingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+		if s.logger != nil {
+			logPayload(s.ctx, s.logger, s.fields, s.fullMethod, "received", m)
+		}
+		if !s.serverStreams {
+			s.once.Do(func() { s.finish(nil) })
+		}
+		return nil
+	}
+	s.once.Do(func() {
+		if errors.Is(err, io.EOF) {
+			s.finish(nil)
+			return
+		}
+		s.finish(err)
+	})
+	return err
+}
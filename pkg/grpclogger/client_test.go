@@ -0,0 +1,215 @@
+package grpclogger_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/grpclogger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newClientConn returns a connection to billing:443, which is not connected as long as no call is made on it.
+func newClientConn(t *testing.T) *grpc.ClientConn {
+	cc, err := grpc.NewClient("passthrough:///billing:443", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+	return cc
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []grpclogger.Option
+		err     error
+		want    map[string]interface{}
+		missing []string
+		skipped bool
+	}{
+		{
+			name: "Successful call",
+			want: map[string]interface{}{
+				"short_message": "gRPC /billing.Payments/Charge OK",
+				"level":         float64(6),
+				"_grpc_service": "billing.Payments",
+				"_grpc_method":  "Charge",
+				"_grpc_kind":    "unary",
+				"_grpc_target":  "passthrough:///billing:443",
+				"_remote_addr":  "10.0.0.8:443",
+				"_request_id":   "r-1",
+			},
+			missing: []string{"_error", "_grpc_request", "_grpc_response"},
+		},
+		{
+			name: "Failed call",
+			err:  status.Error(codes.DeadlineExceeded, "deadline exceeded"),
+			want: map[string]interface{}{
+				"short_message": "gRPC /billing.Payments/Charge DeadlineExceeded",
+				"level":         float64(3),
+				"_error":        "rpc error: code = DeadlineExceeded desc = deadline exceeded",
+			},
+			missing: []string{"_grpc_response"},
+		},
+		{
+			name: "Payloads",
+			opts: []grpclogger.Option{grpclogger.WithPayloads()},
+			want: map[string]interface{}{"_grpc_request": `"order-42"`, "_grpc_response": "true"},
+		},
+		{
+			name:    "Errors only skips successful calls",
+			opts:    []grpclogger.Option{grpclogger.WithErrorsOnly()},
+			skipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			interceptor := grpclogger.UnaryClientInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), tt.opts...)
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				for _, opt := range opts {
+					if p, ok := opt.(grpc.PeerCallOption); ok {
+						*p.PeerAddr = peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 8), Port: 443}}
+					}
+				}
+				if tt.err != nil {
+					return tt.err
+				}
+				reply.(*wrapperspb.BoolValue).Value = true
+				return nil
+			}
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "r-1")
+			err := interceptor(ctx, "/billing.Payments/Charge", wrapperspb.String("order-42"), &wrapperspb.BoolValue{}, newClientConn(t), invoker)
+			assert.Equal(t, tt.err, err)
+
+			sent := decode(t, transport)
+			if tt.skipped {
+				assert.Empty(t, sent)
+				return
+			}
+			require.Len(t, sent, 1)
+			for key, want := range tt.want {
+				assert.Equal(t, want, sent[0][key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, sent[0], key)
+			}
+		})
+	}
+}
+
+// fakeClientStream is a grpc.ClientStream receiving the given messages, and ending with the given error.
+type fakeClientStream struct {
+	grpc.ClientStream
+	incoming []string
+	err      error
+}
+
+func (s *fakeClientStream) SendMsg(interface{}) error { return nil }
+
+func (s *fakeClientStream) RecvMsg(m interface{}) error {
+	if len(s.incoming) == 0 {
+		return s.err
+	}
+	m.(*wrapperspb.StringValue).Value = s.incoming[0]
+	s.incoming = s.incoming[1:]
+	return nil
+}
+
+// streamer returns a grpc.Streamer opening the given stream, or failing with the given error.
+func streamer(stream grpc.ClientStream, err error) grpc.Streamer {
+	return func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return stream, err
+	}
+}
+
+// receiveAll receives the messages of the stream until it ends.
+func receiveAll(stream grpc.ClientStream) error {
+	for {
+		var msg wrapperspb.StringValue
+		if err := stream.RecvMsg(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	serverStream := &grpc.StreamDesc{ServerStreams: true}
+
+	t.Run("Server stream ending with EOF", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamClientInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing))
+		stream, err := interceptor(context.Background(), serverStream, newClientConn(t), "/chat.Rooms/Listen",
+			streamer(&fakeClientStream{incoming: []string{"hi", "bye"}, err: io.EOF}, nil))
+		require.NoError(t, err)
+		require.NoError(t, stream.SendMsg(wrapperspb.String("room-1")))
+		assert.Equal(t, io.EOF, receiveAll(stream))
+		assert.Equal(t, io.EOF, receiveAll(stream))
+
+		sent := decode(t, transport)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "gRPC /chat.Rooms/Listen OK", sent[0]["short_message"])
+		assert.Equal(t, "server_stream", sent[0]["_grpc_kind"])
+		assert.Equal(t, "passthrough:///billing:443", sent[0]["_grpc_target"])
+		assert.Equal(t, float64(1), sent[0]["_grpc_messages_sent"])
+		assert.Equal(t, float64(2), sent[0]["_grpc_messages_received"])
+	})
+
+	t.Run("Server stream failing", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamClientInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), grpclogger.WithErrorsOnly())
+		stream, err := interceptor(context.Background(), serverStream, newClientConn(t), "/chat.Rooms/Listen",
+			streamer(&fakeClientStream{incoming: []string{"hi"}, err: status.Error(codes.Unavailable, "connection reset")}, nil))
+		require.NoError(t, err)
+		require.Error(t, receiveAll(stream))
+
+		sent := decode(t, transport)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "gRPC /chat.Rooms/Listen Unavailable", sent[0]["short_message"])
+		assert.Equal(t, float64(3), sent[0]["level"])
+		assert.Equal(t, float64(1), sent[0]["_grpc_messages_received"])
+	})
+
+	t.Run("Client stream ending with the response", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamClientInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), grpclogger.WithPayloads())
+		stream, err := interceptor(context.Background(), &grpc.StreamDesc{ClientStreams: true}, newClientConn(t), "/chat.Rooms/Upload",
+			streamer(&fakeClientStream{incoming: []string{"done"}}, nil))
+		require.NoError(t, err)
+		require.NoError(t, stream.SendMsg(wrapperspb.String("chunk")))
+		require.NoError(t, stream.RecvMsg(&wrapperspb.StringValue{}))
+
+		sent := decode(t, transport)
+		require.Len(t, sent, 3)
+		assert.Equal(t, "gRPC /chat.Rooms/Upload sent message", sent[0]["short_message"])
+		assert.Equal(t, `"chunk"`, sent[0]["_grpc_payload"])
+		assert.Equal(t, "gRPC /chat.Rooms/Upload received message", sent[1]["short_message"])
+		assert.Equal(t, "gRPC /chat.Rooms/Upload OK", sent[2]["short_message"])
+		assert.Equal(t, "client_stream", sent[2]["_grpc_kind"])
+		assert.NotContains(t, sent[2], "_grpc_payload")
+	})
+
+	t.Run("Stream failing to open", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamClientInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing))
+		_, err := interceptor(context.Background(), serverStream, newClientConn(t), "/chat.Rooms/Listen",
+			streamer(nil, status.Error(codes.Unauthenticated, "missing token")))
+		require.Error(t, err)
+
+		sent := decode(t, transport)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "gRPC /chat.Rooms/Listen Unauthenticated", sent[0]["short_message"])
+		assert.Equal(t, float64(4), sent[0]["level"])
+	})
+}
@@ -0,0 +1,206 @@
+package grpclogger
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/tracecontext"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The fields of the messages logged by the interceptors. The names of the request and trace ID fields are the ones
+// renamed by the field naming schemes, see gelflogger.WithFieldNaming.
+const (
+	// ServiceField is the field of the service of the call, e.g. billing.Payments.
+	ServiceField = "grpc_service"
+	// MethodField is the field of the method of the call, e.g. Charge.
+	MethodField = "grpc_method"
+	// KindField is the field of the kind of the call: unary, client_stream, server_stream or bidi_stream.
+	KindField = "grpc_kind"
+	// CodeField is the field of the status code of the call, e.g. OK or NotFound.
+	CodeField = "grpc_code"
+	// DurationField is the field of the duration of the call in milliseconds.
+	DurationField = "duration_ms"
+	// ErrorField is the field of the error of a failed call.
+	ErrorField = "error"
+	// RequestField is the field of the request of a unary call, see WithPayloads.
+	RequestField = "grpc_request"
+	// ResponseField is the field of the response of a unary call, see WithPayloads.
+	ResponseField = "grpc_response"
+	// PayloadField is the field of a message sent or received on a stream, see WithPayloads.
+	PayloadField = "grpc_payload"
+	// ReceivedField is the field of the number of messages received on a stream.
+	ReceivedField = "grpc_messages_received"
+	// SentField is the field of the number of messages sent on a stream.
+	SentField = "grpc_messages_sent"
+	// TraceIDField is the field of the trace ID, of the x-trace-id or the traceparent metadata.
+	TraceIDField = "trace_id"
+	// SpanIDField is the field of the span ID of the traceparent metadata.
+	SpanIDField = "span_id"
+)
+
+// Option configures the interceptors, see UnaryServerInterceptor.
+type Option func(*config)
+
+// config holds the settings of the interceptors.
+//
+// - payloads: Whether the requests and responses are logged, see WithPayloads.
+// - errorsOnly: Whether only failed calls are logged, see WithErrorsOnly.
+// - metadata: The fields of the metadata, by metadata key, see WithMetadataField.
+// - codeToLevel: The Graylog (Syslog) level of the status codes, see WithCodeToLevel.
+type config struct {
+	payloads    bool
+	errorsOnly  bool
+	metadata    map[string]string
+	codeToLevel func(codes.Code) int
+}
+
+// newConfig returns the config of the given Options.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		metadata:    map[string]string{"x-request-id": "request_id", "x-trace-id": TraceIDField},
+		codeToLevel: DefaultCodeToLevel,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithPayloads logs the request and the response of unary calls as RequestField and ResponseField, and every message
+// of streams as debug message with the PayloadField, unless only errors are logged, see WithErrorsOnly. Protocol
+// buffer messages are sent as their protojson encoding, other messages are encoded by the Logger. Payloads may hold
+// personal data, so consider a redacting Processor, see gelflogger.WithProcessorChain.
+func WithPayloads() Option {
+	return func(cfg *config) {
+		cfg.payloads = true
+	}
+}
+
+// WithErrorsOnly logs only the calls failing with a status code other than OK, e.g. to keep the volume of busy
+// services low.
+func WithErrorsOnly() Option {
+	return func(cfg *config) {
+		cfg.errorsOnly = true
+	}
+}
+
+// WithMetadataField logs the value of the metadata of the given key as the given field, in addition to x-request-id
+// as request_id, x-trace-id as trace_id and the trace and span ID of the W3C traceparent metadata. Metadata keys are
+// lowercase.
+func WithMetadataField(key, field string) Option {
+	return func(cfg *config) {
+		cfg.metadata[strings.ToLower(key)] = field
+	}
+}
+
+// WithCodeToLevel selects the Graylog (Syslog) level of the calls by their status code, DefaultCodeToLevel by default.
+func WithCodeToLevel(codeToLevel func(codes.Code) int) Option {
+	return func(cfg *config) {
+		cfg.codeToLevel = codeToLevel
+	}
+}
+
+// DefaultCodeToLevel returns the Graylog (Syslog) level of the calls with the given status code: informational for
+// OK, warning for the codes caused by the client, e.g. NotFound or InvalidArgument, and error for the codes of failing
+// servers, e.g. Internal, Unavailable or DeadlineExceeded.
+func DefaultCodeToLevel(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return gelflogger.Informational
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.ResourceExhausted, codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return gelflogger.Warning
+	default:
+		return gelflogger.Error
+	}
+}
+
+// skip reports whether the call ending with the given error is not logged.
+func (cfg *config) skip(err error) bool {
+	return cfg.errorsOnly && status.Code(err) == codes.OK
+}
+
+// callFields returns the fields of the call of the given method, e.g. /billing.Payments/Charge, and kind, with the
+// fields of the given metadata.
+func (cfg *config) callFields(fullMethod, kind string, md metadata.MD) map[string]interface{} {
+	fields := make(map[string]interface{}, 8)
+	service, method := splitMethod(fullMethod)
+	fields[ServiceField] = service
+	fields[MethodField] = method
+	fields[KindField] = kind
+	for key, field := range cfg.metadata {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			fields[field] = values[0]
+		}
+	}
+	if _, ok := fields[TraceIDField]; !ok {
+		if values := md.Get("traceparent"); len(values) > 0 {
+			if traceID, spanID, ok := tracecontext.ParseTraceparent(values[0]); ok {
+				fields[TraceIDField], fields[SpanIDField] = traceID, spanID
+			}
+		}
+	}
+	return fields
+}
+
+// log logs the call of the given method, which started at the given time, with its status code, duration and error,
+// and the fields of the context of the call, see gelflogger.WithContextExtractor. Errors of the Logger are dropped, as they must not fail the call; they are counted by the statistics of the Logger,
+// see gelflogger.Logger.Stats.
+func (cfg *config) log(ctx context.Context, logger *gelflogger.Logger, fields map[string]interface{}, fullMethod string, start time.Time, err error) {
+	code := status.Code(err)
+	fields[CodeField] = code.String()
+	fields[DurationField] = float64(time.Since(start)) / float64(time.Millisecond)
+	if err != nil {
+		fields[ErrorField] = err
+	}
+	_ = logger.LogAtContext(ctx, cfg.codeToLevel(code), time.Time{}, "gRPC "+fullMethod+" "+code.String(), fields)
+}
+
+// logPayload logs a message sent or received on a stream as debug message, see WithPayloads.
+func logPayload(ctx context.Context, logger *gelflogger.Logger, callFields map[string]interface{}, fullMethod, direction string, msg interface{}) {
+	fields := make(map[string]interface{}, len(callFields)+1)
+	for name, value := range callFields {
+		fields[name] = value
+	}
+	fields[PayloadField] = payload(msg)
+	_ = logger.LogAtContext(ctx, gelflogger.Debug, time.Time{}, "gRPC "+fullMethod+" "+direction+" message", fields)
+}
+
+// payload returns the message to log as payload: the protojson encoding of protocol buffer messages, other messages
+// as they are.
+func payload(msg interface{}) interface{} {
+	if message, ok := msg.(proto.Message); ok {
+		if encoded, err := protojson.Marshal(message); err == nil {
+			return string(encoded)
+		}
+	}
+	return msg
+}
+
+// kind returns the kind of a streaming call.
+func kind(clientStream, serverStream bool) string {
+	switch {
+	case clientStream && serverStream:
+		return "bidi_stream"
+	case clientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
+
+// splitMethod splits the full method of a call, e.g. /billing.Payments/Charge, into service and method.
+func splitMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "", fullMethod
+	}
+	return service, method
+}
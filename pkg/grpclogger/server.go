@@ -0,0 +1,114 @@
+package grpclogger
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// PeerField is the field of the address of the peer of a call, the client for servers and the server for clients, the
+// name renamed to client.ip and client.address by the field naming schemes, see gelflogger.WithFieldNaming.
+const PeerField = "remote_addr"
+
+// UnaryServerInterceptor returns an interceptor logging the unary calls of a gRPC server with the Logger. Every call
+// is logged once it is handled, as message like `gRPC /billing.Payments/Charge NotFound`, with the service, method,
+// kind, status code and duration of the call, the address of the client, the request and trace IDs of the metadata,
+// and the error of failed calls. The level is selected by the status code, see DefaultCodeToLevel.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpclogger.UnaryServerInterceptor(graylogLogger)),
+//		grpc.ChainStreamInterceptor(grpclogger.StreamServerInterceptor(graylogLogger)),
+//	)
+func UnaryServerInterceptor(logger *gelflogger.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if cfg.skip(err) {
+			return resp, err
+		}
+		fields := cfg.serverFields(ctx, info.FullMethod, "unary")
+		if cfg.payloads {
+			fields[RequestField] = payload(req)
+			if err == nil {
+				fields[ResponseField] = payload(resp)
+			}
+		}
+		cfg.log(ctx, logger, fields, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging the streaming calls of a gRPC server with the Logger, like
+// UnaryServerInterceptor, with the numbers of messages received and sent on the stream.
+func StreamServerInterceptor(logger *gelflogger.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		stream := &loggingServerStream{ServerStream: ss}
+		fields := cfg.serverFields(ss.Context(), info.FullMethod, kind(info.IsClientStream, info.IsServerStream))
+		if cfg.payloads && !cfg.errorsOnly {
+			stream.logger, stream.fields, stream.fullMethod = logger, fields, info.FullMethod
+		}
+		err := handler(srv, stream)
+		if cfg.skip(err) {
+			return err
+		}
+		fields[ReceivedField] = stream.received.Load()
+		fields[SentField] = stream.sent.Load()
+		cfg.log(ss.Context(), logger, fields, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// serverFields returns the fields of the call of a server, with the address of the client and the fields of the
+// incoming metadata.
+func (cfg *config) serverFields(ctx context.Context, fullMethod, kind string) map[string]interface{} {
+	md, _ := metadata.FromIncomingContext(ctx)
+	fields := cfg.callFields(fullMethod, kind, md)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[PeerField] = p.Addr.String()
+	}
+	return fields
+}
+
+// loggingServerStream counts the messages of a stream, and logs them if a Logger is set, see WithPayloads.
+type loggingServerStream struct {
+	grpc.ServerStream
+	received, sent atomic.Int64
+	logger         *gelflogger.Logger
+	// fields are the fields of the call, not modified while the stream is open.
+	fields     map[string]interface{}
+	fullMethod string
+}
+
+// RecvMsg receives a message and counts it.
+func (s *loggingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+		if s.logger != nil {
+			logPayload(s.Context(), s.logger, s.fields, s.fullMethod, "received", m)
+		}
+	}
+	return err
+}
+
+// SendMsg sends a message and counts it.
+func (s *loggingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+		if s.logger != nil {
+			logPayload(s.Context(), s.logger, s.fields, s.fullMethod, "sent", m)
+		}
+	}
+	return err
+}
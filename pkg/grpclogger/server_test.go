@@ -0,0 +1,224 @@
+package grpclogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/grpclogger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// decode decodes the sent GELF messages.
+func decode(t *testing.T, transport *helper.RecordingTransport) []map[string]interface{} {
+	sent := make([]map[string]interface{}, 0, len(transport.Messages()))
+	for _, message := range transport.Messages() {
+		var gelfMsg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(message), &gelfMsg))
+		sent = append(sent, gelfMsg)
+	}
+	return sent
+}
+
+// incomingContext returns the context of a call of a client at 10.0.0.7 with the given metadata.
+func incomingContext(pairs ...string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 51234}})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(pairs...))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []grpclogger.Option
+		ctx     context.Context
+		err     error
+		want    map[string]interface{}
+		missing []string
+		skipped bool
+	}{
+		{
+			name: "Successful call",
+			ctx:  incomingContext("x-request-id", "r-1", "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+			want: map[string]interface{}{
+				"short_message": "gRPC /billing.Payments/Charge OK",
+				"level":         float64(6),
+				"_grpc_service": "billing.Payments",
+				"_grpc_method":  "Charge",
+				"_grpc_kind":    "unary",
+				"_grpc_code":    "OK",
+				"_remote_addr":  "10.0.0.7:51234",
+				"_request_id":   "r-1",
+				"_trace_id":     "4bf92f3577b34da6a3ce929d0e0e4736",
+				"_span_id":      "00f067aa0ba902b7",
+			},
+			missing: []string{"_error", "_grpc_request", "_grpc_response"},
+		},
+		{
+			name: "Client error",
+			ctx:  incomingContext("x-trace-id", "abc"),
+			err:  status.Error(codes.NotFound, "no such order"),
+			want: map[string]interface{}{
+				"short_message": "gRPC /billing.Payments/Charge NotFound",
+				"level":         float64(4),
+				"_grpc_code":    "NotFound",
+				"_trace_id":     "abc",
+				"_error":        "rpc error: code = NotFound desc = no such order",
+			},
+		},
+		{
+			name: "Server error",
+			ctx:  context.Background(),
+			err:  status.Error(codes.Unavailable, "database down"),
+			want: map[string]interface{}{"level": float64(3), "_grpc_code": "Unavailable"},
+		},
+		{
+			name: "Payloads",
+			opts: []grpclogger.Option{grpclogger.WithPayloads()},
+			ctx:  context.Background(),
+			want: map[string]interface{}{"_grpc_request": `"order-42"`, "_grpc_response": "true"},
+		},
+		{
+			name:    "Errors only skips successful calls",
+			opts:    []grpclogger.Option{grpclogger.WithErrorsOnly()},
+			ctx:     context.Background(),
+			skipped: true,
+		},
+		{
+			name: "Metadata field and code to level",
+			opts: []grpclogger.Option{
+				grpclogger.WithMetadataField("X-Tenant", "tenant"),
+				grpclogger.WithCodeToLevel(func(codes.Code) int { return gelflogger.Notice }),
+			},
+			ctx:  incomingContext("x-tenant", "acme"),
+			want: map[string]interface{}{"level": float64(5), "_tenant": "acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			interceptor := grpclogger.UnaryServerInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), tt.opts...)
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return wrapperspb.Bool(true), nil
+			}
+			_, err := interceptor(tt.ctx, wrapperspb.String("order-42"), &grpc.UnaryServerInfo{FullMethod: "/billing.Payments/Charge"}, handler)
+			assert.Equal(t, tt.err, err)
+
+			sent := decode(t, transport)
+			if tt.skipped {
+				assert.Empty(t, sent)
+				return
+			}
+			require.Len(t, sent, 1)
+			for key, want := range tt.want {
+				assert.Equal(t, want, sent[0][key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, sent[0], key)
+			}
+			assert.GreaterOrEqual(t, sent[0]["_duration_ms"], float64(0))
+		})
+	}
+}
+
+// fakeServerStream is a grpc.ServerStream receiving the given messages.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	incoming []string
+	sent     []interface{}
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	if len(s.incoming) == 0 {
+		return io.EOF
+	}
+	m.(*wrapperspb.StringValue).Value = s.incoming[0]
+	s.incoming = s.incoming[1:]
+	return nil
+}
+
+func (s *fakeServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+// echo is a bidirectional streaming handler sending back every received message.
+func echo(_ interface{}, stream grpc.ServerStream) error {
+	for {
+		var msg wrapperspb.StringValue
+		if err := stream.RecvMsg(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/chat.Rooms/Talk", IsClientStream: true, IsServerStream: true}
+
+	t.Run("Counts messages", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamServerInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing))
+		stream := &fakeServerStream{ctx: incomingContext("x-request-id", "r-2"), incoming: []string{"hi", "bye"}}
+		require.NoError(t, interceptor(nil, stream, info, echo))
+
+		sent := decode(t, transport)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "gRPC /chat.Rooms/Talk OK", sent[0]["short_message"])
+		assert.Equal(t, "bidi_stream", sent[0]["_grpc_kind"])
+		assert.Equal(t, float64(2), sent[0]["_grpc_messages_received"])
+		assert.Equal(t, float64(2), sent[0]["_grpc_messages_sent"])
+		assert.Equal(t, "r-2", sent[0]["_request_id"])
+		assert.Len(t, stream.sent, 2)
+	})
+
+	t.Run("Payloads", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamServerInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), grpclogger.WithPayloads())
+		stream := &fakeServerStream{ctx: context.Background(), incoming: []string{"hi"}}
+		require.NoError(t, interceptor(nil, stream, info, echo))
+
+		sent := decode(t, transport)
+		require.Len(t, sent, 3)
+		assert.Equal(t, "gRPC /chat.Rooms/Talk received message", sent[0]["short_message"])
+		assert.Equal(t, float64(7), sent[0]["level"])
+		assert.Equal(t, `"hi"`, sent[0]["_grpc_payload"])
+		assert.Equal(t, "gRPC /chat.Rooms/Talk sent message", sent[1]["short_message"])
+		assert.Equal(t, "gRPC /chat.Rooms/Talk OK", sent[2]["short_message"])
+	})
+
+	t.Run("Errors only", func(t *testing.T) {
+		transport := &helper.RecordingTransport{}
+		interceptor := grpclogger.StreamServerInterceptor(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing),
+			grpclogger.WithErrorsOnly(), grpclogger.WithPayloads())
+		require.NoError(t, interceptor(nil, &fakeServerStream{ctx: context.Background(), incoming: []string{"hi"}}, info, echo))
+		assert.Empty(t, transport.Messages())
+
+		failing := func(interface{}, grpc.ServerStream) error { return status.Error(codes.Internal, "boom") }
+		require.Error(t, interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, failing))
+		sent := decode(t, transport)
+		require.Len(t, sent, 1)
+		assert.Equal(t, float64(3), sent[0]["level"])
+	})
+}
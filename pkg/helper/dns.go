@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// StartMockDNSServer starts a DNS server on the loopback address answering A and AAAA queries with the IP addresses
+// of the given hosts, e.g. {"graylog.test": {net.ParseIP("::1"), net.ParseIP("127.0.0.1")}}, and returns a resolver
+// querying it. Other queries are answered with no records. The server is stopped when the test finishes.
+func StartMockDNSServer(t *testing.T, hosts map[string][]net.IP) *net.Resolver {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start mock DNS server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response, err := answerDNSQuery(buf[:n], hosts); err == nil {
+				_, _ = conn.WriteTo(response, addr)
+			}
+		}
+	}()
+
+	address := conn.LocalAddr().String()
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "udp", address)
+		},
+	}
+}
+
+// answerDNSQuery builds the response to the given DNS query from the IP addresses of the hosts.
+func answerDNSQuery(query []byte, hosts map[string][]net.IP) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true})
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+	name := question.Name.String()
+	for _, ip := range hosts[name[:len(name)-1]] {
+		resource := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60}
+		switch ip4 := ip.To4(); {
+		case ip4 != nil && question.Type == dnsmessage.TypeA:
+			err = builder.AResource(resource, dnsmessage.AResource{A: [4]byte(ip4)})
+		case ip4 == nil && question.Type == dnsmessage.TypeAAAA:
+			err = builder.AAAAResource(resource, dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return builder.Finish()
+}
@@ -1,11 +1,14 @@
 package helper
 
 import (
+	"bufio"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
 	"math/big"
 	"net"
 	"testing"
@@ -23,10 +26,39 @@ func StartMockServer(t *testing.T) net.Listener {
 	return l
 }
 
+// ReceiveMessages accepts the connections of the given listener and splits the received streams at the delimiter.
+// Every received message is sent, without its delimiter, to the returned channel.
+// Accepting stops as soon as the listener is closed.
+func ReceiveMessages(t *testing.T, l net.Listener, delimiter byte) <-chan string {
+	t.Helper()
+	messages := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				reader := bufio.NewReader(conn)
+				for {
+					message, err := reader.ReadString(delimiter)
+					if err != nil {
+						return
+					}
+					messages <- message[:len(message)-1]
+				}
+			}()
+		}
+	}()
+	return messages
+}
+
 // StartMockTLSServer creates a mock TLS TCP server and returns a network listener.
 // It loads the X.509 key pair from the given PEM-encoded files, 'testcert.pem' and 'testkey.pem', for TLS configuration.
 // If the key pair cannot be loaded, the function aborts with a fatal error.
 // The server listens on the specified TCP port on the loopback address, "127.0.0.1".
+// It accepts the incoming connections and discards everything they send, so the TLS handshakes of clients complete.
 // The returned listener should be closed after use to free the associated resources.
 // To create these test certificate files, you can use OpenSSL with the following commands in your `test_data` folder under project root:
 //
@@ -38,6 +70,18 @@ func StartMockTLSServer(t *testing.T) net.Listener {
 	if err != nil {
 		t.Fatalf("Failed to start mock TLS TCP server: %v", err)
 	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
 	return l
 }
 
@@ -57,3 +101,32 @@ func CreateTestCertificate() tls.Certificate {
 	derBytes, _ := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
 	return tls.Certificate{Certificate: [][]byte{derBytes}, PrivateKey: privateKey}
 }
+
+// CreateTestCertificatePEM creates a self-signed certificate for the given hosts (IP addresses or DNS names) and
+// returns it together with its private key, both PEM encoded. As the certificate is its own CA, it can also be used
+// as CA bundle to verify itself, e.g. for mutual TLS tests.
+func CreateTestCertificatePEM(hosts ...string) (certPEM, keyPEM []byte) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{Organization: []string{"Acme Co"}, CommonName: "gelf-logger test"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour * 24),
+		IsCA:                  true,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	derBytes, _ := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return certPEM, keyPEM
+}
@@ -0,0 +1,35 @@
+package helper
+
+import "sync"
+
+// RecordingTransport is a transport of gelflogger.NewLoggerWithTransport keeping the sent messages instead of sending
+// them, so tests can check the GELF messages a Logger produces without a server. It is safe for concurrent use, e.g.
+// by asynchronous Loggers.
+type RecordingTransport struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+// Send records the message.
+func (t *RecordingTransport) Send(message []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.messages = append(t.messages, string(message))
+	return nil
+}
+
+// Close does nothing, the recorded messages are kept.
+func (t *RecordingTransport) Close() error { return nil }
+
+// Messages returns a copy of the recorded messages, in the order they were sent.
+func (t *RecordingTransport) Messages() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]string(nil), t.messages...)
+}
+
+// ProcessNothing is a processor of gelflogger.NewLoggerWithTransport which reads nothing from the fields, for Loggers
+// whose level and timestamp are given by the log calls, e.g. gelflogger.Logger.LogAt, or do not matter to the test.
+func ProcessNothing(map[string]interface{}) (int, float64, []byte, error) {
+	return 0, 0, nil, nil
+}
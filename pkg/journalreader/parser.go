@@ -0,0 +1,118 @@
+package journalreader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// maxFieldSize is the maximum size of a binary field of the export format, larger fields are rejected as corrupt.
+const maxFieldSize = 64 << 20
+
+// Entry is a journal entry, its fields by name, e.g. MESSAGE, PRIORITY and _SYSTEMD_UNIT.
+type Entry map[string]string
+
+// Cursor returns the cursor of the entry, the position to resume reading the journal after it.
+func (e Entry) Cursor() string {
+	return e["__CURSOR"]
+}
+
+// ParseExport parses the journal export format, as written by journalctl -o export, and calls handle with every entry.
+// The entries are separated by empty lines, their fields are written as NAME=value lines, or, if the value contains
+// line breaks or binary data, as the name, the 64-bit little-endian size of the value and the value. It returns the
+// first error of reading or handling, or nil at the end of the input.
+func ParseExport(r io.Reader, handle func(Entry) error) error {
+	reader := bufio.NewReader(r)
+	entry := Entry{}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			if len(entry) > 0 {
+				return handle(entry)
+			}
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) == 0 {
+			if len(entry) > 0 {
+				if err := handle(entry); err != nil {
+					return err
+				}
+				entry = Entry{}
+			}
+			continue
+		}
+		if name, value, ok := bytes.Cut(line, []byte("=")); ok {
+			entry[string(name)] = string(value)
+			continue
+		}
+		value, err := readBinaryField(reader)
+		if err != nil {
+			return fmt.Errorf("journal export field %s: %w", line, err)
+		}
+		entry[string(line)] = value
+	}
+}
+
+// readBinaryField reads the size and the value of a binary field of the export format, and its trailing line break.
+func readBinaryField(reader *bufio.Reader) (string, error) {
+	var size uint64
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return "", err
+	}
+	if size > maxFieldSize {
+		return "", fmt.Errorf("size %d exceeds %d bytes", size, maxFieldSize)
+	}
+	value := make([]byte, size+1)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", err
+	}
+	if value[size] != '\n' {
+		return "", errors.New("missing line break after the value")
+	}
+	return string(value[:size]), nil
+}
+
+// MapEntry maps a journal entry to a GELF message:
+//
+//   - MESSAGE becomes the short message and PRIORITY the level, as both use the syslog severities. Entries without
+//     valid priority are informational.
+//   - _SOURCE_REALTIME_TIMESTAMP, the time the entry was logged, or __REALTIME_TIMESTAMP, the time it was received by
+//     the journal, becomes the timestamp.
+//   - The other fields become additional fields, with the name lower-cased and its leading underscores trimmed, e.g.
+//     _SYSTEMD_UNIT becomes systemd_unit and SYSLOG_IDENTIFIER syslog_identifier. The fields of the journal
+//     starting with two underscores, e.g. the cursor and the monotonic timestamp, are omitted.
+func MapEntry(entry Entry) (message string, level int, timestamp time.Time, fields map[string]interface{}) {
+	level = gelflogger.Informational
+	if priority, err := strconv.Atoi(entry["PRIORITY"]); err == nil && priority >= gelflogger.Emergency && priority <= gelflogger.Debug {
+		level = priority
+	}
+	for _, name := range []string{"_SOURCE_REALTIME_TIMESTAMP", "__REALTIME_TIMESTAMP"} {
+		if micros, err := strconv.ParseInt(entry[name], 10, 64); err == nil {
+			timestamp = time.UnixMicro(micros)
+			break
+		}
+	}
+	fields = make(map[string]interface{}, len(entry))
+	for name, value := range entry {
+		switch {
+		case name == "MESSAGE":
+			message = value
+		case name == "PRIORITY", name == "_SOURCE_REALTIME_TIMESTAMP", strings.HasPrefix(name, "__"):
+		default:
+			fields[strings.ToLower(strings.TrimLeft(name, "_"))] = value
+		}
+	}
+	return message, level, timestamp, fields
+}
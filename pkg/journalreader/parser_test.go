@@ -0,0 +1,149 @@
+package journalreader_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/journalreader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// binaryField returns the field in the binary encoding of the journal export format.
+func binaryField(name, value string) string {
+	return name + "\n" + string(binary.LittleEndian.AppendUint64(nil, uint64(len(value)))) + value + "\n"
+}
+
+func TestParseExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  string
+		want    []journalreader.Entry
+		wantErr bool
+	}{
+		{
+			name:   "Text fields",
+			stream: "__CURSOR=s=1\nMESSAGE=started\nPRIORITY=6\n\n__CURSOR=s=2\nMESSAGE=a=b\n\n",
+			want: []journalreader.Entry{
+				{"__CURSOR": "s=1", "MESSAGE": "started", "PRIORITY": "6"},
+				{"__CURSOR": "s=2", "MESSAGE": "a=b"},
+			},
+		},
+		{
+			name:   "Binary field",
+			stream: "__CURSOR=s=1\n" + binaryField("MESSAGE", "panic\n\tmain.go:12") + "PRIORITY=2\n\n",
+			want:   []journalreader.Entry{{"__CURSOR": "s=1", "MESSAGE": "panic\n\tmain.go:12", "PRIORITY": "2"}},
+		},
+		{
+			name:   "Last entry without empty line",
+			stream: "\nMESSAGE=one\n\n\nMESSAGE=two\n",
+			want:   []journalreader.Entry{{"MESSAGE": "one"}, {"MESSAGE": "two"}},
+		},
+		{
+			name:    "Truncated binary field",
+			stream:  "MESSAGE=one\n\nMESSAGE\n\x10\x00\x00\x00\x00\x00\x00\x00short",
+			want:    []journalreader.Entry{{"MESSAGE": "one"}},
+			wantErr: true,
+		},
+		{
+			name:    "Oversized binary field",
+			stream:  "MESSAGE\n\xff\xff\xff\xff\xff\xff\xff\xff",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []journalreader.Entry
+			err := journalreader.ParseExport(strings.NewReader(tt.stream), func(entry journalreader.Entry) error {
+				got = append(got, entry)
+				return nil
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseExportHandleError(t *testing.T) {
+	errStop := errors.New("stop")
+	calls := 0
+	err := journalreader.ParseExport(strings.NewReader("MESSAGE=one\n\nMESSAGE=two\n\n"), func(journalreader.Entry) error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMapEntry(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         journalreader.Entry
+		wantMessage   string
+		wantLevel     int
+		wantTimestamp time.Time
+		wantFields    map[string]interface{}
+	}{
+		{
+			name: "Service entry",
+			entry: journalreader.Entry{
+				"__CURSOR":              "s=1",
+				"__REALTIME_TIMESTAMP":  "1700000000123456",
+				"__MONOTONIC_TIMESTAMP": "42",
+				"MESSAGE":               "connection refused",
+				"PRIORITY":              "3",
+				"SYSLOG_IDENTIFIER":     "nginx",
+				"_SYSTEMD_UNIT":         "nginx.service",
+				"_PID":                  "1234",
+				"_HOSTNAME":             "web-1",
+			},
+			wantMessage:   "connection refused",
+			wantLevel:     gelflogger.Error,
+			wantTimestamp: time.UnixMicro(1700000000123456),
+			wantFields: map[string]interface{}{
+				"syslog_identifier": "nginx",
+				"systemd_unit":      "nginx.service",
+				"pid":               "1234",
+				"hostname":          "web-1",
+			},
+		},
+		{
+			name: "Source timestamp preferred",
+			entry: journalreader.Entry{
+				"__REALTIME_TIMESTAMP":       "1700000000123456",
+				"_SOURCE_REALTIME_TIMESTAMP": "1700000000000001",
+				"MESSAGE":                    "started",
+			},
+			wantMessage:   "started",
+			wantLevel:     gelflogger.Informational,
+			wantTimestamp: time.UnixMicro(1700000000000001),
+			wantFields:    map[string]interface{}{},
+		},
+		{
+			name:        "Invalid priority",
+			entry:       journalreader.Entry{"MESSAGE": "odd", "PRIORITY": "9"},
+			wantMessage: "odd",
+			wantLevel:   gelflogger.Informational,
+			wantFields:  map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, level, timestamp, fields := journalreader.MapEntry(tt.entry)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantLevel, level)
+			require.True(t, tt.wantTimestamp.Equal(timestamp), "timestamp %v", timestamp)
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
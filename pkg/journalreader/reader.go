@@ -0,0 +1,233 @@
+package journalreader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// DefaultJournalctl is the journalctl command the journal is read with by default, see WithJournalctl.
+const DefaultJournalctl = "journalctl"
+
+// cursorSaveInterval is the minimum interval the cursor file is written in while entries are forwarded.
+const cursorSaveInterval = time.Second
+
+// Option configures a Reader, see New.
+type Option func(*Reader)
+
+// WithCursorFile persists the cursor of the last forwarded entry in the file at the given path, e.g.
+// /var/lib/gelf-journal/cursor, so a restarted Reader resumes after the last entry the previous one forwarded instead
+// of skipping the entries written in between. The cursor is written at most every second while entries are forwarded,
+// and when Run or Forward returns.
+func WithCursorFile(path string) Option {
+	return func(r *Reader) {
+		r.cursorPath = path
+	}
+}
+
+// WithMatches restricts the forwarded entries to the ones matching the given journalctl matches, e.g.
+// _SYSTEMD_UNIT=nginx.service. Matches of different fields must all match, matches of the same field are alternatives,
+// see journalctl(1).
+func WithMatches(matches ...string) Option {
+	return func(r *Reader) {
+		r.matches = append(r.matches, matches...)
+	}
+}
+
+// WithFromStart forwards all entries in the journal when the Reader starts without cursor. By default, only the entries
+// written after the start are forwarded.
+func WithFromStart() Option {
+	return func(r *Reader) {
+		r.fromStart = true
+	}
+}
+
+// WithFields adds the given fields to the messages of all entries, e.g. the name of the environment. The fields of the
+// entries take precedence.
+func WithFields(fields map[string]interface{}) Option {
+	return func(r *Reader) {
+		r.fields = fields
+	}
+}
+
+// WithJournalctl sets the journalctl command the journal is read with, DefaultJournalctl by default, e.g. the absolute
+// path of journalctl if it is not in the PATH.
+func WithJournalctl(command string) Option {
+	return func(r *Reader) {
+		r.journalctl = command
+	}
+}
+
+// Reader follows the systemd journal and forwards its entries through a Logger, replacing journalbeat for simple
+// setups. The journal is read with journalctl in the journal export format, see ParseExport, so neither cgo nor
+// libsystemd is needed, and the entries are mapped to GELF messages by MapEntry. The host of the messages is the one
+// of the Logger, the host which logged the entry is its hostname field.
+//
+// - logger: The Logger the entries are forwarded through.
+// - journalctl: The journalctl command, see WithJournalctl.
+// - cursorPath: The path of the cursor file, or an empty string, see WithCursorFile.
+// - matches: The journalctl matches of the forwarded entries, see WithMatches.
+// - fromStart: Whether the whole journal is forwarded without cursor, see WithFromStart.
+// - fields: The fields added to the messages, see WithFields.
+// - cursor: The cursor of the last forwarded entry, or an empty string.
+// - saved: The cursor last written to the cursor file, and the time it was written.
+type Reader struct {
+	logger     *gelflogger.Logger
+	journalctl string
+	cursorPath string
+	matches    []string
+	fromStart  bool
+	fields     map[string]interface{}
+
+	cursor  string
+	saved   string
+	savedAt time.Time
+}
+
+// New creates a Reader forwarding the journal entries through the Logger, see Run.
+//
+// Example usage:
+//
+//	r := journalreader.New(graylogLogger,
+//		journalreader.WithCursorFile("/var/lib/gelf-journal/cursor"), journalreader.WithMatches("_SYSTEMD_UNIT=nginx.service"))
+//	err := r.Run(ctx)
+func New(logger *gelflogger.Logger, opts ...Option) *Reader {
+	r := &Reader{logger: logger, journalctl: DefaultJournalctl}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run follows the journal with journalctl until the context is done, and returns nil then, or if journalctl exits
+// successfully. It resumes after the entry of the cursor file, see WithCursorFile. It returns an error if journalctl
+// fails or its output is corrupt, or the cursor file cannot be read or written.
+func (r *Reader) Run(ctx context.Context) error {
+	if err := r.loadCursor(); err != nil {
+		return err
+	}
+	args := []string{"--output=export", "--follow", "--no-pager"}
+	switch {
+	case r.cursor != "":
+		args = append(args, "--after-cursor="+r.cursor)
+	case !r.fromStart:
+		args = append(args, "--lines=0")
+	}
+	args = append(args, r.matches...)
+
+	cmd := exec.CommandContext(ctx, r.journalctl, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("journalreader: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("journalreader: starting journalctl: %w", err)
+	}
+	forwardErr := r.Forward(stdout)
+	if forwardErr != nil {
+		// Stops journalctl, which might block writing otherwise
+		_ = cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if forwardErr != nil {
+		return forwardErr
+	}
+	if waitErr != nil {
+		return fmt.Errorf("journalreader: journalctl: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Forward forwards the entries of the journal export stream, e.g. of journalctl -o export piped into the process,
+// until its end, and writes the cursor of the last forwarded entry to the cursor file, see WithCursorFile. It returns
+// an error if the stream is corrupt or the cursor file cannot be written. Errors of the Logger are dropped, as a single
+// failed message must not stop the forwarding; they are counted by the statistics of the Logger, see
+// gelflogger.Logger.Stats.
+func (r *Reader) Forward(stream io.Reader) error {
+	err := ParseExport(stream, func(entry Entry) error {
+		r.forward(entry)
+		if r.cursorPath != "" && time.Since(r.savedAt) >= cursorSaveInterval {
+			return r.saveCursor()
+		}
+		return nil
+	})
+	if saveErr := r.saveCursor(); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return fmt.Errorf("journalreader: %w", err)
+	}
+	return nil
+}
+
+// forward logs the entry and records its cursor.
+func (r *Reader) forward(entry Entry) {
+	message, level, timestamp, fields := MapEntry(entry)
+	for name, value := range r.fields {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	_ = r.logger.LogAt(level, timestamp, message, fields)
+	if cursor := entry.Cursor(); cursor != "" {
+		r.cursor = cursor
+	}
+}
+
+// loadCursor reads the cursor file, if any.
+func (r *Reader) loadCursor() error {
+	if r.cursorPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.cursorPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("journalreader: reading the cursor: %w", err)
+	}
+	r.cursor = strings.TrimSpace(string(data))
+	r.saved = r.cursor
+	return nil
+}
+
+// saveCursor writes the cursor to the cursor file, unless it is already written. It is written to a temporary file
+// renamed to the cursor file, so a crash never leaves a partial cursor.
+func (r *Reader) saveCursor() error {
+	if r.cursorPath == "" || r.cursor == r.saved {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.cursorPath), filepath.Base(r.cursorPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(r.cursor + "\n"); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), r.cursorPath); err != nil {
+		return err
+	}
+	r.saved = r.cursor
+	r.savedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,171 @@
+package journalreader_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/journalreader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decoded returns the messages sent through the transport decoded.
+func decoded(t *testing.T, transport *helper.RecordingTransport) []map[string]interface{} {
+	messages := transport.Messages()
+	decoded := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		var gelfMsg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(message), &gelfMsg))
+		decoded = append(decoded, gelfMsg)
+	}
+	return decoded
+}
+
+const stream = "__CURSOR=s=1\n__REALTIME_TIMESTAMP=1700000000000000\nMESSAGE=started\nPRIORITY=6\n_SYSTEMD_UNIT=nginx.service\n\n" +
+	"__CURSOR=s=2\n__REALTIME_TIMESTAMP=1700000001000000\nMESSAGE=connection refused\nPRIORITY=3\n_SYSTEMD_UNIT=nginx.service\n\n"
+
+func TestForward(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	cursorPath := filepath.Join(t.TempDir(), "cursor")
+	r := journalreader.New(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing),
+		journalreader.WithCursorFile(cursorPath), journalreader.WithFields(map[string]interface{}{"env": "prod", "systemd_unit": "ignored"}))
+
+	require.NoError(t, r.Forward(strings.NewReader(stream)))
+
+	sent := decoded(t, transport)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "started", sent[0]["short_message"])
+	assert.Equal(t, float64(gelflogger.Informational), sent[0]["level"])
+	assert.Equal(t, 1700000000.0, sent[0]["timestamp"])
+	assert.Equal(t, "connection refused", sent[1]["short_message"])
+	assert.Equal(t, float64(gelflogger.Error), sent[1]["level"])
+	assert.Equal(t, "nginx.service", sent[1]["_systemd_unit"])
+	assert.Equal(t, "prod", sent[1]["_env"])
+
+	cursor, err := os.ReadFile(cursorPath)
+	require.NoError(t, err)
+	assert.Equal(t, "s=2\n", string(cursor))
+}
+
+func TestForwardCorruptStream(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	cursorPath := filepath.Join(t.TempDir(), "cursor")
+	r := journalreader.New(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), journalreader.WithCursorFile(cursorPath))
+
+	assert.Error(t, r.Forward(strings.NewReader("__CURSOR=s=1\nMESSAGE=one\n\nMESSAGE\n\x10")))
+
+	// The cursor of the forwarded entries is kept
+	assert.Len(t, transport.Messages(), 1)
+	cursor, err := os.ReadFile(cursorPath)
+	require.NoError(t, err)
+	assert.Equal(t, "s=1\n", string(cursor))
+}
+
+// fakeJournalctl writes a script to the directory which records its arguments in the file args and writes the stream
+// to its standard output, and returns its path.
+func fakeJournalctl(t *testing.T, dir, stream string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("journalctl is faked by a shell script")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stream"), []byte(stream), 0o600))
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat " + filepath.Join(dir, "stream") + "\n"
+	path := filepath.Join(dir, "journalctl")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		cursor     string
+		opts       []journalreader.Option
+		wantArgs   string
+		wantCursor string
+	}{
+		{
+			name:       "New entries",
+			wantArgs:   "--output=export --follow --no-pager --lines=0 _SYSTEMD_UNIT=nginx.service",
+			wantCursor: "s=2\n",
+		},
+		{
+			name:       "From start",
+			opts:       []journalreader.Option{journalreader.WithFromStart()},
+			wantArgs:   "--output=export --follow --no-pager _SYSTEMD_UNIT=nginx.service",
+			wantCursor: "s=2\n",
+		},
+		{
+			name:       "Resumed after the cursor",
+			cursor:     "s=0\n",
+			wantArgs:   "--output=export --follow --no-pager --after-cursor=s=0 _SYSTEMD_UNIT=nginx.service",
+			wantCursor: "s=2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cursorPath := filepath.Join(dir, "cursor")
+			if tt.cursor != "" {
+				require.NoError(t, os.WriteFile(cursorPath, []byte(tt.cursor), 0o600))
+			}
+			transport := &helper.RecordingTransport{}
+			opts := append([]journalreader.Option{
+				journalreader.WithJournalctl(fakeJournalctl(t, dir, stream)),
+				journalreader.WithCursorFile(cursorPath),
+				journalreader.WithMatches("_SYSTEMD_UNIT=nginx.service"),
+			}, tt.opts...)
+			r := journalreader.New(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), opts...)
+
+			require.NoError(t, r.Run(context.Background()))
+
+			args, err := os.ReadFile(filepath.Join(dir, "args"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantArgs, strings.TrimSpace(string(args)))
+			assert.Len(t, transport.Messages(), 2)
+			cursor, err := os.ReadFile(cursorPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCursor, string(cursor))
+		})
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "journalctl")
+	if runtime.GOOS == "windows" {
+		t.Skip("journalctl is faked by a shell script")
+	}
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho 'No journal files were found.' >&2\nexit 1\n"), 0o700))
+	r := journalreader.New(gelflogger.NewLoggerWithTransport(&helper.RecordingTransport{}, helper.ProcessNothing), journalreader.WithJournalctl(script))
+
+	err := r.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No journal files were found.")
+
+	r = journalreader.New(gelflogger.NewLoggerWithTransport(&helper.RecordingTransport{}, helper.ProcessNothing),
+		journalreader.WithJournalctl(filepath.Join(dir, "missing")))
+	assert.Error(t, r.Run(context.Background()))
+}
+
+func TestRunCanceled(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "journalctl")
+	if runtime.GOOS == "windows" {
+		t.Skip("journalctl is faked by a shell script")
+	}
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 10\n"), 0o700))
+	r := journalreader.New(gelflogger.NewLoggerWithTransport(&helper.RecordingTransport{}, helper.ProcessNothing), journalreader.WithJournalctl(script))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, r.Run(ctx))
+}
@@ -0,0 +1,131 @@
+package journaltransport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/journal"
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// ErrJournalUnavailable is returned by New if the systemd journal socket cannot be reached, e.g. on hosts without systemd.
+var ErrJournalUnavailable = errors.New("systemd journal is not available")
+
+// Entry is a GELF message mapped to the fields of a systemd journal entry.
+type Entry struct {
+	Message  string
+	Priority journal.Priority
+	Fields   map[string]string
+}
+
+// Transport writes GELF messages to the local systemd journal. It is meant as fallback of a Logger on Linux hosts
+// (see gelflogger.WithFallback), keeping the messages queryable with journalctl while Graylog is unreachable:
+//
+//	journalctl GELF_HOST=web-1 GELF_REQUEST_ID=abc
+type Transport struct{}
+
+var _ gelflogger.Transport = (*Transport)(nil)
+
+// New creates a Transport writing to the systemd journal. It returns ErrJournalUnavailable if the journal is not
+// available on the host.
+//
+// Example usage:
+//
+//	fallback, err := journaltransport.New()
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger, err := gelflogger.NewLogger("graylog.example.com:12201", gelflogger.WithProcessor(zerologger.ProcessZerologFields), gelflogger.WithFallback(fallback))
+func New() (*Transport, error) {
+	if !journal.Enabled() {
+		return nil, ErrJournalUnavailable
+	}
+	return &Transport{}, nil
+}
+
+// Send maps the GELF message to a journal entry, see MapMessage, and writes it to the journal.
+func (t *Transport) Send(message []byte) error {
+	entry, err := MapMessage(message)
+	if err != nil {
+		return err
+	}
+	return journal.Send(entry.Message, entry.Priority, entry.Fields)
+}
+
+// Close is a no-op, the connection to the journal is shared by the process.
+func (t *Transport) Close() error {
+	return nil
+}
+
+// MapMessage maps a GELF message to a journal entry:
+// - short_message becomes the MESSAGE and level the PRIORITY, as both use the syslog severities.
+// - full_message, host, facility and timestamp become GELF_FULL_MESSAGE, GELF_HOST, SYSLOG_IDENTIFIER and GELF_TIMESTAMP.
+// - additional fields become GELF_<NAME>, with the name upper-cased and characters not allowed in journal field names
+// replaced by underscores, e.g. _request-id becomes GELF_REQUEST_ID.
+func MapMessage(message []byte) (Entry, error) {
+	var gelfMsg map[string]interface{}
+	if err := json.Unmarshal(message, &gelfMsg); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Priority: journal.PriInfo, Fields: make(map[string]string, len(gelfMsg))}
+	for k, v := range gelfMsg {
+		switch k {
+		case "version":
+		case "short_message":
+			entry.Message = fieldValue(v)
+		case "level":
+			if level, ok := v.(float64); ok && level >= 0 && level <= 7 {
+				entry.Priority = journal.Priority(level)
+			}
+		case "full_message":
+			if value := fieldValue(v); value != "" {
+				entry.Fields["GELF_FULL_MESSAGE"] = value
+			}
+		case "host":
+			entry.Fields["GELF_HOST"] = fieldValue(v)
+		case "facility":
+			entry.Fields["SYSLOG_IDENTIFIER"] = fieldValue(v)
+		case "timestamp":
+			entry.Fields["GELF_TIMESTAMP"] = fieldValue(v)
+		default:
+			entry.Fields["GELF_"+journalFieldName(strings.TrimPrefix(k, "_"))] = fieldValue(v)
+		}
+	}
+	return entry, nil
+}
+
+// journalFieldName upper-cases the name and replaces all characters except A-Z, 0-9 and _ with underscores.
+func journalFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// fieldValue formats a decoded JSON value as journal field value.
+func fieldValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,70 @@
+package journaltransport_test
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/journal"
+	"github.com/jame-developer/gelf-logger/pkg/journaltransport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    journaltransport.Entry
+		wantErr bool
+	}{
+		{
+			name:    "All fields",
+			message: `{"version":"1.1","host":"web-1","short_message":"disk full","full_message":"disk full\non /var","timestamp":1700000000.5,"level":3,"facility":"app","_request-id":"abc","_attempt":2}`,
+			want: journaltransport.Entry{
+				Message:  "disk full",
+				Priority: journal.PriErr,
+				Fields: map[string]string{
+					"GELF_HOST":         "web-1",
+					"GELF_FULL_MESSAGE": "disk full\non /var",
+					"GELF_TIMESTAMP":    "1700000000.5",
+					"SYSLOG_IDENTIFIER": "app",
+					"GELF_REQUEST_ID":   "abc",
+					"GELF_ATTEMPT":      "2",
+				},
+			},
+		},
+		{
+			name:    "Missing level defaults to info",
+			message: `{"short_message":"hello","full_message":""}`,
+			want: journaltransport.Entry{
+				Message:  "hello",
+				Priority: journal.PriInfo,
+				Fields:   map[string]string{},
+			},
+		},
+		{
+			name:    "Invalid JSON",
+			message: `{"short_message":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := journaltransport.MapMessage([]byte(tt.message))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := journaltransport.New()
+	if journal.Enabled() {
+		assert.NoError(t, err)
+	} else {
+		assert.ErrorIs(t, err, journaltransport.ErrJournalUnavailable)
+	}
+}
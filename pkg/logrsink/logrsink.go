@@ -0,0 +1,137 @@
+package logrsink
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/go-logr/logr"
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+const (
+	// NameField is the field the name of the logger is sent as, the names of WithName joined by slashes like in
+	// logr's funcr, e.g. "manager/deployment-controller".
+	NameField = "logger"
+	// VerbosityField is the field the V-level of Info records is sent as.
+	VerbosityField = "v"
+	// ErrorField is the field the error of Error records is sent as.
+	ErrorField = "error"
+	// missingValue is the value of a key without value, like in logr's funcr.
+	missingValue = "<no-value>"
+)
+
+// LogSink is a logr.LogSink sending the records with a gelflogger.Logger, e.g. for the operators of controller-runtime:
+//
+//	ctrl.SetLogger(logrsink.NewLogger(graylogLogger, 1))
+//
+// Info records of V-level 0 are sent as informational, the records of higher V-levels, which logr uses for debugging
+// details, as debug, with the V-level as "v" field. Error records are sent as error, with the error as "error" field,
+// sent as its message with its stack trace appended to the full message, see gelflogger.Logger.Log. The key value
+// pairs of the records and of WithValues are sent as additional fields, the names of WithName as "logger" field.
+//
+// The key value pairs of a record take precedence over those of WithValues. Values implementing logr.Marshaler are
+// sent as the result of MarshalLog. A LogSink is safe for concurrent use, like the Logger.
+type LogSink struct {
+	logger    *gelflogger.Logger
+	verbosity int
+	// name is the name of WithName, or empty.
+	name string
+	// fields are the values of WithValues. They are never modified after WithValues, as the LogSinks derived from the
+	// LogSink share them.
+	fields map[string]interface{}
+}
+
+// NewLogSink returns a LogSink sending the records with the given Logger. Info records of V-levels above verbosity are
+// dropped, so 0 sends only the records of logr.Logger.Info, and e.g. 1 also those of V(1).Info.
+func NewLogSink(logger *gelflogger.Logger, verbosity int) *LogSink {
+	return &LogSink{logger: logger, verbosity: verbosity}
+}
+
+// NewLogger returns a logr.Logger with a LogSink sending the records with the given Logger, see NewLogSink.
+func NewLogger(logger *gelflogger.Logger, verbosity int) logr.Logger {
+	return logr.New(NewLogSink(logger, verbosity))
+}
+
+// Init is called by logr.New. The LogSink does not log the caller, so it ignores the call depth.
+func (s *LogSink) Init(logr.RuntimeInfo) {}
+
+// Enabled reports whether Info records of the V-level are sent, see NewLogSink.
+func (s *LogSink) Enabled(level int) bool {
+	return level <= s.verbosity
+}
+
+// Info sends the Info record of the V-level. Errors of the Logger are dropped, as logr has no way to report them;
+// they are counted by the statistics of the Logger, see gelflogger.Logger.Stats.
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	graylogLevel := gelflogger.Informational
+	if level > 0 {
+		graylogLevel = gelflogger.Debug
+	}
+	fields := s.recordFields(keysAndValues)
+	fields[VerbosityField] = level
+	_ = s.logger.LogAt(graylogLevel, time.Time{}, msg, fields)
+}
+
+// Error sends the Error record. A nil error is sent without "error" field. Errors of the Logger are dropped like by
+// Info.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := s.recordFields(keysAndValues)
+	if err != nil {
+		fields[ErrorField] = err
+	}
+	_ = s.logger.LogAt(gelflogger.Error, time.Time{}, msg, fields)
+}
+
+// WithValues returns a LogSink adding the given key value pairs to every record.
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	derived := *s
+	derived.fields = maps.Clone(s.fields)
+	if derived.fields == nil {
+		derived.fields = make(map[string]interface{}, len(keysAndValues)/2)
+	}
+	addKeysAndValues(derived.fields, keysAndValues)
+	return &derived
+}
+
+// WithName returns a LogSink with the given name appended to its name, separated by a slash.
+func (s *LogSink) WithName(name string) logr.LogSink {
+	derived := *s
+	if derived.name == "" {
+		derived.name = name
+	} else {
+		derived.name += "/" + name
+	}
+	return &derived
+}
+
+// recordFields returns the fields of a record with the given key value pairs.
+func (s *LogSink) recordFields(keysAndValues []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(s.fields)+len(keysAndValues)/2+2)
+	maps.Copy(fields, s.fields)
+	addKeysAndValues(fields, keysAndValues)
+	if s.name != "" {
+		fields[NameField] = s.name
+	}
+	return fields
+}
+
+// addKeysAndValues adds the key value pairs to the fields. Keys other than strings are converted by fmt.Sprint, a
+// key without value gets the value "<no-value>" and the values implementing logr.Marshaler are replaced by the
+// result of MarshalLog, like in logr's funcr.
+func addKeysAndValues(fields map[string]interface{}, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var value interface{} = missingValue
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		if marshaler, ok := value.(logr.Marshaler); ok {
+			value = marshaler.MarshalLog()
+		}
+		fields[key] = value
+	}
+}
@@ -0,0 +1,119 @@
+package logrsink_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/logrsink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// maskedToken is a logr.Marshaler hiding its value.
+type maskedToken string
+
+func (maskedToken) MarshalLog() interface{} { return "***" }
+
+func TestLogSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     func(logger logr.Logger)
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name: "Info",
+			log: func(logger logr.Logger) {
+				logger.Info("reconciled", "namespace", "default", "replicas", 3)
+			},
+			want: map[string]interface{}{
+				"short_message": "reconciled",
+				"level":         float64(6),
+				"_v":            float64(0),
+				"_namespace":    "default",
+				"_replicas":     float64(3),
+			},
+			missing: []string{"_logger"},
+		},
+		{
+			name: "V-level",
+			log: func(logger logr.Logger) {
+				logger.V(1).Info("cache synced")
+			},
+			want: map[string]interface{}{"level": float64(7), "_v": float64(1)},
+		},
+		{
+			name: "Error",
+			log: func(logger logr.Logger) {
+				logger.Error(errors.New("conflict"), "update failed", "object", "web")
+			},
+			want:    map[string]interface{}{"level": float64(3), "_error": "conflict", "_object": "web"},
+			missing: []string{"_v"},
+		},
+		{
+			name: "Error without error",
+			log: func(logger logr.Logger) {
+				logger.Error(nil, "invalid spec")
+			},
+			want:    map[string]interface{}{"level": float64(3)},
+			missing: []string{"_error"},
+		},
+		{
+			name: "Names and values",
+			log: func(logger logr.Logger) {
+				logger.WithName("manager").WithValues("controller", "deployment").WithName("reconciler").Info("started", "controller", "replicaset")
+			},
+			want: map[string]interface{}{"_logger": "manager/reconciler", "_controller": "replicaset"},
+		},
+		{
+			name: "Odd key values and marshalers",
+			log: func(logger logr.Logger) {
+				logger.Info("login", "token", maskedToken("secret"), 42, "answer", "dangling")
+			},
+			want: map[string]interface{}{"_token": "***", "_42": "answer", "_dangling": "<no-value>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			tt.log(logrsink.NewLogger(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), 1))
+
+			require.Len(t, transport.Messages(), 1)
+			var gelfMsg map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(transport.Messages()[0]), &gelfMsg))
+			for key, want := range tt.want {
+				assert.Equal(t, want, gelfMsg[key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, gelfMsg, key)
+			}
+		})
+	}
+}
+
+func TestLogSinkVerbosity(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := logrsink.NewLogger(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), 1)
+	logger.V(2).Info("dropped")
+	logger.V(1).Info("sent")
+	assert.False(t, logger.V(2).Enabled())
+	require.Len(t, transport.Messages(), 1)
+	assert.Contains(t, transport.Messages()[0], `"short_message":"sent"`)
+}
+
+func TestLogSinkWithValuesIsolated(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	base := logrsink.NewLogger(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), 0).WithValues("component", "base")
+	base.WithValues("request", "r-1").Info("child")
+	base.Info("parent")
+
+	require.Len(t, transport.Messages(), 2)
+	assert.Contains(t, transport.Messages()[0], `"_request":"r-1"`)
+	assert.NotContains(t, transport.Messages()[1], `"_request"`)
+	assert.Contains(t, transport.Messages()[1], `"_component":"base"`)
+}
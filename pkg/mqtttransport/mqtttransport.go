@@ -0,0 +1,80 @@
+package mqtttransport
+
+import (
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// QoS is the MQTT quality of service level used to publish the GELF messages.
+type QoS byte
+
+const (
+	AtMostOnce  QoS = 0 // Fire and forget, messages can get lost.
+	AtLeastOnce QoS = 1 // Acknowledged delivery, messages can be duplicated.
+	ExactlyOnce QoS = 2 // Assured delivery, every message arrives exactly once.
+)
+
+// DefaultPublishTimeout is the time a publish waits for the completion of the MQTT flow of its QoS level.
+const DefaultPublishTimeout = 5 * time.Second
+
+// PublishFunc publishes the payload to the topic with the given QoS level.
+// It allows plugging in clients other than paho.mqtt.golang, e.g. an MQTT 5 client of github.com/eclipse/paho.golang.
+type PublishFunc func(topic string, qos QoS, payload []byte) error
+
+// Transport publishes GELF messages to an MQTT topic.
+//
+// The MQTT session is owned by the caller, so an existing client of the application can be reused for log shipping.
+// Closing the Transport does not disconnect the client.
+type Transport struct {
+	publish PublishFunc
+	topic   string
+	qos     QoS
+}
+
+var _ gelflogger.Transport = (*Transport)(nil)
+
+// New creates a Transport publishing GELF messages with the given MQTT 3.1.1 client to the topic.
+// Every publish waits up to DefaultPublishTimeout for the completion of the flow of the QoS level.
+//
+// Example usage:
+//
+//	opts := mqtt.NewClientOptions().AddBroker("tcp://broker.example.com:1883").SetClientID("edge-agent")
+//	client := mqtt.NewClient(opts)
+//	if token := client.Connect(); token.Wait() && token.Error() != nil {
+//	  // handle error
+//	}
+//	logger := gelflogger.NewLoggerWithTransport(mqtttransport.New(client, "logs/gelf", mqtttransport.AtLeastOnce), zerologger.ProcessZerologFields)
+func New(client mqtt.Client, topic string, qos QoS) *Transport {
+	return NewWithPublishFunc(func(topic string, qos QoS, payload []byte) error {
+		token := client.Publish(topic, byte(qos), false, payload)
+		if !token.WaitTimeout(DefaultPublishTimeout) {
+			return fmt.Errorf("publishing to MQTT topic %q timed out after %s", topic, DefaultPublishTimeout)
+		}
+		return token.Error()
+	}, topic, qos)
+}
+
+// NewWithPublishFunc creates a Transport publishing GELF messages with the given PublishFunc to the topic.
+//
+// Example with an MQTT 5 client of github.com/eclipse/paho.golang:
+//
+//	transport := mqtttransport.NewWithPublishFunc(func(topic string, qos mqtttransport.QoS, payload []byte) error {
+//		_, err := cm.Publish(context.Background(), &paho.Publish{Topic: topic, QoS: byte(qos), Payload: payload})
+//		return err
+//	}, "logs/gelf", mqtttransport.AtLeastOnce)
+func NewWithPublishFunc(publish PublishFunc, topic string, qos QoS) *Transport {
+	return &Transport{publish: publish, topic: topic, qos: qos}
+}
+
+// Send publishes the message to the topic of the Transport.
+func (t *Transport) Send(message []byte) error {
+	return t.publish(t.topic, t.qos, message)
+}
+
+// Close is a no-op, the MQTT client stays connected since it is owned by the caller.
+func (t *Transport) Close() error {
+	return nil
+}
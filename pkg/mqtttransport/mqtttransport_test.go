@@ -0,0 +1,87 @@
+package mqtttransport_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/mqtttransport"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockToken is a completed mqtt.Token carrying the given error.
+type mockToken struct {
+	err error
+}
+
+func (m mockToken) Wait() bool                     { return true }
+func (m mockToken) WaitTimeout(time.Duration) bool { return true }
+func (m mockToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+func (m mockToken) Error() error { return m.err }
+
+// mockClient records the publishes of the transport, all other methods of mqtt.Client are not implemented.
+type mockClient struct {
+	mqtt.Client
+	topic   string
+	qos     byte
+	payload string
+	err     error
+}
+
+func (m *mockClient) Publish(topic string, qos byte, _ bool, payload interface{}) mqtt.Token {
+	m.topic = topic
+	m.qos = qos
+	m.payload = string(payload.([]byte))
+	return mockToken{err: m.err}
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		qos        mqtttransport.QoS
+		publishErr error
+		wantErr    bool
+	}{
+		{
+			name:    "QoS 0",
+			qos:     mqtttransport.AtMostOnce,
+			wantErr: false,
+		},
+		{
+			name:    "QoS 2",
+			qos:     mqtttransport.ExactlyOnce,
+			wantErr: false,
+		},
+		{
+			name:       "Publish failure",
+			qos:        mqtttransport.AtLeastOnce,
+			publishErr: errors.New("not connected"),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{err: tt.publishErr}
+			logger := gelflogger.NewLoggerWithTransport(mqtttransport.New(client, "logs/gelf", tt.qos), func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			})
+
+			err := logger.Log("hello mqtt", map[string]interface{}{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "logs/gelf", client.topic)
+			assert.Equal(t, byte(tt.qos), client.qos)
+			assert.Contains(t, client.payload, `"short_message":"hello mqtt"`)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package natstransport
+
+import (
+	"context"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultPublishTimeout is the time a JetStream publish waits for the acknowledgement of the stream.
+const DefaultPublishTimeout = 5 * time.Second
+
+// Transport publishes GELF messages onto a NATS subject.
+//
+// With core NATS the messages are published fire-and-forget, with JetStream every publish waits for the
+// acknowledgement of the stream bound to the subject, so messages are persisted before Send returns.
+// The NATS connection is owned by the caller; closing the Transport only flushes pending messages.
+type Transport struct {
+	conn           *nats.Conn
+	js             jetstream.JetStream
+	subject        string
+	PublishTimeout time.Duration
+}
+
+var _ gelflogger.Transport = (*Transport)(nil)
+
+// New creates a Transport publishing GELF messages with core NATS onto the given subject.
+//
+// Example usage:
+//
+//	nc, err := nats.Connect("nats://edge.example.com:4222")
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger := gelflogger.NewLoggerWithTransport(natstransport.New(nc, "logs.gelf"), zerologger.ProcessZerologFields)
+func New(conn *nats.Conn, subject string) *Transport {
+	return &Transport{conn: conn, subject: subject, PublishTimeout: DefaultPublishTimeout}
+}
+
+// NewJetStream creates a Transport publishing GELF messages with JetStream onto the given subject.
+// A stream capturing the subject must exist, otherwise every publish fails with jetstream.ErrNoStreamResponse.
+func NewJetStream(conn *nats.Conn, subject string) (*Transport, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &Transport{conn: conn, js: js, subject: subject, PublishTimeout: DefaultPublishTimeout}, nil
+}
+
+// Send publishes the message onto the subject of the Transport.
+func (t *Transport) Send(message []byte) error {
+	if t.js == nil {
+		return t.conn.Publish(t.subject, message)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), t.PublishTimeout)
+	defer cancel()
+	_, err := t.js.Publish(ctx, t.subject, message)
+	return err
+}
+
+// Close flushes the messages buffered by the NATS connection. The connection itself stays open.
+func (t *Transport) Close() error {
+	if t.conn.IsClosed() {
+		return nil
+	}
+	return t.conn.FlushTimeout(t.PublishTimeout)
+}
@@ -0,0 +1,125 @@
+package natstransport_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/natstransport"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedMessage struct {
+	subject string
+	payload string
+}
+
+// startMockNATSServer starts a minimal NATS server speaking just enough of the client protocol for publishing.
+// Publishes with a reply subject are answered with a JetStream acknowledgement.
+func startMockNATSServer(t *testing.T) (string, <-chan publishedMessage) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	published := make(chan publishedMessage, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveNATS(conn, published)
+		}
+	}()
+	return "nats://" + l.Addr().String(), published
+}
+
+func serveNATS(conn net.Conn, published chan<- publishedMessage) {
+	defer func() { _ = conn.Close() }()
+	_, _ = fmt.Fprint(conn, `INFO {"server_id":"mock","version":"2.10.0","proto":1,"headers":true,"max_payload":1048576}`+"\r\n")
+	reader := bufio.NewReader(conn)
+	inboxSid := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			_, _ = fmt.Fprint(conn, "PONG\r\n")
+		case "SUB":
+			inboxSid = args[len(args)-1]
+		case "PUB":
+			size, _ := strconv.Atoi(args[len(args)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			published <- publishedMessage{subject: args[1], payload: string(payload[:size])}
+			if len(args) == 4 {
+				ack := `{"stream":"LOGS","seq":1}`
+				_, _ = fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", args[2], inboxSid, len(ack), ack)
+			}
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	url, published := startMockNATSServer(t)
+
+	tests := []struct {
+		name      string
+		jetStream bool
+	}{
+		{
+			name:      "Core NATS",
+			jetStream: false,
+		},
+		{
+			name:      "JetStream",
+			jetStream: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nc, err := nats.Connect(url)
+			require.NoError(t, err)
+			defer nc.Close()
+
+			transport := natstransport.New(nc, "logs.gelf")
+			if tt.jetStream {
+				transport, err = natstransport.NewJetStream(nc, "logs.gelf")
+				require.NoError(t, err)
+			}
+
+			logger := gelflogger.NewLoggerWithTransport(transport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			})
+			gw := &gelflogger.GelfWriter{Logger: logger}
+			_, err = gw.Write([]byte(`{"message":"hello nats"}`))
+			assert.NoError(t, err)
+			assert.NoError(t, transport.Close())
+
+			select {
+			case msg := <-published:
+				assert.Equal(t, "logs.gelf", msg.subject)
+				assert.Contains(t, msg.payload, `"short_message":"hello nats"`)
+			case <-time.After(time.Second):
+				t.Fatal("message was not published")
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package otelexporter
+
+import (
+	"context"
+	"errors"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// The fields the records are sent with by the Exporter, next to the fields of their attributes and resource.
+const (
+	// ScopeField is the field of the name of the instrumentation scope, usually the package emitting the record.
+	ScopeField = "otel.scope.name"
+	// ScopeVersionField is the field of the version of the instrumentation scope.
+	ScopeVersionField = "otel.scope.version"
+	// SeverityTextField is the field of the severity text of the record, e.g. the level name of the bridged logging
+	// library.
+	SeverityTextField = "severity_text"
+	// EventNameField is the field of the event name of the record.
+	EventNameField = "event_name"
+	// TraceIDField is the field of the trace ID of the record.
+	TraceIDField = "trace_id"
+	// SpanIDField is the field of the span ID of the record.
+	SpanIDField = "span_id"
+)
+
+// Exporter is an sdklog.Exporter sending the records of the OpenTelemetry logging API as GELF messages with a
+// gelflogger.Logger, so applications and libraries logging through OpenTelemetry, e.g. by its bridges of slog, zap or
+// logrus, ship their logs to Graylog:
+//
+//   - The body of the record is sent as short message, other bodies than strings in their text form. Records without
+//     body are sent with their event name.
+//   - The timestamp of the record is sent as timestamp, or its observed timestamp if it has none.
+//   - The severity number of the record is sent as Graylog (Syslog) level, see ConvertSeverityToGraylog, and its
+//     severity text as SeverityTextField.
+//   - The attributes of the record and of its resource, e.g. service.name, are sent as additional fields, the
+//     attributes of the record taking precedence. Maps are flattened into dotted field names, e.g. "id" of the map
+//     "user" is sent as "_user.id".
+//   - The instrumentation scope, the event name, the trace ID and the span ID are sent as ScopeField,
+//     ScopeVersionField, EventNameField, TraceIDField and SpanIDField.
+//   - Slice attributes are sent as text, as JSON arrays.
+type Exporter struct {
+	logger *gelflogger.Logger
+}
+
+var _ sdklog.Exporter = (*Exporter)(nil)
+
+// NewExporter returns an Exporter sending the records with the given Logger. It is usually wrapped by a
+// sdklog.BatchProcessor, unless the Logger is asynchronous already, see gelflogger.WithAsync.
+//
+// Example usage:
+//
+//	provider := sdklog.NewLoggerProvider(
+//		sdklog.WithResource(res),
+//		sdklog.WithProcessor(sdklog.NewBatchProcessor(otelexporter.NewExporter(graylogLogger))),
+//	)
+//	global.SetLoggerProvider(provider)
+func NewExporter(logger *gelflogger.Logger) *Exporter {
+	return &Exporter{logger: logger}
+}
+
+// Export sends the records. It returns the errors of gelflogger.Logger.LogAt, which the SDK reports to the error
+// handler of OpenTelemetry, and sends no further records once the context is done.
+func (e *Exporter) Export(ctx context.Context, records []sdklog.Record) error {
+	var errs []error
+	for i := range records {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		record := &records[i]
+		timestamp := record.Timestamp()
+		if timestamp.IsZero() {
+			timestamp = record.ObservedTimestamp()
+		}
+		if err := e.logger.LogAt(ConvertSeverityToGraylog(record.Severity()), timestamp, message(record), fields(record)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ForceFlush flushes the Logger, see gelflogger.Logger.Flush.
+func (e *Exporter) ForceFlush(ctx context.Context) error {
+	return e.logger.Flush(ctx)
+}
+
+// Shutdown flushes the Logger, see gelflogger.Logger.Flush. It does not close the Logger, which may be used besides
+// the Exporter, so its owner closes it.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.logger.Flush(ctx)
+}
+
+// ConvertSeverityToGraylog returns the Graylog (Syslog) level of the given severity number of OpenTelemetry: Trace and
+// Debug as debug, Info as informational, Warn as warning, Error as error, and Fatal as critical. Records without
+// severity are sent as informational.
+func ConvertSeverityToGraylog(severity log.Severity) int {
+	switch {
+	case severity >= log.SeverityFatal1:
+		return gelflogger.Critical
+	case severity >= log.SeverityError1:
+		return gelflogger.Error
+	case severity >= log.SeverityWarn1:
+		return gelflogger.Warning
+	case severity >= log.SeverityInfo1 || severity == log.SeverityUndefined:
+		return gelflogger.Informational
+	default:
+		return gelflogger.Debug
+	}
+}
+
+// message returns the short message of the record: its body, or its event name if it has no body.
+func message(record *sdklog.Record) string {
+	body := record.Body()
+	switch body.Kind() {
+	case log.KindEmpty:
+		return record.EventName()
+	case log.KindString:
+		return body.AsString()
+	default:
+		return body.String()
+	}
+}
+
+// fields returns the additional fields of the record.
+func fields(record *sdklog.Record) map[string]interface{} {
+	fields := make(map[string]interface{}, record.AttributesLen()+8)
+	if res := record.Resource(); res != nil {
+		for _, attr := range res.Attributes() {
+			if attr.Valid() {
+				fields[string(attr.Key)] = attr.Value.AsInterface()
+			}
+		}
+	}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		addValue(fields, kv.Key, kv.Value)
+		return true
+	})
+	if scope := record.InstrumentationScope(); scope.Name != "" {
+		fields[ScopeField] = scope.Name
+		if scope.Version != "" {
+			fields[ScopeVersionField] = scope.Version
+		}
+	}
+	if text := record.SeverityText(); text != "" {
+		fields[SeverityTextField] = text
+	}
+	if name := record.EventName(); name != "" {
+		fields[EventNameField] = name
+	}
+	if traceID := record.TraceID(); traceID.IsValid() {
+		fields[TraceIDField] = traceID.String()
+	}
+	if spanID := record.SpanID(); spanID.IsValid() {
+		fields[SpanIDField] = spanID.String()
+	}
+	return fields
+}
+
+// addValue adds the attribute value with the given name to the fields, flattening maps into dotted field names.
+func addValue(fields map[string]interface{}, name string, value log.Value) {
+	if value.Kind() == log.KindMap {
+		for _, kv := range value.AsMap() {
+			addValue(fields, name+"."+kv.Key, kv.Value)
+		}
+		return
+	}
+	if value.Kind() != log.KindEmpty {
+		fields[name] = logValue(value)
+	}
+}
+
+// logValue returns the Go value of a log attribute, which the Logger encodes as additional field: numbers as they are,
+// other values as text, e.g. slices as JSON arrays.
+func logValue(value log.Value) interface{} {
+	switch value.Kind() {
+	case log.KindBool:
+		return value.AsBool()
+	case log.KindFloat64:
+		return value.AsFloat64()
+	case log.KindInt64:
+		return value.AsInt64()
+	case log.KindString:
+		return value.AsString()
+	case log.KindBytes:
+		return value.AsBytes()
+	case log.KindSlice:
+		values := value.AsSlice()
+		slice := make([]interface{}, len(values))
+		for i, v := range values {
+			slice[i] = logValue(v)
+		}
+		return slice
+	case log.KindMap:
+		kvs := value.AsMap()
+		object := make(map[string]interface{}, len(kvs))
+		for _, kv := range kvs {
+			object[kv.Key] = logValue(kv.Value)
+		}
+		return object
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package otelexporter_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/otelexporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExporter(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	timestamp := time.Unix(1700000000, 500000000)
+
+	tests := []struct {
+		name    string
+		record  func() log.Record
+		ctx     context.Context
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name: "Body, severity, timestamp and attributes",
+			record: func() log.Record {
+				var record log.Record
+				record.SetBody(log.StringValue("payment failed"))
+				record.SetSeverity(log.SeverityError)
+				record.SetSeverityText("ERROR")
+				record.SetTimestamp(timestamp)
+				record.AddAttributes(
+					log.Int("attempt", 3),
+					log.Float64("amount", 9.5),
+					log.Bool("retry", true),
+					log.Slice("tags", log.StringValue("card"), log.StringValue("eu")),
+					log.Map("user", log.String("id", "u-7"), log.Map("plan", log.String("name", "pro"))),
+					log.String("service.name", "payments-worker"),
+				)
+				return record
+			},
+			ctx: context.Background(),
+			want: map[string]interface{}{
+				"short_message":       "payment failed",
+				"level":               float64(3),
+				"timestamp":           1700000000.5,
+				"_severity_text":      "ERROR",
+				"_attempt":            float64(3),
+				"_amount":             9.5,
+				"_retry":              "true",
+				"_tags":               `["card","eu"]`,
+				"_user.id":            "u-7",
+				"_user.plan.name":     "pro",
+				"_service.name":       "payments-worker",
+				"_service.version":    "1.4.0",
+				"_otel.scope.name":    "github.com/acme/payments",
+				"_otel.scope.version": "0.3.0",
+			},
+			missing: []string{"_trace_id", "_span_id", "_event_name"},
+		},
+		{
+			name: "Trace context",
+			record: func() log.Record {
+				var record log.Record
+				record.SetBody(log.StringValue("charged"))
+				record.SetSeverity(log.SeverityInfo)
+				return record
+			},
+			ctx: trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: trace.FlagsSampled,
+			})),
+			want: map[string]interface{}{
+				"level":     float64(6),
+				"_trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"_span_id":  "00f067aa0ba902b7",
+			},
+		},
+		{
+			name: "Event without body",
+			record: func() log.Record {
+				var record log.Record
+				record.SetEventName("order.placed")
+				record.SetSeverity(log.SeverityDebug)
+				record.SetObservedTimestamp(timestamp)
+				return record
+			},
+			ctx: context.Background(),
+			want: map[string]interface{}{
+				"short_message": "order.placed",
+				"level":         float64(7),
+				"timestamp":     1700000000.5,
+				"_event_name":   "order.placed",
+			},
+		},
+		{
+			name: "Map body",
+			record: func() log.Record {
+				var record log.Record
+				record.SetBody(log.MapValue(log.String("order", "o-1")))
+				record.SetSeverity(log.SeverityFatal)
+				return record
+			},
+			ctx:  context.Background(),
+			want: map[string]interface{}{"short_message": "[order:o-1]", "level": float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			exporter := otelexporter.NewExporter(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing))
+			provider := sdklog.NewLoggerProvider(
+				sdklog.WithResource(resource.NewSchemaless(
+					attribute.String("service.name", "payments"),
+					attribute.String("service.version", "1.4.0"),
+				)),
+				sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)),
+			)
+			logger := provider.Logger("github.com/acme/payments", log.WithInstrumentationVersion("0.3.0"))
+			logger.Emit(tt.ctx, tt.record())
+			require.NoError(t, provider.Shutdown(context.Background()))
+
+			require.Len(t, transport.Messages(), 1)
+			var gelfMsg map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(transport.Messages()[0]), &gelfMsg))
+			for key, want := range tt.want {
+				assert.Equal(t, want, gelfMsg[key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, gelfMsg, key)
+			}
+		})
+	}
+}
+
+func TestExporterCanceled(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	exporter := otelexporter.NewExporter(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var record sdklog.Record
+	record.SetBody(log.StringValue("dropped"))
+	assert.ErrorIs(t, exporter.Export(ctx, []sdklog.Record{record}), context.Canceled)
+	assert.Empty(t, transport.Messages())
+}
+
+func TestConvertSeverityToGraylog(t *testing.T) {
+	tests := []struct {
+		severity log.Severity
+		want     int
+	}{
+		{log.SeverityUndefined, gelflogger.Informational},
+		{log.SeverityTrace, gelflogger.Debug},
+		{log.SeverityDebug4, gelflogger.Debug},
+		{log.SeverityInfo, gelflogger.Informational},
+		{log.SeverityInfo4, gelflogger.Informational},
+		{log.SeverityWarn, gelflogger.Warning},
+		{log.SeverityError2, gelflogger.Error},
+		{log.SeverityFatal4, gelflogger.Critical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, otelexporter.ConvertSeverityToGraylog(tt.severity))
+		})
+	}
+}
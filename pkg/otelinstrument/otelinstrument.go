@@ -0,0 +1,186 @@
+package otelinstrument
+
+import (
+	"context"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer and meter of the instrumentation.
+const instrumentationName = "github.com/jame-developer/gelf-logger/pkg/otelinstrument"
+
+// Outcomes recorded in the gelf.outcome attribute.
+const (
+	OutcomeSuccess    = "success"
+	OutcomeError      = "error"
+	OutcomeConnected  = "connected"
+	OutcomeFailedOver = "failed_over"
+	OutcomeClosed     = "closed"
+)
+
+// Attribute keys of the spans and metrics.
+const (
+	AttributeOutcome  = attribute.Key("gelf.outcome")
+	AttributeMessages = attribute.Key("gelf.messages")
+	AttributeBytes    = attribute.Key("gelf.bytes")
+	AttributeEndpoint = attribute.Key("server.address")
+	AttributeAttempts = attribute.Key("gelf.connect.attempts")
+)
+
+// config holds the providers and attributes of the instrumentation.
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	attributes     []attribute.KeyValue
+}
+
+// Option configures the instrumentation.
+type Option func(*config)
+
+// WithTracerProvider sets the TracerProvider creating the spans, the global one by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the MeterProvider creating the metrics, the global one by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithAttributes adds the attributes to all spans and metrics, e.g. the transport and the endpoint of the Logger:
+//
+//	otelinstrument.WithAttributes(attribute.String("gelf.transport", "tcp"), attribute.String("server.address", "graylog:12201"))
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+// instrumentation records the spans and metrics of a single Logger.
+type instrumentation struct {
+	tracer       trace.Tracer
+	attributes   []attribute.KeyValue
+	sendDuration metric.Float64Histogram
+	sentBytes    metric.Int64Counter
+	connects     metric.Int64Counter
+	disconnects  metric.Int64Counter
+}
+
+// Instrument creates OpenTelemetry spans and metrics around the send and connect operations of the Logger, so the
+// latency of the log shipping can be correlated with the traces of the application:
+//
+//   - a "gelf send" span per send, with the number of messages, their size and the outcome
+//   - a "gelf connect" span per re-established connection, from the first reconnect attempt until the connection
+//     was established, with the endpoint, the number of attempts and whether it failed over
+//   - the metrics gelf.send.duration, gelf.sent.bytes, gelf.connects and gelf.disconnects
+//
+// The sends are observed through gelflogger.Logger.ObserveSends, replacing a send observer set before, the connects
+// through gelflogger.Logger.Events until the Logger is closed.
+func Instrument(logger *gelflogger.Logger, opts ...Option) error {
+	cfg := config{tracerProvider: otel.GetTracerProvider(), meterProvider: otel.GetMeterProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	i := &instrumentation{tracer: cfg.tracerProvider.Tracer(instrumentationName), attributes: cfg.attributes}
+	var err error
+	if i.sendDuration, err = meter.Float64Histogram("gelf.send.duration", metric.WithUnit("s"),
+		metric.WithDescription("Duration of the sends to Graylog.")); err != nil {
+		return err
+	}
+	if i.sentBytes, err = meter.Int64Counter("gelf.sent.bytes", metric.WithUnit("By"),
+		metric.WithDescription("Bytes of the messages sent to Graylog.")); err != nil {
+		return err
+	}
+	if i.connects, err = meter.Int64Counter("gelf.connects",
+		metric.WithDescription("Connections re-established to Graylog.")); err != nil {
+		return err
+	}
+	if i.disconnects, err = meter.Int64Counter("gelf.disconnects",
+		metric.WithDescription("Connections to Graylog which were lost.")); err != nil {
+		return err
+	}
+
+	logger.ObserveSends(i.observeSend)
+	go i.observeConnects(logger.Events())
+	return nil
+}
+
+// observeSend records the span and metrics of a send.
+func (i *instrumentation) observeSend(result gelflogger.SendResult) {
+	outcome := OutcomeSuccess
+	if result.Err != nil {
+		outcome = OutcomeError
+	}
+	attributes := append([]attribute.KeyValue{AttributeOutcome.String(outcome)}, i.attributes...)
+	_, span := i.tracer.Start(context.Background(), "gelf send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(result.Start),
+		trace.WithAttributes(attributes...),
+		trace.WithAttributes(AttributeMessages.Int(result.Messages), AttributeBytes.Int(result.Bytes)))
+	if result.Err != nil {
+		span.RecordError(result.Err)
+		span.SetStatus(codes.Error, result.Err.Error())
+	}
+	span.End(trace.WithTimestamp(result.Start.Add(result.Latency)))
+
+	ctx := context.Background()
+	i.sendDuration.Record(ctx, result.Latency.Seconds(), metric.WithAttributes(attributes...))
+	if result.Err == nil {
+		i.sentBytes.Add(ctx, int64(result.Bytes), metric.WithAttributes(i.attributes...))
+	}
+}
+
+// observeConnects records the connect spans and the connection metrics until the events channel is closed.
+func (i *instrumentation) observeConnects(events <-chan gelflogger.Event) {
+	ctx := context.Background()
+	var span trace.Span
+	attempts := 0
+	for event := range events {
+		switch event.Type {
+		case gelflogger.EventDisconnected:
+			i.disconnects.Add(ctx, 1, metric.WithAttributes(append([]attribute.KeyValue{AttributeEndpoint.String(event.Address)}, i.attributes...)...))
+		case gelflogger.EventReconnecting:
+			if span == nil {
+				_, span = i.tracer.Start(ctx, "gelf connect",
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithTimestamp(event.Time),
+					trace.WithAttributes(i.attributes...))
+			}
+			attempts++
+			if event.Reason != nil {
+				span.RecordError(event.Reason, trace.WithTimestamp(event.Time))
+			}
+		case gelflogger.EventConnected, gelflogger.EventFailedOver:
+			outcome := OutcomeConnected
+			if event.Type == gelflogger.EventFailedOver {
+				outcome = OutcomeFailedOver
+			}
+			attributes := append([]attribute.KeyValue{AttributeOutcome.String(outcome), AttributeEndpoint.String(event.Address)}, i.attributes...)
+			i.connects.Add(ctx, 1, metric.WithAttributes(attributes...))
+			if span != nil {
+				span.SetAttributes(AttributeAttempts.Int(attempts))
+				span.SetAttributes(attributes...)
+				span.End(trace.WithTimestamp(event.Time))
+				span, attempts = nil, 0
+			}
+		}
+	}
+	if span != nil {
+		// The Logger was closed while reconnecting
+		span.SetAttributes(AttributeOutcome.String(OutcomeClosed), AttributeAttempts.Int(attempts))
+		span.SetStatus(codes.Error, "logger closed while reconnecting")
+		span.End(trace.WithTimestamp(time.Now()))
+	}
+}
@@ -0,0 +1,110 @@
+package otelinstrument_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/otelinstrument"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// flakyTransport fails every second send.
+type flakyTransport struct {
+	sends int
+}
+
+func (f *flakyTransport) Send([]byte) error {
+	f.sends++
+	if f.sends%2 == 0 {
+		return errors.New("transport down")
+	}
+	return nil
+}
+
+func (f *flakyTransport) Close() error { return nil }
+
+func processNothing(map[string]interface{}) (int, float64, []byte, error) {
+	return 6, 0, nil, nil
+}
+
+func TestInstrumentSends(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	logger := gelflogger.NewLoggerWithTransport(&flakyTransport{}, processNothing)
+	require.NoError(t, otelinstrument.Instrument(logger,
+		otelinstrument.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		otelinstrument.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		otelinstrument.WithAttributes(attribute.String("gelf.transport", "test"))))
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	for i := 0; i < 2; i++ {
+		_ = logger.Log("traced", map[string]interface{}{})
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "gelf send", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), otelinstrument.AttributeOutcome.String(otelinstrument.OutcomeSuccess))
+	assert.Contains(t, spans[0].Attributes(), otelinstrument.AttributeMessages.Int(1))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("gelf.transport", "test"))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), otelinstrument.AttributeOutcome.String(otelinstrument.OutcomeError))
+
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &metrics))
+	require.Len(t, metrics.ScopeMetrics, 1)
+	for _, m := range metrics.ScopeMetrics[0].Metrics {
+		if m.Name == "gelf.send.duration" {
+			var count uint64
+			for _, point := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				count += point.Count
+			}
+			assert.Equal(t, uint64(2), count)
+			return
+		}
+	}
+	t.Error("gelf.send.duration was not recorded")
+}
+
+func TestInstrumentConnects(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	recorder := tracetest.NewSpanRecorder()
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithHealthCheckInterval(20*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, otelinstrument.Instrument(logger,
+		otelinstrument.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))))
+
+	// The server closes the connection, the transport reconnects in the background
+	_ = (<-accepted).Close()
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	require.NoError(t, logger.Close(context.Background()))
+
+	span := recorder.Ended()[0]
+	assert.Equal(t, "gelf connect", span.Name())
+	assert.Contains(t, span.Attributes(), otelinstrument.AttributeOutcome.String(otelinstrument.OutcomeConnected))
+	assert.Contains(t, span.Attributes(), otelinstrument.AttributeEndpoint.String(server.Addr().String()))
+}
@@ -0,0 +1,83 @@
+package promcollector
+
+import (
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector exposing the internals of a gelflogger.Logger, so log shipping problems can be
+// alerted on:
+//
+//   - gelflogger_messages_sent_total: messages sent to Graylog
+//   - gelflogger_bytes_sent_total: bytes of the sent messages
+//   - gelflogger_errors_total: failed sends
+//   - gelflogger_dropped_total: dropped messages
+//   - gelflogger_reconnects_total: connections re-established after the connection was lost
+//   - gelflogger_queue_depth: messages waiting in the queue of an asynchronous Logger
+//   - gelflogger_send_duration_seconds: histogram of the send latency
+//
+// The labels passed to New are added to all metrics, so several Loggers can be told apart.
+type Collector struct {
+	logger *gelflogger.Logger
+
+	sent       *prometheus.Desc
+	bytesSent  *prometheus.Desc
+	errors     *prometheus.Desc
+	dropped    *prometheus.Desc
+	reconnects *prometheus.Desc
+	queueDepth *prometheus.Desc
+	latency    prometheus.Histogram
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// New creates a Collector for the given Logger, adding the labels to all metrics. It observes the sends of the Logger
+// for the latency histogram, replacing a send observer set before, see gelflogger.Logger.ObserveSends.
+//
+// Example usage:
+//
+//	prometheus.MustRegister(promcollector.New(logger, prometheus.Labels{"logger": "audit"}))
+func New(logger *gelflogger.Logger, labels prometheus.Labels) *Collector {
+	c := &Collector{
+		logger:     logger,
+		sent:       prometheus.NewDesc("gelflogger_messages_sent_total", "Number of messages sent to Graylog.", nil, labels),
+		bytesSent:  prometheus.NewDesc("gelflogger_bytes_sent_total", "Number of bytes of the messages sent to Graylog.", nil, labels),
+		errors:     prometheus.NewDesc("gelflogger_errors_total", "Number of failed sends.", nil, labels),
+		dropped:    prometheus.NewDesc("gelflogger_dropped_total", "Number of dropped messages.", nil, labels),
+		reconnects: prometheus.NewDesc("gelflogger_reconnects_total", "Number of connections re-established after the connection was lost.", nil, labels),
+		queueDepth: prometheus.NewDesc("gelflogger_queue_depth", "Number of messages waiting in the queue.", nil, labels),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "gelflogger_send_duration_seconds",
+			Help:        "Latency of the sends to Graylog.",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}),
+	}
+	logger.ObserveSends(func(result gelflogger.SendResult) {
+		c.latency.Observe(result.Latency.Seconds())
+	})
+	return c
+}
+
+// Describe sends the descriptors of the metrics to the channel.
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.sent
+	descs <- c.bytesSent
+	descs <- c.errors
+	descs <- c.dropped
+	descs <- c.reconnects
+	descs <- c.queueDepth
+	c.latency.Describe(descs)
+}
+
+// Collect sends the current values of the metrics, taken from gelflogger.Logger.Stats, to the channel.
+func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
+	stats := c.logger.Stats()
+	metrics <- prometheus.MustNewConstMetric(c.sent, prometheus.CounterValue, float64(stats.MessagesSent))
+	metrics <- prometheus.MustNewConstMetric(c.bytesSent, prometheus.CounterValue, float64(stats.BytesSent))
+	metrics <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(stats.SendErrors))
+	metrics <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.Dropped))
+	metrics <- prometheus.MustNewConstMetric(c.reconnects, prometheus.CounterValue, float64(stats.Reconnects))
+	metrics <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(stats.QueueDepth))
+	c.latency.Collect(metrics)
+}
@@ -0,0 +1,69 @@
+package promcollector_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/promcollector"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTransport fails every send after the first one.
+type failingTransport struct {
+	sends int
+}
+
+func (f *failingTransport) Send([]byte) error {
+	f.sends++
+	if f.sends > 1 {
+		return errors.New("transport down")
+	}
+	return nil
+}
+
+func (f *failingTransport) Close() error { return nil }
+
+func TestCollector(t *testing.T) {
+	logger := gelflogger.NewLoggerWithTransport(&failingTransport{}, func(map[string]interface{}) (int, float64, []byte, error) {
+		return 6, 0, nil, nil
+	})
+	collector := promcollector.New(logger, prometheus.Labels{"logger": "test"})
+	for i := 0; i < 2; i++ {
+		_ = logger.Log("measured", map[string]interface{}{})
+	}
+
+	expected := `
+# HELP gelflogger_dropped_total Number of dropped messages.
+# TYPE gelflogger_dropped_total counter
+gelflogger_dropped_total{logger="test"} 1
+# HELP gelflogger_errors_total Number of failed sends.
+# TYPE gelflogger_errors_total counter
+gelflogger_errors_total{logger="test"} 1
+# HELP gelflogger_messages_sent_total Number of messages sent to Graylog.
+# TYPE gelflogger_messages_sent_total counter
+gelflogger_messages_sent_total{logger="test"} 1
+# HELP gelflogger_queue_depth Number of messages waiting in the queue.
+# TYPE gelflogger_queue_depth gauge
+gelflogger_queue_depth{logger="test"} 0
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"gelflogger_dropped_total", "gelflogger_errors_total", "gelflogger_messages_sent_total", "gelflogger_queue_depth")
+	require.NoError(t, err)
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "gelflogger_send_duration_seconds" {
+			assert.Equal(t, uint64(2), family.GetMetric()[0].GetHistogram().GetSampleCount())
+			return
+		}
+	}
+	t.Error("the latency histogram was not collected")
+}
@@ -0,0 +1,163 @@
+package relay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// chunkHeaderSize is the size of the header of a GELF chunk: the magic bytes, the message ID, the sequence number
+	// and the sequence count.
+	chunkHeaderSize = 12
+	// maxChunks is the maximum number of chunks of a GELF message, as accepted by Graylog.
+	maxChunks = 128
+)
+
+// chunkMagic are the magic bytes starting every chunk of a chunked GELF message.
+var chunkMagic = []byte{0x1e, 0x0f}
+
+// partialMessage is a chunked message whose chunks are being received.
+//
+// - chunks: The data of the chunks by sequence number, nil for the chunks not yet received.
+// - received: The number of chunks received.
+// - size: The total size of the data received.
+// - started: The time the first chunk was received, see Relay.chunkTimeout.
+type partialMessage struct {
+	chunks   [][]byte
+	received int
+	size     int
+	started  time.Time
+}
+
+// assembler reassembles chunked GELF messages from their datagrams. Messages whose chunks are not complete within the
+// timeout are discarded, like Graylog does. At most maxPartials messages are reassembled at a time, so a sender
+// spraying chunks of distinct message IDs cannot exhaust the memory before they expire; the oldest one is discarded
+// for a new one beyond.
+type assembler struct {
+	lock        sync.Mutex
+	timeout     time.Duration
+	maxSize     int
+	maxPartials int
+	partials    map[uint64]*partialMessage
+	now         func() time.Time
+}
+
+// newAssembler returns an assembler discarding incomplete messages after the timeout, messages exceeding maxSize, and
+// the oldest incomplete message beyond maxPartials.
+func newAssembler(timeout time.Duration, maxSize, maxPartials int) *assembler {
+	return &assembler{
+		timeout:     timeout,
+		maxSize:     maxSize,
+		maxPartials: maxPartials,
+		partials:    make(map[uint64]*partialMessage),
+		now:         time.Now,
+	}
+}
+
+// add adds a datagram. It returns the complete message if the datagram is an unchunked message or completes a chunked
+// one, and nil otherwise. evicted is true if the oldest incomplete message was discarded for the one of the datagram.
+// An error is returned for invalid chunks. Duplicate chunks are ignored.
+func (a *assembler) add(datagram []byte) (message []byte, evicted bool, err error) {
+	if !bytes.HasPrefix(datagram, chunkMagic) {
+		return datagram, false, nil
+	}
+	if len(datagram) < chunkHeaderSize {
+		return nil, false, fmt.Errorf("GELF chunk of %d bytes is shorter than its header", len(datagram))
+	}
+	id := binary.BigEndian.Uint64(datagram[2:10])
+	seq, count := int(datagram[10]), int(datagram[11])
+	if count == 0 || count > maxChunks || seq >= count {
+		return nil, false, fmt.Errorf("GELF chunk %d of %d is invalid", seq, count)
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	partial, ok := a.partials[id]
+	if !ok {
+		if len(a.partials) >= a.maxPartials {
+			a.evictOldest()
+			evicted = true
+		}
+		partial = &partialMessage{chunks: make([][]byte, count), started: a.now()}
+		a.partials[id] = partial
+	}
+	if len(partial.chunks) != count {
+		delete(a.partials, id)
+		return nil, evicted, fmt.Errorf("GELF chunk %d of %d of a message of %d chunks", seq, count, len(partial.chunks))
+	}
+	if partial.chunks[seq] != nil {
+		return nil, evicted, nil
+	}
+	partial.chunks[seq] = bytes.Clone(datagram[chunkHeaderSize:])
+	partial.received++
+	partial.size += len(datagram) - chunkHeaderSize
+	if partial.size > a.maxSize {
+		delete(a.partials, id)
+		return nil, evicted, fmt.Errorf("chunked GELF message exceeds %d bytes", a.maxSize)
+	}
+	if partial.received < count {
+		return nil, evicted, nil
+	}
+	delete(a.partials, id)
+	return bytes.Join(partial.chunks, nil), evicted, nil
+}
+
+// evictOldest discards the incomplete message whose first chunk was received first. The lock must be held.
+func (a *assembler) evictOldest() {
+	var oldestID uint64
+	var oldest *partialMessage
+	for id, partial := range a.partials {
+		if oldest == nil || partial.started.Before(oldest.started) {
+			oldestID, oldest = id, partial
+		}
+	}
+	delete(a.partials, oldestID)
+}
+
+// expire discards the messages whose chunks are not complete within the timeout, and returns their number.
+func (a *assembler) expire() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	expired := 0
+	for id, partial := range a.partials {
+		if a.now().Sub(partial.started) > a.timeout {
+			delete(a.partials, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+// decompress returns the message decompressed, if it is compressed with gzip or zlib, as the GELF UDP senders may do.
+// Uncompressed messages are returned as they are. Messages decompressing to more than maxSize bytes are rejected, so a
+// small compressed datagram cannot exhaust the memory.
+func decompress(message []byte, maxSize int) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch {
+	case len(message) >= 2 && message[0] == 0x1f && message[1] == 0x8b:
+		reader, err = gzip.NewReader(bytes.NewReader(message))
+	case len(message) >= 2 && message[0] == 0x78 && (uint16(message[0])<<8|uint16(message[1]))%31 == 0:
+		reader, err = zlib.NewReader(bytes.NewReader(message))
+	default:
+		return message, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	decompressed, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxSize {
+		return nil, fmt.Errorf("decompressed GELF message exceeds %d bytes", maxSize)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,326 @@
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// DefaultChunkTimeout is the time the chunks of a chunked UDP message must be received within by default, like the
+// default of Graylog, see WithChunkTimeout.
+const DefaultChunkTimeout = 5 * time.Second
+
+// DefaultMaxMessageSize is the maximum size of a relayed message after reassembly and decompression by default, see
+// WithMaxMessageSize.
+const DefaultMaxMessageSize = 8 << 20
+
+// DefaultMaxPartialMessages is the maximum number of chunked UDP messages reassembled at a time by default, see
+// WithMaxPartialMessages.
+const DefaultMaxPartialMessages = 1024
+
+// maxDatagramSize is the maximum size of a UDP datagram.
+const maxDatagramSize = 65535
+
+// Option configures a Relay, see New.
+type Option func(*Relay)
+
+// WithChunkTimeout sets the time the chunks of a chunked UDP message must be received within, DefaultChunkTimeout by
+// default. Incomplete messages are discarded once it elapsed.
+func WithChunkTimeout(timeout time.Duration) Option {
+	return func(r *Relay) {
+		r.chunkTimeout = timeout
+	}
+}
+
+// WithMaxMessageSize sets the maximum size of a message after reassembly and decompression, DefaultMaxMessageSize by
+// default. Larger messages are discarded.
+func WithMaxMessageSize(size int) Option {
+	return func(r *Relay) {
+		r.maxMessageSize = size
+	}
+}
+
+// WithMaxPartialMessages sets the maximum number of chunked UDP messages whose chunks are received at a time,
+// DefaultMaxPartialMessages by default. The oldest incomplete message is discarded for the first chunk of another one
+// beyond, so a sender spraying chunks of distinct message IDs cannot exhaust the memory.
+func WithMaxPartialMessages(count int) Option {
+	return func(r *Relay) {
+		r.maxPartials = count
+	}
+}
+
+// WithNewlineFraming also splits the TCP streams at newlines, for senders terminating their GELF TCP messages with a
+// newline instead of a null byte. By default, only null bytes delimit the messages, as GELF TCP specifies, so the
+// messages may contain newlines, e.g. between the members of pretty-printed JSON.
+func WithNewlineFraming() Option {
+	return func(r *Relay) {
+		r.newlineFraming = true
+	}
+}
+
+// Stats are the counters of a Relay, see Relay.Stats.
+type Stats struct {
+	// Received is the number of messages received, after the reassembly of chunked messages.
+	Received uint64
+	// Forwarded is the number of messages handed to the Logger. Messages the Logger fails to send are counted by its
+	// own statistics, see gelflogger.Logger.Stats.
+	Forwarded uint64
+	// Invalid is the number of messages and chunks discarded as invalid, e.g. not JSON, too large or corrupt.
+	Invalid uint64
+	// Expired is the number of chunked messages discarded as their chunks were not complete in time, or as too many
+	// messages were incomplete, see WithMaxPartialMessages.
+	Expired uint64
+}
+
+// Relay receives GELF messages over UDP and TCP and forwards them through a Logger, so legacy emitters which only
+// speak GELF UDP benefit from the reliable delivery of the Logger: its queue, retries, spool and failover, see
+// gelflogger.WithAsync, gelflogger.WithSpool and gelflogger.WithFailoverAddresses. The UDP messages are reassembled
+// from their chunks and decompressed, the TCP messages split at their null byte delimiters. The messages
+// are checked to be JSON objects and forwarded as they are, see gelflogger.Logger.LogRaw.
+//
+// - logger: The Logger the messages are forwarded through.
+// - chunkTimeout: The time the chunks of a message must be received within, see WithChunkTimeout.
+// - maxMessageSize: The maximum size of a message, see WithMaxMessageSize.
+// - maxPartials: The maximum number of chunked messages reassembled at a time, see WithMaxPartialMessages.
+// - newlineFraming: Whether newlines delimit the TCP messages too, see WithNewlineFraming.
+// - received, forwarded, invalid, expired: The counters of Stats.
+type Relay struct {
+	logger         *gelflogger.Logger
+	chunkTimeout   time.Duration
+	maxMessageSize int
+	maxPartials    int
+	newlineFraming bool
+
+	received  atomic.Uint64
+	forwarded atomic.Uint64
+	invalid   atomic.Uint64
+	expired   atomic.Uint64
+}
+
+// New creates a Relay forwarding the received messages through the Logger.
+//
+// Example usage:
+//
+//	logger, err := gelflogger.NewLogger("tls://graylog.example.com:12201",
+//		gelflogger.WithAsync(10000, 2), gelflogger.WithFailoverAddresses("tls://graylog-2.example.com:12201"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = relay.New(logger).ListenAndServe(ctx, ":12201", ":12201")
+func New(logger *gelflogger.Logger, opts ...Option) *Relay {
+	r := &Relay{
+		logger:         logger,
+		chunkTimeout:   DefaultChunkTimeout,
+		maxMessageSize: DefaultMaxMessageSize,
+		maxPartials:    DefaultMaxPartialMessages,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Stats returns the counters of the Relay.
+func (r *Relay) Stats() Stats {
+	return Stats{
+		Received:  r.received.Load(),
+		Forwarded: r.forwarded.Load(),
+		Invalid:   r.invalid.Load(),
+		Expired:   r.expired.Load(),
+	}
+}
+
+// ListenAndServe listens for GELF UDP and TCP on the given addresses, e.g. :12201, and serves them until the context
+// is done, see ServeUDP and ServeTCP. An empty address disables the protocol. It returns nil once the context is done,
+// or the first error of listening or serving.
+func (r *Relay) ListenAndServe(ctx context.Context, udpAddress, tcpAddress string) error {
+	if udpAddress == "" && tcpAddress == "" {
+		return errors.New("relay: neither a UDP nor a TCP address to listen on")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 2)
+	servers := 0
+	if udpAddress != "" {
+		conn, err := net.ListenPacket("udp", udpAddress)
+		if err != nil {
+			return err
+		}
+		servers++
+		go func() { errs <- r.ServeUDP(ctx, conn) }()
+	}
+	if tcpAddress != "" {
+		listener, err := net.Listen("tcp", tcpAddress)
+		if err != nil {
+			cancel()
+			for ; servers > 0; servers-- {
+				<-errs
+			}
+			return err
+		}
+		servers++
+		go func() { errs <- r.ServeTCP(ctx, listener) }()
+	}
+	var firstErr error
+	for ; servers > 0; servers-- {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	return firstErr
+}
+
+// ServeUDP receives GELF UDP datagrams on the connection until the context is done, and closes it then. It returns nil
+// once the context is done, or the error of reading, after the expiry of the incomplete chunked messages stopped.
+func (r *Relay) ServeUDP(ctx context.Context, conn net.PacketConn) error {
+	var expiry sync.WaitGroup
+	defer expiry.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	chunks := newAssembler(r.chunkTimeout, r.maxMessageSize, r.maxPartials)
+	expiry.Add(1)
+	go func() {
+		defer expiry.Done()
+		ticker := time.NewTicker(r.chunkTimeout)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.expired.Add(uint64(chunks.expire()))
+			}
+		}
+	}()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("relay: reading UDP: %w", err)
+		}
+		message, evicted, err := chunks.add(buf[:n])
+		if evicted {
+			r.expired.Add(1)
+		}
+		if err != nil {
+			r.invalid.Add(1)
+			continue
+		}
+		if message == nil {
+			continue
+		}
+		message, err = decompress(message, r.maxMessageSize)
+		if err != nil {
+			r.received.Add(1)
+			r.invalid.Add(1)
+			continue
+		}
+		r.forward(message)
+	}
+}
+
+// ServeTCP accepts GELF TCP connections on the listener until the context is done, and closes it and the connections
+// then. It returns nil once the context is done, or the error of accepting.
+func (r *Relay) ServeTCP(ctx context.Context, listener net.Listener) error {
+	// The connections are closed before waiting for them, also if accepting fails
+	var connections sync.WaitGroup
+	defer connections.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer stop()
+	defer func() { _ = listener.Close() }()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("relay: accepting TCP: %w", err)
+		}
+		connections.Add(1)
+		go func() {
+			defer connections.Done()
+			r.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn forwards the messages of a TCP connection until it is closed or the context is done. A message exceeding
+// the maximum size closes the connection, as the stream cannot be resynchronized.
+func (r *Relay) serveConn(ctx context.Context, conn net.Conn) {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, min(64<<10, r.maxMessageSize)), r.maxMessageSize)
+	if r.newlineFraming {
+		scanner.Split(scanFramesOrLines)
+	} else {
+		scanner.Split(scanFrames)
+	}
+	for scanner.Scan() {
+		if message := scanner.Bytes(); len(bytes.TrimSpace(message)) > 0 {
+			r.forward(message)
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		r.received.Add(1)
+		r.invalid.Add(1)
+	}
+}
+
+// scanFrames is a bufio.SplitFunc splitting the GELF TCP stream at the null bytes. A final frame without delimiter is
+// returned at the end of the stream.
+func scanFrames(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	return scanDelimited(data, atEOF, "\x00")
+}
+
+// scanFramesOrLines is a bufio.SplitFunc splitting the GELF TCP stream at the null bytes and the newlines, see
+// WithNewlineFraming.
+func scanFramesOrLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	return scanDelimited(data, atEOF, "\x00\n")
+}
+
+// scanDelimited splits the stream at any of the delimiters, like a bufio.SplitFunc.
+func scanDelimited(data []byte, atEOF bool, delimiters string) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, delimiters); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// forward forwards the message through the Logger, unless it is not a JSON object. Errors of the Logger are counted by
+// its statistics.
+func (r *Relay) forward(message []byte) {
+	r.received.Add(1)
+	message = bytes.TrimSpace(message)
+	if len(message) == 0 || message[0] != '{' || !json.Valid(message) {
+		r.invalid.Add(1)
+		return
+	}
+	r.forwarded.Add(1)
+	_ = r.logger.LogRaw(message)
+}
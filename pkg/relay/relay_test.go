@@ -0,0 +1,242 @@
+package relay_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/relay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startUDPRelay serves a Relay on a local UDP port until the test ends, and returns the Relay, the transport of its
+// Logger and the address.
+func startUDPRelay(t *testing.T, opts ...relay.Option) (*relay.Relay, *helper.RecordingTransport, string) {
+	t.Helper()
+	transport := &helper.RecordingTransport{}
+	r := relay.New(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), opts...)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.ServeUDP(ctx, conn) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return r, transport, conn.LocalAddr().String()
+}
+
+// compress returns the message compressed with gzip or zlib.
+func compress(t *testing.T, message string, useZlib bool) []byte {
+	var buf bytes.Buffer
+	var writer interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+	if useZlib {
+		writer = zlib.NewWriter(&buf)
+	} else {
+		writer = gzip.NewWriter(&buf)
+	}
+	_, err := writer.Write([]byte(message))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+// chunk returns the GELF chunk of the message with the given ID.
+func chunk(id uint64, seq, count int, data string) []byte {
+	datagram := binary.BigEndian.AppendUint64([]byte{0x1e, 0x0f}, id)
+	return append(append(datagram, byte(seq), byte(count)), data...)
+}
+
+func TestServeUDP(t *testing.T) {
+	message := `{"version":"1.1","host":"legacy","short_message":"disk full","level":3,"_disk":"/dev/sda1"}`
+	tests := []struct {
+		name      string
+		datagrams [][]byte
+		want      []string
+		wantStats relay.Stats
+	}{
+		{
+			name:      "Uncompressed",
+			datagrams: [][]byte{[]byte(message)},
+			want:      []string{message},
+			wantStats: relay.Stats{Received: 1, Forwarded: 1},
+		},
+		{
+			name:      "Gzip",
+			datagrams: [][]byte{compress(t, message, false)},
+			want:      []string{message},
+			wantStats: relay.Stats{Received: 1, Forwarded: 1},
+		},
+		{
+			name:      "Zlib",
+			datagrams: [][]byte{compress(t, message, true)},
+			want:      []string{message},
+			wantStats: relay.Stats{Received: 1, Forwarded: 1},
+		},
+		{
+			name:      "Chunks out of order and duplicated",
+			datagrams: [][]byte{chunk(7, 2, 3, message[60:]), chunk(7, 0, 3, message[:30]), chunk(7, 2, 3, message[60:]), chunk(7, 1, 3, message[30:60])},
+			want:      []string{message},
+			wantStats: relay.Stats{Received: 1, Forwarded: 1},
+		},
+		{
+			name:      "Invalid messages",
+			datagrams: [][]byte{[]byte("disk full"), []byte(`{"short_message":`), chunk(8, 3, 2, "{}"), []byte{0x1f, 0x8b, 0, 0}, []byte(message)},
+			want:      []string{message},
+			wantStats: relay.Stats{Received: 4, Forwarded: 1, Invalid: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, transport, address := startUDPRelay(t)
+			conn, err := net.Dial("udp", address)
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+			for _, datagram := range tt.datagrams {
+				_, err := conn.Write(datagram)
+				require.NoError(t, err)
+			}
+
+			assert.Eventually(t, func() bool { return r.Stats() == tt.wantStats }, 2*time.Second, 5*time.Millisecond)
+			assert.Equal(t, tt.wantStats, r.Stats())
+			assert.Equal(t, tt.want, transport.Messages())
+		})
+	}
+}
+
+func TestServeUDPFromLogger(t *testing.T) {
+	r, transport, address := startUDPRelay(t)
+	sender, err := gelflogger.NewLogger("udp://"+address, gelflogger.WithCompression(gelflogger.CompressionGzip))
+	require.NoError(t, err)
+	defer func() { _ = sender.Close(context.Background()) }()
+
+	// Large enough to be chunked after compression
+	stack := make([]string, 2000)
+	for i := range stack {
+		stack[i] = strings.Repeat(string(rune('a'+i%26)), i%13) + ".go:" + string(rune('0'+i%10))
+	}
+	require.NoError(t, sender.Log("panic", map[string]interface{}{"stack": strings.Join(stack, "\n"), "level": "error"}))
+
+	assert.Eventually(t, func() bool { return r.Stats().Forwarded == 1 }, 2*time.Second, 5*time.Millisecond)
+	sent := transport.Messages()
+	require.Len(t, sent, 1)
+	var gelfMsg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(sent[0]), &gelfMsg))
+	assert.Equal(t, "panic", gelfMsg["short_message"])
+	assert.Equal(t, strings.Join(stack, "\n"), gelfMsg["_stack"])
+}
+
+func TestServeUDPChunkTimeout(t *testing.T) {
+	r, transport, address := startUDPRelay(t, relay.WithChunkTimeout(20*time.Millisecond))
+	conn, err := net.Dial("udp", address)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.Write(chunk(9, 0, 2, `{"short_message":`))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return r.Stats().Expired == 1 }, 2*time.Second, 5*time.Millisecond)
+	_, err = conn.Write(chunk(9, 1, 2, `"late"}`))
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, transport.Messages())
+}
+
+// startTCPRelay serves a Relay on a local TCP port until the test ends, and returns the Relay, the transport of its
+// Logger and the address.
+func startTCPRelay(t *testing.T, opts ...relay.Option) (*relay.Relay, *helper.RecordingTransport, string) {
+	t.Helper()
+	transport := &helper.RecordingTransport{}
+	r := relay.New(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), opts...)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.ServeTCP(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return r, transport, listener.Addr().String()
+}
+
+func TestServeUDPMaxPartialMessages(t *testing.T) {
+	r, transport, address := startUDPRelay(t, relay.WithMaxPartialMessages(2))
+	conn, err := net.Dial("udp", address)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	// The third message discards the first, the oldest incomplete one
+	for id := uint64(1); id <= 3; id++ {
+		_, err := conn.Write(chunk(id, 0, 2, `{"short_message":`))
+		require.NoError(t, err)
+	}
+	for id := uint64(2); id <= 3; id++ {
+		_, err := conn.Write(chunk(id, 1, 2, `"complete"}`))
+		require.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool { return r.Stats().Forwarded == 2 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(1), r.Stats().Expired)
+	assert.Len(t, transport.Messages(), 2)
+}
+
+func TestServeTCP(t *testing.T) {
+	r, transport, address := startTCPRelay(t, relay.WithMaxMessageSize(100))
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"short_message":"one"}` + "\x00" + `{"short_message":"two"}` + "\n\x00not json\x00" + "{\n\t\"short_message\":\"three\"\n}"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool { return r.Stats().Forwarded == 3 }, 2*time.Second, 5*time.Millisecond)
+
+	// An oversized message closes the connection
+	conn, err = net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.Write([]byte(`{"short_message":"` + strings.Repeat("x", 200) + `"}` + "\x00"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return r.Stats().Invalid == 2 }, 2*time.Second, 5*time.Millisecond)
+
+	// The newlines of the third message are kept, as only null bytes delimit the messages
+	assert.Equal(t, []string{`{"short_message":"one"}`, `{"short_message":"two"}`, "{\n\t\"short_message\":\"three\"\n}"}, transport.Messages())
+}
+
+func TestServeTCPNewlineFraming(t *testing.T) {
+	r, transport, address := startTCPRelay(t, relay.WithNewlineFraming())
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"short_message":"one"}` + "\n" + `{"short_message":"two"}` + "\r\n" + `{"short_message":"three"}` + "\x00"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool { return r.Stats().Forwarded == 3 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{`{"short_message":"one"}`, `{"short_message":"two"}`, `{"short_message":"three"}`}, transport.Messages())
+}
+
+func TestListenAndServe(t *testing.T) {
+	r := relay.New(gelflogger.NewLoggerWithTransport(&helper.RecordingTransport{}, helper.ProcessNothing))
+	assert.Error(t, r.ListenAndServe(context.Background(), "", ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, r.ListenAndServe(ctx, "127.0.0.1:0", "127.0.0.1:0"))
+
+	assert.Error(t, r.ListenAndServe(context.Background(), "127.0.0.1:0", "256.0.0.1:0"))
+}
@@ -0,0 +1,156 @@
+package sloglogger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// Handler is a slog.Handler sending the records with a gelflogger.Logger. The message of the record is sent as short
+// message, its time as timestamp and its level as Graylog (Syslog) level, see ConvertSlogLevelToGraylog. The attributes
+// are sent as additional fields; the attributes of groups, either of slog.Group or of Handler.WithGroup, are flattened
+// into dotted field names, e.g. the attribute "id" of the group "user" is sent as "_user.id". Attributes with an empty
+// key are dropped, except for groups, whose attributes are inlined as slog specifies.
+//
+// Error values are sent as their message, with their stack trace appended to the full message.
+//
+// A Handler is safe for concurrent use, like the Logger.
+type Handler struct {
+	logger *gelflogger.Logger
+	opts   slog.HandlerOptions
+	// fields are the attributes of WithAttrs, flattened already. They are never modified after WithAttrs, as the
+	// Handlers derived from the Handler share them.
+	fields map[string]interface{}
+	// groups are the groups of WithGroup, the prefix of the field names of the attributes.
+	groups []string
+}
+
+// NewHandler returns a Handler sending the records with the given Logger. Of the options, which may be nil:
+//   - Level: The minimum level of the records, slog.LevelInfo if nil.
+//   - AddSource: Adds the source of the log call as "caller" field, e.g. handler.go:42 like zap and zerolog, which the
+//     field naming schemes split into file and line, see gelflogger.WithFieldNaming.
+//   - ReplaceAttr: Called for every attribute that is not a group, with the groups it is in. The message, the time and
+//     the level are GELF fields rather than attributes, so it is not called for them.
+//
+// Example usage:
+//
+//	graylogLogger, err := gelflogger.NewLogger("graylog.example.com:12201")
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger := slog.New(sloglogger.NewHandler(graylogLogger, nil))
+//	logger.Info("order placed", "order_id", 42, slog.Group("user", "id", 7))
+func NewHandler(logger *gelflogger.Logger, opts *slog.HandlerOptions) *Handler {
+	h := &Handler{logger: logger}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether records of the given level are sent, see NewHandler.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle sends the record with the fields of the context, see gelflogger.WithContextExtractor. It returns the errors
+// of gelflogger.Logger.LogAtContext.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(map[string]interface{}, len(h.fields)+record.NumAttrs()+1)
+	maps.Copy(fields, h.fields)
+	record.Attrs(func(attr slog.Attr) bool {
+		h.addAttr(fields, h.groups, attr)
+		return true
+	})
+	if h.opts.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		if frame.File != "" {
+			fields["caller"] = frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+	}
+	return h.logger.LogAtContext(ctx, ConvertSlogLevelToGraylog(record.Level), record.Time, record.Message, fields)
+}
+
+// WithAttrs returns a Handler adding the given attributes to every record, in the current groups.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	derived := *h
+	derived.fields = maps.Clone(h.fields)
+	if derived.fields == nil {
+		derived.fields = make(map[string]interface{}, len(attrs))
+	}
+	for _, attr := range attrs {
+		h.addAttr(derived.fields, h.groups, attr)
+	}
+	return &derived
+}
+
+// WithGroup returns a Handler putting the attributes of the records and of WithAttrs into the given group. An empty
+// name is ignored, as slog specifies.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.groups = append(slices.Clip(h.groups), name)
+	return &derived
+}
+
+// addAttr adds the attribute in the given groups to the fields, flattening the attributes of groups.
+func (h *Handler) addAttr(fields map[string]interface{}, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = h.opts.ReplaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groups = append(slices.Clip(groups), attr.Key)
+		}
+		for _, member := range attr.Value.Group() {
+			h.addAttr(fields, groups, member)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	name := attr.Key
+	if len(groups) > 0 {
+		name = strings.Join(groups, ".") + "." + name
+	}
+	fields[name] = attr.Value.Any()
+}
+
+// ConvertSlogLevelToGraylog converts a slog level to the equivalent Graylog (Syslog) level. As slog permits levels
+// between its named ones, the levels are mapped by range: below slog.LevelInfo to Debug (7), slog.LevelInfo to
+// Informational (6), between slog.LevelInfo and slog.LevelWarn to Notice (5), from slog.LevelWarn to Warning (4), from
+// slog.LevelError to Error (3) and from slog.LevelError+4 on to Critical (2).
+func ConvertSlogLevelToGraylog(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError+4:
+		return gelflogger.Critical
+	case level >= slog.LevelError:
+		return gelflogger.Error
+	case level >= slog.LevelWarn:
+		return gelflogger.Warning
+	case level > slog.LevelInfo:
+		return gelflogger.Notice
+	case level >= slog.LevelInfo:
+		return gelflogger.Informational
+	default:
+		return gelflogger.Debug
+	}
+}
@@ -0,0 +1,205 @@
+package sloglogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/sloglogger"
+	"github.com/jame-developer/gelf-logger/pkg/tracecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sentMessages decodes the messages sent by the transport.
+func sentMessages(t *testing.T, transport *helper.RecordingTransport) []map[string]interface{} {
+	sent := make([]map[string]interface{}, 0, len(transport.Messages()))
+	for _, message := range transport.Messages() {
+		var gelfMsg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(message), &gelfMsg))
+		sent = append(sent, gelfMsg)
+	}
+	return sent
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *slog.HandlerOptions
+		log     func(logger *slog.Logger)
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name: "Message, level and attributes",
+			log: func(logger *slog.Logger) {
+				logger.Warn("disk almost full", "free", "5%", slog.Int("mounts", 3), slog.Bool("critical", false))
+			},
+			want: map[string]interface{}{
+				"short_message": "disk almost full",
+				"level":         float64(4),
+				"_free":         "5%",
+				"_mounts":       float64(3),
+				"_critical":     "false",
+			},
+		},
+		{
+			name: "Groups are flattened",
+			log: func(logger *slog.Logger) {
+				logger.Info("order placed", slog.Group("user", "id", 7, slog.Group("address", "city", "Berlin")))
+			},
+			want: map[string]interface{}{"_user.id": float64(7), "_user.address.city": "Berlin"},
+		},
+		{
+			name: "WithGroup and WithAttrs",
+			log: func(logger *slog.Logger) {
+				logger.With("service", "billing").WithGroup("request").With("id", "r-1").WithGroup("db").Info("query", "rows", 2)
+			},
+			want: map[string]interface{}{"_service": "billing", "_request.id": "r-1", "_request.db.rows": float64(2)},
+		},
+		{
+			name: "Empty keys and groups",
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("").Info("inlined", slog.Group("", "inline", 1), slog.Group("empty"), slog.Attr{}, slog.String("", "dropped"))
+			},
+			want:    map[string]interface{}{"_inline": float64(1)},
+			missing: []string{"_empty", "_"},
+		},
+		{
+			name: "Error",
+			log: func(logger *slog.Logger) {
+				logger.Error("payment failed", "error", errors.New("timeout"))
+			},
+			want: map[string]interface{}{"level": float64(3), "_error": "timeout"},
+		},
+		{
+			name: "Log valuer",
+			log: func(logger *slog.Logger) {
+				logger.Info("login", "user", secret("hunter2"))
+			},
+			want: map[string]interface{}{"_user": "***"},
+		},
+		{
+			name: "Replace attributes",
+			opts: &slog.HandlerOptions{ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+				if attr.Key == "password" {
+					return slog.Attr{}
+				}
+				if len(groups) > 0 && groups[0] == "user" {
+					attr.Value = slog.StringValue(strings.ToUpper(attr.Value.String()))
+				}
+				return attr
+			}},
+			log: func(logger *slog.Logger) {
+				logger.Info("login", "password", "hunter2", slog.Group("user", "name", "ada"))
+			},
+			want:    map[string]interface{}{"_user.name": "ADA"},
+			missing: []string{"_password"},
+		},
+		{
+			name: "Source",
+			opts: &slog.HandlerOptions{AddSource: true},
+			log: func(logger *slog.Logger) {
+				logger.Info("started")
+			},
+			want: map[string]interface{}{"short_message": "started"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			logger := slog.New(sloglogger.NewHandler(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), tt.opts))
+			tt.log(logger)
+
+			sent := sentMessages(t, transport)
+			require.Len(t, sent, 1)
+			for key, want := range tt.want {
+				assert.Equal(t, want, sent[0][key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, sent[0], key)
+			}
+			if tt.opts != nil && tt.opts.AddSource {
+				assert.Contains(t, sent[0]["_caller"], "sloglogger_test.go:")
+			} else {
+				assert.NotContains(t, sent[0], "_caller")
+			}
+		})
+	}
+}
+
+// secret is a slog.LogValuer hiding its value.
+type secret string
+
+func (secret) LogValue() slog.Value { return slog.StringValue("***") }
+
+func TestHandlerTimestamp(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	handler := sloglogger.NewHandler(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), nil)
+	record := slog.NewRecord(time.Unix(1700000000, 500000000), slog.LevelInfo, "imported", 0)
+	require.NoError(t, handler.Handle(context.Background(), record))
+
+	sent := sentMessages(t, transport)
+	require.Len(t, sent, 1)
+	assert.Equal(t, 1700000000.5, sent[0]["timestamp"])
+}
+
+func TestHandlerContext(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing, gelflogger.WithContextExtractor(tracecontext.Extract))
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	slog.New(sloglogger.NewHandler(logger, nil)).InfoContext(ctx, "charged")
+
+	sent := sentMessages(t, transport)
+	require.Len(t, sent, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sent[0]["_trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", sent[0]["_span_id"])
+}
+
+func TestHandlerEnabled(t *testing.T) {
+	logger := gelflogger.NewLoggerWithTransport(&helper.RecordingTransport{}, helper.ProcessNothing)
+	ctx := context.Background()
+
+	handler := sloglogger.NewHandler(logger, nil)
+	assert.False(t, handler.Enabled(ctx, slog.LevelDebug))
+	assert.True(t, handler.Enabled(ctx, slog.LevelInfo))
+
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelError)
+	handler = sloglogger.NewHandler(logger, &slog.HandlerOptions{Level: level})
+	assert.False(t, handler.Enabled(ctx, slog.LevelWarn))
+	level.Set(slog.LevelDebug)
+	assert.True(t, handler.Enabled(ctx, slog.LevelDebug))
+}
+
+func TestConvertSlogLevelToGraylog(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  int
+	}{
+		{level: slog.LevelDebug - 4, want: 7},
+		{level: slog.LevelDebug, want: 7},
+		{level: slog.LevelInfo, want: 6},
+		{level: slog.LevelInfo + 2, want: 5},
+		{level: slog.LevelWarn, want: 4},
+		{level: slog.LevelError, want: 3},
+		{level: slog.LevelError + 2, want: 3},
+		{level: slog.LevelError + 4, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, sloglogger.ConvertSlogLevelToGraylog(tt.level))
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package sqllogger
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// errNamedArgs is the error of named arguments passed to a driver not supporting them, like the one of database/sql.
+var errNamedArgs = errors.New("sqllogger: driver does not support the use of Named Parameters")
+
+// loggingConn is a driver.Conn logging its slow queries. It implements the optional interfaces of database/sql,
+// passing them on to the wrapped connection, or returning driver.ErrSkip if it does not implement them, so
+// database/sql falls back to the way it takes for the wrapped connection.
+type loggingConn struct {
+	driver.Conn
+	cfg *config
+}
+
+var (
+	_ driver.ConnPrepareContext = (*loggingConn)(nil)
+	_ driver.ConnBeginTx        = (*loggingConn)(nil)
+	_ driver.ExecerContext      = (*loggingConn)(nil)
+	_ driver.QueryerContext     = (*loggingConn)(nil)
+	_ driver.NamedValueChecker  = (*loggingConn)(nil)
+	_ driver.Pinger             = (*loggingConn)(nil)
+	_ driver.SessionResetter    = (*loggingConn)(nil)
+	_ driver.Validator          = (*loggingConn)(nil)
+)
+
+// Prepare prepares a statement logging its slow executions.
+func (c *loggingConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a statement logging its slow executions. Slow preparations are logged as well.
+func (c *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	c.cfg.logSlow(ctx, query, 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingStmt{Stmt: stmt, query: query, cfg: c.cfg}, nil
+}
+
+// BeginTx starts a transaction. Connections not implementing driver.ConnBeginTx support the default options only, like
+// in database/sql.
+func (c *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 {
+		return nil, errors.New("sqllogger: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sqllogger: driver does not support read-only transactions")
+	}
+	return c.Conn.Begin()
+}
+
+// ExecContext executes a statement and logs it if it is slow.
+func (c *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.cfg.logSlow(ctx, query, len(args), start, err)
+	return result, err
+}
+
+// QueryContext runs a query and logs it if it is slow.
+func (c *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.cfg.logSlow(ctx, query, len(args), start, err)
+	return rows, err
+}
+
+// CheckNamedValue checks an argument with the wrapped connection.
+func (c *loggingConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// Ping pings the wrapped connection.
+func (c *loggingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the session of the wrapped connection.
+func (c *loggingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the wrapped connection is valid.
+func (c *loggingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// loggingStmt is a driver.Stmt logging its slow executions.
+type loggingStmt struct {
+	driver.Stmt
+	query string
+	cfg   *config
+}
+
+var (
+	_ driver.StmtExecContext  = (*loggingStmt)(nil)
+	_ driver.StmtQueryContext = (*loggingStmt)(nil)
+)
+
+// Exec executes the statement and logs it if it is slow.
+func (s *loggingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.exec(context.Background(), args)
+}
+
+// exec executes the statement without context and logs it with the fields of the given context if it is slow.
+func (s *loggingStmt) exec(ctx context.Context, args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	result, err := s.Stmt.Exec(args)
+	s.cfg.logSlow(ctx, s.query, len(args), start, err)
+	return result, err
+}
+
+// Query runs the statement and logs it if it is slow.
+func (s *loggingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.run(context.Background(), args)
+}
+
+// run runs the statement without context and logs it with the fields of the given context if it is slow.
+func (s *loggingStmt) run(ctx context.Context, args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.Stmt.Query(args)
+	s.cfg.logSlow(ctx, s.query, len(args), start, err)
+	return rows, err
+}
+
+// ExecContext executes the statement and logs it if it is slow.
+func (s *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.exec(ctx, values)
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, args)
+	s.cfg.logSlow(ctx, s.query, len(args), start, err)
+	return result, err
+}
+
+// QueryContext runs the statement and logs it if it is slow.
+func (s *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.run(ctx, values)
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	s.cfg.logSlow(ctx, s.query, len(args), start, err)
+	return rows, err
+}
+
+// namedValuesToValues returns the values of the arguments for statements without context, which do not support
+// named arguments.
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
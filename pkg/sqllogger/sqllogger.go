@@ -0,0 +1,259 @@
+package sqllogger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// The fields of the messages of slow queries.
+const (
+	// QueryField is the field of the normalized statement of the query, see NormalizeQuery.
+	QueryField = "sql_query"
+	// ArgsField is the field of the number of bind parameters of the query.
+	ArgsField = "sql_args"
+	// DurationField is the field of the duration of the query in milliseconds.
+	DurationField = "duration_ms"
+	// ErrorField is the field of the error of a failed query.
+	ErrorField = "error"
+)
+
+// DefaultThreshold is the duration of the queries logged as slow by default, see WithThreshold.
+const DefaultThreshold = 200 * time.Millisecond
+
+// Option configures the logging of slow queries, see NewConnector.
+type Option func(*config)
+
+// config holds the settings of the logging of slow queries.
+//
+// - logger: The Logger the slow queries are logged with.
+// - threshold: The duration of the queries logged as slow, see WithThreshold.
+// - level: The Graylog (Syslog) level of the slow queries, see WithLevel.
+type config struct {
+	logger    *gelflogger.Logger
+	threshold time.Duration
+	level     int
+}
+
+// WithThreshold logs the queries taking at least the given duration, DefaultThreshold by default. A threshold of 0 logs
+// every query.
+func WithThreshold(threshold time.Duration) Option {
+	return func(cfg *config) {
+		cfg.threshold = threshold
+	}
+}
+
+// WithLevel sets the Graylog (Syslog) level of the slow queries, gelflogger.Warning by default.
+func WithLevel(level int) Option {
+	return func(cfg *config) {
+		cfg.level = level
+	}
+}
+
+// NewConnector returns a driver.Connector logging the slow queries of the connections of the given Connector with the
+// Logger, for applications using database/sql without an ORM. Every query, statement execution and prepared statement
+// taking at least the threshold is logged, as message `Slow SQL query` with the normalized statement, the number of
+// bind parameters, the duration and the error of failed queries; the values of the bind parameters are not logged, as
+// they may hold personal data. The duration of a query is the time until its first rows are returned, not the time
+// the rows are read. Errors of the Logger are dropped, as they must not fail the queries; they are counted by the
+// statistics of the Logger, see gelflogger.Logger.Stats.
+//
+// Example usage:
+//
+//	connector, err := pq.NewConnector(dsn)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	db := sql.OpenDB(sqllogger.NewConnector(connector, graylogLogger, sqllogger.WithThreshold(time.Second)))
+func NewConnector(connector driver.Connector, logger *gelflogger.Logger, opts ...Option) driver.Connector {
+	cfg := &config{logger: logger, threshold: DefaultThreshold, level: gelflogger.Warning}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &loggingConnector{Connector: connector, cfg: cfg}
+}
+
+// OpenDB opens a database of the registered driver with the given name, like sql.Open, logging its slow queries with
+// the Logger, see NewConnector.
+//
+// Example usage:
+//
+//	db, err := sqllogger.OpenDB("postgres", dsn, graylogLogger)
+func OpenDB(driverName, dataSourceName string, logger *gelflogger.Logger, opts ...Option) (*sql.DB, error) {
+	// sql.Open does not connect, it only looks the driver up
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	var connector driver.Connector = dsnConnector{dsn: dataSourceName, driver: drv}
+	if driverCtx, ok := drv.(driver.DriverContext); ok {
+		if connector, err = driverCtx.OpenConnector(dataSourceName); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(NewConnector(connector, logger, opts...)), nil
+}
+
+// dsnConnector is the driver.Connector of the drivers not implementing driver.DriverContext, like the one of sql.Open.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+// Connect opens a connection to the data source.
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver returns the driver.
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// loggingConnector is the driver.Connector returned by NewConnector.
+type loggingConnector struct {
+	driver.Connector
+	cfg *config
+}
+
+// Connect opens a connection logging its slow queries.
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{Conn: conn, cfg: c.cfg}, nil
+}
+
+// Close closes the Connector, if it is an io.Closer, which sql.DB.Close calls.
+func (c *loggingConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// logSlow logs the query with the given number of arguments, which started at the given time, if it took at least the
+// threshold, with the fields of the context of the query, see gelflogger.WithContextExtractor. The driver.ErrSkip of
+// drivers not implementing an optional interface is not logged, as database/sql runs the query another way then.
+func (cfg *config) logSlow(ctx context.Context, query string, args int, start time.Time, err error) {
+	duration := time.Since(start)
+	if duration < cfg.threshold || errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	fields := map[string]interface{}{
+		QueryField:    NormalizeQuery(query),
+		ArgsField:     args,
+		DurationField: float64(duration) / float64(time.Millisecond),
+	}
+	if err != nil {
+		fields[ErrorField] = err
+	}
+	_ = cfg.logger.LogAtContext(ctx, cfg.level, time.Time{}, "Slow SQL query", fields)
+}
+
+// NormalizeQuery returns the normalized statement of a query, so the queries differing only in their literals can be
+// grouped in Graylog: comments are removed, whitespace is collapsed into single spaces, and string and number
+// literals are replaced by ?, e.g.
+//
+//	SELECT * FROM orders
+//	WHERE id = 42 AND status = 'paid' -- recent orders
+//
+// is normalized to `SELECT * FROM orders WHERE id = ? AND status = ?`. Quoted identifiers and bind parameters, e.g.
+// $1, :id or @p1, are kept.
+func NormalizeQuery(query string) string {
+	var normalized strings.Builder
+	normalized.Grow(len(query))
+	space := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case strings.HasPrefix(query[i:], "--"):
+			i = skipUntil(query, i+2, "\n")
+			space = true
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			i = skipUntil(query, i+2, "*/")
+			space = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+			space = true
+			continue
+		}
+		if space && normalized.Len() > 0 {
+			normalized.WriteByte(' ')
+		}
+		space = false
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i)
+			normalized.WriteByte('?')
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i)
+			normalized.WriteString(query[i:end])
+			i = end
+		case isDigit(c) && !continuesWord(normalized.String()):
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			normalized.WriteByte('?')
+		default:
+			normalized.WriteByte(c)
+			i++
+		}
+	}
+	return normalized.String()
+}
+
+// skipUntil returns the index after the given end in the query, searched from the given index, or the length of the
+// query if it does not end.
+func skipUntil(query string, from int, end string) int {
+	index := strings.Index(query[from:], end)
+	if index < 0 {
+		return len(query)
+	}
+	return from + index + len(end)
+}
+
+// skipQuoted returns the index after the literal or identifier quoted by the character at the given index. A doubled
+// quote within is an escaped quote, not the end.
+func skipQuoted(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+// isDigit reports whether the character is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// continuesWord reports whether a digit following the normalized statement is part of an identifier or bind
+// parameter, e.g. of t1 or $1, instead of a number.
+func continuesWord(normalized string) bool {
+	if normalized == "" {
+		return false
+	}
+	c := normalized[len(normalized)-1]
+	return c == '_' || c == '$' || c == ':' || c == '@' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
@@ -0,0 +1,245 @@
+package sqllogger_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/sqllogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errTableMissing is the error of the queries of the fake driver on the table missing.
+var errTableMissing = errors.New(`relation "missing" does not exist`)
+
+// fakeDriver is a driver.Driver opening fakeConns, or plainConns implementing no optional interface.
+type fakeDriver struct {
+	plain bool
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	if d.plain {
+		return plainConn{}, nil
+	}
+	return fakeConn{}, nil
+}
+
+// fakeConnector is the driver.Connector of a fakeDriver.
+type fakeConnector struct {
+	fakeDriver
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.Open("") }
+
+func (c fakeConnector) Driver() driver.Driver { return c.fakeDriver }
+
+// plainConn is a driver.Conn running queries by prepared statements only.
+type plainConn struct{}
+
+func (plainConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+
+func (plainConn) Close() error { return nil }
+
+func (plainConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+// fakeConn is a driver.Conn running queries directly.
+type fakeConn struct {
+	plainConn
+}
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	return run(query, driver.RowsAffected(1))
+}
+
+func (fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return run[driver.Rows](query, &fakeRows{})
+}
+
+// fakeStmt is a driver.Stmt without context.
+type fakeStmt struct {
+	query string
+}
+
+func (s fakeStmt) Close() error { return nil }
+
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return run(s.query, driver.RowsAffected(1))
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return run[driver.Rows](s.query, &fakeRows{})
+}
+
+// run returns the result of the query, failing with errTableMissing for queries of the table missing.
+func run[T any](query string, result T) (T, error) {
+	var none T
+	if strings.Contains(query, "missing") {
+		return none, errTableMissing
+	}
+	return result, nil
+}
+
+// fakeTx is a driver.Tx doing nothing.
+type fakeTx struct{}
+
+func (fakeTx) Commit() error { return nil }
+
+func (fakeTx) Rollback() error { return nil }
+
+// fakeRows are driver.Rows of a single row with a single column.
+type fakeRows struct {
+	read bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"count"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = int64(3)
+	return nil
+}
+
+// decode decodes the sent GELF messages.
+func decode(t *testing.T, transport *helper.RecordingTransport) []map[string]interface{} {
+	sent := make([]map[string]interface{}, 0, len(transport.Messages()))
+	for _, message := range transport.Messages() {
+		var gelfMsg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(message), &gelfMsg))
+		sent = append(sent, gelfMsg)
+	}
+	return sent
+}
+
+func TestNewConnector(t *testing.T) {
+	for _, plain := range []bool{false, true} {
+		name := "Connection running queries directly"
+		if plain {
+			name = "Connection running prepared statements only"
+		}
+		t.Run(name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing)
+			db := sql.OpenDB(sqllogger.NewConnector(fakeConnector{fakeDriver{plain: plain}}, logger, sqllogger.WithThreshold(0)))
+			defer func() { assert.NoError(t, db.Close()) }()
+
+			_, err := db.Exec("UPDATE orders\n\tSET status = 'paid' WHERE id = $1 AND total > 10.5", 42)
+			require.NoError(t, err)
+			var count int
+			require.NoError(t, db.QueryRow("SELECT count(*) FROM orders WHERE customer = ?", "c-7").Scan(&count))
+			assert.Equal(t, 3, count)
+			_, err = db.Exec("DELETE FROM missing")
+			assert.ErrorIs(t, err, errTableMissing)
+
+			var queries []map[string]interface{}
+			for _, gelfMsg := range decode(t, transport) {
+				// Connections without ExecerContext and QueryerContext prepare every statement, which is logged as well, without args
+				if gelfMsg["_sql_args"] != float64(0) || gelfMsg["_error"] != nil || !plain {
+					queries = append(queries, gelfMsg)
+				}
+			}
+			require.Len(t, queries, 3)
+			assert.Equal(t, "Slow SQL query", queries[0]["short_message"])
+			assert.Equal(t, float64(4), queries[0]["level"])
+			assert.Equal(t, "UPDATE orders SET status = ? WHERE id = $1 AND total > ?", queries[0]["_sql_query"])
+			assert.Equal(t, float64(1), queries[0]["_sql_args"])
+			assert.GreaterOrEqual(t, queries[0]["_duration_ms"], float64(0))
+			assert.NotContains(t, queries[0], "_error")
+			assert.Equal(t, "SELECT count(*) FROM orders WHERE customer = ?", queries[1]["_sql_query"])
+			assert.Equal(t, "DELETE FROM missing", queries[2]["_sql_query"])
+			assert.Equal(t, `relation "missing" does not exist`, queries[2]["_error"])
+		})
+	}
+}
+
+func TestNewConnectorThreshold(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing)
+	db := sql.OpenDB(sqllogger.NewConnector(fakeConnector{}, logger))
+	defer func() { assert.NoError(t, db.Close()) }()
+
+	_, err := db.Exec("UPDATE orders SET status = 'paid'")
+	require.NoError(t, err)
+	assert.Empty(t, transport.Messages())
+}
+
+// registerFakeDriver registers fakeDriver once, as -count reruns TestOpenDB and drivers cannot be unregistered.
+var registerFakeDriver sync.Once
+
+func TestOpenDB(t *testing.T) {
+	registerFakeDriver.Do(func() { sql.Register("sqllogger-fake", fakeDriver{}) })
+	transport := &helper.RecordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing)
+
+	_, err := sqllogger.OpenDB("sqllogger-unknown", "", logger)
+	assert.Error(t, err)
+
+	db, err := sqllogger.OpenDB("sqllogger-fake", "", logger, sqllogger.WithThreshold(0), sqllogger.WithLevel(gelflogger.Notice))
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, db.Close()) }()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO orders (id) VALUES (1)")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	sent := decode(t, transport)
+	require.Len(t, sent, 1)
+	assert.Equal(t, float64(5), sent[0]["level"])
+	assert.Equal(t, "INSERT INTO orders (id) VALUES (?)", sent[0]["_sql_query"])
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Whitespace and comments",
+			query: "  SELECT *\n\tFROM orders -- all orders\n WHERE /* paid */ status = $1  ",
+			want:  "SELECT * FROM orders WHERE status = $1",
+		},
+		{
+			name:  "String literals",
+			query: "SELECT * FROM users WHERE name = 'O''Brien' AND city = 'Köln'",
+			want:  "SELECT * FROM users WHERE name = ? AND city = ?",
+		},
+		{
+			name:  "Number literals",
+			query: "SELECT * FROM orders WHERE total > 10.5 AND id IN (1, 2, 3) LIMIT 10 OFFSET -20",
+			want:  "SELECT * FROM orders WHERE total > ? AND id IN (?, ?, ?) LIMIT ? OFFSET -?",
+		},
+		{
+			name:  "Identifiers and bind parameters are kept",
+			query: `SELECT t1.id, "col 2", ` + "`x3`" + ` FROM t1 WHERE a = :p1 AND b = @p2 AND c = $3`,
+			want:  `SELECT t1.id, "col 2", ` + "`x3`" + ` FROM t1 WHERE a = :p1 AND b = @p2 AND c = $3`,
+		},
+		{
+			name:  "Unterminated literal",
+			query: "SELECT 'open",
+			want:  "SELECT ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sqllogger.NormalizeQuery(tt.query))
+		})
+	}
+}
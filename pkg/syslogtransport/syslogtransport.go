@@ -0,0 +1,255 @@
+package syslogtransport
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// Facility is the syslog facility messages are logged with.
+type Facility int
+
+const (
+	Kern   Facility = 0
+	User   Facility = 1
+	Daemon Facility = 3
+	Auth   Facility = 4
+	Local0 Facility = 16
+	Local1 Facility = 17
+	Local2 Facility = 18
+	Local3 Facility = 19
+	Local4 Facility = 20
+	Local5 Facility = 21
+	Local6 Facility = 22
+	Local7 Facility = 23
+)
+
+// StructuredDataID is the SD-ID of the structured data element carrying the additional fields of the GELF messages.
+// 32473 is the private enterprise number reserved for documentation and examples.
+const StructuredDataID = "gelf@32473"
+
+// nilValue is the RFC 5424 NILVALUE used for empty header fields.
+const nilValue = "-"
+
+// Transport renders GELF messages as RFC 5424 syslog messages and sends them to a syslog collector, so the same
+// Logger can feed legacy collectors in environments without Graylog.
+//
+// The GELF level becomes the severity, short_message the MSG, host the HOSTNAME and the GELF facility the APP-NAME.
+// The additional fields become the parameters of the structured data element StructuredDataID. Over UDP and Unix
+// datagram sockets, every message is sent as one datagram, over stream connections (TCP, TLS, Unix) the messages are
+// framed by octet counting as described in RFC 6587.
+type Transport struct {
+	conn      net.Conn
+	connLock  sync.Mutex
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	Facility  Facility
+	AppName   string
+}
+
+var _ gelflogger.Transport = (*Transport)(nil)
+
+// New creates a Transport and connects to the syslog collector.
+// It takes the following arguments:
+// - network: "udp", "tcp", "unix" or "unixgram"
+// - address: the address of the collector, e.g. "syslog.example.com:514" or "/dev/log"
+// - tlsConfig: if set, the TCP connection is secured with TLS (RFC 5425)
+//
+// Messages are logged with the facility User and the name of the executable as APP-NAME, if the GELF message has no
+// facility. Both can be changed with the Facility and AppName fields.
+//
+// Example usage:
+//
+//	transport, err := syslogtransport.New("tcp", "syslog.example.com:601", nil)
+//	if err != nil {
+//	  // handle error
+//	}
+//	transport.Facility = syslogtransport.Local0
+//	logger := gelflogger.NewLoggerWithTransport(transport, zerologger.ProcessZerologFields)
+func New(network, address string, tlsConfig *tls.Config) (*Transport, error) {
+	appName := nilValue
+	if executable, err := os.Executable(); err == nil {
+		appName = executable[strings.LastIndexAny(executable, `/\`)+1:]
+	}
+	t := &Transport{network: network, address: address, tlsConfig: tlsConfig, Facility: User, AppName: appName}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	if err := t.connect(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// connect dials the collector and replaces the current connection. The caller must hold connLock.
+func (t *Transport) connect() error {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if t.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, t.network, t.address, t.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(t.network, t.address)
+	}
+	if err != nil {
+		return err
+	}
+	if t.conn != nil {
+		_ = t.conn.Close()
+	}
+	t.conn = conn
+	return nil
+}
+
+// Send renders the GELF message as syslog message and writes it to the collector.
+// If the write fails, it reconnects once and retries the write.
+func (t *Transport) Send(message []byte) error {
+	syslogMessage, err := Format(message, t.Facility, t.AppName)
+	if err != nil {
+		return err
+	}
+	if t.network != "udp" && t.network != "unixgram" {
+		syslogMessage = append([]byte(strconv.Itoa(len(syslogMessage))+" "), syslogMessage...)
+	}
+
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn != nil {
+		if _, err = t.conn.Write(syslogMessage); err == nil {
+			return nil
+		}
+	}
+	if err = t.connect(); err != nil {
+		return err
+	}
+	_, err = t.conn.Write(syslogMessage)
+	return err
+}
+
+// Close closes the connection to the collector.
+func (t *Transport) Close() error {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// Format renders the GELF message as RFC 5424 syslog message with the given facility. The appName is used as
+// APP-NAME if the GELF message has no facility.
+func Format(message []byte, facility Facility, appName string) ([]byte, error) {
+	var gelfMsg map[string]interface{}
+	if err := json.Unmarshal(message, &gelfMsg); err != nil {
+		return nil, err
+	}
+
+	severity := 6
+	if level, ok := gelfMsg["level"].(float64); ok && level >= 0 && level <= 7 {
+		severity = int(level)
+	}
+	timestamp := nilValue
+	if ts, ok := gelfMsg["timestamp"].(float64); ok && ts > 0 {
+		seconds, fraction := math.Modf(ts)
+		timestamp = time.Unix(int64(seconds), int64(fraction*1e9)).UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+	if gelfFacility, ok := gelfMsg["facility"].(string); ok && gelfFacility != "" {
+		appName = gelfFacility
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ", int(facility)*8+severity, timestamp,
+		headerField(gelfMsg["host"], 255), headerField(appName, 48), headerField(os.Getpid(), 128), nilValue)
+	writeStructuredData(&b, gelfMsg)
+	if shortMessage, ok := gelfMsg["short_message"].(string); ok && shortMessage != "" {
+		b.WriteString(" ")
+		b.WriteString(shortMessage)
+	}
+	return []byte(b.String()), nil
+}
+
+// writeStructuredData writes the additional fields of the GELF message as structured data element, sorted by name,
+// or the NILVALUE if there are none.
+func writeStructuredData(b *strings.Builder, gelfMsg map[string]interface{}) {
+	names := make([]string, 0, len(gelfMsg))
+	for k := range gelfMsg {
+		if strings.HasPrefix(k, "_") {
+			names = append(names, k)
+		}
+	}
+	if len(names) == 0 {
+		b.WriteString(nilValue)
+		return
+	}
+	sort.Strings(names)
+
+	b.WriteString("[" + StructuredDataID)
+	for _, name := range names {
+		fmt.Fprintf(b, ` %s="%s"`, paramName(strings.TrimPrefix(name, "_")), escapeParamValue(formatValue(gelfMsg[name])))
+	}
+	b.WriteString("]")
+}
+
+// headerField formats a header field as printable US-ASCII of at most maxLength characters, or NILVALUE if empty.
+func headerField(v interface{}, maxLength int) string {
+	value := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, formatValue(v))
+	if value == "" {
+		return nilValue
+	}
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	return value
+}
+
+// paramName replaces the characters not allowed in SD-PARAM names with underscores and truncates it to 32 characters.
+func paramName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// escapeParamValue escapes '"', '\' and ']' in SD-PARAM values with a backslash.
+func escapeParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// formatValue formats a decoded JSON value as string.
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,115 @@
+package syslogtransport_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/syslogtransport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	pid := os.Getpid()
+	tests := []struct {
+		name     string
+		message  string
+		facility syslogtransport.Facility
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "With additional fields",
+			message:  `{"version":"1.1","host":"web-1","short_message":"disk full","timestamp":1700000000.5,"level":3,"_request_id":"abc","_path":"/a\"b]"}`,
+			facility: syslogtransport.Local0,
+			want:     fmt.Sprintf(`<131>1 2023-11-14T22:13:20.500000Z web-1 app %d - [gelf@32473 path="/a\"b\]" request_id="abc"] disk full`, pid),
+		},
+		{
+			name:     "GELF facility becomes the app name",
+			message:  `{"host":"web-1","short_message":"started","facility":"billing"}`,
+			facility: syslogtransport.User,
+			want:     fmt.Sprintf(`<14>1 - web-1 billing %d - - started`, pid),
+		},
+		{
+			name:    "Invalid JSON",
+			message: `{"short_message":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := syslogtransport.Format([]byte(tt.message), tt.facility, "app")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	t.Run("TCP with octet counting", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = l.Close() }()
+		received := make(chan string, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			reader := bufio.NewReader(conn)
+			length, _ := reader.ReadString(' ')
+			n, _ := strconv.Atoi(length[:len(length)-1])
+			message := make([]byte, n)
+			_, _ = io.ReadFull(reader, message)
+			received <- string(message)
+		}()
+
+		transport, err := syslogtransport.New("tcp", l.Addr().String(), nil)
+		require.NoError(t, err)
+		defer func() { _ = transport.Close() }()
+		logger := gelflogger.NewLoggerWithTransport(transport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+			return 4, 0, nil, nil
+		})
+		require.NoError(t, logger.Log("hello syslog", map[string]interface{}{}))
+
+		select {
+		case message := <-received:
+			assert.Regexp(t, `^<12>1 - \S+ \S+ \d+ - - hello syslog$`, message)
+		case <-time.After(time.Second):
+			t.Fatal("message was not received")
+		}
+	})
+
+	t.Run("UDP datagram", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = pc.Close() }()
+
+		transport, err := syslogtransport.New("udp", pc.LocalAddr().String(), nil)
+		require.NoError(t, err)
+		defer func() { _ = transport.Close() }()
+		logger := gelflogger.NewLoggerWithTransport(transport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+			return 6, 0, nil, nil
+		})
+		require.NoError(t, logger.Log("hello syslog", map[string]interface{}{}))
+
+		buf := make([]byte, 2048)
+		require.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Regexp(t, `^<14>1 - \S+ \S+ \d+ - - hello syslog$`, string(buf[:n]))
+	})
+}
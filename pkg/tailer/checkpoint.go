@@ -0,0 +1,96 @@
+package tailer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// fingerprintSize is the maximum number of bytes at the start of a file its fingerprint is computed of.
+const fingerprintSize = 1024
+
+// checkpoint is the position in the tailed file persisted across restarts, see WithCheckpoint. The file is recognized
+// by the fingerprint of its first bytes, as its path is reused by the rotated files and inode numbers are neither
+// portable nor stable across file systems.
+//
+// - Offset: The offset of the first line not yet shipped.
+// - Fingerprint: The hex encoded SHA-256 hash of the first FingerprintSize bytes of the file.
+// - FingerprintSize: The number of bytes hashed, fewer than fingerprintSize if the file was smaller.
+type checkpoint struct {
+	Offset          int64  `json:"offset"`
+	Fingerprint     string `json:"fingerprint"`
+	FingerprintSize int    `json:"fingerprint_size"`
+}
+
+// fingerprint returns the hex encoded SHA-256 hash of the first size bytes of the file. ok is false if the file has
+// fewer bytes.
+func fingerprint(file *os.File, size int) (string, bool) {
+	buf := make([]byte, size)
+	if n, _ := file.ReadAt(buf, 0); n < size {
+		return "", false
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// newCheckpoint returns the checkpoint of the offset in the file.
+func newCheckpoint(file *os.File, offset int64) checkpoint {
+	size := int(min(offset, fingerprintSize))
+	sum, _ := fingerprint(file, size)
+	return checkpoint{Offset: offset, Fingerprint: sum, FingerprintSize: size}
+}
+
+// resumeOffset returns the offset to resume tailing the file at, i.e. the offset of the checkpoint if the file is the
+// one of the checkpoint and has not been truncated since. ok is false otherwise.
+func (c checkpoint) resumeOffset(file *os.File, size int64) (int64, bool) {
+	if c.Offset > size {
+		return 0, false
+	}
+	sum, ok := fingerprint(file, c.FingerprintSize)
+	if !ok || sum != c.Fingerprint {
+		return 0, false
+	}
+	return c.Offset, true
+}
+
+// loadCheckpoint reads the checkpoint of the file at path. ok is false if there is none.
+func loadCheckpoint(path string) (checkpoint, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return checkpoint{}, false, nil
+	}
+	if err != nil {
+		return checkpoint{}, false, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return checkpoint{}, false, err
+	}
+	return c, true, nil
+}
+
+// save writes the checkpoint to the file at path. It is written to a temporary file renamed to path, so a crash
+// never leaves a partial checkpoint.
+func (c checkpoint) save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,67 @@
+package tailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser parses a line of a log file into the short message and the fields of its GELF message. The fields are read
+// by the processor of the Logger like the fields of gelflogger.Logger.Log, e.g. the "level" and "time" fields by
+// gelflogger.ProcessFields. An error makes the Tailer send the line as it is, see ParsePlain, with the error as
+// ParseErrorField, so no line is lost.
+type Parser func(line string) (message string, fields map[string]interface{}, err error)
+
+// ParsePlain is the Parser of plain text lines, the default of the Tailer. The line is the short message, without
+// fields, so it is logged at the level informational.
+func ParsePlain(line string) (string, map[string]interface{}, error) {
+	return line, map[string]interface{}{}, nil
+}
+
+// ParseJSON is the Parser of JSON lines, e.g. written by zerolog, zap or slog. The "message" or "msg" member is the
+// short message, the others are the fields.
+func ParseJSON(line string) (string, map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON line: %w", err)
+	}
+	if message, ok := fields["message"].(string); ok {
+		return message, fields, nil
+	}
+	if message, ok := fields["msg"].(string); ok {
+		delete(fields, "msg")
+		return message, fields, nil
+	}
+	return "", nil, fmt.Errorf("JSON line without message or msg")
+}
+
+// RegexParser returns a Parser of lines matching the pattern, e.g. of access logs or legacy text formats. The named
+// groups become the fields, the group "message" the short message. Without message group, the line is the short
+// message. Empty groups are omitted. Lines not matching the pattern are rejected.
+//
+// Example usage:
+//
+//	parser := tailer.RegexParser(regexp.MustCompile(`^(?P<time>\S+) (?P<level>[A-Z]+) (?P<message>.*)$`))
+func RegexParser(pattern *regexp.Regexp) Parser {
+	names := pattern.SubexpNames()
+	return func(line string) (string, map[string]interface{}, error) {
+		match := pattern.FindStringSubmatch(line)
+		if match == nil {
+			return "", nil, fmt.Errorf("line does not match %s", pattern)
+		}
+		message := line
+		fields := make(map[string]interface{}, len(names))
+		for i, name := range names {
+			if name == "" || match[i] == "" {
+				continue
+			}
+			if name == "message" {
+				message = strings.TrimSpace(match[i])
+				continue
+			}
+			fields[name] = match[i]
+		}
+		return message, fields, nil
+	}
+}
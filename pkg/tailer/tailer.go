@@ -0,0 +1,313 @@
+package tailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// The fields added to the messages of the tailed lines.
+const (
+	// FileField is the field of the path of the tailed file.
+	FileField = "file_path"
+	// ParseErrorField is the field of the error of a line the Parser rejected, which is sent as it is instead.
+	ParseErrorField = "parse_error"
+)
+
+// DefaultPollInterval is the interval the tailed file is checked for new lines, rotation and truncation by default,
+// see WithPollInterval.
+const DefaultPollInterval = 250 * time.Millisecond
+
+// maxLineSize is the maximum size of a line; longer lines are split, so a file without line breaks cannot exhaust the
+// memory.
+const maxLineSize = 1 << 20
+
+// readBufferSize is the size of the chunks the tailed file is read in.
+const readBufferSize = 32 << 10
+
+// Option configures a Tailer, see New.
+type Option func(*Tailer)
+
+// WithParser sets the Parser of the lines, ParsePlain by default.
+func WithParser(parser Parser) Option {
+	return func(t *Tailer) {
+		t.parser = parser
+	}
+}
+
+// WithPollInterval sets the interval the file is checked for new lines, rotation and truncation, DefaultPollInterval by
+// default.
+func WithPollInterval(interval time.Duration) Option {
+	return func(t *Tailer) {
+		t.pollInterval = interval
+	}
+}
+
+// WithCheckpoint persists the offset of the shipped lines in the file at the given path, e.g.
+// /var/lib/myapp/app.log.checkpoint, so a restarted Tailer resumes where the previous one stopped instead of shipping
+// the file again or skipping the lines written in between. The checkpoint is written after every poll which shipped
+// lines, and when Run returns. It is ignored if the file was rotated or truncated since.
+func WithCheckpoint(path string) Option {
+	return func(t *Tailer) {
+		t.checkpointPath = path
+	}
+}
+
+// WithStartAtEnd skips the lines already in the file when the Tailer starts without checkpoint, like tail -f. By
+// default, the whole file is shipped. The files the tailed file is rotated to later are always shipped from their
+// start.
+func WithStartAtEnd() Option {
+	return func(t *Tailer) {
+		t.startAtEnd = true
+	}
+}
+
+// WithFields adds the given fields to the messages of all lines, e.g. the name of the application writing the file.
+// The fields of the parsed lines take precedence.
+func WithFields(fields map[string]interface{}) Option {
+	return func(t *Tailer) {
+		t.fields = fields
+	}
+}
+
+// Tailer follows a log file like tail -F and ships its lines through a Logger, a lightweight alternative to running
+// Filebeat on small hosts. It detects the rotation of the file, i.e. the file being renamed or removed and created
+// anew, by comparing the file at the path with the one read, and the truncation of the file, e.g. by logrotate's
+// copytruncate, by its size shrinking below the offset read. The lines the old file received before the rotation are
+// shipped before the new file is read from its start.
+//
+// - logger: The Logger the lines are shipped with.
+// - path: The path of the tailed file.
+// - parser: The Parser of the lines, see WithParser.
+// - pollInterval: The interval of checking the file, see WithPollInterval.
+// - checkpointPath: The path of the checkpoint, or an empty string, see WithCheckpoint.
+// - startAtEnd: Whether the lines in the file at the start are skipped, see WithStartAtEnd.
+// - fields: The fields added to the messages, see WithFields.
+// - file, info: The file read and its FileInfo at the time it was opened, or nil while the file does not exist.
+// - offset: The offset of the first line not yet shipped.
+// - pending: The start of the next line, read without its line break yet.
+// - saved: The offset of the last checkpoint written, or -1.
+// - buf: The buffer the file is read into.
+type Tailer struct {
+	logger         *gelflogger.Logger
+	path           string
+	parser         Parser
+	pollInterval   time.Duration
+	checkpointPath string
+	startAtEnd     bool
+	fields         map[string]interface{}
+
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	pending []byte
+	saved   int64
+	buf     []byte
+}
+
+// New creates a Tailer shipping the lines of the file at the given path through the Logger, see Run. The file does not
+// need to exist yet.
+//
+// Example usage:
+//
+//	t := tailer.New(graylogLogger, "/var/log/myapp/app.log",
+//		tailer.WithParser(tailer.ParseJSON), tailer.WithCheckpoint("/var/lib/myapp/app.log.checkpoint"))
+//	err := t.Run(ctx)
+func New(logger *gelflogger.Logger, path string, opts ...Option) *Tailer {
+	t := &Tailer{logger: logger, path: path, parser: ParsePlain, pollInterval: DefaultPollInterval, saved: -1}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Run tails the file until the context is done, and returns nil then. It returns an error if the file or the
+// checkpoint cannot be read, or the checkpoint cannot be written. Errors of the Logger are dropped, as a single failed
+// message must not stop the tailing; they are counted by the statistics of the Logger, see gelflogger.Logger.Stats.
+// The last partial line of the file is held back until its line break is written, or the file is rotated.
+func (t *Tailer) Run(ctx context.Context) error {
+	defer t.close()
+	if err := t.open(true); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := t.poll(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return t.saveCheckpoint()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll ships the new lines of the file, handles its rotation and truncation, and writes the checkpoint.
+func (t *Tailer) poll() error {
+	if t.file == nil {
+		if err := t.open(false); err != nil || t.file == nil {
+			return err
+		}
+	}
+	if err := t.readLines(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(t.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Renamed or removed, the new file is opened once it is created. The old file is read meanwhile, as it may
+		// still be written to.
+	case err != nil:
+		return err
+	case !os.SameFile(t.info, info):
+		// Rotated, the old file was read to its end above.
+		t.flushPending()
+		t.close()
+		if err := t.open(false); err != nil {
+			return err
+		}
+		if err := t.readLines(); err != nil {
+			return err
+		}
+	case info.Size() < t.offset:
+		// Truncated, the lines written since are read from the start.
+		t.offset, t.pending = 0, t.pending[:0]
+		if err := t.readLines(); err != nil {
+			return err
+		}
+	}
+	return t.saveCheckpoint()
+}
+
+// open opens the file at the path. On the first call, the offset is taken from the checkpoint, or the end of the file
+// if WithStartAtEnd is set; files opened later, i.e. after a rotation, are read from their start. It is no error if the
+// file does not exist, the file is nil then.
+func (t *Tailer) open(first bool) error {
+	file, err := os.Open(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	t.file, t.info, t.offset, t.pending = file, info, 0, t.pending[:0]
+	if !first {
+		t.saved = -1
+		return nil
+	}
+	if t.checkpointPath != "" {
+		c, ok, err := loadCheckpoint(t.checkpointPath)
+		if err != nil {
+			return fmt.Errorf("reading the checkpoint: %w", err)
+		}
+		if ok {
+			if offset, ok := c.resumeOffset(file, info.Size()); ok {
+				t.offset, t.saved = offset, offset
+				return nil
+			}
+		}
+	}
+	if t.startAtEnd {
+		t.offset = info.Size()
+	}
+	return nil
+}
+
+// close closes the file read.
+func (t *Tailer) close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file, t.info = nil, nil
+	}
+}
+
+// readLines reads the file from the offset to its end and ships the complete lines. The offset is advanced past the
+// shipped lines, a trailing partial line is kept in pending.
+func (t *Tailer) readLines() error {
+	if t.buf == nil {
+		t.buf = make([]byte, readBufferSize)
+	}
+	buf := t.buf
+	position := t.offset + int64(len(t.pending))
+	for {
+		n, err := t.file.ReadAt(buf, position)
+		position += int64(n)
+		t.pending = append(t.pending, buf[:n]...)
+		for {
+			i := bytes.IndexByte(t.pending, '\n')
+			if i < 0 && len(t.pending) < maxLineSize {
+				break
+			}
+			if i < 0 {
+				i = maxLineSize - 1
+			}
+			t.ship(string(bytes.TrimSuffix(t.pending[:i], []byte("\r"))))
+			t.offset += int64(i + 1)
+			t.pending = t.pending[:copy(t.pending, t.pending[i+1:])]
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// flushPending ships the partial line of a rotated file, which will not be completed anymore.
+func (t *Tailer) flushPending() {
+	if len(t.pending) > 0 {
+		t.ship(string(t.pending))
+		t.offset += int64(len(t.pending))
+		t.pending = t.pending[:0]
+	}
+}
+
+// ship parses the line and logs it. Blank lines are skipped.
+func (t *Tailer) ship(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	message, fields, err := t.parser(line)
+	if err != nil {
+		message, fields = line, map[string]interface{}{ParseErrorField: err.Error()}
+	}
+	if fields == nil {
+		fields = make(map[string]interface{}, len(t.fields)+1)
+	}
+	for name, value := range t.fields {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	fields[FileField] = t.path
+	_ = t.logger.Log(message, fields)
+}
+
+// saveCheckpoint writes the checkpoint if configured and the offset changed since it was last written.
+func (t *Tailer) saveCheckpoint() error {
+	if t.checkpointPath == "" || t.file == nil || t.offset == t.saved {
+		return nil
+	}
+	if err := newCheckpoint(t.file, t.offset).save(t.checkpointPath); err != nil {
+		return fmt.Errorf("writing the checkpoint: %w", err)
+	}
+	t.saved = t.offset
+	return nil
+}
@@ -0,0 +1,229 @@
+package tailer_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/tailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shortMessages returns the short messages of the messages sent through the transport.
+func shortMessages(transport *helper.RecordingTransport) []string {
+	messages := transport.Messages()
+	shortMessages := make([]string, 0, len(messages))
+	for _, message := range messages {
+		var gelfMsg map[string]interface{}
+		if err := json.Unmarshal([]byte(message), &gelfMsg); err == nil {
+			shortMessages = append(shortMessages, gelfMsg["short_message"].(string))
+		}
+	}
+	return shortMessages
+}
+
+// appendFile appends the text to the file, creating it if necessary.
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(text)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+// startTailer runs a Tailer of the file until the test ends, and returns the transport of its Logger and a function
+// stopping it.
+func startTailer(t *testing.T, path string, opts ...tailer.Option) (*helper.RecordingTransport, func()) {
+	t.Helper()
+	transport := &helper.RecordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tailer.New(logger, path, append([]tailer.Option{tailer.WithPollInterval(5 * time.Millisecond)}, opts...)...).Run(ctx)
+	}()
+	stopped := false
+	stop := func() {
+		if !stopped {
+			stopped = true
+			cancel()
+			assert.NoError(t, <-done)
+		}
+	}
+	t.Cleanup(stop)
+	return transport, stop
+}
+
+// waitFor waits until the transport received the given short messages.
+func waitFor(t *testing.T, transport *helper.RecordingTransport, want ...string) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return assert.ObjectsAreEqual(want, shortMessages(transport)) }, 2*time.Second, 5*time.Millisecond,
+		"want %v", want)
+	assert.Equal(t, want, shortMessages(transport))
+}
+
+func TestTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "started\n")
+	transport, _ := startTailer(t, path, tailer.WithFields(map[string]interface{}{"app": "billing"}))
+	waitFor(t, transport, "started")
+
+	appendFile(t, path, "order placed\npartial")
+	waitFor(t, transport, "started", "order placed")
+	appendFile(t, path, " line\r\n\n")
+	waitFor(t, transport, "started", "order placed", "partial line")
+
+	var gelfMsg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(transport.Messages()[0]), &gelfMsg))
+	assert.Equal(t, path, gelfMsg["_"+tailer.FileField])
+	assert.Equal(t, "billing", gelfMsg["_app"])
+}
+
+func TestTailerRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "first\n")
+	transport, _ := startTailer(t, path)
+	waitFor(t, transport, "first")
+
+	// Rotated by renaming, with a last line written to the old file
+	appendFile(t, path, "last of old")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, "first of new\n")
+	waitFor(t, transport, "first", "last of old", "first of new")
+
+	// Truncated in place, like copytruncate
+	require.NoError(t, os.Truncate(path, 0))
+	appendFile(t, path, "after\n")
+	waitFor(t, transport, "first", "last of old", "first of new", "after")
+}
+
+func TestTailerMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	transport, _ := startTailer(t, path, tailer.WithStartAtEnd())
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "created later\n")
+	waitFor(t, transport, "created later")
+}
+
+func TestTailerCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	checkpoint := filepath.Join(dir, "app.log.checkpoint")
+	appendFile(t, path, "one\ntwo\n")
+
+	transport, stop := startTailer(t, path, tailer.WithCheckpoint(checkpoint))
+	waitFor(t, transport, "one", "two")
+	stop()
+
+	// Resumed after the lines shipped before
+	appendFile(t, path, "three\n")
+	transport, stop = startTailer(t, path, tailer.WithCheckpoint(checkpoint))
+	waitFor(t, transport, "three")
+	stop()
+
+	// Ignored for a different file at the path
+	require.NoError(t, os.Remove(path))
+	appendFile(t, path, "uno\ndos\ntres\ncuatro\n")
+	transport, _ = startTailer(t, path, tailer.WithCheckpoint(checkpoint))
+	waitFor(t, transport, "uno", "dos", "tres", "cuatro")
+}
+
+func TestTailerStartAtEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old\n")
+	transport, _ := startTailer(t, path, tailer.WithStartAtEnd())
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "new\n")
+	waitFor(t, transport, "new")
+}
+
+func TestTailerParsers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, `{"level":"error","msg":"disk full"}`+"\nnot json\n")
+	transport, _ := startTailer(t, path, tailer.WithParser(tailer.ParseJSON))
+	waitFor(t, transport, "disk full", "not json")
+
+	messages := transport.Messages()
+	var first, second map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(messages[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(messages[1]), &second))
+	assert.Equal(t, float64(gelflogger.Error), first["level"])
+	assert.Contains(t, second["_"+tailer.ParseErrorField], "invalid JSON line")
+}
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		parser      tailer.Parser
+		line        string
+		wantMessage string
+		wantFields  map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "Plain",
+			parser:      tailer.ParsePlain,
+			line:        "backup done",
+			wantMessage: "backup done",
+			wantFields:  map[string]interface{}{},
+		},
+		{
+			name:        "JSON with message",
+			parser:      tailer.ParseJSON,
+			line:        `{"message":"order placed","level":"info","order_id":7}`,
+			wantMessage: "order placed",
+			wantFields:  map[string]interface{}{"message": "order placed", "level": "info", "order_id": float64(7)},
+		},
+		{
+			name:        "JSON with msg",
+			parser:      tailer.ParseJSON,
+			line:        `{"msg":"order placed","level":"info"}`,
+			wantMessage: "order placed",
+			wantFields:  map[string]interface{}{"level": "info"},
+		},
+		{name: "JSON without message", parser: tailer.ParseJSON, line: `{"level":"info"}`, wantErr: true},
+		{name: "Invalid JSON", parser: tailer.ParseJSON, line: `{"level":`, wantErr: true},
+		{
+			name:        "Regex",
+			parser:      tailer.RegexParser(regexp.MustCompile(`^(?P<time>\S+) (?P<level>[A-Z]+) (?:\[(?P<component>\w+)\] )?(?P<message>.*)$`)),
+			line:        "2024-05-02T10:31:00Z WARN disk almost full",
+			wantMessage: "disk almost full",
+			wantFields:  map[string]interface{}{"time": "2024-05-02T10:31:00Z", "level": "WARN"},
+		},
+		{
+			name:        "Regex without message group",
+			parser:      tailer.RegexParser(regexp.MustCompile(`status=(?P<status>\d+)`)),
+			line:        "GET /orders status=200",
+			wantMessage: "GET /orders status=200",
+			wantFields:  map[string]interface{}{"status": "200"},
+		},
+		{
+			name:    "Regex not matching",
+			parser:  tailer.RegexParser(regexp.MustCompile(`^\d+$`)),
+			line:    "no number",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, fields, err := tt.parser(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
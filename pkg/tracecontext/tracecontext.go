@@ -0,0 +1,115 @@
+package tracecontext
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// The fields of the trace context, named like the fields of the gRPC interceptors and the OpenTelemetry exporter.
+const (
+	// TraceIDField is the field of the trace ID, 32 lowercase hex digits, or 16 for the 64-bit trace IDs of B3.
+	TraceIDField = "trace_id"
+	// SpanIDField is the field of the span ID, 16 lowercase hex digits.
+	SpanIDField = "span_id"
+)
+
+// Extract returns the trace and span ID of the context as TraceIDField and SpanIDField, so the messages logged within a
+// trace can be correlated with it in Graylog. It is a gelflogger.ContextExtractor, see
+// gelflogger.WithContextExtractor. The IDs are taken from the first of:
+//
+//   - The span context of OpenTelemetry, e.g. of the active span of an instrumented handler, see
+//     trace.SpanContextFromContext.
+//   - The W3C traceparent of the incoming gRPC metadata, see ParseTraceparent.
+//   - The B3 headers of the incoming gRPC metadata, either the single b3 header or x-b3-traceid and x-b3-spanid, see
+//     ParseB3.
+//
+// It returns nil if the context carries no valid trace context.
+func Extract(ctx context.Context) map[string]interface{} {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return fields(spanContext.TraceID().String(), spanContext.SpanID().String())
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	if traceID, spanID, ok := ParseTraceparent(first(md, "traceparent")); ok {
+		return fields(traceID, spanID)
+	}
+	if traceID, spanID, ok := ParseB3(first(md, "b3")); ok {
+		return fields(traceID, spanID)
+	}
+	traceID, spanID := strings.ToLower(first(md, "x-b3-traceid")), strings.ToLower(first(md, "x-b3-spanid"))
+	if !validTraceID(traceID) || !validSpanID(spanID) {
+		return nil
+	}
+	return fields(traceID, spanID)
+}
+
+// ParseTraceparent returns the trace and span ID of a W3C traceparent, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01. ok is false if the traceparent is invalid or has the
+// all-zero trace or span ID.
+func ParseTraceparent(traceparent string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return "", "", false
+	}
+	traceID, spanID = strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !validTraceID(traceID) || !validSpanID(spanID) {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// ParseB3 returns the trace and span ID of a single B3 header, e.g. 80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1,
+// with a trace ID of 32 or 16 hex digits. ok is false if the header is invalid, or only carries the sampling decision,
+// e.g. 0.
+func ParseB3(b3 string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(b3), "-")
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	traceID, spanID = strings.ToLower(parts[0]), strings.ToLower(parts[1])
+	if !validTraceID(traceID) || !validSpanID(spanID) {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// fields returns the fields of the trace and span ID.
+func fields(traceID, spanID string) map[string]interface{} {
+	return map[string]interface{}{TraceIDField: traceID, SpanIDField: spanID}
+}
+
+// first returns the first value of the metadata key, or an empty string.
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// validTraceID reports whether the trace ID has 32 or 16 lowercase hex digits, not all zero.
+func validTraceID(id string) bool {
+	return (len(id) == 32 || len(id) == 16) && hexNotZero(id)
+}
+
+// validSpanID reports whether the span ID has 16 lowercase hex digits, not all zero.
+func validSpanID(id string) bool {
+	return len(id) == 16 && hexNotZero(id)
+}
+
+// hexNotZero reports whether the ID consists of lowercase hex digits, not all zero.
+func hexNotZero(id string) bool {
+	zero := true
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+		zero = zero && c == '0'
+	}
+	return !zero
+}
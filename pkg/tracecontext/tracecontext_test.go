@@ -0,0 +1,134 @@
+package tracecontext_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jame-developer/gelf-logger/pkg/tracecontext"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestExtract(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	incoming := func(pairs ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]interface{}
+	}{
+		{
+			name: "OpenTelemetry span context",
+			ctx:  trace.ContextWithSpanContext(incoming("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"), spanContext),
+			want: map[string]interface{}{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736", "span_id": "00f067aa0ba902b7"},
+		},
+		{
+			name: "W3C traceparent",
+			ctx:  incoming("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"),
+			want: map[string]interface{}{"trace_id": "0af7651916cd43dd8448eb211c80319c", "span_id": "b7ad6b7169203331"},
+		},
+		{
+			name: "Single B3 header",
+			ctx:  incoming("b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"),
+			want: map[string]interface{}{"trace_id": "80f198ee56343ba864fe8b2a57d3eff7", "span_id": "e457b5a2e4d86bd1"},
+		},
+		{
+			name: "Multiple B3 headers",
+			ctx:  incoming("x-b3-traceid", "463AC35C9F6413AD", "x-b3-spanid", "a2fb4a1d1a96d312"),
+			want: map[string]interface{}{"trace_id": "463ac35c9f6413ad", "span_id": "a2fb4a1d1a96d312"},
+		},
+		{
+			name: "Invalid headers",
+			ctx:  incoming("traceparent", "00-00000000000000000000000000000000-b7ad6b7169203331-01", "b3", "0", "x-b3-traceid", "not-hex"),
+		},
+		{
+			name: "No trace context",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tracecontext.Extract(tt.ctx)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+		wantSpanID  string
+		wantOK      bool
+	}{
+		{
+			name:        "Valid",
+			traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantSpanID:  "00f067aa0ba902b7",
+			wantOK:      true,
+		},
+		{name: "Missing flags", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{name: "Invalid version", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "Short trace ID", traceparent: "00-4bf92f3577b34da6-00f067aa0ba902b7-01"},
+		{name: "Zero span ID", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{name: "Not hex", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID, ok := tracecontext.ParseTraceparent(tt.traceparent)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantTraceID, traceID)
+			assert.Equal(t, tt.wantSpanID, spanID)
+		})
+	}
+}
+
+func TestParseB3(t *testing.T) {
+	tests := []struct {
+		name        string
+		b3          string
+		wantTraceID string
+		wantSpanID  string
+		wantOK      bool
+	}{
+		{
+			name:        "128-bit trace ID",
+			b3:          "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90",
+			wantTraceID: "80f198ee56343ba864fe8b2a57d3eff7",
+			wantSpanID:  "e457b5a2e4d86bd1",
+			wantOK:      true,
+		},
+		{
+			name:        "64-bit trace ID without sampling",
+			b3:          "463ac35c9f6413ad-a2fb4a1d1a96d312",
+			wantTraceID: "463ac35c9f6413ad",
+			wantSpanID:  "a2fb4a1d1a96d312",
+			wantOK:      true,
+		},
+		{name: "Sampling decision only", b3: "0"},
+		{name: "Invalid trace ID", b3: "463ac35c9f64-a2fb4a1d1a96d312-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID, ok := tracecontext.ParseB3(tt.b3)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantTraceID, traceID)
+			assert.Equal(t, tt.wantSpanID, spanID)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package wstransport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// Transport sends GELF messages as WebSocket text messages, one GELF message per WebSocket message.
+//
+// It allows shipping logs through ingresses and proxies which only permit HTTP and WebSocket upgrades.
+// If a write fails, the Transport reconnects once and retries the write.
+type Transport struct {
+	conn     *websocket.Conn
+	connLock sync.Mutex
+	url      string
+	header   http.Header
+	dialer   *websocket.Dialer
+}
+
+var _ gelflogger.Transport = (*Transport)(nil)
+
+// New creates a Transport and connects to the given ws:// or wss:// URL.
+// It takes the following arguments:
+// - url: the WebSocket endpoint, e.g. "wss://graylog.example.com/gelf"
+// - tlsConfig: the TLS configuration used for wss:// URLs (optional)
+// - header: additional HTTP headers sent with the upgrade request, e.g. for authorization (optional)
+//
+// Example usage:
+//
+//	transport, err := wstransport.New("wss://graylog.example.com/gelf", nil, http.Header{"Authorization": {"Bearer " + token}})
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger := gelflogger.NewLoggerWithTransport(transport, zerologger.ProcessZerologFields)
+func New(url string, tlsConfig *tls.Config, header http.Header) (*Transport, error) {
+	t := &Transport{
+		url:    url,
+		header: header,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 5 * time.Second,
+			TLSClientConfig:  tlsConfig,
+		},
+	}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	if err := t.connect(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// connect dials the WebSocket endpoint and replaces the current connection. The caller must hold connLock.
+func (t *Transport) connect() error {
+	conn, resp, err := t.dialer.Dial(t.url, t.header)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+	if t.conn != nil {
+		_ = t.conn.Close()
+	}
+	t.conn = conn
+	return nil
+}
+
+// Send writes the message as a WebSocket text message. If the write fails, it reconnects once and retries the write.
+func (t *Transport) Send(message []byte) error {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn != nil {
+		if err := t.conn.WriteMessage(websocket.TextMessage, message); err == nil {
+			return nil
+		}
+	}
+	if err := t.connect(); err != nil {
+		return err
+	}
+	return t.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// Close sends a close frame to the server and closes the connection.
+func (t *Transport) Close() error {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn == nil {
+		return nil
+	}
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = t.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
@@ -0,0 +1,105 @@
+package wstransport_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/wstransport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedMessage struct {
+	messageType int
+	payload     string
+}
+
+// startMockWebSocketServer starts a WebSocket server forwarding every received message to the returned channel.
+func startMockWebSocketServer(t *testing.T, useTLS bool) (string, <-chan receivedMessage) {
+	received := make(chan receivedMessage, 10)
+	upgrader := websocket.Upgrader{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		for {
+			messageType, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- receivedMessage{messageType: messageType, payload: string(payload)}
+		}
+	})
+
+	var server *httptest.Server
+	if useTLS {
+		server = httptest.NewTLSServer(handler)
+	} else {
+		server = httptest.NewServer(handler)
+	}
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http"), received
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		useTLS  bool
+		url     string
+		wantErr bool
+	}{
+		{
+			name:    "ws",
+			useTLS:  false,
+			wantErr: false,
+		},
+		{
+			name:    "wss",
+			useTLS:  true,
+			wantErr: false,
+		},
+		{
+			name:    "Unreachable URL",
+			url:     "ws://127.0.0.1:1/gelf",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, received := startMockWebSocketServer(t, tt.useTLS)
+			if tt.url != "" {
+				url = tt.url
+			}
+
+			transport, err := wstransport.New(url, &tls.Config{InsecureSkipVerify: true}, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer func() { _ = transport.Close() }()
+
+			logger := gelflogger.NewLoggerWithTransport(transport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			})
+			assert.NoError(t, logger.Log("hello websocket", map[string]interface{}{}))
+
+			select {
+			case msg := <-received:
+				assert.Equal(t, websocket.TextMessage, msg.messageType)
+				assert.Contains(t, msg.payload, `"short_message":"hello websocket"`)
+			case <-time.After(time.Second):
+				t.Fatal("message was not received")
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package zaplogger
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// The fields the entries of zap are sent with by the Core, next to the fields of the log calls.
+const (
+	// LoggerField is the field of the name of the logger, see zap.Logger.Named.
+	LoggerField = "logger"
+	// CallerField is the field of the caller, e.g. zaplogger/core.go:42, see zap.AddCaller.
+	CallerField = "caller"
+	// StacktraceField is the field of the stack trace, see zap.AddStacktrace.
+	StacktraceField = "stacktrace"
+)
+
+// Core is a zapcore.Core sending the entries of zap with a gelflogger.Logger. Unlike a core writing JSON to a
+// gelflogger.GelfWriter, it takes the entries and fields of zap as they are, without encoding and parsing them again:
+//
+//   - The message of the entry is sent as short message, its time as timestamp and its level as Graylog (Syslog) level,
+//     see LogLevelMap.
+//   - The fields keep their types, e.g. integers and floats are sent as numbers. Durations are sent as seconds, e.g.
+//     1.5, like the JSON encoder of zap does by default, so Graylog can aggregate them.
+//   - Errors, e.g. of zap.Error, are sent as their message, with their stack trace, e.g. of github.com/pkg/errors,
+//     appended to the full message, see gelflogger.Logger.Log.
+//   - The fields of objects, e.g. of zap.Object, and the fields following a zap.Namespace are flattened into dotted
+//     field names, e.g. "id" in the namespace "user" is sent as "_user.id".
+//   - The name of the logger, the caller and the stack trace of the entry are sent as LoggerField, CallerField and
+//     StacktraceField.
+//   - DPanic, Panic and Fatal entries, which zap may follow by a panic or the exit of the process, are sent right away,
+//     even if the Logger is asynchronous, after the messages queued before are flushed, see Sync and
+//     gelflogger.Logger.LogAtNow.
+//
+// A Core is safe for concurrent use, like the Logger.
+type Core struct {
+	zapcore.LevelEnabler
+	logger *gelflogger.Logger
+	// fields are the fields of With, flattened already. They are never modified after With, as the Cores derived from
+	// the Core share them.
+	fields map[string]interface{}
+	// namespace is the prefix of the field names of the namespaces opened by With, e.g. "user.".
+	namespace string
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore returns a Core sending the entries of the enabled levels, e.g. zap.InfoLevel, with the given Logger.
+//
+// Example usage:
+//
+//	logger := zap.New(zapcore.NewTee(consoleCore, zaplogger.NewCore(graylogLogger, zap.InfoLevel)))
+func NewCore(logger *gelflogger.Logger, level zapcore.LevelEnabler) *Core {
+	return &Core{LevelEnabler: level, logger: logger}
+}
+
+// With returns a Core adding the given fields to every entry.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+	derived := *c
+	derived.fields = maps.Clone(c.fields)
+	if derived.fields == nil {
+		derived.fields = make(map[string]interface{}, len(fields))
+	}
+	derived.namespace = addFields(derived.fields, c.namespace, fields)
+	return &derived
+}
+
+// Check adds the Core to the checked entry if the level of the entry is enabled.
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write sends the entry with the given fields. It returns the errors of gelflogger.Logger.LogAt, or of Sync and
+// LogAtNow for the levels above zapcore.ErrorLevel, which zap reports to its error output.
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	gelfFields := make(map[string]interface{}, len(c.fields)+len(fields)+3)
+	maps.Copy(gelfFields, c.fields)
+	addFields(gelfFields, c.namespace, fields)
+	if entry.LoggerName != "" {
+		gelfFields[LoggerField] = entry.LoggerName
+	}
+	if entry.Caller.Defined {
+		gelfFields[CallerField] = entry.Caller.TrimmedPath()
+	}
+	if entry.Stack != "" {
+		gelfFields[StacktraceField] = entry.Stack
+	}
+	graylogLevel, ok := LogLevelMap[entry.Level]
+	if !ok {
+		graylogLevel = gelflogger.Informational
+	}
+	if entry.Level > zapcore.ErrorLevel {
+		// zap may panic or exit right after writing the entry, so it is sent right away, like its own cores sync these
+		// entries. The queued messages are flushed first, so they precede the entry
+		syncErr := c.Sync()
+		return errors.Join(syncErr, c.logger.LogAtNow(graylogLevel, entry.Time, entry.Message, gelfFields))
+	}
+	return c.logger.LogAt(graylogLevel, entry.Time, entry.Message, gelfFields)
+}
+
+// Sync flushes the Logger, waiting at most gelflogger.DefaultCloseTimeout for the pending messages to be sent, see
+// gelflogger.Logger.Flush. zap.Logger.Sync calls it, e.g. before the process exits.
+func (c *Core) Sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), gelflogger.DefaultCloseTimeout)
+	defer cancel()
+	return c.logger.Flush(ctx)
+}
+
+// addFields adds the zap fields in the given namespace to the GELF fields, and returns the namespace of the following
+// fields, which zap.Namespace fields open.
+func addFields(gelfFields map[string]interface{}, namespace string, fields []zapcore.Field) string {
+	for _, field := range fields {
+		switch field.Type {
+		case zapcore.NamespaceType:
+			namespace += field.Key + "."
+		case zapcore.ErrorType:
+			// The error itself is sent, so the Logger adds its stack trace to the full message
+			if err, ok := field.Interface.(error); ok {
+				gelfFields[namespace+field.Key] = err
+			}
+		default:
+			encoder := zapcore.NewMapObjectEncoder()
+			field.AddTo(encoder)
+			addFlattened(gelfFields, namespace, encoder.Fields)
+		}
+	}
+	return namespace
+}
+
+// addFlattened adds the fields encoded by a zapcore.MapObjectEncoder with the given prefix to the GELF fields,
+// flattening the fields of objects and namespaces into dotted names, and converting durations to seconds.
+func addFlattened(gelfFields map[string]interface{}, prefix string, encoded map[string]interface{}) {
+	for key, value := range encoded {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			addFlattened(gelfFields, prefix+key+".", v)
+		case time.Duration:
+			gelfFields[prefix+key] = v.Seconds()
+		default:
+			gelfFields[prefix+key] = value
+		}
+	}
+}
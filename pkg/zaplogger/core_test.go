@@ -0,0 +1,166 @@
+package zaplogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+	"github.com/jame-developer/gelf-logger/pkg/zaplogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// stackError is an error with a stack trace like the errors of github.com/pkg/errors.
+type stackError struct {
+	msg string
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprint(s, e.msg+"\nmain.handler\n\t/app/main.go:42")
+		return
+	}
+	_, _ = fmt.Fprint(s, e.msg)
+}
+
+func TestCore(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     func(logger *zap.Logger)
+		want    map[string]interface{}
+		missing []string
+	}{
+		{
+			name: "Message, level and typed fields",
+			log: func(logger *zap.Logger) {
+				logger.Warn("slow query", zap.Int("rows", 42), zap.Float64("ratio", 0.25), zap.Duration("took", 1500*time.Millisecond), zap.String("table", "orders"))
+			},
+			want: map[string]interface{}{
+				"short_message": "slow query",
+				"level":         float64(4),
+				"_rows":         float64(42),
+				"_ratio":        0.25,
+				"_took":         1.5,
+				"_table":        "orders",
+			},
+		},
+		{
+			name: "Namespaces and objects are flattened",
+			log: func(logger *zap.Logger) {
+				user := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+					enc.AddInt("id", 7)
+					enc.AddString("role", "admin")
+					enc.AddDuration("session", 90*time.Second)
+					return nil
+				})
+				logger.Info("login", zap.Object("user", user), zap.Namespace("request"), zap.String("id", "r-1"))
+			},
+			want: map[string]interface{}{"_user.id": float64(7), "_user.role": "admin", "_user.session": 90.0, "_request.id": "r-1"},
+		},
+		{
+			name: "With and namespaces of With",
+			log: func(logger *zap.Logger) {
+				logger.With(zap.String("service", "billing"), zap.Namespace("request")).With(zap.String("id", "r-1")).Info("handled", zap.Int("status", 200))
+			},
+			want:    map[string]interface{}{"_service": "billing", "_request.id": "r-1", "_request.status": float64(200)},
+			missing: []string{"_status"},
+		},
+		{
+			name: "Error with stack trace",
+			log: func(logger *zap.Logger) {
+				logger.Error("payment failed", zap.Error(&stackError{msg: "timeout"}), zap.Error(nil))
+			},
+			want:    map[string]interface{}{"level": float64(3), "_error": "timeout"},
+			missing: []string{"_errorVerbose"},
+		},
+		{
+			name: "Logger name",
+			log: func(logger *zap.Logger) {
+				logger.Named("billing").Named("payments").Info("started")
+			},
+			want: map[string]interface{}{"_logger": "billing.payments"},
+		},
+		{
+			name: "Levels below the enabled level",
+			log: func(logger *zap.Logger) {
+				logger.Debug("dropped")
+				logger.DPanic("sent")
+			},
+			want: map[string]interface{}{"short_message": "sent", "level": float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &helper.RecordingTransport{}
+			core := zaplogger.NewCore(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), zap.InfoLevel)
+			tt.log(zap.New(core))
+
+			require.Len(t, transport.Messages(), 1)
+			var gelfMsg map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(transport.Messages()[0]), &gelfMsg))
+			for key, want := range tt.want {
+				assert.Equal(t, want, gelfMsg[key], key)
+			}
+			for _, key := range tt.missing {
+				assert.NotContains(t, gelfMsg, key)
+			}
+		})
+	}
+}
+
+func TestCoreErrorStackTrace(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := zap.New(zaplogger.NewCore(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), zap.InfoLevel))
+	logger.Error("payment failed", zap.Error(&stackError{msg: "timeout"}))
+
+	require.Len(t, transport.Messages(), 1)
+	var gelfMsg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(transport.Messages()[0]), &gelfMsg))
+	assert.Contains(t, gelfMsg["full_message"], "main.handler")
+}
+
+func TestCoreCaller(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	logger := zap.New(zaplogger.NewCore(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), zap.InfoLevel), zap.AddCaller())
+	logger.Info("started")
+
+	require.Len(t, transport.Messages(), 1)
+	assert.Contains(t, transport.Messages()[0], `"_caller":"zaplogger/core_test.go:`)
+}
+
+func TestCoreTimestamp(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	core := zaplogger.NewCore(gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing), zap.InfoLevel)
+	entry := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Unix(1700000000, 500000000), Message: "imported"}
+	require.NoError(t, core.Write(entry, nil))
+	require.NoError(t, core.Sync())
+
+	require.Len(t, transport.Messages(), 1)
+	assert.Contains(t, transport.Messages()[0], `"timestamp":1700000000.5`)
+}
+
+func TestCoreFatal(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	graylogLogger := gelflogger.NewLoggerWithTransport(transport, helper.ProcessNothing, gelflogger.WithAsync(10, 1))
+	logger := zap.New(zaplogger.NewCore(graylogLogger, zap.InfoLevel), zap.WithFatalHook(zapcore.WriteThenPanic))
+
+	logger.Info("cache full")
+	assert.Panics(t, func() { logger.Fatal("out of memory", zap.String("component", "cache")) })
+
+	// The entry was sent before zap panicked, after the queued entry, without waiting for the workers of the
+	// asynchronous Logger
+	require.Len(t, transport.Messages(), 2)
+	assert.Contains(t, transport.Messages()[0], `"short_message":"cache full"`)
+	assert.Contains(t, transport.Messages()[1], `"short_message":"out of memory"`)
+	assert.Contains(t, transport.Messages()[1], `"level":0`)
+	assert.NoError(t, graylogLogger.Close(context.Background()))
+}
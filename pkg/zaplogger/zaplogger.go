@@ -7,8 +7,8 @@ import (
 	gelflogger "github.com/jame-developer/gelf-logger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"io"
 	"log"
+	"slices"
 	"time"
 )
 
@@ -23,6 +23,27 @@ var LogLevelMap = map[zapcore.Level]int{
 	zapcore.FatalLevel:  0, // Emergency
 }
 
+// Options are the options of NewZapLoggerWithOptions. The zero Options are the settings of NewZapLogger.
+//
+// - UseTLS: Whether to use TLS for the connection.
+// - TLSConfig: The TLS configuration to use, nil for the default one.
+// - Level: The levels written to Graylog, e.g. zap.DebugLevel or a zap.AtomicLevel changed at runtime. Nil is
+// zap.InfoLevel.
+// - EncoderConfig: Nil for the native Core, see NewCore. Otherwise, the Graylog core encodes the entries as JSON with
+// the encoder of the config and writes them to a GelfWriter, which sends the JSON as full message. As the GelfWriter
+// reads the fields written by the encoder, the message, the time and the level are written as "message", "time" and
+// "level" regardless of the keys of the config, and the level must be encoded as name, e.g. by
+// zapcore.LowercaseLevelEncoder or zapcore.CapitalLevelEncoder, but not with colors. The time may be encoded by any of
+// the time encoders of zap.
+// - GelfOptions: Additional Options of the gelflogger.Logger, e.g. gelflogger.WithAsync.
+type Options struct {
+	UseTLS        bool
+	TLSConfig     *tls.Config
+	Level         zapcore.LevelEnabler
+	EncoderConfig *zapcore.EncoderConfig
+	GelfOptions   []gelflogger.Option
+}
+
 // NewZapLogger creates a new Zap logger with the specified Graylog address and TLS configuration.
 // It takes the following parameters:
 //   - address: the address of the Graylog server
@@ -30,32 +51,53 @@ var LogLevelMap = map[zapcore.Level]int{
 //   - tslConfig: the TLS configuration to use (can be nil if useTLS is false)
 //   - otherZapCores: optional additional Zap cores to include in the logger's core
 //
-// It first initializes a new GelfLogger using the provided address, useTLS, tslConfig, and ProcessZapLoggerFields function.
-// If the GelfLogger initialization is successful, it creates a GelfWriter using the GelfLogger.
-// It then creates a Zap core by adding the GelfWriter to a MultiWriter and creating a Zap core with JSON encoder and InfoLevel.
-// If otherZapCores are provided, it appends the Gelf core to the otherZapCores and creates a Tee core.
-// Otherwise, it creates the Tee core with only the Gelf core.
+// It is NewZapLoggerWithOptions with the given TLS settings, sending the messages of InfoLevel and above to Graylog
+// with the native Core.
+func NewZapLogger(address string, useTSL bool, tslConfig *tls.Config, otherZapCores ...zapcore.Core) (*zap.Logger, error) {
+	return NewZapLoggerWithOptions(address, Options{UseTLS: useTSL, TLSConfig: tslConfig}, otherZapCores...)
+}
+
+// NewZapLoggerWithOptions creates a new Zap logger with the specified Graylog address and Options.
+// It first initializes a new GelfLogger using the provided address, the TLS settings and GelfOptions of the Options, and
+// the ProcessZapLoggerFields function.
+// It then creates a Core with the Level of the Options sending the entries with the GelfLogger, see NewCore, or, with
+// an EncoderConfig, a Zap core writing to a GelfWriter with JSON encoder of the EncoderConfig, see gelfEncoderConfig.
+// Syncing the core, as zap does on fatal messages, flushes the pending messages of the GelfLogger, see
+// gelflogger.Logger.Flush.
+// It appends the Gelf core to the otherZapCores, if any, and creates a Tee core of them, so the messages are written
+// to the other cores as well.
 // Finally, it creates and returns a new Zap logger with the Tee core.
 // If the GelfLogger initialization fails, it returns nil and the error from the GelfLogger initialization.
-func NewZapLogger(address string, useTSL bool, tslConfig *tls.Config, otherZapCores ...zapcore.Core) (*zap.Logger, error) {
-	graylogLogger, gelfLoggerInitErr := gelflogger.NewLogger(address, useTSL, tslConfig, ProcessZapLoggerFields)
+//
+// Example usage:
+//
+//	logger, err := NewZapLoggerWithOptions("graylog.example.com:12201", Options{Level: zap.DebugLevel})
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger.Debug("Hello, World!")
+func NewZapLoggerWithOptions(address string, options Options, otherZapCores ...zapcore.Core) (*zap.Logger, error) {
+	opts := append(gelfOptions(options.UseTLS, options.TLSConfig), options.GelfOptions...)
+	graylogLogger, gelfLoggerInitErr := gelflogger.NewLogger(address, opts...)
 	if gelfLoggerInitErr == nil {
-		gelfWriter := gelflogger.GelfWriter{
-			Logger: graylogLogger,
+		level := options.Level
+		if level == nil {
+			level = zap.InfoLevel
 		}
-		logWriter := zapcore.AddSync(io.MultiWriter(&gelfWriter))
-		gelfCore := zapcore.NewCore(
-			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
-			logWriter,
-			zap.InfoLevel,
-		)
-		if otherZapCores != nil {
-			otherZapCores = []zapcore.Core{gelfCore}
-		} else {
-			otherZapCores = append(otherZapCores)
+		var gelfCore zapcore.Core = NewCore(graylogLogger, level)
+		if options.EncoderConfig != nil {
+			gelfWriter := gelflogger.GelfWriter{
+				Logger: graylogLogger,
+			}
+			// The GelfWriter implements Sync, so zap flushes the pending messages before exiting on fatal messages
+			logWriter := zapcore.AddSync(&gelfWriter)
+			gelfCore = zapcore.NewCore(
+				zapcore.NewJSONEncoder(gelfEncoderConfig(*options.EncoderConfig)),
+				logWriter,
+				level,
+			)
 		}
-
-		core := zapcore.NewTee(otherZapCores...)
+		core := zapcore.NewTee(append(slices.Clip(otherZapCores), gelfCore)...)
 
 		return zap.New(core), nil
 	}
@@ -63,18 +105,30 @@ func NewZapLogger(address string, useTSL bool, tslConfig *tls.Config, otherZapCo
 	return nil, gelfLoggerInitErr
 }
 
-func ProcessZapLoggerFields(fields map[string]interface{}) (int, float64, []byte, error) {
-	if _, ok := fields["time"]; !ok {
-		fields["time"] = float64(time.Now().UnixMilli())
+// gelfEncoderConfig returns the encoder config with the message, the time and the level written as "message", "time"
+// and "level", the fields read by the GelfWriter and ProcessZapLoggerFields, instead of e.g. "msg" and "ts" of the
+// production encoder config. Without time or level encoder, the epoch time and lowercase level encoders are used.
+func gelfEncoderConfig(config zapcore.EncoderConfig) zapcore.EncoderConfig {
+	config.MessageKey = "message"
+	config.TimeKey = "time"
+	config.LevelKey = "level"
+	if config.EncodeTime == nil {
+		config.EncodeTime = zapcore.EpochTimeEncoder
 	}
-	if _, ok := fields["time"].(float64); !ok {
-		return 0, 0, nil, fmt.Errorf("field `time` is not of type loat64; invalid log message format")
+	if config.EncodeLevel == nil {
+		config.EncodeLevel = zapcore.LowercaseLevelEncoder
 	}
-	if _, ok := fields["level"]; !ok {
-		fields["level"] = "info"
+	return config
+}
+
+// ProcessZapLoggerFields is the processor of the messages written by zap. The time field may have any of the formats
+// understood by gelflogger.ParseTimestamp, e.g. the ISO 8601 or epoch time encoders of zap. The level field may be a
+// zap level name or a number taken as Syslog level, see gelflogger.ParseLevel.
+func ProcessZapLoggerFields(fields map[string]interface{}) (int, float64, []byte, error) {
+	graylogLevel, glTimeStamp, err := readLevelAndTime(fields)
+	if err != nil {
+		return 0, 0, nil, err
 	}
-	graylogLevel := ConvertZapLogLevelToGraylog(fields["level"].(string))
-	glTimeStamp := fields["time"].(float64) / 1000
 	fields["level"] = graylogLevel
 	fullMessage, err := json.Marshal(&fields)
 	if err != nil {
@@ -87,6 +141,44 @@ func ProcessZapLoggerFields(fields map[string]interface{}) (int, float64, []byte
 	return graylogLevel, glTimeStamp, fullMessage, nil
 }
 
+// ReadZapLoggerFields is the field reader counterpart of ProcessZapLoggerFields, see gelflogger.WithFieldReader. It
+// reads the level and the timestamp like ProcessZapLoggerFields and removes the "level", "time" and "message" fields,
+// without encoding the full message.
+func ReadZapLoggerFields(fields map[string]interface{}) (int, float64, error) {
+	graylogLevel, glTimeStamp, err := readLevelAndTime(fields)
+	if err != nil {
+		return 0, 0, err
+	}
+	delete(fields, "level")
+	delete(fields, "time")
+	delete(fields, "message")
+	return graylogLevel, glTimeStamp, nil
+}
+
+// readLevelAndTime reads the Graylog level and the timestamp of the fields written by zap.
+func readLevelAndTime(fields map[string]interface{}) (int, float64, error) {
+	glTimeStamp := float64(time.Now().UnixMilli()) / 1000
+	if value, ok := fields["time"]; ok {
+		parsed, err := gelflogger.ParseTimestamp(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("field `time`: %w; invalid log message format", err)
+		}
+		glTimeStamp = parsed
+	}
+	graylogLevel := 6
+	switch level := fields["level"].(type) {
+	case string:
+		graylogLevel = ConvertZapLogLevelToGraylog(level)
+	case nil:
+	default:
+		// Numbers are taken as Syslog levels
+		if parsed, err := gelflogger.ParseLevel(level); err == nil {
+			graylogLevel = parsed
+		}
+	}
+	return graylogLevel, glTimeStamp, nil
+}
+
 // ConvertZapLogLevelToGraylog converts a Zap log level to a Graylog log level.
 // It takes a string `level` as input and returns an integer corresponding to the Graylog log level.
 // It first parses the input `level` using `zapcore.ParseLevel`.
@@ -104,3 +196,12 @@ func ConvertZapLogLevelToGraylog(level string) int {
 	}
 	return 6
 }
+
+// gelfOptions returns the Options of the gelflogger.Logger for the given TLS settings.
+func gelfOptions(useTSL bool, tslConfig *tls.Config) []gelflogger.Option {
+	opts := []gelflogger.Option{gelflogger.WithProcessor(ProcessZapLoggerFields), gelflogger.WithFieldReader(ReadZapLoggerFields)}
+	if useTSL {
+		opts = append(opts, gelflogger.WithTLS(tslConfig))
+	}
+	return opts
+}
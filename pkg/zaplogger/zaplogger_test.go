@@ -1,11 +1,14 @@
 package zaplogger_test
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	gelflogger "github.com/jame-developer/gelf-logger"
 	"github.com/jame-developer/gelf-logger/pkg/helper"
 	"github.com/jame-developer/gelf-logger/pkg/zaplogger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
@@ -70,7 +73,7 @@ func TestNewZapLogger(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Test NewZapLogger
-			_, err := zaplogger.NewZapLogger(tc.address, tc.useTLS, &tls.Config{}, tc.otherCores...)
+			_, err := zaplogger.NewZapLogger(tc.address, tc.useTLS, tc.tlsConfig, tc.otherCores...)
 			if !tc.wantErr {
 				assert.NoError(t, err)
 			} else {
@@ -96,6 +99,15 @@ func TestProcessZapLoggerFields(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "ISO8601_Time",
+			input: map[string]interface{}{
+				"level":   "error",
+				"time":    "2024-05-01T12:00:00.123+0200",
+				"message": "This is a test log message",
+			},
+			wantErr: false,
+		},
 		{
 			name: "Incorrect_Time",
 			input: map[string]interface{}{
@@ -161,3 +173,142 @@ func TestConvertZapLogLevelToGraylog(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessZapLoggerFieldsLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         interface{}
+		expectedLevel int
+	}{
+		{name: "Level name", level: "warn", expectedLevel: 4},
+		{name: "Syslog number", level: 3.0, expectedLevel: 3},
+		{name: "Syslog integer", level: 7, expectedLevel: 7},
+		{name: "Out of range number", level: 42.0, expectedLevel: 6},
+		{name: "Unsupported type", level: true, expectedLevel: 6},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			level, _, _, err := zaplogger.ProcessZapLoggerFields(map[string]interface{}{"level": tc.level, "message": "numeric level"})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLevel, level)
+		})
+	}
+}
+
+func TestReadZapLoggerFields(t *testing.T) {
+	fields := map[string]interface{}{"level": "warn", "time": 1700000000.5, "message": "read", "user": "42"}
+	level, timestamp, err := zaplogger.ReadZapLoggerFields(fields)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, level)
+	assert.Equal(t, 1700000000.5, timestamp)
+	assert.Equal(t, map[string]interface{}{"user": "42"}, fields)
+
+	_, _, err = zaplogger.ReadZapLoggerFields(map[string]interface{}{"time": "incorrect value"})
+	assert.Error(t, err)
+}
+
+func TestNewZapLoggerCores(t *testing.T) {
+	tests := []struct {
+		name  string
+		other bool
+	}{
+		{name: "With other core", other: true},
+		{name: "Without other core", other: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := helper.StartMockServer(t)
+			t.Cleanup(func() { _ = mockServer.Close() })
+			received := helper.ReceiveMessages(t, mockServer, 0)
+
+			var other bytes.Buffer
+			var cores []zapcore.Core
+			if tc.other {
+				cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&other), zap.InfoLevel))
+			}
+			logger, err := zaplogger.NewZapLogger(mockServer.Addr().String(), false, nil, cores...)
+			require.NoError(t, err)
+			logger.Info("combined cores", zap.String("sink", "both"))
+
+			select {
+			case message := <-received:
+				assert.Contains(t, message, `"short_message":"combined cores"`)
+				assert.NotContains(t, message, `"_ts"`)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the GELF core received no message")
+			}
+			if tc.other {
+				assert.Contains(t, other.String(), `"msg":"combined cores"`)
+			}
+		})
+	}
+}
+
+func TestNewZapLoggerWithOptions(t *testing.T) {
+	capitalConfig := zap.NewDevelopmentEncoderConfig()
+	capitalConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+
+	tests := []struct {
+		name      string
+		options   zaplogger.Options
+		log       func(logger *zap.Logger)
+		wantLevel float64
+		want      []string
+	}{
+		{
+			name:      "Default level drops debug",
+			log:       func(logger *zap.Logger) { logger.Debug("dropped"); logger.Warn("sent") },
+			wantLevel: 4,
+			want:      []string{`"short_message":"sent"`},
+		},
+		{
+			name:      "Debug level",
+			options:   zaplogger.Options{Level: zap.DebugLevel},
+			log:       func(logger *zap.Logger) { logger.Debug("sent") },
+			wantLevel: 7,
+			want:      []string{`"short_message":"sent"`},
+		},
+		{
+			name:      "Encoder config",
+			options:   zaplogger.Options{EncoderConfig: &capitalConfig},
+			log:       func(logger *zap.Logger) { logger.Error("sent", zap.Int("attempt", 3)) },
+			wantLevel: 3,
+			want:      []string{`"short_message":"sent"`, `"_attempt":3`},
+		},
+		{
+			name:      "Gelf options",
+			options:   zaplogger.Options{GelfOptions: []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"service": "billing"})}},
+			log:       func(logger *zap.Logger) { logger.Info("sent") },
+			wantLevel: 6,
+			want:      []string{`"_service":"billing"`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := helper.StartMockServer(t)
+			t.Cleanup(func() { _ = mockServer.Close() })
+			received := helper.ReceiveMessages(t, mockServer, 0)
+
+			logger, err := zaplogger.NewZapLoggerWithOptions(mockServer.Addr().String(), tc.options)
+			require.NoError(t, err)
+			tc.log(logger)
+
+			select {
+			case message := <-received:
+				var gelfMsg map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(message), &gelfMsg))
+				assert.Equal(t, tc.wantLevel, gelfMsg["level"], message)
+				for _, want := range tc.want {
+					assert.Contains(t, message, want)
+				}
+				assert.NotContains(t, gelfMsg, "_msg", message)
+				assert.NotContains(t, gelfMsg, "_ts", message)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the GELF core received no message")
+			}
+		})
+	}
+}
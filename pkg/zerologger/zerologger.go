@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog"
 	"io"
 	"log"
+	"slices"
 	"time"
 )
 
@@ -29,13 +30,17 @@ var LogLevelMap = map[zerolog.Level]int{
 // - tslConfig: a *tls.Config object to configure the TLS connection (optional)
 // - otherZeroLogWriter: zero or more additional io.Writer objects to write logs to (optional)
 // The logger is created in the following steps:
-// 1. The gelflogger.NewLogger function is called with the given address, useTLS, tslConfig, and ProcessZerologFields to create a gelflogger.Logger object.
-// 2. If the gelflogger.Logger initialization is successful, a gelflogger.GelfWriter is created with the graylogLogger.
-// 3. If otherZeroLogWriter is not nil, a new slice is created with only the gelfWriter. Otherwise, otherZeroLogWriter remains unchanged.
+// 1. The gelflogger.NewLogger function is called with the given address and the Options for useTLS, tslConfig and ProcessZerologFields to create a gelflogger.Logger object.
+// 2. If the gelflogger.Logger initialization is successful, a LevelWriter is created with the graylogLogger, so the
+// level of the messages is taken from zerolog instead of parsed from the JSON.
+// 3. The gelfWriter is appended to otherZeroLogWriter, so the messages are written to the other writers as well.
 // 4. The zerolog.TimeFieldFormat is set to a GELF compatible timestamp format.
-// 5. A zerolog.MultiLevelWriter is created with otherZeroLogWriter as the variadic argument.
+// 5. A zerolog.MultiLevelWriter is created with the other writers and the gelfWriter.
 // 6. A zerolog.Logger is created with the multiLevelWriter, Timestamp, and Logger options.
 //
+// On fatal messages, zerolog closes its writer before exiting the process, which closes the LevelWriter, so the
+// pending messages of the GelfLogger are sent before the process exits, see gelflogger.Logger.Close.
+//
 // Example usage:
 //
 //	logger, err := NewZeroLogger("graylog.example.com:12201", true, nil, os.Stdout)
@@ -44,40 +49,69 @@ var LogLevelMap = map[zerolog.Level]int{
 //	}
 //	logger.Info().Msg("Hello, World!")
 func NewZeroLogger(address string, useTSL bool, tslConfig *tls.Config, otherZeroLogWriter ...io.Writer) (zerolog.Logger, error) {
-	graylogLogger, gelfLoggerInitErr := gelflogger.NewLogger(address, useTSL, tslConfig, ProcessZerologFields)
+	graylogLogger, gelfLoggerInitErr := gelflogger.NewLogger(address, gelfOptions(useTSL, tslConfig)...)
 	if gelfLoggerInitErr == nil {
-		gelfWriter := gelflogger.GelfWriter{
-			Logger: graylogLogger,
-		}
-
-		if otherZeroLogWriter != nil {
-			otherZeroLogWriter = []io.Writer{&gelfWriter}
-		} else {
-			otherZeroLogWriter = append(otherZeroLogWriter)
-		}
+		gelfWriter := NewLevelWriter(graylogLogger, zerolog.TraceLevel)
+		writers := append(slices.Clip(otherZeroLogWriter), io.Writer(gelfWriter))
 
 		// Set the time field format to a GELF compatible timestamp format see also https://go2docs.graylog.org/5-0/getting_in_log_data/gelf.html?tocpath=Getting%20in%20Logs%7CLog%20Sources%7CGELF%7C_____0#GELFPayloadSpecification
 		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
 
 		// Create the Multilevel writer and create the zero logger.
-		multiLevelWriter := zerolog.MultiLevelWriter(otherZeroLogWriter...)
+		multiLevelWriter := zerolog.MultiLevelWriter(writers...)
 		return zerolog.New(multiLevelWriter).With().Timestamp().Logger(), nil
 	}
 
 	return zerolog.New(nil), gelfLoggerInitErr
 }
-func ProcessZerologFields(fields map[string]interface{}) (int, float64, []byte, error) {
-	if _, ok := fields["time"]; !ok {
-		fields["time"] = float64(time.Now().UnixMilli())
+
+// LevelWriter is a zerolog.LevelWriter writing to a gelflogger.GelfWriter. zerolog passes the level of every message
+// to WriteLevel, so the level is taken as it is instead of parsed from the level field of the JSON, see
+// gelflogger.GelfWriter.WriteWithLevel, and messages below MinLevel are dropped before the JSON is decoded. Messages of
+// zerolog.NoLevel, e.g. of zerolog.Logger.Log, are not filtered, their level is read from the JSON like by Write.
+//
+// Use it with zerolog.MultiLevelWriter, or as the writer of zerolog.New, which calls WriteLevel as well:
+//
+//	logger := zerolog.New(zerologger.NewLevelWriter(graylogLogger, zerolog.InfoLevel)).With().Timestamp().Logger()
+type LevelWriter struct {
+	gelflogger.GelfWriter
+	// MinLevel is the lowest level of the messages sent to Graylog.
+	MinLevel zerolog.Level
+}
+
+var _ zerolog.LevelWriter = (*LevelWriter)(nil)
+
+// NewLevelWriter returns a LevelWriter sending the messages of at least the given level with the Logger.
+func NewLevelWriter(logger *gelflogger.Logger, minLevel zerolog.Level) *LevelWriter {
+	return &LevelWriter{GelfWriter: gelflogger.GelfWriter{Logger: logger}, MinLevel: minLevel}
+}
+
+// WriteLevel writes the message of the given level, see LevelWriter. Dropped messages are reported as written, like
+// zerolog does for the messages below the level of a logger.
+func (w *LevelWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if level == zerolog.NoLevel {
+		return w.Write(p)
 	}
-	if _, ok := fields["time"].(float64); !ok {
-		return 0, 0, nil, fmt.Errorf("field `time` is not of type loat64; invalid log message format")
+	if level < w.MinLevel || level == zerolog.Disabled {
+		return len(p), nil
 	}
-	if _, ok := fields["level"]; !ok {
-		fields["level"] = "info"
+	graylogLevel, ok := LogLevelMap[level]
+	if !ok {
+		// zerolog.TraceLevel
+		graylogLevel = 7
+	}
+	return w.WriteWithLevel(graylogLevel, p)
+}
+
+// ProcessZerologFields is the processor of the messages written by zerolog. The time field may have any of the
+// zerolog.TimeFieldFormat formats understood by gelflogger.ParseTimestamp, e.g. time.RFC3339 or TimeFormatUnixMs. The
+// level field may be a zerolog level name or number string, or, e.g. written by a zerolog.LevelFieldMarshalFunc, a
+// number taken as Syslog level, see gelflogger.ParseLevel.
+func ProcessZerologFields(fields map[string]interface{}) (int, float64, []byte, error) {
+	graylogLevel, glTimeStamp, err := readLevelAndTime(fields)
+	if err != nil {
+		return 0, 0, nil, err
 	}
-	graylogLevel := ConvertZerologLevelToGraylog(fields["level"].(string))
-	glTimeStamp := fields["time"].(float64) / 1000
 	fields["level"] = graylogLevel
 	fullMessage, err := json.Marshal(&fields)
 	if err != nil {
@@ -90,6 +124,44 @@ func ProcessZerologFields(fields map[string]interface{}) (int, float64, []byte,
 	return graylogLevel, glTimeStamp, fullMessage, nil
 }
 
+// ReadZerologFields is the field reader counterpart of ProcessZerologFields, see gelflogger.WithFieldReader. It
+// reads the level and the timestamp like ProcessZerologFields and removes the "level", "time" and "message" fields,
+// without encoding the full message.
+func ReadZerologFields(fields map[string]interface{}) (int, float64, error) {
+	graylogLevel, glTimeStamp, err := readLevelAndTime(fields)
+	if err != nil {
+		return 0, 0, err
+	}
+	delete(fields, "level")
+	delete(fields, "time")
+	delete(fields, "message")
+	return graylogLevel, glTimeStamp, nil
+}
+
+// readLevelAndTime reads the Graylog level and the timestamp of the fields written by zerolog.
+func readLevelAndTime(fields map[string]interface{}) (int, float64, error) {
+	glTimeStamp := float64(time.Now().UnixMilli()) / 1000
+	if value, ok := fields["time"]; ok {
+		parsed, err := gelflogger.ParseTimestamp(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("field `time`: %w; invalid log message format", err)
+		}
+		glTimeStamp = parsed
+	}
+	graylogLevel := 6
+	switch level := fields["level"].(type) {
+	case string:
+		graylogLevel = ConvertZerologLevelToGraylog(level)
+	case nil:
+	default:
+		// Numbers are taken as Syslog levels
+		if parsed, err := gelflogger.ParseLevel(level); err == nil {
+			graylogLevel = parsed
+		}
+	}
+	return graylogLevel, glTimeStamp, nil
+}
+
 // ConvertZerologLevelToGraylog converts a zerolog level to the equivalent Graylog (Syslog) level.
 func ConvertZerologLevelToGraylog(level string) int {
 	parsedLevel, err := zerolog.ParseLevel(level)
@@ -101,3 +173,12 @@ func ConvertZerologLevelToGraylog(level string) int {
 	}
 	return 6
 }
+
+// gelfOptions returns the Options of the gelflogger.Logger for the given TLS settings.
+func gelfOptions(useTSL bool, tslConfig *tls.Config) []gelflogger.Option {
+	opts := []gelflogger.Option{gelflogger.WithProcessor(ProcessZerologFields), gelflogger.WithFieldReader(ReadZerologFields)}
+	if useTSL {
+		opts = append(opts, gelflogger.WithTLS(tslConfig))
+	}
+	return opts
+}
@@ -1,11 +1,15 @@
 package zerologger_test
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	gelflogger "github.com/jame-developer/gelf-logger"
 	"github.com/jame-developer/gelf-logger/pkg/helper"
 	"github.com/jame-developer/gelf-logger/pkg/zerologger"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"io"
 	"os"
 	"testing"
@@ -64,7 +68,7 @@ func TestNewZeroLogger(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Test NewZapLogger
-			_, err := zerologger.NewZeroLogger(tc.address, tc.useTLS, &tls.Config{}, tc.OtherZeroLogWriter...)
+			_, err := zerologger.NewZeroLogger(tc.address, tc.useTLS, tc.tlsConfig, tc.OtherZeroLogWriter...)
 			if !tc.wantErr {
 				assert.NoError(t, err)
 			} else {
@@ -90,6 +94,15 @@ func TestProcessZerologFields(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "RFC3339_Time",
+			input: map[string]interface{}{
+				"level":   "error",
+				"time":    "2024-05-01T12:00:00+02:00",
+				"message": "This is a test log message",
+			},
+			wantErr: false,
+		},
 		{
 			name: "Incorrect_Time",
 			input: map[string]interface{}{
@@ -157,3 +170,96 @@ func TestConvertZerologLevelToGraylog(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessZerologFieldsLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         interface{}
+		expectedLevel int
+	}{
+		{name: "Zerolog level number", level: "2", expectedLevel: 4},
+		{name: "Syslog number", level: 3.0, expectedLevel: 3},
+		{name: "Syslog integer", level: 7, expectedLevel: 7},
+		{name: "Out of range number", level: 42.0, expectedLevel: 6},
+		{name: "Unsupported type", level: true, expectedLevel: 6},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			level, _, _, err := zerologger.ProcessZerologFields(map[string]interface{}{"level": tc.level, "message": "numeric level"})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLevel, level)
+		})
+	}
+}
+
+func TestReadZerologFields(t *testing.T) {
+	fields := map[string]interface{}{"level": "warn", "time": 1700000000500.0, "message": "read", "user": "42"}
+	level, timestamp, err := zerologger.ReadZerologFields(fields)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, level)
+	assert.Equal(t, 1700000000.5, timestamp)
+	assert.Equal(t, map[string]interface{}{"user": "42"}, fields)
+
+	_, _, err = zerologger.ReadZerologFields(map[string]interface{}{"time": "incorrect value"})
+	assert.Error(t, err)
+}
+
+func TestLevelWriter(t *testing.T) {
+	transport := &helper.RecordingTransport{}
+	graylogLogger := gelflogger.NewLoggerWithTransport(transport, zerologger.ProcessZerologFields,
+		gelflogger.WithFieldReader(zerologger.ReadZerologFields))
+	logger := zerolog.New(zerologger.NewLevelWriter(graylogLogger, zerolog.InfoLevel))
+
+	logger.Debug().Msg("dropped")
+	logger.Warn().Str("free", "5%").Msg("disk almost full")
+	logger.WithLevel(zerolog.FatalLevel).Msg("fatal")
+	logger.Log().Str("level", "error").Msg("without zerolog level")
+
+	require.Len(t, transport.Messages(), 3)
+	wantLevels := []float64{4, 2, 3}
+	for i, message := range transport.Messages() {
+		var gelfMsg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(message), &gelfMsg))
+		assert.Equal(t, wantLevels[i], gelfMsg["level"], message)
+		assert.NotContains(t, gelfMsg, "_level", message)
+	}
+	assert.Contains(t, transport.Messages()[0], `"_free":"5%"`)
+}
+
+func TestNewZeroLoggerWriters(t *testing.T) {
+	tests := []struct {
+		name  string
+		other bool
+	}{
+		{name: "With other writer", other: true},
+		{name: "Without other writer", other: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := helper.StartMockServer(t)
+			t.Cleanup(func() { _ = mockServer.Close() })
+			received := helper.ReceiveMessages(t, mockServer, 0)
+
+			var other bytes.Buffer
+			var writers []io.Writer
+			if tc.other {
+				writers = append(writers, &other)
+			}
+			logger, err := zerologger.NewZeroLogger(mockServer.Addr().String(), false, nil, writers...)
+			require.NoError(t, err)
+			logger.Info().Str("sink", "both").Msg("combined writers")
+
+			select {
+			case message := <-received:
+				assert.Contains(t, message, `"short_message":"combined writers"`)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the GELF writer received no message")
+			}
+			if tc.other {
+				assert.Contains(t, other.String(), `"message":"combined writers"`)
+			}
+		})
+	}
+}
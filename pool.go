@@ -0,0 +1,121 @@
+package gelflogger
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// poolRetryInterval is the time a failed connection of a pool is skipped before it is used, and thereby replaced by a
+// new connection, again.
+const poolRetryInterval = time.Second
+
+// pooledTransport distributes the messages across a pool of connections to increase the throughput of a single
+// Logger. The health of every connection is tracked by its consecutive send errors: messages are sent through the
+// healthy connections in turn, a failed connection is skipped for poolRetryInterval and then replaced by a new
+// connection on its next use.
+type pooledTransport struct {
+	members []*poolMember
+	next    atomic.Uint64
+}
+
+// poolMember is a single connection of a pooledTransport together with its consecutive send errors.
+type poolMember struct {
+	transport Transport
+	failures  atomic.Int64
+	retryAt   atomic.Int64
+}
+
+// healthy reports whether the member can be used for the next message.
+func (m *poolMember) healthy(now time.Time) bool {
+	return m.failures.Load() == 0 || now.UnixNano() >= m.retryAt.Load()
+}
+
+// recordFailure marks the member as unhealthy for poolRetryInterval.
+func (m *poolMember) recordFailure() {
+	m.failures.Add(1)
+	m.retryAt.Store(time.Now().Add(poolRetryInterval).UnixNano())
+}
+
+// newPooledTransport creates a pooledTransport with size connections, each created by newConnection.
+func newPooledTransport(size int, newConnection func() (Transport, error)) (*pooledTransport, error) {
+	p := &pooledTransport{members: make([]*poolMember, 0, size)}
+	for i := 0; i < size; i++ {
+		transport, err := newConnection()
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.members = append(p.members, &poolMember{transport: transport})
+	}
+	return p, nil
+}
+
+// Send sends the message through the next healthy connection of the pool. If the send fails, the connection is
+// marked as unhealthy and the message is sent through the next connection.
+func (p *pooledTransport) Send(message []byte) error {
+	return p.try(func(transport Transport) error { return transport.Send(message) })
+}
+
+// SendBatch sends the messages through the next healthy connection of the pool like Send.
+func (p *pooledTransport) SendBatch(messages [][]byte) error {
+	return p.try(func(transport Transport) error { return sendBatch(transport, messages) })
+}
+
+// try calls send with the next healthy connection of the pool. If it fails, the connection is marked as unhealthy and
+// send is called with the next connection.
+func (p *pooledTransport) try(send func(transport Transport) error) error {
+	first := p.selectMember()
+	errs := make([]error, 0, len(p.members))
+	for i := range p.members {
+		member := p.members[(first+i)%len(p.members)]
+		if err := send(member.transport); err != nil {
+			member.recordFailure()
+			errs = append(errs, err)
+			continue
+		}
+		member.failures.Store(0)
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// selectMember returns the index of the next healthy connection in turn. If no connection is healthy, the one of
+// the turn is used.
+func (p *pooledTransport) selectMember() int {
+	start := int((p.next.Add(1) - 1) % uint64(len(p.members)))
+	now := time.Now()
+	for i := range p.members {
+		index := (start + i) % len(p.members)
+		if p.members[index].healthy(now) {
+			return index
+		}
+	}
+	return start
+}
+
+// ensureConnection ensures the connections of all pool members supporting the check.
+func (p *pooledTransport) ensureConnection() error {
+	errs := make([]error, 0, len(p.members))
+	for _, member := range p.members {
+		if checker, ok := member.transport.(connectionChecker); ok {
+			if err := checker.ensureConnection(); err != nil {
+				member.recordFailure()
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) == len(p.members) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Close closes all connections of the pool.
+func (p *pooledTransport) Close() error {
+	errs := make([]error, 0, len(p.members))
+	for _, member := range p.members {
+		errs = append(errs, member.transport.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package gelflogger_test
+
+import (
+	"bufio"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestConnectionPool(t *testing.T) {
+	tests := []struct {
+		name      string
+		poolSize  int
+		wantConns int64
+	}{
+		{
+			name:      "Without pool",
+			poolSize:  0,
+			wantConns: 1,
+		},
+		{
+			name:      "Pool of three connections",
+			poolSize:  3,
+			wantConns: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := helper.StartMockServer(t)
+			defer func() { _ = mockServer.Close() }()
+
+			var conns atomic.Int64
+			messages := make(chan string, 10)
+			go func() {
+				for {
+					conn, err := mockServer.Accept()
+					if err != nil {
+						return
+					}
+					conns.Add(1)
+					go func() {
+						defer func() { _ = conn.Close() }()
+						reader := bufio.NewReader(conn)
+						for {
+							message, err := reader.ReadString(0)
+							if err != nil {
+								return
+							}
+							messages <- message
+						}
+					}()
+				}
+			}()
+
+			logger, err := gelflogger.NewLogger(mockServer.Addr().String(), gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			}), gelflogger.WithConnectionPool(tt.poolSize))
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+			for i := 0; i < 6; i++ {
+				if err := logger.Log("pooled", map[string]interface{}{}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+			for i := 0; i < 6; i++ {
+				select {
+				case <-messages:
+				case <-time.After(time.Second):
+					t.Fatalf("received %d messages, want 6", i)
+				}
+			}
+			if got := conns.Load(); got != tt.wantConns {
+				t.Errorf("server accepted %d connections, want %d", got, tt.wantConns)
+			}
+		})
+	}
+}
+
+func TestConnectionPoolUnreachable(t *testing.T) {
+	_, err := gelflogger.NewLogger("invalid:address", gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+		return 0, 0, nil, nil
+	}), gelflogger.WithConnectionPool(2))
+	if err == nil {
+		t.Error("NewLogger() error = nil, want an error when the pool cannot connect")
+	}
+}
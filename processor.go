@@ -0,0 +1,124 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The Graylog (Syslog) levels, from the most to the least severe, e.g. for MessageBuilder.Level.
+const (
+	Emergency = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+// levelNames maps the level names of common logging libraries to Graylog (Syslog) levels.
+var levelNames = map[string]int{
+	"emergency": 0,
+	"emerg":     0,
+	"alert":     1,
+	"panic":     1,
+	"critical":  2,
+	"crit":      2,
+	"fatal":     2,
+	"dpanic":    2,
+	"error":     3,
+	"err":       3,
+	"warning":   4,
+	"warn":      4,
+	"notice":    5,
+	"info":      6,
+	"debug":     7,
+	"trace":     7,
+}
+
+// ProcessFields is the processor NewLogger uses unless WithProcessor is given. It reads the level and the timestamp
+// like ReadFields. The full message is the JSON encoding of all fields, errors encoded as their message. The "level",
+// "time" and "message" fields are removed from the fields, so they are not sent as additional fields.
+func ProcessFields(fields map[string]interface{}) (int, float64, []byte, error) {
+	fullMessage, err := json.Marshal(encodeErrors(fields))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	level, timestamp, err := ReadFields(fields)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return level, timestamp, fullMessage, nil
+}
+
+// ReadFields is the field reader NewLogger uses unless WithProcessor or WithFieldReader is given, the counterpart of
+// ProcessFields without the full message. It reads the level from the "level" field, a level name or a Syslog level
+// number, see ParseLevel, informational if missing or unknown, and the timestamp from the "time" field in any format
+// understood by ParseTimestamp, the current time if missing. The "level", "time" and "message" fields are removed from
+// the fields, so they are not sent as additional fields.
+func ReadFields(fields map[string]interface{}) (int, float64, error) {
+	level := 6
+	if value, ok := fields["level"]; ok {
+		if parsed, err := ParseLevel(value); err == nil {
+			level = parsed
+		}
+	}
+	timestamp := float64(time.Now().UnixMilli()) / 1000
+	if value, ok := fields["time"]; ok {
+		parsed, err := ParseTimestamp(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("field `time`: %w; invalid log message format", err)
+		}
+		timestamp = parsed
+	}
+	delete(fields, "level")
+	delete(fields, "time")
+	delete(fields, "message")
+	return level, timestamp, nil
+}
+
+// ParseLevel returns the Graylog (Syslog) level of the level field of a log record: a level name like "warn",
+// "error" or "critical", compared case-insensitively, or a Syslog level number from 0, emergency, to 7, debug, given
+// as number of any type or as numeric string. Other values are rejected with an error.
+func ParseLevel(value interface{}) (int, error) {
+	var level float64
+	switch v := value.(type) {
+	case string:
+		if named, ok := levelNames[strings.ToLower(v)]; ok {
+			return named, nil
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unknown level %q", v)
+		}
+		level = parsed
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("unknown level %q", v)
+		}
+		level = parsed
+	default:
+		rv := reflect.ValueOf(value)
+		switch {
+		case rv.CanInt():
+			level = float64(rv.Int())
+		case rv.CanUint():
+			level = float64(rv.Uint())
+		case rv.CanFloat():
+			level = rv.Float()
+		default:
+			return 0, fmt.Errorf("unsupported level of type %T", value)
+		}
+	}
+	if level != math.Trunc(level) || level < 0 || level > 7 {
+		return 0, fmt.Errorf("level %v is not a Syslog level from 0 to 7", level)
+	}
+	return int(level), nil
+}
@@ -0,0 +1,88 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestProcessFields(t *testing.T) {
+	tests := []struct {
+		name          string
+		fields        map[string]interface{}
+		wantLevel     int
+		wantTimestamp float64
+		wantErr       bool
+	}{
+		{name: "Level name", fields: map[string]interface{}{"level": "warn", "time": 1700000000123.0}, wantLevel: 4, wantTimestamp: 1700000000.123},
+		{name: "Upper case level name", fields: map[string]interface{}{"level": "ERROR", "time": 1700000000000.0}, wantLevel: 3, wantTimestamp: 1700000000},
+		{name: "Graylog level number", fields: map[string]interface{}{"level": 7.0, "time": 1700000000000.0}, wantLevel: 7, wantTimestamp: 1700000000},
+		{name: "Unknown level", fields: map[string]interface{}{"level": "verbose", "time": 1700000000000.0}, wantLevel: 6, wantTimestamp: 1700000000},
+		{name: "Missing level and time", fields: map[string]interface{}{}, wantLevel: 6},
+		{name: "Invalid time", fields: map[string]interface{}{"time": "yesterday"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields["message"] = "processed"
+			tt.fields["request_id"] = "42"
+			level, timestamp, fullMessage, err := gelflogger.ProcessFields(tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if level != tt.wantLevel {
+				t.Errorf("ProcessFields() level = %d, want %d", level, tt.wantLevel)
+			}
+			if tt.wantTimestamp != 0 && timestamp != tt.wantTimestamp {
+				t.Errorf("ProcessFields() timestamp = %v, want %v", timestamp, tt.wantTimestamp)
+			}
+			if timestamp == 0 {
+				t.Error("ProcessFields() timestamp = 0, want the current time")
+			}
+			var full map[string]interface{}
+			if err := json.Unmarshal(fullMessage, &full); err != nil || full["message"] != "processed" {
+				t.Errorf("ProcessFields() full message = %s, want all fields", fullMessage)
+			}
+			if len(tt.fields) != 1 || tt.fields["request_id"] != "42" {
+				t.Errorf("fields after ProcessFields() = %v, want only the additional fields", tt.fields)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "Level name", value: "Warning", want: 4},
+		{name: "Numeric string", value: "2", want: 2},
+		{name: "Float", value: 3.0, want: 3},
+		{name: "Integer", value: int8(0), want: 0},
+		{name: "Unsigned integer", value: uint(7), want: 7},
+		{name: "JSON number", value: json.Number("5"), want: 5},
+		{name: "Out of range", value: 8, wantErr: true},
+		{name: "Fraction", value: 3.5, wantErr: true},
+		{name: "Unknown name", value: "verbose", wantErr: true},
+		{name: "Unsupported type", value: true, wantErr: true},
+		{name: "Nil", value: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gelflogger.ParseLevel(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseLevel() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// RedactionMask replaces the redacted values, see Redactor.
+const RedactionMask = "[REDACTED]"
+
+// DefaultRedactedFields are the names of the fields whose values NewDefaultRedactor masks.
+var DefaultRedactedFields = []string{"password", "passwd", "secret", "token", "access_token", "refresh_token", "api_key", "authorization", "cookie", "ssn"}
+
+var (
+	// EmailPattern matches e-mail addresses.
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// CreditCardPattern matches credit card numbers of 13 to 19 digits, optionally grouped by spaces or dashes. A
+	// Redactor masks only the matches passing the Luhn check of card numbers, so other long numbers like timestamps in
+	// milliseconds are kept.
+	CreditCardPattern = regexp.MustCompile(`\b\d{4}[ \-]?\d{4}[ \-]?\d{4}[ \-]?\d{1,7}\b`)
+)
+
+// Redactor is a Processor masking personal and secret data before the messages leave the process, see
+// WithProcessorChain. The values of the fields with one of the configured names are replaced by RedactionMask as a
+// whole, whatever their type, at any depth of nested maps. The parts of string values, of the short message and of
+// the full message matching one of the configured patterns are replaced by RedactionMask. If the full message is a
+// JSON object, like the one of ProcessFields, the fields inside it are redacted as well.
+type Redactor struct {
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+var _ Processor = (*Redactor)(nil)
+
+// NewRedactor creates a Redactor masking the values of the fields with the given names, compared case-insensitively,
+// and the parts of the values matching the given patterns.
+//
+// Example usage:
+//
+//	redactor := gelflogger.NewRedactor([]string{"password", "iban"}, gelflogger.EmailPattern)
+//	logger, err := gelflogger.NewLogger(address, gelflogger.WithProcessorChain(redactor))
+func NewRedactor(fieldNames []string, patterns ...*regexp.Regexp) *Redactor {
+	fields := make(map[string]bool, len(fieldNames))
+	for _, name := range fieldNames {
+		fields[strings.ToLower(name)] = true
+	}
+	return &Redactor{fields: fields, patterns: patterns}
+}
+
+// NewDefaultRedactor creates a Redactor masking the DefaultRedactedFields, e-mail addresses and credit card numbers.
+func NewDefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactedFields, EmailPattern, CreditCardPattern)
+}
+
+// Process redacts the message in place.
+func (r *Redactor) Process(msg *Message) error {
+	msg.ShortMessage = r.redactString(msg.ShortMessage)
+	msg.FullMessage = r.redactFullMessage(msg.FullMessage)
+	for name, value := range msg.Additional {
+		msg.Additional[name] = r.redactField(name, value)
+	}
+	return nil
+}
+
+// redactField returns the redacted value of the named field.
+func (r *Redactor) redactField(name string, value interface{}) interface{} {
+	if r.fields[strings.ToLower(name)] {
+		return RedactionMask
+	}
+	return r.redactValue(value)
+}
+
+// redactValue returns the value with the sensitive fields of nested maps and the parts of strings matching the
+// patterns redacted. Nested maps and slices are copied, as they may be shared with the caller.
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for name, nested := range v {
+			redacted[name] = r.redactField(name, nested)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, nested := range v {
+			redacted[i] = r.redactValue(nested)
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, len(v))
+		for i, nested := range v {
+			redacted[i] = r.redactString(nested)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// redactString replaces the parts of s matching the patterns.
+func (r *Redactor) redactString(s string) string {
+	for _, pattern := range r.patterns {
+		if pattern == CreditCardPattern {
+			s = pattern.ReplaceAllStringFunc(s, redactCardNumber)
+			continue
+		}
+		s = pattern.ReplaceAllString(s, RedactionMask)
+	}
+	return s
+}
+
+// redactCardNumber returns RedactionMask if the match of CreditCardPattern is a valid card number, or the match.
+func redactCardNumber(match string) string {
+	if luhnValid(match) {
+		return RedactionMask
+	}
+	return match
+}
+
+// luhnValid reports whether the digits of number, ignoring the separators, pass the Luhn check: doubling every
+// second digit from the right, the digit sum is a multiple of 10.
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			continue
+		}
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactFullMessage redacts the fields of a full message holding a JSON object, or the parts of any other full message
+// matching the patterns.
+func (r *Redactor) redactFullMessage(fullMessage string) string {
+	if !strings.HasPrefix(fullMessage, "{") {
+		return r.redactString(fullMessage)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(fullMessage), &fields); err != nil {
+		return r.redactString(fullMessage)
+	}
+	redacted, err := json.Marshal(r.redactValue(fields))
+	if err != nil {
+		return r.redactString(fullMessage)
+	}
+	return string(redacted)
+}
@@ -0,0 +1,93 @@
+package gelflogger_test
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestRedactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		redactor *gelflogger.Redactor
+		msg      gelflogger.Message
+		want     gelflogger.Message
+	}{
+		{
+			name:     "Sensitive fields are masked whatever their type",
+			redactor: gelflogger.NewDefaultRedactor(),
+			msg: gelflogger.Message{Additional: map[string]interface{}{
+				"Password": "hunter2", "token": 42, "user": "42",
+				"request": map[string]interface{}{"Authorization": "Bearer abc", "path": "/orders"},
+			}},
+			want: gelflogger.Message{Additional: map[string]interface{}{
+				"Password": gelflogger.RedactionMask, "token": gelflogger.RedactionMask, "user": "42",
+				"request": map[string]interface{}{"Authorization": gelflogger.RedactionMask, "path": "/orders"},
+			}},
+		},
+		{
+			name:     "Patterns are masked in values and messages",
+			redactor: gelflogger.NewDefaultRedactor(),
+			msg: gelflogger.Message{
+				ShortMessage: "mail sent to jane.doe@example.com",
+				FullMessage:  "card 4111 1111 1111 1111 declined",
+				Additional:   map[string]interface{}{"note": "paid with 4111-1111-1111-1111", "recipients": []interface{}{"a@example.org", 7}},
+			},
+			want: gelflogger.Message{
+				ShortMessage: "mail sent to [REDACTED]",
+				FullMessage:  "card [REDACTED] declined",
+				Additional:   map[string]interface{}{"note": "paid with [REDACTED]", "recipients": []interface{}{"[REDACTED]", 7}},
+			},
+		},
+		{
+			name:     "Only numbers passing the Luhn check are masked as card numbers",
+			redactor: gelflogger.NewDefaultRedactor(),
+			msg: gelflogger.Message{
+				ShortMessage: "charged 5500 0000 0000 0004 at 1700000000000",
+				Additional:   map[string]interface{}{"typo": "4111 1111 1111 1112", "timestamp_ms": "1700000000000"},
+			},
+			want: gelflogger.Message{
+				ShortMessage: "charged [REDACTED] at 1700000000000",
+				Additional:   map[string]interface{}{"typo": "4111 1111 1111 1112", "timestamp_ms": "1700000000000"},
+			},
+		},
+		{
+			name:     "JSON full message is redacted by field",
+			redactor: gelflogger.NewRedactor([]string{"iban"}),
+			msg:      gelflogger.Message{FullMessage: `{"iban":"DE02120300000000202051","user":"42"}`, Additional: map[string]interface{}{}},
+			want:     gelflogger.Message{FullMessage: `{"iban":"[REDACTED]","user":"42"}`, Additional: map[string]interface{}{}},
+		},
+		{
+			name:     "Custom pattern",
+			redactor: gelflogger.NewRedactor(nil, regexp.MustCompile(`sk_live_\w+`)),
+			msg:      gelflogger.Message{ShortMessage: "using key sk_live_abc123", Additional: map[string]interface{}{"password": "kept"}},
+			want:     gelflogger.Message{ShortMessage: "using key [REDACTED]", Additional: map[string]interface{}{"password": "kept"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.msg
+			if err := tt.redactor.Process(&msg); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if !reflect.DeepEqual(msg, tt.want) {
+				t.Errorf("Process() = %+v, want %+v", msg, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorInChain(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, gelflogger.WithProcessorChain(gelflogger.NewDefaultRedactor()))
+	if err := logger.Log("login", map[string]interface{}{"password": "hunter2", "user": "jane"}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if got := transport.messages[0]; strings.Contains(got, "hunter2") {
+		t.Errorf("sent %s, want the password redacted from the fields and the full message", got)
+	}
+}
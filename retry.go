@@ -0,0 +1,70 @@
+package gelflogger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+// ErrTemporary is wrapped by the errors of sends which failed for a reason that may go away by itself, e.g. a lost
+// connection, a timeout or an unavailable Graylog server. Such sends are retried, see WithRetry.
+var ErrTemporary = errors.New("temporary failure")
+
+// ErrPermanent is wrapped by the errors of messages which cannot be sent however often they are retried, e.g.
+// because they cannot be encoded or are too large.
+var ErrPermanent = errors.New("permanent failure")
+
+const (
+	// DefaultRetryDelay is the delay before the first retry of a send which failed temporarily.
+	DefaultRetryDelay = 100 * time.Millisecond
+	// DefaultMaxRetryDelay is the maximum delay between two retries of a send.
+	DefaultMaxRetryDelay = 5 * time.Second
+)
+
+// classify wraps the error with ErrTemporary or ErrPermanent, unless it wraps one of them already. Transports can
+// classify their errors themselves by wrapping ErrTemporary or ErrPermanent. Otherwise, network errors, closed
+// connections and the errors of a transport which is reconnecting or whose circuit breaker is open are temporary,
+// all other errors are permanent.
+func classify(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrTemporary), errors.Is(err, ErrPermanent):
+		return err
+	case isTemporary(err):
+		return fmt.Errorf("%w: %w", ErrTemporary, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrPermanent, err)
+	}
+}
+
+// isTemporary reports whether the error is caused by the network or the availability of Graylog.
+func isTemporary(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, ErrNotConnected) ||
+		errors.Is(err, ErrWriteTimeout) ||
+		errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// retry calls send and retries it while it fails temporarily, at most as often as the retry budget of the Logger
+// allows, see WithRetry. The retries stop early once the Logger is closing. The returned error is classified.
+func (l *Logger) retry(send func() error) error {
+	err := classify(send())
+	for attempt := 0; attempt < l.retryBudget && errors.Is(err, ErrTemporary); attempt++ {
+		select {
+		case <-time.After(l.retryBackoff.delay(attempt)):
+		case <-l.closing:
+			return err
+		}
+		err = classify(send())
+	}
+	return err
+}
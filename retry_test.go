@@ -0,0 +1,93 @@
+package gelflogger_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// failingTransport fails the first failures sends with err.
+type failingTransport struct {
+	err      error
+	failures int
+	attempts int
+}
+
+func (f *failingTransport) Send([]byte) error {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *failingTransport) Close() error { return nil }
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "network error", err: &net.OpError{Op: "write", Net: "tcp", Err: errors.New("broken")}, want: gelflogger.ErrTemporary},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: gelflogger.ErrTemporary},
+		{name: "circuit open", err: gelflogger.ErrCircuitOpen, want: gelflogger.ErrTemporary},
+		{name: "classified by the transport", err: fmt.Errorf("%w: too large", gelflogger.ErrPermanent), want: gelflogger.ErrPermanent},
+		{name: "other error", err: errors.New("unexpected"), want: gelflogger.ErrPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := gelflogger.NewLoggerWithTransport(&failingTransport{err: tt.err, failures: 1}, processNothing)
+			err := logger.Log("failing", map[string]interface{}{})
+			if !errors.Is(err, tt.want) || !errors.Is(err, tt.err) {
+				t.Errorf("Log() error = %v, want it to wrap %v and %v", err, tt.want, tt.err)
+			}
+		})
+	}
+
+	t.Run("encoding error", func(t *testing.T) {
+		logger := gelflogger.NewLoggerWithTransport(&failingTransport{}, func(map[string]interface{}) (int, float64, []byte, error) {
+			return 0, 0, nil, errors.New("invalid fields")
+		})
+		if err := logger.Log("invalid", map[string]interface{}{}); !errors.Is(err, gelflogger.ErrPermanent) {
+			t.Errorf("Log() error = %v, want ErrPermanent", err)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	temporary := &net.OpError{Op: "write", Net: "tcp", Err: errors.New("broken")}
+	tests := []struct {
+		name         string
+		err          error
+		failures     int
+		budget       int
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "recovers within the budget", err: temporary, failures: 2, budget: 3, wantAttempts: 3},
+		{name: "budget exhausted", err: temporary, failures: 5, budget: 2, wantErr: gelflogger.ErrTemporary, wantAttempts: 3},
+		{name: "permanent failure", err: errors.New("unexpected"), failures: 5, budget: 3, wantErr: gelflogger.ErrPermanent, wantAttempts: 1},
+		{name: "no budget", err: temporary, failures: 1, budget: 0, wantErr: gelflogger.ErrTemporary, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &failingTransport{err: tt.err, failures: tt.failures}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithRetry(tt.budget, time.Millisecond))
+			err := logger.Log("retried", map[string]interface{}{})
+			if (tt.wantErr == nil && err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Log() error = %v, want %v", err, tt.wantErr)
+			}
+			if transport.attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", transport.attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package gelflogger
+
+// Route sends the GELF messages matching its predicate through its own transport instead of the transport of the
+// Logger, e.g. errors to a dedicated high-retention Graylog input.
+//
+// Match receives the formatted GELF message, with the standard fields ("level", "short_message", ...) and the
+// additional fields prefixed with an underscore. If Transport is nil, the matching messages are dropped.
+type Route struct {
+	Match     func(gelfMsg map[string]interface{}) bool
+	Transport Transport
+}
+
+// LevelAtMost returns a Route predicate matching messages with a level less than or equal to the given syslog
+// severity, i.e. at least as severe, e.g. LevelAtMost(3) matches errors, critical, alert and emergency messages.
+func LevelAtMost(level int) func(gelfMsg map[string]interface{}) bool {
+	return func(gelfMsg map[string]interface{}) bool {
+		messageLevel, ok := gelfLevel(gelfMsg)
+		return ok && messageLevel <= level
+	}
+}
+
+// LevelAbove returns a Route predicate matching messages with a level greater than the given syslog severity, i.e.
+// less severe, e.g. LevelAbove(5) matches info and debug messages.
+func LevelAbove(level int) func(gelfMsg map[string]interface{}) bool {
+	return func(gelfMsg map[string]interface{}) bool {
+		messageLevel, ok := gelfLevel(gelfMsg)
+		return ok && messageLevel > level
+	}
+}
+
+// gelfLevel returns the level of the formatted GELF message.
+func gelfLevel(gelfMsg map[string]interface{}) (int, bool) {
+	switch level := gelfMsg["level"].(type) {
+	case int:
+		return level, true
+	case float64:
+		return int(level), true
+	default:
+		return 0, false
+	}
+}
+
+// route returns the transport for the message: the one of the first matching Route, or the transport of the Logger if
+// no Route matches. The returned boolean is false if the message is dropped by its Route. The members of the formatted
+// GELF message the Routes match are only built if Routes are configured.
+func (l *Logger) route(msg *Message) (Transport, bool) {
+	if len(l.routes) == 0 {
+		return l.transport, true
+	}
+	gelfMsg, err := msg.gelfFields(l.encoding())
+	if err != nil {
+		// Not encodable messages are rejected before they are routed
+		return l.transport, true
+	}
+	for _, r := range l.routes {
+		if r.Match(gelfMsg) {
+			return r.Transport, r.Transport != nil
+		}
+	}
+	return l.transport, true
+}
@@ -0,0 +1,62 @@
+package gelflogger_test
+
+import (
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestRoutes(t *testing.T) {
+	defaultTransport := &recordingTransport{}
+	errorTransport := &recordingTransport{}
+	auditTransport := &recordingTransport{}
+
+	level := 0
+	logger := gelflogger.NewLoggerWithTransport(defaultTransport, func(fields map[string]interface{}) (int, float64, []byte, error) {
+		return level, 0, nil, nil
+	}, gelflogger.WithRoutes(
+		gelflogger.Route{Match: func(gelfMsg map[string]interface{}) bool { return gelfMsg["_audit"] == "yes" }, Transport: auditTransport},
+		gelflogger.Route{Match: gelflogger.LevelAtMost(3), Transport: errorTransport},
+		gelflogger.Route{Match: gelflogger.LevelAbove(6)},
+	))
+
+	tests := []struct {
+		name   string
+		level  int
+		fields map[string]interface{}
+		want   *recordingTransport
+	}{
+		{name: "Error to the error input", level: 3, want: errorTransport},
+		{name: "Critical to the error input", level: 2, want: errorTransport},
+		{name: "Warning to the default input", level: 4, want: defaultTransport},
+		{name: "Info to the default input", level: 6, want: defaultTransport},
+		{name: "Debug dropped", level: 7, want: nil},
+		{name: "First matching route wins", level: 3, fields: map[string]interface{}{"audit": "yes"}, want: auditTransport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := map[*recordingTransport]int{}
+			for _, transport := range []*recordingTransport{defaultTransport, errorTransport, auditTransport} {
+				before[transport] = len(transport.messages)
+			}
+			level = tt.level
+			fields := tt.fields
+			if fields == nil {
+				fields = map[string]interface{}{}
+			}
+			if err := logger.Log("routed", fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			for transport, count := range before {
+				wantCount := count
+				if transport == tt.want {
+					wantCount++
+				}
+				if len(transport.messages) != wantCount {
+					t.Errorf("transport received %d messages, want %d", len(transport.messages), wantCount)
+				}
+			}
+		})
+	}
+}
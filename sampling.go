@@ -0,0 +1,38 @@
+package gelflogger
+
+import "sync/atomic"
+
+// sampler keeps 1 in N messages of the levels with a sample rate, see WithSampling.
+type sampler struct {
+	rates    map[int]uint64
+	counters map[int]*atomic.Uint64
+}
+
+// newSampler creates a sampler for the given sample rates by Graylog (Syslog) level, or returns nil if no level is
+// sampled. Rates of one or less keep every message of their level.
+func newSampler(rates map[int]int) *sampler {
+	s := &sampler{rates: make(map[int]uint64), counters: make(map[int]*atomic.Uint64)}
+	for level, rate := range rates {
+		if rate > 1 {
+			s.rates[level] = uint64(rate)
+			s.counters[level] = &atomic.Uint64{}
+		}
+	}
+	if len(s.rates) == 0 {
+		return nil
+	}
+	return s
+}
+
+// sample reports whether the message of the given level is kept, and the sample rate of its level, which is one for
+// levels which are not sampled. The first message of every N is kept, so rare messages are not lost entirely.
+func (s *sampler) sample(level int) (uint64, bool) {
+	if s == nil {
+		return 1, true
+	}
+	rate, ok := s.rates[level]
+	if !ok {
+		return 1, true
+	}
+	return rate, (s.counters[level].Add(1)-1)%rate == 0
+}
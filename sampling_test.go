@@ -0,0 +1,64 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+// processLevel uses the "level" field as Graylog (Syslog) level.
+func processLevel(fields map[string]interface{}) (int, float64, []byte, error) {
+	level := fields["level"].(int)
+	delete(fields, "level")
+	return level, 0, nil, nil
+}
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		name  string
+		rates map[int]int
+		level int
+		want  []string
+	}{
+		{name: "sampled level", rates: map[int]int{7: 3}, level: 7, want: []string{"0", "3", "6"}},
+		{name: "level without sample rate", rates: map[int]int{7: 3}, level: 3, want: []string{"0", "1", "2", "3", "4", "5", "6"}},
+		{name: "sample rate of one", rates: map[int]int{7: 1}, level: 7, want: []string{"0", "1", "2", "3", "4", "5", "6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processLevel, gelflogger.WithSampling(tt.rates))
+			for i := 0; i < 7; i++ {
+				if err := logger.Log(strconv.Itoa(i), map[string]interface{}{"level": tt.level}); err != nil {
+					t.Fatalf("Log() error = %v", err)
+				}
+			}
+
+			var got []string
+			for _, message := range transport.messages {
+				var gelfMsg map[string]interface{}
+				if err := json.Unmarshal([]byte(message), &gelfMsg); err != nil {
+					t.Fatalf("invalid GELF message %s: %v", message, err)
+				}
+				got = append(got, gelfMsg["short_message"].(string))
+				rate := tt.rates[tt.level]
+				if rate > 1 && (gelfMsg["_sampled"] != "true" || gelfMsg["_sample_rate"] != float64(rate)) {
+					t.Errorf("message %s lacks the sampling fields with rate %d", message, rate)
+				}
+				if rate <= 1 && (gelfMsg["_sampled"] != nil || gelfMsg["_sample_rate"] != nil) {
+					t.Errorf("message %s of a level which is not sampled has sampling fields", message)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sent %v, want %v", got, tt.want)
+			}
+			if logger.Dropped() != 0 {
+				t.Errorf("Dropped() = %d, want 0", logger.Dropped())
+			}
+		})
+	}
+}
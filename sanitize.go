@@ -0,0 +1,26 @@
+package gelflogger
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// strippedControl reports whether the rune is a control character stripped from the strings of a message, see
+// WithUnsanitizedStrings: the C0 controls other than tab, line feed and carriage return, DEL and the C1 controls.
+func strippedControl(r rune) bool {
+	return (r < 0x20 && r != '\t' && r != '\n' && r != '\r') || (r >= 0x7f && r <= 0x9f)
+}
+
+// sanitizeString returns the string with every run of invalid UTF-8 bytes replaced by a single replacement character
+// and the control characters stripped, like appendString does while encoding, see WithUnsanitizedStrings.
+func sanitizeString(s string) string {
+	if utf8.ValidString(s) && strings.IndexFunc(s, strippedControl) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if strippedControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(s, "\ufffd"))
+}
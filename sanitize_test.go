@@ -0,0 +1,87 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestSanitizing(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             []gelflogger.Option
+		message          string
+		fields           map[string]interface{}
+		wantShortMessage string
+		wantFullMessage  string
+		wantField        string
+	}{
+		{
+			name:             "Control characters stripped",
+			message:          "bell\a and escape\x1b[31m",
+			fields:           map[string]interface{}{"stack": "line 1\n\tline 2\r\x00", "value": "del\x7f c1\u0085"},
+			wantShortMessage: "bell and escape[31m",
+			wantFullMessage:  "line 1\n\tline 2\r",
+			wantField:        "del c1",
+		},
+		{
+			name:             "Invalid UTF-8 replaced",
+			message:          "bad \xff\xfe\xfd bytes",
+			fields:           map[string]interface{}{"stack": "ok", "value": "a\xc3b"},
+			wantShortMessage: "bad \ufffd bytes",
+			wantFullMessage:  "ok",
+			wantField:        "a\ufffdb",
+		},
+		{
+			name:             "Unsanitized",
+			opts:             []gelflogger.Option{gelflogger.WithUnsanitizedStrings()},
+			message:          "bell\a bad \xff\xfe",
+			fields:           map[string]interface{}{"stack": "nul\x00", "value": "del\x7f"},
+			wantShortMessage: "bell\a bad \ufffd\ufffd",
+			wantFullMessage:  "nul\x00",
+			wantField:        "del\x7f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			opts := append([]gelflogger.Option{gelflogger.WithFullMessageField("stack")}, tt.opts...)
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, opts...)
+			if err := logger.Log(tt.message, tt.fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			if gelfMsg["short_message"] != tt.wantShortMessage {
+				t.Errorf("short_message = %q, want %q", gelfMsg["short_message"], tt.wantShortMessage)
+			}
+			if gelfMsg["full_message"] != tt.wantFullMessage {
+				t.Errorf("full_message = %q, want %q", gelfMsg["full_message"], tt.wantFullMessage)
+			}
+			if gelfMsg["_value"] != tt.wantField {
+				t.Errorf("_value = %q, want %q", gelfMsg["_value"], tt.wantField)
+			}
+		})
+	}
+}
+
+func TestSanitizingRoutes(t *testing.T) {
+	transport := &recordingTransport{}
+	var matched string
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithRoutes(gelflogger.Route{
+		Match: func(gelfMsg map[string]interface{}) bool {
+			matched, _ = gelfMsg["short_message"].(string)
+			return false
+		},
+	}))
+	if err := logger.Log("bell\a\xff", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if matched != "bell\ufffd" {
+		t.Errorf("Route matched short_message %q, want the sanitized %q", matched, "bell\ufffd")
+	}
+}
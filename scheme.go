@@ -0,0 +1,73 @@
+package gelflogger
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultPort is the port of the GELF inputs of Graylog, used for tcp://, tls:// and udp:// addresses without a port.
+const DefaultPort = "12201"
+
+// NewTransport creates the transport for the scheme of the given address, as NewLogger does:
+//
+//   - tcp://host:port, or an address without scheme, sends the messages over TCP, using TLS if useTLS is set.
+//   - tls://host:port sends the messages over TCP with TLS.
+//   - udp://host:port sends the messages as GELF UDP datagrams, chunking large messages.
+//   - http://host:port/path and https://host:port/path post every message to the GELF HTTP input, the path
+//     defaults to /gelf.
+//   - unix:///path/to/socket sends the messages over a Unix domain stream socket.
+//
+// The tcp://, tls:// and udp:// addresses default to DefaultPort. Options configuring a feature the transport of the
+// scheme does not support have no effect.
+func NewTransport(address string, useTLS bool, tlsConfig *tls.Config, opts ...Option) (Transport, error) {
+	return newConfiguredTransport(address, useTLS, tlsConfig, newConfig(opts))
+}
+
+// newConfiguredTransport creates the transport for the scheme of the given address and configuration.
+func newConfiguredTransport(address string, useTLS bool, tlsConfig *tls.Config, cfg config) (Transport, error) {
+	scheme, rest, found := strings.Cut(address, "://")
+	if !found {
+		return newConfiguredTCPTransport(address, useTLS, tlsConfig, cfg)
+	}
+	switch scheme = strings.ToLower(scheme); scheme {
+	case "tcp", "tls":
+		cfg.failoverAddresses = withoutScheme(scheme, cfg.failoverAddresses)
+		return newConfiguredTCPTransport(withDefaultPort(rest), useTLS || scheme == "tls", tlsConfig, cfg)
+	case "udp":
+		return nilIfErr(newUDPTransport(withDefaultPort(rest), cfg.compression))
+	case "http", "https":
+		return nilIfErr(newHTTPTransport(address, tlsConfig, cfg))
+	case "unix":
+		cfg.network = "unix"
+		cfg.failoverAddresses = withoutScheme(scheme, cfg.failoverAddresses)
+		return newConfiguredTCPTransport(rest, false, nil, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in address %s", scheme, address)
+	}
+}
+
+// withDefaultPort appends DefaultPort to the given address if it has no port.
+func withDefaultPort(address string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(strings.Trim(address, "[]"), DefaultPort)
+	}
+	return address
+}
+
+// withoutScheme removes the given scheme from the addresses, so failover addresses may be given with or without the
+// scheme of the primary address. Addresses of the TCP based schemes without port default to DefaultPort.
+func withoutScheme(scheme string, addresses []string) []string {
+	trimmed := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if rest, found := strings.CutPrefix(address, scheme+"://"); found {
+			address = rest
+		}
+		if scheme != "unix" {
+			address = withDefaultPort(address)
+		}
+		trimmed = append(trimmed, address)
+	}
+	return trimmed
+}
@@ -0,0 +1,185 @@
+package gelflogger_test
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func processNothing(map[string]interface{}) (int, float64, []byte, error) {
+	return 6, 0, nil, nil
+}
+
+func TestAddressScheme(t *testing.T) {
+	tcpServer := helper.StartMockServer(t)
+	defer func() { _ = tcpServer.Close() }()
+	tcpMessages := helper.ReceiveMessages(t, tcpServer, 0)
+
+	tlsServer := helper.StartMockTLSServer(t)
+	defer func() { _ = tlsServer.Close() }()
+
+	socket := filepath.Join(t.TempDir(), "gelf.sock")
+	unixServer, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on Unix socket: %v", err)
+	}
+	defer func() { _ = unixServer.Close() }()
+	unixMessages := helper.ReceiveMessages(t, unixServer, 0)
+
+	udpServer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on UDP: %v", err)
+	}
+	defer func() { _ = udpServer.Close() }()
+	udpMessages := receiveDatagrams(udpServer)
+
+	httpMessages := make(chan string, 10)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		httpMessages <- r.URL.Path + " " + string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer httpServer.Close()
+
+	tests := []struct {
+		name     string
+		address  string
+		opts     []gelflogger.Option
+		received <-chan string
+		wantErr  bool
+	}{
+		{name: "TCP", address: "tcp://" + tcpServer.Addr().String(), received: tcpMessages},
+		{name: "TLS", address: "tls://" + tlsServer.Addr().String(), opts: []gelflogger.Option{gelflogger.WithTLS(&tls.Config{InsecureSkipVerify: true})}},
+		{name: "UDP", address: "udp://" + udpServer.LocalAddr().String(), received: udpMessages},
+		{name: "HTTP with default path", address: httpServer.URL, received: httpMessages},
+		{name: "HTTP with path", address: httpServer.URL + "/custom", received: httpMessages},
+		{name: "Unix socket", address: "unix://" + socket, received: unixMessages},
+		{name: "Unsupported scheme", address: "ftp://" + tcpServer.Addr().String(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := gelflogger.NewLogger(tt.address, append(tt.opts, gelflogger.WithProcessor(processNothing))...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := logger.Log("scheme", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			if tt.received == nil {
+				return
+			}
+			select {
+			case message := <-tt.received:
+				if !strings.Contains(message, `"short_message":"scheme"`) {
+					t.Errorf("received %q, want the GELF message", message)
+				}
+				if strings.HasPrefix(tt.address, "http") && !strings.HasPrefix(message, "/gelf ") && !strings.HasPrefix(message, "/custom ") {
+					t.Errorf("received %q, want it posted to the path of the address or /gelf", message)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("message was not received")
+			}
+		})
+	}
+}
+
+func TestHTTPTransportErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	transport, err := gelflogger.NewTransport(server.URL, false, nil)
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	defer func() { _ = transport.Close() }()
+	if err := transport.Send([]byte(`{}`)); err == nil {
+		t.Error("Send() error = nil, want an error for a 400 response")
+	}
+}
+
+func TestUDPChunking(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on UDP: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+	messages := receiveDatagrams(server)
+
+	transport, err := gelflogger.NewTransport("udp://"+server.LocalAddr().String(), false, nil)
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	defer func() { _ = transport.Close() }()
+
+	large, _ := json.Marshal(map[string]string{"short_message": strings.Repeat("x", 5000)})
+	if err := transport.Send(large); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	select {
+	case message := <-messages:
+		if message != string(large) {
+			t.Errorf("reassembled message has %d bytes, want %d", len(message), len(large))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+	}
+
+	if err := transport.Send(make([]byte, 200*1420)); err == nil {
+		t.Error("Send() error = nil, want an error for a message exceeding the maximum number of chunks")
+	}
+}
+
+// receiveDatagrams reads the GELF UDP datagrams sent to the connection, reassembling chunked messages, and sends the
+// messages to the returned channel.
+func receiveDatagrams(conn net.PacketConn) <-chan string {
+	messages := make(chan string, 10)
+	go func() {
+		chunks := map[string][][]byte{}
+		buf := make([]byte, 65536)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			datagram := append([]byte(nil), buf[:n]...)
+			if n < 12 || datagram[0] != 0x1e || datagram[1] != 0x0f {
+				messages <- string(datagram)
+				continue
+			}
+			id, seq, count := string(datagram[2:10]), datagram[10], int(datagram[11])
+			if chunks[id] == nil {
+				chunks[id] = make([][]byte, count)
+			}
+			chunks[id][seq] = datagram[12:]
+			var message []byte
+			for _, chunk := range chunks[id] {
+				if chunk == nil {
+					message = nil
+					break
+				}
+				message = append(message, chunk...)
+			}
+			if message != nil {
+				delete(chunks, id)
+				messages <- string(message)
+			}
+		}
+	}()
+	return messages
+}
@@ -0,0 +1,19 @@
+package gelflogger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// renderShortMessage returns the short message of a log record: the message itself, or, if the record has no message
+// and a short message template is configured, the template rendered with the fields, see WithShortMessageTemplate.
+func (l *Logger) renderShortMessage(message string, fields map[string]interface{}) (string, error) {
+	if message != "" || l.shortMessageTemplate == nil {
+		return message, nil
+	}
+	var rendered strings.Builder
+	if err := l.shortMessageTemplate.Execute(&rendered, fields); err != nil {
+		return "", fmt.Errorf("short message template: %w", err)
+	}
+	return rendered.String(), nil
+}
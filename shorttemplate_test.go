@@ -0,0 +1,90 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"text/template"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestWithShortMessageTemplate(t *testing.T) {
+	accessLog := template.Must(template.New("access").Parse("{{.method}} {{.path}} -> {{.status}}"))
+	failing := template.Must(template.New("failing").Parse("{{index .codes 5}}"))
+
+	tests := []struct {
+		name             string
+		tmpl             *template.Template
+		message          string
+		fields           map[string]interface{}
+		wantShortMessage string
+		wantErr          error
+	}{
+		{
+			name:             "Rendered from fields",
+			tmpl:             accessLog,
+			fields:           map[string]interface{}{"method": "GET", "path": "/orders", "status": 200},
+			wantShortMessage: "GET /orders -> 200",
+		},
+		{
+			name:             "Message kept",
+			tmpl:             accessLog,
+			message:          "request served",
+			fields:           map[string]interface{}{"method": "GET", "path": "/orders", "status": 200},
+			wantShortMessage: "request served",
+		},
+		{
+			name:             "Missing field",
+			tmpl:             accessLog,
+			fields:           map[string]interface{}{"method": "GET", "path": "/orders"},
+			wantShortMessage: "GET /orders -> <no value>",
+		},
+		{
+			name:    "Failing template",
+			tmpl:    failing,
+			fields:  map[string]interface{}{"codes": []int{1}},
+			wantErr: gelflogger.ErrPermanent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields, gelflogger.WithShortMessageTemplate(tt.tmpl))
+			err := logger.Log(tt.message, tt.fields)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || len(transport.messages) != 0 {
+					t.Errorf("Log() error = %v, sent %d messages, want %v", err, len(transport.messages), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			if gelfMsg["short_message"] != tt.wantShortMessage {
+				t.Errorf("short_message = %q, want %q", gelfMsg["short_message"], tt.wantShortMessage)
+			}
+		})
+	}
+}
+
+func TestGelfWriterShortMessageTemplate(t *testing.T) {
+	transport := &recordingTransport{}
+	writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields,
+		gelflogger.WithShortMessageTemplate(template.Must(template.New("access").Parse("{{.level}}: {{.method}} {{.path}}"))))}
+	if _, err := writer.Write([]byte(`{"level":"warn","method":"POST","path":"/orders"}`)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var gelfMsg map[string]interface{}
+	if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+		t.Fatalf("invalid GELF message: %v", err)
+	}
+	if gelfMsg["short_message"] != "warn: POST /orders" || gelfMsg["level"] != float64(4) {
+		t.Errorf("sent %s, want the short message rendered from the record", transport.messages[0])
+	}
+}
@@ -0,0 +1,425 @@
+package gelflogger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// spoolSegments is the number of segments a Spool with a maximum size is split into, the granularity in which the
+	// oldest messages are dropped once the spool is full.
+	spoolSegments = 16
+	// defaultSpoolSegmentSize is the size of the segments of a Spool without a maximum size.
+	defaultSpoolSegmentSize = 1 << 20
+	// spoolSegmentExt is the file extension of the segments of a Spool.
+	spoolSegmentExt = ".spool"
+	// DefaultSpoolRetryInterval is the interval in which a Logger retries replaying its Spool while Graylog is
+	// unreachable.
+	DefaultSpoolRetryInterval = 5 * time.Second
+)
+
+// ErrSpoolDecrypt is the reason reported to the OnDrop callback for spooled messages dropped on replay because they
+// could not be decrypted, e.g. as they were written with another key or corrupted. The reason wraps the underlying
+// error, the message reported is the encrypted line.
+var ErrSpoolDecrypt = errors.New("spooled message could not be decrypted")
+
+// Spool is a persistent write-ahead log for GELF messages which could not be sent, see WithSpool. The messages are
+// appended as JSON lines to segment files in a directory, so they survive restarts of the application, and are
+// replayed in order once the Graylog server is reachable again.
+//
+// The spool is bounded by its maximum size and retention: once the segments exceed the maximum size, the oldest
+// segment is removed, and segments whose last message is older than the retention are removed as well.
+//
+// Spools created with NewEncryptedSpool encrypt every message with AES-GCM before it is written, so no plaintext
+// log payload is buffered on disk.
+//
+// RetryInterval is the interval in which a Logger retries replaying the spool while the Graylog server is
+// unreachable, DefaultSpoolRetryInterval by default.
+type Spool struct {
+	RetryInterval time.Duration
+
+	dir         string
+	maxSize     int64
+	retention   time.Duration
+	segmentSize int64
+	aead        cipher.AEAD
+
+	lock     sync.Mutex
+	segments []*spoolSegment
+	active   *os.File
+	next     uint64
+	size     int64
+	closed   bool
+}
+
+// spoolSegment is a single segment file of a Spool.
+type spoolSegment struct {
+	path     string
+	size     int64
+	modified time.Time
+}
+
+var _ Transport = (*Spool)(nil)
+
+// NewSpool creates a Spool storing its segments in the given directory, which is created if it does not exist.
+// Segments left by a previous run are kept and replayed first. A maxSize or retention of zero or less disables the
+// respective limit.
+//
+// Example usage:
+//
+//	spool, err := gelflogger.NewSpool("/var/spool/app/gelf", 500<<20, 24*time.Hour)
+//	if err != nil {
+//	  // handle error
+//	}
+//	logger, err := gelflogger.NewLogger("graylog.example.com:12201", gelflogger.WithProcessor(zerologger.ProcessZerologFields), gelflogger.WithSpool(spool))
+func NewSpool(dir string, maxSize int64, retention time.Duration) (*Spool, error) {
+	return newSpool(dir, maxSize, retention, nil)
+}
+
+// NewEncryptedSpool creates a Spool like NewSpool, which encrypts every message with AES-GCM using the given key
+// before writing it to disk. The key must be 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256. Segments
+// left by a previous run must have been written with the same key, otherwise an error is returned, so a wrong key does
+// not discard the spooled messages. Corrupted messages which cannot be decrypted are skipped on replay, as they could
+// never be sent, see ErrSpoolDecrypt.
+//
+// Example usage:
+//
+//	key, err := hex.DecodeString(os.Getenv("GELF_SPOOL_KEY"))
+//	if err != nil {
+//	  // handle error
+//	}
+//	spool, err := gelflogger.NewEncryptedSpool("/var/spool/app/gelf", 500<<20, 24*time.Hour, key)
+func NewEncryptedSpool(dir string, maxSize int64, retention time.Duration, key []byte) (*Spool, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSpool(dir, maxSize, retention, aead)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkKey(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkKey verifies that the first message of the oldest segment can be decrypted with the key of the spool.
+func (s *Spool) checkKey() error {
+	if len(s.segments) == 0 {
+		return nil
+	}
+	file, err := os.Open(s.segments[0].path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if len(line) <= 1 {
+		// An empty segment holds nothing to decrypt
+		return nil
+	}
+	if _, err = s.decrypt(bytes.TrimSuffix(line, []byte{'\n'})); err != nil {
+		return fmt.Errorf("spool %s was written with another key or unencrypted: %w", s.dir, err)
+	}
+	return nil
+}
+
+// newSpool creates a Spool storing its segments in the given directory, encrypting the messages with aead unless it
+// is nil.
+func newSpool(dir string, maxSize int64, retention time.Duration, aead cipher.AEAD) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	s := &Spool{RetryInterval: DefaultSpoolRetryInterval, dir: dir, maxSize: maxSize, retention: retention, segmentSize: defaultSpoolSegmentSize, aead: aead}
+	if maxSize > 0 {
+		s.segmentSize = max(maxSize/spoolSegments, 1)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		number, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), spoolSegmentExt), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), spoolSegmentExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, &spoolSegment{path: filepath.Join(dir, entry.Name()), size: info.Size(), modified: info.ModTime()})
+		s.size += info.Size()
+		s.next = max(s.next, number+1)
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].path < s.segments[j].path })
+	return s, nil
+}
+
+// Send appends the message, encrypted if the spool was created by NewEncryptedSpool, to the active segment, starting
+// a new segment if the active one is full. Segments beyond the maximum size or retention are removed, oldest first.
+func (s *Spool) Send(message []byte) error {
+	line, err := s.encrypt(message)
+	if err != nil {
+		return err
+	}
+	line = append(line[:len(line):len(line)], '\n')
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return fs.ErrClosed
+	}
+	s.purge(int64(len(line)))
+	if s.active != nil && s.segments[len(s.segments)-1].size+int64(len(line)) > s.segmentSize {
+		if err := s.seal(); err != nil {
+			return err
+		}
+	}
+	if s.active == nil {
+		if err := s.startSegment(); err != nil {
+			return err
+		}
+	}
+	segment := s.segments[len(s.segments)-1]
+	n, err := s.active.Write(line)
+	segment.size += int64(n)
+	segment.modified = time.Now()
+	s.size += int64(n)
+	return err
+}
+
+// startSegment creates a new segment and makes it the active one. The caller must hold the lock.
+func (s *Spool) startSegment() error {
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", s.next, spoolSegmentExt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	s.next++
+	s.active = file
+	s.segments = append(s.segments, &spoolSegment{path: path, modified: time.Now()})
+	return nil
+}
+
+// seal closes the active segment, the next message starts a new one. The caller must hold the lock.
+func (s *Spool) seal() error {
+	if s.active == nil {
+		return nil
+	}
+	err := s.active.Close()
+	s.active = nil
+	return err
+}
+
+// purge removes the segments older than the retention, and the oldest segments while the spool would exceed its
+// maximum size with the given number of additional bytes. The caller must hold the lock.
+func (s *Spool) purge(additional int64) {
+	for len(s.segments) > 0 {
+		oldest := s.segments[0]
+		expired := s.retention > 0 && time.Since(oldest.modified) > s.retention
+		full := s.maxSize > 0 && s.size+additional > s.maxSize
+		if !expired && !full {
+			return
+		}
+		if len(s.segments) == 1 && s.active != nil {
+			_ = s.seal()
+		}
+		_ = os.Remove(oldest.path)
+		s.size -= oldest.size
+		s.segments = s.segments[1:]
+	}
+}
+
+// Pending reports whether the spool holds messages which were not replayed yet.
+func (s *Spool) Pending() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.size > 0
+}
+
+// Replay sends the spooled messages, oldest first, with the given function and removes them once sent. If sending a
+// message fails, replaying stops and the message, together with all messages after it, is kept for the next replay.
+// Messages appended while replaying are replayed as well. Messages which cannot be decrypted are skipped, as they
+// could never be sent; the Logger replaying its spool counts them as dropped, see ErrSpoolDecrypt.
+func (s *Spool) Replay(send func(message []byte) error) error {
+	return s.replay(send, nil)
+}
+
+// replay replays the spooled messages like Replay, and reports the messages which cannot be decrypted to drop, unless
+// it is nil.
+func (s *Spool) replay(send func(message []byte) error, drop func(message []byte, reason error)) error {
+	for {
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			return fs.ErrClosed
+		}
+		s.purge(0)
+		if len(s.segments) == 0 {
+			s.lock.Unlock()
+			return nil
+		}
+		// Seal the segment being replayed, so new messages are appended to the next one
+		if len(s.segments) == 1 {
+			if err := s.seal(); err != nil {
+				s.lock.Unlock()
+				return err
+			}
+		}
+		segment := s.segments[0]
+		s.lock.Unlock()
+
+		err := replayFile(segment.path, func(line []byte) error {
+			message, err := s.decrypt(line)
+			if err != nil {
+				// The message can never be sent, keeping it would block the messages after it
+				if drop != nil {
+					drop(line, fmt.Errorf("%w: %w", ErrSpoolDecrypt, err))
+				}
+				return nil
+			}
+			return send(message)
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			s.lock.Lock()
+			if len(s.segments) == 0 || s.segments[0] != segment {
+				// The segment was purged while being replayed, drop the remaining messages written back by replayFile
+				_ = os.Remove(segment.path)
+			} else if info, statErr := os.Stat(segment.path); statErr == nil {
+				s.size += info.Size() - segment.size
+				segment.size = info.Size()
+			}
+			s.lock.Unlock()
+			return err
+		}
+
+		s.lock.Lock()
+		if len(s.segments) > 0 && s.segments[0] == segment {
+			s.size -= segment.size
+			s.segments = s.segments[1:]
+		}
+		s.lock.Unlock()
+	}
+}
+
+// encrypt encrypts the message with a random nonce, which is prepended to the sealed message, and encodes the result
+// with base64, so it fits on a single line. Messages of unencrypted spools are returned as they are.
+func (s *Spool) encrypt(message []byte) ([]byte, error) {
+	if s.aead == nil {
+		return message, nil
+	}
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(message)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := s.aead.Seal(nonce, nonce, message, nil)
+	return base64.StdEncoding.AppendEncode(nil, sealed), nil
+}
+
+// decrypt reverses encrypt. Lines of unencrypted spools are returned as they are.
+func (s *Spool) decrypt(line []byte) ([]byte, error) {
+	if s.aead == nil {
+		return line, nil
+	}
+	sealed, err := base64.StdEncoding.AppendDecode(nil, line)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, errors.New("spooled message is too short")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	return s.aead.Open(ciphertext[:0], nonce, ciphertext, nil)
+}
+
+// Close closes the active segment. The spooled messages are kept for the next run.
+func (s *Spool) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	return s.seal()
+}
+
+// sendSpooled sends the message through the transport of the Logger, or appends it to the spool if that fails. While
+// the spool holds messages, new messages are appended to it as well, so the messages reach Graylog in order.
+func (l *Logger) sendSpooled(message []byte) error {
+	if !l.spool.Pending() {
+		if err := l.sendCounted(l.transport, message); err == nil {
+			return nil
+		}
+	}
+	return l.spoolMessage(message)
+}
+
+// spoolMessage appends the message to the spool of the Logger and makes sure the spool is replayed once the Graylog
+// server is reachable again. If the spool fails, the message is handed to the fallback, if configured.
+func (l *Logger) spoolMessage(message []byte) error {
+	err := l.spool.Send(message)
+	l.replaySpool()
+	if err == nil || l.fallback == nil {
+		return err
+	}
+	if fallbackErr := l.fallback.Send(message); fallbackErr != nil {
+		return errors.Join(err, fallbackErr)
+	}
+	return nil
+}
+
+// replaySpool starts replaying the spool in the background, unless it is already being replayed. The replay is
+// retried every RetryInterval of the spool until all messages were sent or the Logger is closed.
+func (l *Logger) replaySpool() {
+	if !l.replaying.CompareAndSwap(false, true) {
+		return
+	}
+	started := l.goBackground(func() {
+		for {
+			err := l.spool.replay(l.sendToTransport, l.drop)
+			if errors.Is(err, fs.ErrClosed) {
+				l.replaying.Store(false)
+				return
+			}
+			if err != nil {
+				select {
+				case <-l.closing:
+					l.replaying.Store(false)
+					return
+				case <-time.After(l.spool.RetryInterval):
+				}
+				continue
+			}
+			l.replaying.Store(false)
+			// A message spooled after the replay finished would otherwise wait for the next failure
+			if !l.spool.Pending() || !l.replaying.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	})
+	if !started {
+		l.replaying.Store(false)
+	}
+}
@@ -0,0 +1,256 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestSpoolReplayInOrder(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := gelflogger.NewSpool(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSpool() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := spool.Send([]byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The spooled messages survive a restart
+	spool, err = gelflogger.NewSpool(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSpool() error = %v", err)
+	}
+	defer func() { _ = spool.Close() }()
+	if !spool.Pending() {
+		t.Fatal("Pending() = false after restart, want true")
+	}
+
+	failing := &recordingTransport{failAfter: 2}
+	if err := spool.Replay(failing.Send); err == nil {
+		t.Fatal("Replay() error = nil, want the send error")
+	}
+	working := &recordingTransport{}
+	if err := spool.Replay(working.Send); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	got := append(failing.messages, working.messages...)
+	want := []string{`{"n":0}`, `{"n":1}`, `{"n":2}`, `{"n":3}`, `{"n":4}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+	if spool.Pending() {
+		t.Error("Pending() = true after a complete replay, want false")
+	}
+}
+
+func TestSpoolLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxSize   int64
+		retention time.Duration
+		wait      time.Duration
+		wantFirst string
+		wantCount int
+	}{
+		{name: "Unlimited", wantFirst: `{"n":00}`, wantCount: 20},
+		{name: "Oldest segments dropped beyond the maximum size", maxSize: 100, wantFirst: `{"n":09}`, wantCount: 11},
+		{name: "Segments dropped after the retention", retention: 50 * time.Millisecond, wait: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spool, err := gelflogger.NewSpool(t.TempDir(), tt.maxSize, tt.retention)
+			if err != nil {
+				t.Fatalf("NewSpool() error = %v", err)
+			}
+			defer func() { _ = spool.Close() }()
+			for i := 0; i < 20; i++ {
+				if err := spool.Send([]byte(fmt.Sprintf(`{"n":%02d}`, i))); err != nil {
+					t.Fatalf("Send() error = %v", err)
+				}
+			}
+			time.Sleep(tt.wait)
+
+			replayed := &recordingTransport{}
+			if err := spool.Replay(replayed.Send); err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if len(replayed.messages) != tt.wantCount {
+				t.Fatalf("replayed %d messages, want %d", len(replayed.messages), tt.wantCount)
+			}
+			if tt.wantCount > 0 && replayed.messages[0] != tt.wantFirst {
+				t.Errorf("first replayed message = %s, want %s", replayed.messages[0], tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestLoggerSpool(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := gelflogger.NewSpool(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSpool() error = %v", err)
+	}
+	defer func() { _ = spool.Close() }()
+	spool.RetryInterval = 20 * time.Millisecond
+
+	transport := &recordingTransport{down: true}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithSpool(spool))
+	for i := 0; i < 3; i++ {
+		if err := logger.Log(fmt.Sprintf("spooled %d", i), map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) == 0 || !spool.Pending() {
+		t.Fatal("messages were not spooled while the transport is down")
+	}
+
+	transport.lock.Lock()
+	transport.down = false
+	transport.lock.Unlock()
+	if err := logger.Log("spooled 3", map[string]interface{}{}); err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for spool.Pending() {
+		if time.Now().After(deadline) {
+			t.Fatal("spool was not replayed after the transport recovered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	if len(transport.messages) != 4 {
+		t.Fatalf("transport received %d messages, want 4", len(transport.messages))
+	}
+	for i, message := range transport.messages {
+		if want := fmt.Sprintf(`"short_message":"spooled %d"`, i); !strings.Contains(message, want) {
+			t.Errorf("message %d = %s, want it to contain %s", i, message, want)
+		}
+	}
+}
+
+func TestEncryptedSpool(t *testing.T) {
+	dir := t.TempDir()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	spool, err := gelflogger.NewEncryptedSpool(dir, 0, 0, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedSpool() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := spool.Send([]byte(fmt.Sprintf(`{"secret":%d}`, i))); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if strings.Contains(string(content), "secret") {
+			t.Errorf("segment %s contains the plaintext messages: %s", entry.Name(), content)
+		}
+	}
+
+	if _, err := gelflogger.NewEncryptedSpool(dir, 0, 0, []byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Error("NewEncryptedSpool() with another key error = nil, want an error")
+	}
+	if _, err := gelflogger.NewEncryptedSpool(t.TempDir(), 0, 0, []byte("short")); err == nil {
+		t.Error("NewEncryptedSpool() with an invalid key length error = nil, want an error")
+	}
+
+	spool, err = gelflogger.NewEncryptedSpool(dir, 0, 0, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedSpool() error = %v", err)
+	}
+	defer func() { _ = spool.Close() }()
+	transport := &recordingTransport{}
+	if err := spool.Replay(transport.Send); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	want := []string{`{"secret":0}`, `{"secret":1}`, `{"secret":2}`}
+	if !reflect.DeepEqual(transport.messages, want) {
+		t.Errorf("replayed %v, want %v", transport.messages, want)
+	}
+}
+
+func TestEncryptedSpoolWrongKey(t *testing.T) {
+	dir, otherDir := t.TempDir(), t.TempDir()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, spooled := range []struct {
+		dir     string
+		key     []byte
+		message string
+	}{
+		{dir: dir, key: key, message: `{"secret":0}`},
+		{dir: otherDir, key: []byte("fedcba9876543210fedcba9876543210"), message: `{"secret":1}`},
+	} {
+		spool, err := gelflogger.NewEncryptedSpool(spooled.dir, 0, 0, spooled.key)
+		if err != nil {
+			t.Fatalf("NewEncryptedSpool() error = %v", err)
+		}
+		if err := spool.Send([]byte(spooled.message)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if err := spool.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+	// The segment written with the other key follows the one written with the key of the spool
+	content, err := os.ReadFile(filepath.Join(otherDir, "00000000000000000000.spool"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000001.spool"), content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	spool, err := gelflogger.NewEncryptedSpool(dir, 0, 0, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedSpool() error = %v", err)
+	}
+	defer func() { _ = spool.Close() }()
+	var reasons []error
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithSpool(spool),
+		gelflogger.WithOnDrop(func(message []byte, reason error) {
+			reasons = append(reasons, reason)
+		}))
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if want := []string{`{"secret":0}`}; !reflect.DeepEqual(transport.messages, want) {
+		t.Errorf("replayed %v, want %v", transport.messages, want)
+	}
+	if len(reasons) != 1 || !errors.Is(reasons[0], gelflogger.ErrSpoolDecrypt) {
+		t.Errorf("OnDrop() reasons = %v, want one wrapping ErrSpoolDecrypt", reasons)
+	}
+	if logger.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", logger.Dropped())
+	}
+}
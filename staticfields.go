@@ -0,0 +1,55 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// staticField is an additional field attached to every message of a Logger, see WithStaticFields. It is encoded once,
+// when the Logger is configured, and appended to the encoded messages, see Message.appendGELF.
+//
+// - key: The name of the field in the GELF message, prefixed with an underscore.
+// - encoded: The JSON encoded member, e.g. "_service":"billing".
+type staticField struct {
+	key     string
+	encoded []byte
+}
+
+// newStaticFields encodes the given fields sorted by name. Invalid names are sanitized, see gelfFieldName, and the
+// values are coerced like the fields of the log calls, see coerceFieldValue.
+func newStaticFields(fields map[string]interface{}) []staticField {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	static := make([]staticField, 0, len(names))
+	for _, name := range names {
+		value, ok, _ := coerceFieldValue(fields[name], false)
+		if !ok {
+			continue
+		}
+		encodedValue, err := json.Marshal(value)
+		if err != nil {
+			encodedValue, _ = json.Marshal(fmt.Sprint(value))
+		}
+		key, _ := gelfFieldName(name, false)
+		encodedKey, _ := json.Marshal(key)
+		encoded := append(append(encodedKey, ':'), encodedValue...)
+		static = append(static, staticField{key: key, encoded: encoded})
+	}
+	return static
+}
+
+// mergeStaticFields returns the fields of base and added, the fields of added replacing the ones of the same name in
+// base. base is not modified, as it may be shared with other Loggers.
+func mergeStaticFields(base, added []staticField) []staticField {
+	merged := make([]staticField, 0, len(base)+len(added))
+	for _, field := range base {
+		if !slices.ContainsFunc(added, func(other staticField) bool { return other.key == field.key }) {
+			merged = append(merged, field)
+		}
+	}
+	return append(merged, added...)
+}
@@ -0,0 +1,120 @@
+package gelflogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestWithStaticFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []gelflogger.Option
+		fields map[string]interface{}
+		want   map[string]interface{}
+		// formatted is a field which must be encoded as a string
+		formatted string
+	}{
+		{
+			name:   "Static fields are attached",
+			opts:   []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"service": "billing", "replicas": 3, "canary": true})},
+			fields: map[string]interface{}{"user": "42"},
+			want:   map[string]interface{}{"_service": "billing", "_replicas": float64(3), "_canary": "true", "_user": "42"},
+		},
+		{
+			name:   "Fields of the log call take precedence",
+			opts:   []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"service": "billing", "region": "eu"})},
+			fields: map[string]interface{}{"region": "us"},
+			want:   map[string]interface{}{"_service": "billing", "_region": "us"},
+		},
+		{
+			name: "Later options replace fields of the same name",
+			opts: []gelflogger.Option{
+				gelflogger.WithStaticFields(map[string]interface{}{"service": "billing", "environment": "staging"}),
+				gelflogger.WithStaticFields(map[string]interface{}{"environment": "production"}),
+			},
+			want: map[string]interface{}{"_service": "billing", "_environment": "production"},
+		},
+		{
+			name:      "Values not encodable as JSON are formatted",
+			opts:      []gelflogger.Option{gelflogger.WithStaticFields(map[string]interface{}{"channel": make(chan int)})},
+			want:      map[string]interface{}{},
+			formatted: "_channel",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)
+			if err := logger.Log("static", tt.fields); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(transport.messages))
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			for name, want := range tt.want {
+				if gelfMsg[name] != want {
+					t.Errorf("field %s = %v, want %v", name, gelfMsg[name], want)
+				}
+			}
+			if value, ok := gelfMsg[tt.formatted].(string); tt.formatted != "" && (!ok || value == "") {
+				t.Errorf("field %s = %v, want it formatted as string", tt.formatted, gelfMsg[tt.formatted])
+			}
+			if gelfMsg["short_message"] != "static" {
+				t.Errorf("short_message = %v, want static", gelfMsg["short_message"])
+			}
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	transport := &recordingTransport{}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing,
+		gelflogger.WithStaticFields(map[string]interface{}{"service": "billing"}),
+		gelflogger.WithDeduplication(time.Minute))
+	component := logger.With(map[string]interface{}{"component": "invoices"})
+	request := component.With(map[string]interface{}{"request_id": "r-1", "component": "payments"})
+
+	for _, l := range []*gelflogger.Logger{logger, component, request, request, logger} {
+		if err := l.Log("handled", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	if err := request.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := logger.Log("after close", map[string]interface{}{}); !errors.Is(err, gelflogger.ErrLoggerClosed) {
+		t.Errorf("Log() of the root Logger after closing a derived one error = %v, want ErrLoggerClosed", err)
+	}
+
+	want := []map[string]interface{}{
+		{"_service": "billing"},
+		{"_service": "billing", "_component": "invoices"},
+		{"_service": "billing", "_component": "payments", "_request_id": "r-1"},
+		{"_service": "billing", "_component": "payments", "_request_id": "r-1", "_repeat_count": float64(1)},
+		{"_service": "billing"},
+	}
+	if len(transport.messages) != len(want) {
+		t.Fatalf("sent %d messages %v, want %d", len(transport.messages), transport.messages, len(want))
+	}
+	for i, message := range transport.messages {
+		var gelfMsg map[string]interface{}
+		if err := json.Unmarshal([]byte(message), &gelfMsg); err != nil {
+			t.Fatalf("invalid GELF message %s: %v", message, err)
+		}
+		for _, name := range []string{"_service", "_component", "_request_id", "_repeat_count"} {
+			if gelfMsg[name] != want[i][name] {
+				t.Errorf("message %d field %s = %v, want %v", i, name, gelfMsg[name], want[i][name])
+			}
+		}
+	}
+}
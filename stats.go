@@ -0,0 +1,100 @@
+package gelflogger
+
+import "time"
+
+// Stats is a snapshot of the counters of a Logger, see Logger.Stats.
+type Stats struct {
+	// MessagesSent is the number of messages sent through the transports of the Logger, not counting the messages
+	// taken by the fallback or the spool.
+	MessagesSent uint64
+	// BytesSent is the number of bytes of the sent messages, without framing.
+	BytesSent uint64
+	// SendErrors is the number of failed sends through the transports, every retry counts as separate send.
+	SendErrors uint64
+	// Reconnects is the number of connections re-established after the connection to Graylog was lost.
+	Reconnects uint64
+	// QueueDepth is the number of messages waiting in the queue of an asynchronous Logger.
+	QueueDepth int
+	// QueueBytes is the number of bytes of the messages waiting in the queue, see WithQueueMemoryLimit.
+	QueueBytes int64
+	// Dropped is the number of dropped messages, see Logger.Dropped.
+	Dropped uint64
+}
+
+// Stats returns a snapshot of the counters of the Logger since it was created, e.g. to expose the health of the log
+// pipeline in a status endpoint of the application. The counters are read one after another, so a snapshot taken
+// while messages are logged is not necessarily consistent across the counters.
+func (l *Logger) Stats() Stats {
+	return Stats{
+		MessagesSent: l.sent.Load(),
+		BytesSent:    l.bytesSent.Load(),
+		SendErrors:   l.sendErrors.Load(),
+		Reconnects:   l.events.reconnects.Load(),
+		QueueDepth:   len(l.queue),
+		QueueBytes:   l.queuedBytes.Load(),
+		Dropped:      l.dropped.Load(),
+	}
+}
+
+// SendResult describes a single send through a transport of a Logger, see Logger.ObserveSends.
+type SendResult struct {
+	// Start is the time the send started.
+	Start time.Time
+	// Latency is the time the send took.
+	Latency time.Duration
+	// Messages is the number of messages sent, more than one for batches.
+	Messages int
+	// Bytes is the number of bytes of the messages, without framing.
+	Bytes int
+	// Err is the error of the send, nil if it succeeded.
+	Err error
+}
+
+// SendObserver is called after every send through a transport of the Logger, see Logger.ObserveSends.
+type SendObserver func(result SendResult)
+
+// ObserveSends sets the observer called after every send through the transports of the Logger, e.g. to record the
+// latency of the sends in a histogram. It replaces the previous observer, nil removes it. The observer is called
+// synchronously by the sending goroutine, it must not block or log through the same Logger.
+func (l *Logger) ObserveSends(observe SendObserver) {
+	if observe == nil {
+		l.sendObserver.Store(nil)
+		return
+	}
+	l.sendObserver.Store(&observe)
+}
+
+// count records the outcome of sending the messages through a transport, which took the time since start.
+func (l *Logger) count(start time.Time, err error, messages ...[]byte) error {
+	bytes := 0
+	for _, message := range messages {
+		bytes += len(message)
+	}
+	if observe := l.sendObserver.Load(); observe != nil {
+		(*observe)(SendResult{Start: start, Latency: time.Since(start), Messages: len(messages), Bytes: bytes, Err: err})
+	}
+	if err != nil {
+		l.sendErrors.Add(1)
+		return err
+	}
+	l.sent.Add(uint64(len(messages)))
+	l.bytesSent.Add(uint64(bytes))
+	return nil
+}
+
+// sendCounted sends the message through the transport and counts the outcome.
+func (l *Logger) sendCounted(transport Transport, message []byte) error {
+	start := time.Now()
+	return l.count(start, transport.Send(message), message)
+}
+
+// sendToTransport sends the message through the transport of the Logger and counts the outcome.
+func (l *Logger) sendToTransport(message []byte) error {
+	return l.sendCounted(l.transport, message)
+}
+
+// sendBatchCounted sends the messages through the transport and counts the outcome.
+func (l *Logger) sendBatchCounted(transport Transport, messages [][]byte) error {
+	start := time.Now()
+	return l.count(start, sendBatch(transport, messages), messages...)
+}
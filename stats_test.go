@@ -0,0 +1,92 @@
+package gelflogger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestStats(t *testing.T) {
+	transport := &recordingTransport{failAfter: 2}
+	logger := gelflogger.NewLoggerWithTransport(transport, processNothing)
+	for i := 0; i < 3; i++ {
+		_ = logger.Log("counted", map[string]interface{}{})
+	}
+
+	stats := logger.Stats()
+	bytesSent := uint64(len(transport.messages[0]) + len(transport.messages[1]))
+	want := gelflogger.Stats{MessagesSent: 2, BytesSent: bytesSent, SendErrors: 1, Dropped: 1}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestStatsQueueDepth(t *testing.T) {
+	blocking := &blockingTransport{started: make(chan struct{}, 10), release: make(chan struct{})}
+	logger := gelflogger.NewLoggerWithTransport(blocking, processNothing, gelflogger.WithAsync(10, 1))
+	defer func() { _ = logger.Close(context.Background()) }()
+	defer close(blocking.release)
+
+	// The worker blocks on the first message, the other two wait in the queue
+	for i := 0; i < 3; i++ {
+		if err := logger.Log("queued", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+		if i == 0 {
+			<-blocking.started
+		}
+	}
+	if depth := logger.Stats().QueueDepth; depth != 2 {
+		t.Errorf("Stats().QueueDepth = %d, want 2", depth)
+	}
+}
+
+func TestStatsReconnects(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	accepted := acceptConnections(server)
+
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithHealthCheckInterval(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	_ = (<-accepted).Close()
+	select {
+	case <-accepted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the connection closed by the server was not re-established")
+	}
+	for deadline := time.Now().Add(time.Second); logger.Stats().Reconnects != 1; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats().Reconnects = %d, want 1", logger.Stats().Reconnects)
+		}
+	}
+}
+
+func TestObserveSends(t *testing.T) {
+	logger := gelflogger.NewLoggerWithTransport(&recordingTransport{failAfter: 1}, processNothing)
+	var errs []error
+	logger.ObserveSends(func(result gelflogger.SendResult) {
+		if result.Start.IsZero() || result.Latency < 0 || result.Messages != 1 || result.Bytes == 0 {
+			t.Errorf("SendResult = %+v, want the start, latency and size of a single message", result)
+		}
+		errs = append(errs, result.Err)
+	})
+	for i := 0; i < 2; i++ {
+		_ = logger.Log("observed", map[string]interface{}{})
+	}
+	if len(errs) != 2 || errs[0] != nil || errs[1] == nil {
+		t.Errorf("observed errors %v, want a successful and a failed send", errs)
+	}
+
+	logger.ObserveSends(nil)
+	_ = logger.Log("unobserved", map[string]interface{}{})
+	if len(errs) != 2 {
+		t.Errorf("observed %d sends after removing the observer, want 2", len(errs))
+	}
+}
@@ -0,0 +1,161 @@
+package gelflogger
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultHealthCheckInterval is the interval in which the supervisor of a TCP transport probes its connection.
+	DefaultHealthCheckInterval = 10 * time.Second
+	// healthProbeTimeout is the time a health probe waits for the server to close the connection.
+	healthProbeTimeout = 10 * time.Millisecond
+)
+
+// supervise maintains the connection of the transport in the background until the transport is closed, so the
+// write path never has to check the connection or dial: it probes the connection every health check interval and
+// re-establishes it once the probe fails, switches back to the primary address once the failback interval elapsed
+// while being connected to a failover address, and keeps idle connections from being dropped by firewalls, see
+// keepIdleConnection.
+func (t *tcpTransport) supervise() {
+	interval := time.Duration(0)
+	// Idle connections are checked twice per period, so they are handled at most half a period late
+	for _, candidate := range []time.Duration{t.healthCheckInterval, t.failbackInterval, t.idleTimeout / 2, t.keepaliveInterval / 2} {
+		if candidate > 0 && (interval <= 0 || candidate < interval) {
+			interval = candidate
+		}
+	}
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastProbe := time.Now()
+	for {
+		select {
+		case <-t.closed:
+			return
+		case <-ticker.C:
+		}
+		if t.healthCheckInterval > 0 && time.Since(lastProbe) >= t.healthCheckInterval {
+			lastProbe = time.Now()
+			_ = t.checkHealth()
+		}
+		t.failback()
+		t.keepIdleConnection()
+	}
+}
+
+// checkHealth probes the connection and re-establishes it in the background if the probe fails. It returns the error
+// of the failed probe.
+func (t *tcpTransport) checkHealth() error {
+	t.connLock.Lock()
+	conn := t.conn
+	t.connLock.Unlock()
+	if conn == nil {
+		return nil
+	}
+	err := probe(conn)
+	if err == nil {
+		return nil
+	}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	if t.conn == conn {
+		t.disconnected(err)
+	}
+	return err
+}
+
+// probe checks whether the connection is still alive. Graylog never writes to a GELF connection, so the probe reads
+// with a short deadline: running into the deadline means the connection is alive, while the end of the stream or any
+// other error means the server closed or reset the connection. Unlike a zero-byte write, this detects connections
+// closed by the server before the next message is lost. Reads and writes may run concurrently on a connection, so the
+// probe does not hold the connection lock.
+func probe(conn net.Conn) error {
+	if err := conn.SetReadDeadline(time.Now().Add(healthProbeTimeout)); err != nil {
+		return err
+	}
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+	var buf [1]byte
+	_, err := conn.Read(buf[:])
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil
+	}
+	if err == nil {
+		return errors.New("unexpected data received from the Graylog server")
+	}
+	return err
+}
+
+// failback re-checks the primary address once the failback interval elapsed while being connected to a failover
+// endpoint. If the primary is reachable again, the connection is switched back to it. The primary is dialed without
+// holding the connection lock, so sends are not blocked by the dial.
+func (t *tcpTransport) failback() {
+	t.connLock.Lock()
+	due := t.conn != nil && t.current != 0 && t.failbackInterval > 0 && time.Since(t.lastFailbackCheck) >= t.failbackInterval
+	if due {
+		t.lastFailbackCheck = time.Now()
+	}
+	t.connLock.Unlock()
+	if !due {
+		return
+	}
+
+	conn, err := t.dial(t.ctx, t.addresses[0])
+	if err != nil {
+		return
+	}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	select {
+	case <-t.closed:
+		_ = conn.Close()
+	default:
+		t.connected(conn, 0)
+	}
+}
+
+// keepIdleConnection keeps an idle connection from being dropped by firewalls: once the keepalive interval elapsed
+// without a write, an empty frame is written, and once the idle timeout elapsed, the connection is replaced by a new
+// one, see WithKeepalive and WithIdleTimeout.
+func (t *tcpTransport) keepIdleConnection() {
+	t.connLock.Lock()
+	idle := time.Duration(0)
+	if t.conn != nil {
+		idle = time.Since(t.lastActivity)
+	}
+	if t.keepaliveInterval > 0 && idle >= t.keepaliveInterval && (t.idleTimeout <= 0 || idle < t.idleTimeout) {
+		t.connLock.Unlock()
+		// An empty frame, the delimiter alone
+		_ = t.write([]byte{})
+		return
+	}
+	recycle := t.idleTimeout > 0 && idle >= t.idleTimeout
+	start := t.current
+	t.connLock.Unlock()
+	if !recycle {
+		return
+	}
+
+	conn, index, err := t.dialFrom(t.ctx, start)
+	if err != nil {
+		// The idle connection is kept, the next check tries again
+		return
+	}
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	select {
+	case <-t.closed:
+		_ = conn.Close()
+	default:
+		if t.conn != nil && time.Since(t.lastActivity) < t.idleTimeout {
+			// The connection was used or replaced while dialing
+			_ = conn.Close()
+			return
+		}
+		t.connected(conn, index)
+	}
+}
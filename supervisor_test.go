@@ -0,0 +1,73 @@
+package gelflogger_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestSupervisorReconnectsProactively(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithHealthCheckInterval(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	// The server closes the connection, the supervisor notices it and reconnects without a message being logged
+	first := <-accepted
+	_ = first.Close()
+	select {
+	case second := <-accepted:
+		defer func() { _ = second.Close() }()
+	case <-time.After(time.Second):
+		t.Fatal("the supervisor did not re-establish the connection closed by the server")
+	}
+
+	if err := logger.Log("after reconnect", map[string]interface{}{}); err != nil {
+		t.Errorf("Log() error = %v, want the message to be sent through the new connection", err)
+	}
+}
+
+func TestSupervisorKeepsHealthyConnection(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	messages := helper.ReceiveMessages(t, server, 0)
+
+	logger, err := gelflogger.NewLogger(server.Addr().String(), gelflogger.WithProcessor(processNothing), gelflogger.WithHealthCheckInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+
+	// Probes running between the messages must not interfere with them
+	for i := 0; i < 10; i++ {
+		if err := logger.Log("healthy", map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case <-messages:
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of 10 messages", i)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package gelflogger_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+// shortWriteConn accepts at most limit bytes per write, without error.
+type shortWriteConn struct {
+	net.Conn
+	limit   int
+	written bytes.Buffer
+}
+
+func (c *shortWriteConn) Write(p []byte) (int, error) {
+	if len(p) > c.limit {
+		p = p[:c.limit]
+	}
+	return c.written.Write(p)
+}
+
+func TestWriteFull(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr error
+	}{
+		{name: "Short writes retried", limit: 3},
+		{name: "No progress", limit: 0, wantErr: io.ErrShortWrite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &shortWriteConn{limit: tt.limit}
+			payload := []byte(`{"short_message":"hello"}` + "\x00")
+			n, err := gelflogger.WriteFull(conn, payload)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteFull() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (n != len(payload) || conn.written.String() != string(payload)) {
+				t.Errorf("WriteFull() wrote %d bytes %q, want %q", n, conn.written.String(), payload)
+			}
+		})
+	}
+}
+
+func TestTCPTransportFrames(t *testing.T) {
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	received := helper.ReceiveMessages(t, server, 0)
+
+	transport, err := gelflogger.NewTransport(server.Addr().String(), false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = transport.Close() }()
+
+	// Messages smaller and larger than the write buffer, sent one by one and in a batch
+	want := []string{"first", strings.Repeat("a", 100<<10), "third", strings.Repeat("b", 40<<10), "fifth"}
+	if err := transport.Send([]byte(want[0])); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	batcher, ok := transport.(interface{ SendBatch(messages [][]byte) error })
+	if !ok {
+		t.Fatal("TCP transport does not send batches")
+	}
+	batch := make([][]byte, 0, len(want)-1)
+	for _, message := range want[1:] {
+		batch = append(batch, []byte(message))
+	}
+	if err := batcher.SendBatch(batch); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	for i, message := range want {
+		select {
+		case got := <-received:
+			if got != message {
+				t.Errorf("frame %d has %d bytes, want %d", i, len(got), len(message))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d frames, want %d", i, len(want))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Magnitudes above which numeric timestamps are taken as milliseconds, microseconds and nanoseconds. In seconds, they
+// would lie after the year 5000.
+const (
+	minUnixMillis = 1e11
+	minUnixMicros = 1e14
+	minUnixNanos  = 1e17
+)
+
+// iso8601 is the ISO 8601 layout with the offset written without colon, as used by zap's ISO8601TimeEncoder.
+const iso8601 = "2006-01-02T15:04:05.999999999Z0700"
+
+// ParseTimestamp normalizes the timestamp of a log record to the UNIX timestamp in seconds GELF expects. It accepts:
+//
+//   - time.Time.
+//   - Numbers, including json.Number and numeric strings, as UNIX timestamp in seconds, milliseconds, microseconds or
+//     nanoseconds, told apart by their magnitude, e.g. 1700000000.5 and 1700000000500 are the same time. So zerolog's
+//     TimeFormatUnix, TimeFormatUnixMs, TimeFormatUnixMicro and TimeFormatUnixNano are all understood.
+//   - Strings in RFC 3339 format, with or without fractional seconds, e.g. zerolog's default time.RFC3339, or in
+//     ISO 8601 format with the offset written without colon, e.g. by zap's ISO8601TimeEncoder.
+//
+// Other values are rejected with an error.
+func ParseTimestamp(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return unixTimestamp(v), nil
+	case float64:
+		return unixSeconds(v)
+	case float32:
+		return unixSeconds(float64(v))
+	case int:
+		return unixSeconds(float64(v))
+	case int64:
+		return unixSeconds(float64(v))
+	case uint64:
+		return unixSeconds(float64(v))
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q: %w", v, err)
+		}
+		return unixSeconds(f)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return unixSeconds(f)
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			var iso8601Err error
+			if t, iso8601Err = time.Parse(iso8601, v); iso8601Err != nil {
+				return 0, fmt.Errorf("invalid timestamp %q, neither a UNIX timestamp nor RFC 3339: %w", v, err)
+			}
+		}
+		return float64(t.UnixNano()) / 1e9, nil
+	default:
+		return 0, fmt.Errorf("invalid timestamp of type %T", value)
+	}
+}
+
+// unixSeconds converts the numeric UNIX timestamp in seconds, milliseconds, microseconds or nanoseconds to seconds.
+func unixSeconds(f float64) (float64, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid timestamp %v", f)
+	}
+	switch abs := math.Abs(f); {
+	case abs >= minUnixNanos:
+		return f / 1e9, nil
+	case abs >= minUnixMicros:
+		return f / 1e6, nil
+	case abs >= minUnixMillis:
+		return f / 1e3, nil
+	default:
+		return f, nil
+	}
+}
+
+// unixTimestamp returns the UNIX timestamp in seconds of the time. The fraction is added to the whole seconds, rather
+// than dividing the nanoseconds, so e.g. 0.25 seconds are not turned into 0.2499998.
+func unixTimestamp(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
@@ -0,0 +1,47 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	const want = 1700000000.5
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    float64
+		wantErr bool
+	}{
+		{name: "Float seconds", value: 1700000000.5, want: want},
+		{name: "Float milliseconds", value: 1700000000500.0, want: want},
+		{name: "Integer milliseconds", value: int64(1700000000500), want: want},
+		{name: "Integer seconds", value: 1700000000, want: 1700000000},
+		{name: "Microseconds", value: int64(1700000000500000), want: want},
+		{name: "Nanoseconds", value: int64(1700000000500000000), want: want},
+		{name: "JSON number", value: json.Number("1700000000500"), want: want},
+		{name: "Numeric string", value: "1700000000.5", want: want},
+		{name: "RFC 3339", value: "2023-11-14T22:13:20Z", want: 1700000000},
+		{name: "RFC 3339 with nanoseconds and offset", value: "2023-11-15T00:13:20.5+02:00", want: want},
+		{name: "ISO 8601 of zap", value: "2023-11-15T00:13:20.500+0200", want: want},
+		{name: "time.Time", value: time.Unix(1700000000, 500000000), want: want},
+		{name: "Invalid string", value: "yesterday", wantErr: true},
+		{name: "Unsupported type", value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gelflogger.ParseTimestamp(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimestamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
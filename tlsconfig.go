@@ -0,0 +1,225 @@
+package gelflogger
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tlsMaterial holds the client certificate and CA bundle configured with the mutual TLS Options, either as file
+// paths or as PEM bytes. Certificates loaded from files are reloaded as soon as one of the files changes.
+type tlsMaterial struct {
+	certFile, keyFile, caFile string
+	certPEM, keyPEM, caPEM    []byte
+
+	lock         sync.Mutex
+	certificate  *tls.Certificate
+	rootCAs      *x509.CertPool
+	loadedAt     map[string]time.Time
+	loadedBefore bool
+}
+
+// load (re)loads the certificate and the CA bundle if they were not loaded yet or one of the files changed since.
+func (m *tlsMaterial) load() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	modTimes := make(map[string]time.Time, 3)
+	changed := !m.loadedBefore
+	for _, file := range []string{m.certFile, m.keyFile, m.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+		if !info.ModTime().Equal(m.loadedAt[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	certPEM, keyPEM, caPEM := m.certPEM, m.keyPEM, m.caPEM
+	var err error
+	if m.certFile != "" {
+		if certPEM, err = os.ReadFile(m.certFile); err != nil {
+			return err
+		}
+	}
+	if m.keyFile != "" {
+		if keyPEM, err = os.ReadFile(m.keyFile); err != nil {
+			return err
+		}
+	}
+	if m.caFile != "" {
+		if caPEM, err = os.ReadFile(m.caFile); err != nil {
+			return err
+		}
+	}
+
+	var certificate *tls.Certificate
+	if certPEM != nil || keyPEM != nil {
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("loading TLS client certificate: %w", err)
+		}
+		certificate = &pair
+	}
+	var rootCAs *x509.CertPool
+	if caPEM != nil {
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return errors.New("loading TLS CA bundle: no valid PEM certificate found")
+		}
+	}
+
+	m.certificate, m.rootCAs, m.loadedAt, m.loadedBefore = certificate, rootCAs, modTimes, true
+	return nil
+}
+
+// apply returns a copy of the given TLS configuration using the loaded client certificate and CA bundle.
+func (m *tlsMaterial) apply(config *tls.Config) (*tls.Config, error) {
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	config = config.Clone()
+	if m.certificate != nil {
+		config.Certificates = []tls.Certificate{*m.certificate}
+	}
+	if m.rootCAs != nil {
+		config.RootCAs = m.rootCAs
+	}
+	return config, nil
+}
+
+// tlsPolicy holds the TLS requirements enforced on every connection regardless of the supplied TLS configuration.
+type tlsPolicy struct {
+	minVersion   uint16
+	cipherSuites []uint16
+	pins         [][sha256.Size]byte
+}
+
+// enforce returns a copy of the given TLS configuration meeting the policy. A minimum version above the one of the
+// policy is kept.
+//
+// If SPKI pins are configured, they replace the verification of the certificate chain and host name: the
+// connection is only accepted if one of the certificates presented by the server has a pinned public key.
+func (p tlsPolicy) enforce(config *tls.Config) *tls.Config {
+	if p.minVersion == 0 && p.cipherSuites == nil && p.pins == nil {
+		return config
+	}
+	config = config.Clone()
+	if config.MinVersion < p.minVersion {
+		config.MinVersion = p.minVersion
+	}
+	if p.cipherSuites != nil {
+		config.CipherSuites = p.cipherSuites
+	}
+	if p.pins != nil {
+		config.InsecureSkipVerify = true // The chain is verified by the pins in VerifyPeerCertificate instead
+		config.VerifyPeerCertificate = p.verifyPins
+	}
+	return config
+}
+
+// verifyPins accepts the certificates presented by the server if the SPKI SHA-256 fingerprint of one of them is pinned.
+func (p tlsPolicy) verifyPins(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	for _, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return err
+		}
+		fingerprint := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range p.pins {
+			if subtle.ConstantTimeCompare(fingerprint[:], pin[:]) == 1 {
+				return nil
+			}
+		}
+	}
+	return errors.New("none of the certificates presented by the server matches a pinned SPKI fingerprint")
+}
+
+// parseSPKIPins decodes SPKI SHA-256 fingerprints given base64 encoded, as in "pin-sha256" values, or hex encoded,
+// optionally with colons separating the bytes.
+func parseSPKIPins(fingerprints []string) ([][sha256.Size]byte, error) {
+	if fingerprints == nil {
+		return nil, nil
+	}
+	pins := make([][sha256.Size]byte, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		decoded, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+		if err != nil {
+			decoded, err = base64.StdEncoding.DecodeString(fingerprint)
+		}
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid SPKI SHA-256 fingerprint %q", fingerprint)
+		}
+		pins = append(pins, [sha256.Size]byte(decoded))
+	}
+	return pins, nil
+}
+
+// clientTLSConfig returns the TLS configuration used to connect to the given address. It is based on the given
+// configuration, or a configuration requiring TLS 1.2 if nil, extended by the configured client certificate and CA
+// bundle and adjusted to the policy. If no server name is set, the host of the address is used to verify the server
+// certificate.
+func clientTLSConfig(address string, config *tls.Config, material *tlsMaterial, policy tlsPolicy) (*tls.Config, error) {
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if material != nil {
+		var err error
+		if config, err = material.apply(config); err != nil {
+			return nil, err
+		}
+	}
+	config = policy.enforce(config)
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			config = config.Clone()
+			config.ServerName = host
+		}
+	}
+	return config, nil
+}
+
+// prepareTLS checks the TLS configuration against the strict TLS mode and stores the decoded SPKI pins in the
+// tlsPolicy of the config.
+func (c *config) prepareTLS(tlsConfig *tls.Config) error {
+	if c.strictTLS {
+		if err := checkStrictTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	pins, err := parseSPKIPins(c.spkiPins)
+	if err != nil {
+		return err
+	}
+	c.tlsPolicy.pins = pins
+	return nil
+}
+
+// checkStrictTLS returns an error if the given TLS configuration disables the verification of the server certificate.
+func checkStrictTLS(config *tls.Config) error {
+	if config != nil && config.InsecureSkipVerify {
+		return errors.New("strict TLS mode: the TLS configuration must not set InsecureSkipVerify")
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package gelflogger_test
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestMutualTLS(t *testing.T) {
+	serverCertPEM, serverKeyPEM := helper.CreateTestCertificatePEM("127.0.0.1")
+	clientCertPEM, clientKeyPEM := helper.CreateTestCertificatePEM("client")
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCertPEM)
+
+	dir := t.TempDir()
+	files := map[string][]byte{"client.crt": clientCertPEM, "client.key": clientKeyPEM, "ca.crt": serverCertPEM}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opts    []gelflogger.Option
+		wantErr bool
+	}{
+		{
+			name: "Certificate and CA from files",
+			opts: []gelflogger.Option{
+				gelflogger.WithClientCertificateFiles(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")),
+				gelflogger.WithCAFile(filepath.Join(dir, "ca.crt")),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Certificate and CA from PEM",
+			opts: []gelflogger.Option{
+				gelflogger.WithClientCertificatePEM(clientCertPEM, clientKeyPEM),
+				gelflogger.WithCAPEM(serverCertPEM),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Missing certificate file",
+			opts: []gelflogger.Option{
+				gelflogger.WithClientCertificateFiles(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "client.key")),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid CA bundle",
+			opts: []gelflogger.Option{
+				gelflogger.WithCAPEM([]byte("not a certificate")),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTLSServer, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = mockTLSServer.Close() }()
+			messages := helper.ReceiveMessages(t, mockTLSServer, 0)
+
+			// TLS is enabled by the mutual TLS options, no TLS configuration is passed.
+			logger, err := gelflogger.NewLogger(mockTLSServer.Addr().String(), append(tt.opts, gelflogger.WithProcessor(processNothing))...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if err := logger.Log("mutual tls", map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			select {
+			case got := <-messages:
+				if !strings.Contains(got, `"short_message":"mutual tls"`) {
+					t.Errorf("received %q, want the mutual TLS message", got)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("message was not received")
+			}
+		})
+	}
+}
+
+func TestTLSPolicy(t *testing.T) {
+	serverCert := helper.CreateTestCertificate()
+
+	tests := []struct {
+		name         string
+		serverConfig *tls.Config
+		clientConfig *tls.Config
+		opts         []gelflogger.Option
+		wantInitErr  bool
+	}{
+		{
+			name:         "Minimum version met",
+			serverConfig: &tls.Config{MaxVersion: tls.VersionTLS13},
+			clientConfig: &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10},
+			opts:         []gelflogger.Option{gelflogger.WithTLSMinVersion(tls.VersionTLS12)},
+		},
+		{
+			name:         "Minimum version enforced over the supplied configuration",
+			serverConfig: &tls.Config{MaxVersion: tls.VersionTLS11},
+			clientConfig: &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10},
+			opts:         []gelflogger.Option{gelflogger.WithTLSMinVersion(tls.VersionTLS12)},
+			wantInitErr:  true,
+		},
+		{
+			name:         "Cipher suites enforced over the supplied configuration",
+			serverConfig: &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_GCM_SHA256}},
+			clientConfig: &tls.Config{InsecureSkipVerify: true, CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_GCM_SHA256}},
+			opts:         []gelflogger.Option{gelflogger.WithCipherSuites(tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384)},
+			wantInitErr:  true,
+		},
+		{
+			name:         "Strict mode refuses InsecureSkipVerify",
+			serverConfig: &tls.Config{},
+			clientConfig: &tls.Config{InsecureSkipVerify: true},
+			opts:         []gelflogger.Option{gelflogger.WithStrictTLS()},
+			wantInitErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.serverConfig.Certificates = []tls.Certificate{serverCert}
+			mockTLSServer, err := tls.Listen("tcp", "127.0.0.1:0", tt.serverConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = mockTLSServer.Close() }()
+			helper.ReceiveMessages(t, mockTLSServer, 0)
+
+			logger, err := gelflogger.NewLogger(mockTLSServer.Addr().String(), append(tt.opts, gelflogger.WithTLS(tt.clientConfig), gelflogger.WithProcessor(processNothing))...)
+			if (err != nil) != tt.wantInitErr {
+				t.Fatalf("NewLogger() error = %v, wantErr %v", err, tt.wantInitErr)
+			}
+			if tt.wantInitErr {
+				return
+			}
+			if err := logger.Log("tls policy", map[string]interface{}{}); err != nil {
+				t.Errorf("Log() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPinnedSPKI(t *testing.T) {
+	serverCert := helper.CreateTestCertificate()
+	leaf, err := x509.ParseCertificate(serverCert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+	otherFingerprint := sha256.Sum256([]byte("other key"))
+
+	tests := []struct {
+		name        string
+		pins        []string
+		wantInitErr bool
+	}{
+		{
+			name: "Base64 pin matches",
+			pins: []string{base64.StdEncoding.EncodeToString(fingerprint[:])},
+		},
+		{
+			name: "Hex pin matches one of several pins",
+			pins: []string{hex.EncodeToString(otherFingerprint[:]), hex.EncodeToString(fingerprint[:])},
+		},
+		{
+			name:        "Pin does not match",
+			pins:        []string{base64.StdEncoding.EncodeToString(otherFingerprint[:])},
+			wantInitErr: true,
+		},
+		{
+			name:        "Invalid pin",
+			pins:        []string{"not a fingerprint"},
+			wantInitErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTLSServer, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = mockTLSServer.Close() }()
+			helper.ReceiveMessages(t, mockTLSServer, 0)
+
+			// The self-signed server certificate is neither trusted nor issued for the address, only the pin is checked.
+			logger, err := gelflogger.NewLogger(mockTLSServer.Addr().String(), gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 6, 0, nil, nil
+			}), gelflogger.WithPinnedSPKI(tt.pins...))
+			if (err != nil) != tt.wantInitErr {
+				t.Fatalf("NewLogger() error = %v, wantErr %v", err, tt.wantInitErr)
+			}
+			if tt.wantInitErr {
+				return
+			}
+			if err := logger.Log("pinned", map[string]interface{}{}); err != nil {
+				t.Errorf("Log() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSHandshake(t *testing.T) {
+	mockTLSServer := helper.StartMockTLSServer(t)
+	silentServer := helper.StartMockServer(t)
+	defer t.Cleanup(func() {
+		_ = mockTLSServer.Close()
+		_ = silentServer.Close()
+	})
+
+	tests := []struct {
+		name      string
+		address   string
+		tlsConfig *tls.Config
+		timeout   time.Duration
+		wantErr   string
+	}{
+		{
+			name:      "Handshake succeeds",
+			address:   mockTLSServer.Addr().String(),
+			tlsConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		{
+			name:      "Untrusted server certificate",
+			address:   mockTLSServer.Addr().String(),
+			tlsConfig: &tls.Config{},
+			wantErr:   "certificate",
+		},
+		{
+			name:      "Server never answers the handshake",
+			address:   silentServer.Addr().String(),
+			tlsConfig: &tls.Config{InsecureSkipVerify: true},
+			timeout:   100 * time.Millisecond,
+			wantErr:   "deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			_, err := gelflogger.NewLogger(tt.address, gelflogger.WithTLS(tt.tlsConfig), gelflogger.WithProcessor(func(fields map[string]interface{}) (int, float64, []byte, error) {
+				return 0, 0, nil, nil
+			}), gelflogger.WithTLSHandshakeTimeout(timeout))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("NewLogger() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "TLS handshake") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewLogger() error = %v, want a TLS handshake error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,553 @@
+package gelflogger
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNotConnected is wrapped by the errors of the TCP transport if no connection to Graylog could be established, and
+// for messages sent while the connection is re-established. It is a temporary error.
+var ErrNotConnected = errors.New("not connected to Graylog")
+
+// errReconnecting is returned by the TCP transport for messages sent while the connection is re-established.
+var errReconnecting = fmt.Errorf("%w, reconnecting in the background", ErrNotConnected)
+
+// tcpWriteBufferSize is the size of the buffer the frames are written to before they are flushed to the connection.
+const tcpWriteBufferSize = 32 << 10
+
+// ErrWriteTimeout is returned by the TCP transport for messages whose write was aborted by the watchdog, as the
+// Graylog server did not read them within the write timeout, see WithWriteTimeout. It is a temporary error.
+var ErrWriteTimeout = errors.New("write to Graylog timed out")
+
+// Transport is the channel a Logger uses to ship encoded GELF messages to their destination.
+//
+// The Logger formats every message into a GELF JSON document and hands the resulting bytes to Send.
+// Implementations are responsible for any connection handling (connecting, reconnecting, framing) that
+// the destination requires. Close releases the resources held by the transport.
+//
+// The default transport, created by NewLogger, sends messages over TCP (optionally with TLS), or over UDP, HTTP or a
+// Unix domain socket depending on the scheme of the address, see NewTransport. Additional
+// transports, e.g. for NATS, live in their own packages below pkg/ and are used with NewLoggerWithTransport.
+type Transport interface {
+	Send(message []byte) error
+	Close() error
+}
+
+// FramingMode defines how consecutive GELF messages are delimited on a TCP stream.
+type FramingMode int
+
+const (
+	// FramingNullByte terminates every message with a null byte, as required by the GELF TCP input of Graylog.
+	FramingNullByte FramingMode = iota
+	// FramingNewline terminates every message with a newline, for receivers reading newline delimited JSON.
+	FramingNewline
+)
+
+// delimiter returns the byte terminating every message in the FramingMode.
+func (m FramingMode) delimiter() byte {
+	if m == FramingNewline {
+		return '\n'
+	}
+	return 0
+}
+
+// NewTCPTransport creates the TCP transport NewLogger uses by default and connects it to the given address.
+// The connection related Options, e.g. WithFailoverAddresses, WithConnectionPool or the TLS Options, are applied.
+// It allows combining TCP connections with other transports, e.g. in a MirrorTransport.
+func NewTCPTransport(address string, useTLS bool, tlsConfig *tls.Config, opts ...Option) (Transport, error) {
+	return newConfiguredTCPTransport(address, useTLS, tlsConfig, newConfig(opts))
+}
+
+// newConfiguredTCPTransport creates the TCP transport for the given configuration: a single connection failing over
+// across the addresses, a connection per address if load balancing is enabled, and a pool of either of them if a
+// connection pool is configured.
+func newConfiguredTCPTransport(address string, useTLS bool, tlsConfig *tls.Config, cfg config) (Transport, error) {
+	if err := cfg.prepareTLS(tlsConfig); err != nil {
+		return nil, err
+	}
+	useTLS = useTLS || cfg.tlsPolicy.pins != nil
+
+	addresses := append([]string{address}, cfg.failoverAddresses...)
+	newConnection := func() (Transport, error) {
+		if cfg.loadBalancing != NoLoadBalancing && len(addresses) > 1 {
+			return nilIfErr(newBalancedTransport(addresses, useTLS, tlsConfig, cfg))
+		}
+		return nilIfErr(newTCPTransport(addresses, useTLS, tlsConfig, cfg))
+	}
+	if cfg.poolSize > 1 {
+		return nilIfErr(newPooledTransport(cfg.poolSize, newConnection))
+	}
+	return newConnection()
+}
+
+// nilIfErr converts the result of a transport constructor to a Transport, which is nil if the constructor failed.
+func nilIfErr[T Transport](transport T, err error) (Transport, error) {
+	if err != nil {
+		return nil, err
+	}
+	return transport, nil
+}
+
+// connectionChecker is implemented by transports that hold a long-lived connection which can be verified and
+// re-established before a message is sent.
+type connectionChecker interface {
+	ensureConnection() error
+}
+
+// tcpTransport sends GELF messages to a Graylog server over a TCP connection.
+//
+// The tcpTransport struct has the following fields:
+// - conn: The network connection to the Graylog server.
+// - writer: The buffered writer of conn the frames are written to, flushed at the end of every send, see write.
+// - connLock: A mutex used to ensure thread-safe access to the conn field.
+// - addresses: The addresses of the Graylog servers, the first one is the primary, the others are failover endpoints.
+// - current: The index of the address the connection is established to.
+// - useTLS: A boolean value indicating whether to use TLS for the connection.
+// - tslConfig: The TLS configuration to use if useTLS is true.
+// - tlsMaterial: The client certificate and CA bundle added to the TLS configuration, if configured.
+// - tlsPolicy: The minimum TLS version and cipher suites enforced on the TLS configuration.
+// - timeout: The maximum duration of establishing a connection, see WithTimeout.
+// - tlsHandshakeTimeout: The maximum duration of the TLS handshake performed when connecting.
+// - writeTimeout: The maximum duration of a write before the watchdog aborts it, see WithWriteTimeout.
+// - framing: The FramingMode delimiting the messages on the stream.
+// - failbackInterval: The interval in which the primary is re-checked while connected to a failover endpoint.
+// - lastFailbackCheck: The time the primary was last checked.
+// - resolver: The resolver used to look up the IP addresses of the Graylog hosts, net.DefaultResolver if nil.
+// - rotateResolved: A boolean value indicating whether consecutive dials rotate among the resolved IP addresses.
+// - rotation: The number of dials of a rotating transport, selecting the resolved IP address to start with.
+// - connectionAttemptDelay: The delay after which the next resolved IP address is dialed concurrently.
+// - network: The network dialed, "tcp" or "unix" for Unix domain sockets.
+// - backoff: The delays between the reconnect attempts made in the background after the connection was lost.
+// - reconnecting: A boolean value indicating whether the connection is being re-established in the background.
+// - failFast: A boolean value indicating whether a lost connection is not re-established, see WithFailFast.
+// - failure: The error the connection was lost with, returned for the sends of a fail-fast transport.
+// - healthCheckInterval: The interval in which the supervisor probes the connection.
+// - events: The hub receiving the connection events, see Logger.Events.
+// - idleTimeout: The idle period after which the connection is recycled, see WithIdleTimeout.
+// - keepaliveInterval: The idle period after which an empty frame is sent, see WithKeepalive.
+// - lastActivity: The time of the last write to the connection, or of its establishment.
+// - ctx: The context of the dials, canceled by Close.
+// - cancel: Cancels ctx.
+// - background: The goroutines of the supervisor and the reconnect, waited for by Close.
+// - closed: A channel closed by Close, stopping the supervisor and the reconnect attempts.
+type tcpTransport struct {
+	conn                   net.Conn
+	writer                 *bufio.Writer
+	connLock               sync.Mutex
+	addresses              []string
+	current                int
+	useTLS                 bool
+	tslConfig              *tls.Config
+	tlsMaterial            *tlsMaterial
+	tlsPolicy              tlsPolicy
+	timeout                time.Duration
+	tlsHandshakeTimeout    time.Duration
+	writeTimeout           time.Duration
+	framing                FramingMode
+	failbackInterval       time.Duration
+	lastFailbackCheck      time.Time
+	resolver               *net.Resolver
+	rotateResolved         bool
+	rotation               atomic.Uint64
+	connectionAttemptDelay time.Duration
+	network                string
+	backoff                backoff
+	reconnecting           bool
+	failFast               bool
+	failure                error
+	healthCheckInterval    time.Duration
+	events                 *eventHub
+	idleTimeout            time.Duration
+	keepaliveInterval      time.Duration
+	lastActivity           time.Time
+	ctx                    context.Context
+	cancel                 context.CancelFunc
+	background             sync.WaitGroup
+	closed                 chan struct{}
+}
+
+// newTCPTransport creates a tcpTransport and establishes the initial connection to the first reachable address.
+func newTCPTransport(addresses []string, useTLS bool, tslConfig *tls.Config, cfg config) (*tcpTransport, error) {
+	t := newUnconnectedTCPTransport(addresses, useTLS, tslConfig, cfg)
+	t.connLock.Lock()
+	err := t.connect()
+	t.connLock.Unlock()
+	if err != nil {
+		// Stop the supervisor
+		_ = t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// newUnconnectedTCPTransport creates a tcpTransport without connecting it, it starts connecting in the background on
+// its first send. The supervisor of the transport is started right away.
+func newUnconnectedTCPTransport(addresses []string, useTLS bool, tslConfig *tls.Config, cfg config) *tcpTransport {
+	t := &tcpTransport{
+		addresses:              addresses,
+		useTLS:                 useTLS || cfg.tlsMaterial != nil,
+		tslConfig:              tslConfig,
+		tlsMaterial:            cfg.tlsMaterial,
+		tlsPolicy:              cfg.tlsPolicy,
+		timeout:                cfg.timeout,
+		tlsHandshakeTimeout:    cfg.tlsHandshakeTimeout,
+		writeTimeout:           cfg.writeTimeout,
+		framing:                cfg.framing,
+		failbackInterval:       cfg.failbackInterval,
+		resolver:               cfg.resolver,
+		rotateResolved:         cfg.rotateResolved,
+		connectionAttemptDelay: cfg.connectionAttemptDelay,
+		network:                cfg.network,
+		backoff:                cfg.reconnectBackoff,
+		healthCheckInterval:    cfg.healthCheckInterval,
+		events:                 cfg.events,
+		idleTimeout:            cfg.idleTimeout,
+		keepaliveInterval:      cfg.keepaliveInterval,
+		failFast:               cfg.failFast,
+		closed:                 make(chan struct{}),
+	}
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.background.Add(1)
+	go func() {
+		defer t.background.Done()
+		t.supervise()
+	}()
+	return t
+}
+
+// dial establishes a connection to the given address using either TCP or TLS, depending on the value of the useTLS flag.
+// Transports for the unix network connect to the Unix domain socket at the address instead.
+//
+// The host of the address is resolved on every call, so reconnects follow DNS changes instead of sticking to a
+// stale IP. The resolved IP addresses are dialed in turn until one of them is reachable, alternating between IPv6 and
+// IPv4 and racing the attempts after the connectionAttemptDelay, see dialParallel. If rotateResolved is set, every
+// call starts with the next resolved IP address, spreading the connections across all A/AAAA records.
+func (t *tcpTransport) dial(ctx context.Context, address string) (net.Conn, error) {
+	dialer := net.Dialer{
+		Timeout:   t.timeout,        // Timeout of the connection attempt
+		KeepAlive: 30 * time.Second, // 30 seconds keep-alive interval
+	}
+
+	var tlsConfig *tls.Config
+	if t.useTLS {
+		var err error
+		if tlsConfig, err = clientTLSConfig(address, t.tslConfig, t.tlsMaterial, t.tlsPolicy); err != nil {
+			return nil, err
+		}
+	}
+	targets := []string{address}
+	if t.network != "unix" {
+		var err error
+		if targets, err = t.resolve(ctx, address); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := dialParallel(ctx, &dialer, t.network, targets, t.connectionAttemptDelay)
+	if err != nil {
+		return nil, err
+	}
+	if t.useTLS {
+		tlsConn := tls.Client(conn, tlsConfig) // Wrap the connection with TLS
+		ctx, cancel := context.WithTimeout(ctx, t.tlsHandshakeTimeout)
+		defer cancel()
+		// Perform the handshake right away, so certificate and protocol errors surface when connecting instead of
+		// on the first write.
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+		}
+		conn = tlsConn
+	}
+	return conn, nil
+}
+
+// resolve looks up the IP addresses of the host of the given address and returns them joined with its port.
+// Addresses with an IP address as host are returned unchanged. The IP addresses of dual-stack hosts are interleaved,
+// so a broken route of one address family does not delay connecting over the other one.
+func (t *tcpTransport) resolve(ctx context.Context, address string) ([]string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return []string{address}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	resolver := t.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ips, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if t.rotateResolved {
+		offset = int((t.rotation.Add(1) - 1) % uint64(len(ips)))
+	}
+	targets := make([]string, 0, len(ips))
+	for i := range ips {
+		targets = append(targets, net.JoinHostPort(ips[(offset+i)%len(ips)].String(), port))
+	}
+	return interleaveFamilies(targets), nil
+}
+
+// connect establishes a connection, starting with the current address and failing over to the next addresses
+// until one of them is reachable. If the connection is successful, it replaces the one stored in the conn field.
+// The caller must hold connLock.
+func (t *tcpTransport) connect() error {
+	conn, index, err := t.dialFrom(t.ctx, t.current)
+	if err != nil {
+		return err
+	}
+	t.connected(conn, index)
+	return nil
+}
+
+// dialFrom dials the addresses starting with the one at the given index, until one of them is reachable, and returns
+// the connection together with the index of its address. It does not touch the connection of the transport, so the
+// caller does not need to hold connLock.
+func (t *tcpTransport) dialFrom(ctx context.Context, start int) (net.Conn, int, error) {
+	errs := make([]error, 0, len(t.addresses))
+	for i := range t.addresses {
+		index := (start + i) % len(t.addresses)
+		conn, err := t.dial(ctx, t.addresses[index])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return conn, index, nil
+	}
+	return nil, 0, fmt.Errorf("%w: %w", ErrNotConnected, errors.Join(errs...))
+}
+
+// connected makes conn, established to the address at the given index, the connection of the transport.
+// The caller must hold connLock.
+func (t *tcpTransport) connected(conn net.Conn, index int) {
+	if index != 0 && t.current != index {
+		// Failed over, the primary is checked again after the failback interval
+		t.lastFailbackCheck = time.Now()
+	}
+	t.setConn(conn, index)
+	if index != 0 {
+		t.events.emit(EventFailedOver, t.addresses[index], nil)
+	} else {
+		t.events.emit(EventConnected, t.addresses[index], nil)
+	}
+}
+
+// disconnected drops the broken connection, which failed with the given reason, and starts re-establishing it in
+// the background, see reconnect. The caller must hold connLock.
+func (t *tcpTransport) disconnected(reason error) {
+	if t.conn != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+		t.events.emit(EventDisconnected, t.addresses[t.current], reason)
+	}
+	select {
+	case <-t.closed:
+		return
+	default:
+	}
+	if t.failFast {
+		if t.failure == nil {
+			t.failure = reason
+		}
+		return
+	}
+	if !t.reconnecting {
+		t.reconnecting = true
+		t.background.Add(1)
+		go func() {
+			defer t.background.Done()
+			t.reconnect()
+		}()
+	}
+}
+
+// reconnect re-establishes the connection in the background, failing over across the addresses. The first attempt
+// is made right away, the following ones after the delays of the backoff, until an attempt succeeds or the transport
+// is closed. The connection lock is only held to install the new connection, so sends are not blocked by the dials.
+func (t *tcpTransport) reconnect() {
+	var err error
+	for attempt := 0; ; attempt++ {
+		t.connLock.Lock()
+		start := t.current
+		t.connLock.Unlock()
+
+		t.events.emit(EventReconnecting, t.addresses[start], err)
+		var conn net.Conn
+		var index int
+		conn, index, err = t.dialFrom(t.ctx, start)
+		if err == nil {
+			t.connLock.Lock()
+			defer t.connLock.Unlock()
+			t.reconnecting = false
+			select {
+			case <-t.closed:
+				_ = conn.Close()
+			default:
+				t.events.reconnected()
+				t.connected(conn, index)
+			}
+			return
+		}
+
+		select {
+		case <-t.closed:
+			t.connLock.Lock()
+			t.reconnecting = false
+			t.connLock.Unlock()
+			return
+		case <-time.After(t.backoff.delay(attempt)):
+		}
+	}
+}
+
+// setConn replaces the current connection by conn, established to the address at the given index.
+// The caller must hold connLock.
+func (t *tcpTransport) setConn(conn net.Conn, index int) {
+	if t.conn != nil {
+		_ = t.conn.Close()
+	}
+	t.conn = conn
+	if t.writer == nil {
+		t.writer = bufio.NewWriterSize(fullWriter{conn}, tcpWriteBufferSize)
+	} else {
+		// Discards the frames and the error left over by a failed write to the previous connection
+		t.writer.Reset(fullWriter{conn})
+	}
+	t.current = index
+	t.lastActivity = time.Now()
+}
+
+// ensureConnection checks if the transport has an active connection. The liveness of the connection is checked by
+// the supervisor, see supervise. If there is no connection, it is re-established in the background and an error is
+// returned right away, so the caller is not delayed by the reconnect.
+func (t *tcpTransport) ensureConnection() error {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn == nil {
+		t.disconnected(nil)
+		return t.notConnected()
+	}
+	return nil
+}
+
+// notConnected returns the error of a send without connection: errReconnecting, or, for a fail-fast transport, which
+// does not reconnect, ErrNotConnected wrapping the error the connection was lost with.
+func (t *tcpTransport) notConnected() error {
+	if !t.failFast {
+		return errReconnecting
+	}
+	if t.failure == nil {
+		return ErrNotConnected
+	}
+	return fmt.Errorf("%w: %w", ErrNotConnected, t.failure)
+}
+
+// Send writes the message, terminated by the delimiter of the FramingMode, to the connection.
+// If the write fails, or the connection is being re-established, the error is returned right away and the connection
+// is re-established in the background, failing over to the next reachable address.
+func (t *tcpTransport) Send(message []byte) error {
+	return t.write(message)
+}
+
+// SendBatch writes the messages, each terminated by the delimiter of the FramingMode, to the connection with as few
+// writes as the buffer allows. Write errors are handled like by Send.
+func (t *tcpTransport) SendBatch(messages [][]byte) error {
+	return t.write(messages...)
+}
+
+// write writes the messages, each terminated by the delimiter of the FramingMode, to the buffered writer of the
+// connection, and flushes it, so no frame is left in the buffer once write returned. The frames are written to the
+// connection completely, see fullWriter. If the write fails, or the watchdog aborts it after the write timeout, parts
+// of a frame may have been written, so the connection cannot be used for the next frame: it is closed, discarding the
+// buffered frames, and re-established in the background.
+func (t *tcpTransport) write(messages ...[]byte) error {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	if t.conn == nil {
+		t.disconnected(nil)
+		return t.notConnected()
+	}
+	if t.writeTimeout > 0 {
+		if err := t.conn.SetWriteDeadline(time.Now().Add(t.writeTimeout)); err != nil {
+			t.disconnected(err)
+			return err
+		}
+	}
+	delimiter := t.framing.delimiter()
+	for _, message := range messages {
+		// Errors are kept by the writer and returned by Flush
+		_, _ = t.writer.Write(message)
+		_ = t.writer.WriteByte(delimiter)
+	}
+	if err := t.writer.Flush(); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			err = fmt.Errorf("%w: %s did not read for %v: %w", ErrWriteTimeout, t.addresses[t.current], t.writeTimeout, err)
+		}
+		t.disconnected(err)
+		return err
+	}
+	t.lastActivity = time.Now()
+	return nil
+}
+
+// fullWriter writes to the connection until the whole buffer is written, retrying short writes, which connections
+// wrapping a net.Conn, e.g. of a custom dialer, may return without error. A write making no progress fails with
+// io.ErrShortWrite.
+type fullWriter struct {
+	conn net.Conn
+}
+
+// Write writes the whole buffer to the connection, or returns the number of bytes written before the error.
+func (w fullWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := w.conn.Write(p[written:])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if n == 0 {
+			return written, io.ErrShortWrite
+		}
+	}
+	return written, nil
+}
+
+// Close closes the underlying connection, stops reconnecting and waits until the goroutines of the transport, the
+// supervisor and a running reconnect, have returned. Dials in progress are canceled.
+func (t *tcpTransport) Close() error {
+	t.connLock.Lock()
+	select {
+	case <-t.closed:
+	default:
+		close(t.closed)
+	}
+	t.cancel()
+	var err error
+	if t.conn != nil {
+		err = t.conn.Close()
+		t.conn = nil
+	}
+	t.connLock.Unlock()
+
+	t.background.Wait()
+	return err
+}
@@ -0,0 +1,36 @@
+package gelflogger
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// truncationMark is appended to truncated short messages, see WithShortMessageLimit.
+const truncationMark = "…"
+
+// truncateShortMessage truncates the short message of the message to at most limit bytes at a rune boundary, marked
+// by an ellipsis, and puts the complete short message in front of the full message, unless the full message starts
+// with it already.
+func truncateShortMessage(msg *Message, limit int) {
+	shortMessage := msg.ShortMessage
+	if len(shortMessage) <= limit {
+		return
+	}
+	mark := truncationMark
+	if limit <= len(mark) {
+		mark = ""
+	}
+	cut := limit - len(mark)
+	for cut > 0 && !utf8.RuneStart(shortMessage[cut]) {
+		cut--
+	}
+	msg.ShortMessage = shortMessage[:cut] + mark
+	switch {
+	case strings.HasPrefix(msg.FullMessage, shortMessage):
+		// The full message starts with the complete text already, e.g. of a split multi-line message
+	case msg.FullMessage != "":
+		msg.FullMessage = shortMessage + "\n\n" + msg.FullMessage
+	default:
+		msg.FullMessage = shortMessage
+	}
+}
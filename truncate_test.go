@@ -0,0 +1,47 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestWithShortMessageLimit(t *testing.T) {
+	tests := []struct {
+		name             string
+		limit            int
+		processor        func(fields map[string]interface{}) (int, float64, []byte, error)
+		message          string
+		wantShortMessage string
+		wantFullMessage  string
+	}{
+		{name: "Short enough", limit: 10, processor: processNothing, message: "short", wantShortMessage: "short"},
+		{name: "Truncated", limit: 10, processor: processNothing, message: "0123456789abc", wantShortMessage: "0123456…", wantFullMessage: "0123456789abc"},
+		{name: "Truncated at a rune boundary", limit: 8, processor: processNothing, message: "ääääää", wantShortMessage: "ää…", wantFullMessage: "ääääää"},
+		{name: "Limit too small for the mark", limit: 3, processor: processNothing, message: "abcdef", wantShortMessage: "abc", wantFullMessage: "abcdef"},
+		{name: "Complete message in front of the full message", limit: 10, processor: gelflogger.ProcessFields, message: "0123456789abc", wantShortMessage: "0123456…", wantFullMessage: "0123456789abc\n\n{}"},
+		{name: "Unlimited", processor: processNothing, message: strings.Repeat("x", 100), wantShortMessage: strings.Repeat("x", 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, tt.processor, gelflogger.WithShortMessageLimit(tt.limit))
+			if err := logger.Log(tt.message, map[string]interface{}{}); err != nil {
+				t.Fatalf("Log() error = %v", err)
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message: %v", err)
+			}
+			if gelfMsg["short_message"] != tt.wantShortMessage {
+				t.Errorf("short_message = %q, want %q", gelfMsg["short_message"], tt.wantShortMessage)
+			}
+			if fullMessage, _ := gelfMsg["full_message"].(string); fullMessage != tt.wantFullMessage {
+				t.Errorf("full_message = %q, want %q", fullMessage, tt.wantFullMessage)
+			}
+		})
+	}
+}
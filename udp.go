@@ -0,0 +1,84 @@
+package gelflogger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+)
+
+const (
+	// udpChunkSize is the maximum size of a GELF UDP datagram, chosen to fit into the MTU of common networks.
+	udpChunkSize = 1420
+	// udpChunkHeaderSize is the size of the header of a GELF chunk: the magic bytes, the message ID, the sequence
+	// number and the sequence count.
+	udpChunkHeaderSize = 12
+	// udpMaxChunks is the maximum number of chunks of a GELF message accepted by Graylog.
+	udpMaxChunks = 128
+)
+
+// udpChunkMagic are the magic bytes starting every chunk of a chunked GELF message.
+var udpChunkMagic = [2]byte{0x1e, 0x0f}
+
+// udpTransport sends GELF messages as UDP datagrams. Messages exceeding udpChunkSize are split into GELF chunks,
+// which Graylog reassembles.
+type udpTransport struct {
+	conn        net.Conn
+	lock        sync.Mutex
+	compression Compression
+}
+
+// newUDPTransport creates a udpTransport sending its datagrams, compressed with the given Compression, to the given
+// address.
+func newUDPTransport(address string, compression Compression) (*udpTransport, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &udpTransport{conn: conn, compression: compression}, nil
+}
+
+// Send sends the message in a single datagram, or chunked if it exceeds udpChunkSize after compression. Messages
+// needing more than udpMaxChunks chunks are rejected, as Graylog would discard them.
+func (t *udpTransport) Send(message []byte) error {
+	message, err := t.compression.compress(message)
+	if err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(message) <= udpChunkSize {
+		_, err := t.conn.Write(message)
+		return err
+	}
+
+	dataSize := udpChunkSize - udpChunkHeaderSize
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > udpMaxChunks {
+		return fmt.Errorf("%w: %w: %d bytes exceed the maximum of %d UDP chunks", ErrPermanent, ErrMessageTooLarge, len(message), udpMaxChunks)
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, udpChunkSize)
+	for seq := 0; seq < count; seq++ {
+		data := message[seq*dataSize : min((seq+1)*dataSize, len(message))]
+		chunk = append(chunk[:0], udpChunkMagic[:]...)
+		chunk = binary.BigEndian.AppendUint64(chunk, binary.BigEndian.Uint64(id[:]))
+		chunk = append(chunk, byte(seq), byte(count))
+		chunk = append(chunk, data...)
+		if _, err := t.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the UDP socket.
+func (t *udpTransport) Close() error {
+	return t.conn.Close()
+}
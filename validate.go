@@ -0,0 +1,175 @@
+package gelflogger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxMessageSize is the maximum size of an encoded GELF message in bytes, the most Graylog reassembles from the
+	// chunks of a UDP message.
+	MaxMessageSize = udpMaxChunks * (udpChunkSize - udpChunkHeaderSize)
+	// MaxFieldValueSize is the maximum size of a string field value in bytes Graylog can index, the limit of the
+	// indexed terms of Elasticsearch and OpenSearch.
+	MaxFieldValueSize = 32766
+)
+
+// ErrMessageTooLarge is wrapped by the errors of messages too large to be sent, e.g. exceeding the chunks of a UDP
+// message or rejected by a GELF HTTP input as too large. It is a permanent error.
+var ErrMessageTooLarge = errors.New("GELF message too large")
+
+// ErrInvalidMessage is wrapped by the ValidationError of a message violating the GELF specification, see Validate and
+// WithValidation.
+var ErrInvalidMessage = errors.New("invalid GELF message")
+
+// Violation is a violation of the GELF specification found by Validate.
+type Violation struct {
+	// Field is the name of the violating field in the GELF message, e.g. short_message or _user_id, or empty if the
+	// violation concerns the message as a whole, e.g. its size.
+	Field string
+	// Reason describes the violation.
+	Reason string
+}
+
+// String returns the field and the reason of the violation.
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.Reason
+	}
+	return v.Field + ": " + v.Reason
+}
+
+// ValidationError is the error of a message rejected by a Logger validating its messages, see WithValidation. It
+// wraps ErrInvalidMessage.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Error lists the violations.
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		reasons[i] = violation.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidMessage, strings.Join(reasons, "; "))
+}
+
+// Unwrap returns ErrInvalidMessage.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidMessage
+}
+
+// Validate checks the message against the GELF specification, as it is, without the sanitizing and coercion of a
+// Logger, e.g. to catch producers of invalid messages in tests, and returns the violations sorted by field, or none
+// if the message is valid:
+//
+//   - The version must be 1.1, the host and the short message must not be empty.
+//   - The level must be a Syslog level from 0 to 7, the timestamp must be a finite, non-negative number.
+//   - The names of the additional fields may only contain letters, digits, underscores, dots and dashes, and must not
+//     be id, which Graylog reserves.
+//   - The values of the additional fields must be strings of at most MaxFieldValueSize bytes, or finite numbers.
+//   - The encoded message must not exceed MaxMessageSize bytes.
+func Validate(msg *Message) []Violation {
+	gelfMsg := make(map[string]interface{}, len(msg.Additional)+6)
+	for name, value := range msg.Additional {
+		gelfMsg["_"+name] = value
+	}
+	gelfMsg["version"] = msg.Version
+	gelfMsg["host"] = msg.Host
+	gelfMsg["short_message"] = msg.ShortMessage
+	gelfMsg["timestamp"] = msg.Timestamp
+	gelfMsg["level"] = msg.Level
+	if msg.FullMessage != "" {
+		gelfMsg["full_message"] = msg.FullMessage
+	}
+	encoded, err := json.Marshal(gelfMsg)
+	if err != nil {
+		violations := validateFields(gelfMsg, 0)
+		return append(violations, Violation{Reason: fmt.Sprintf("not encodable as JSON: %v", err)})
+	}
+	return validateFields(gelfMsg, len(encoded))
+}
+
+// validateFields checks the members of a GELF message and the size of its encoding, see Validate.
+func validateFields(gelfMsg map[string]interface{}, size int) []Violation {
+	var violations []Violation
+	names := make([]string, 0, len(gelfMsg))
+	for name := range gelfMsg {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if reason := validateField(name, gelfMsg[name]); reason != "" {
+			violations = append(violations, Violation{Field: name, Reason: reason})
+		}
+	}
+	if size > MaxMessageSize {
+		violations = append(violations, Violation{Reason: fmt.Sprintf("encoded message of %d bytes exceeds %d bytes", size, MaxMessageSize)})
+	}
+	return violations
+}
+
+// validateField returns the reason why the member of a GELF message violates the specification, or an empty string.
+func validateField(name string, value interface{}) string {
+	switch name {
+	case "version":
+		if value != gelfVersion {
+			return fmt.Sprintf("must be %q, not %v", gelfVersion, value)
+		}
+	case "host", "short_message":
+		if s, _ := value.(string); s == "" {
+			return "must not be empty"
+		}
+	case "full_message":
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("must be a string, not %T", value)
+		}
+	case "level":
+		if level, ok := value.(int); !ok || level < 0 || level > 7 {
+			return fmt.Sprintf("must be a Syslog level from 0 to 7, not %v", value)
+		}
+	case "timestamp":
+		if ts, ok := value.(float64); !ok || math.IsNaN(ts) || math.IsInf(ts, 0) || ts < 0 {
+			return fmt.Sprintf("must be a finite, non-negative number, not %v", value)
+		}
+	default:
+		key, ok := strings.CutPrefix(name, "_")
+		if !ok {
+			return "additional fields must be prefixed with an underscore"
+		}
+		if _, err := gelfFieldName(key, true); err != nil {
+			return strings.TrimPrefix(err.Error(), ErrInvalidFieldName.Error()+": ")
+		}
+		return validateFieldValue(value)
+	}
+	return ""
+}
+
+// validateFieldValue returns the reason why the value of an additional field violates the specification, or an empty
+// string.
+func validateFieldValue(value interface{}) string {
+	if _, ok := value.(json.Number); ok {
+		return ""
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		if rv.Len() > MaxFieldValueSize {
+			return fmt.Sprintf("value of %d bytes exceeds %d bytes", rv.Len(), MaxFieldValueSize)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprintf("value must be a finite number, not %v", f)
+		}
+	default:
+		return fmt.Sprintf("value must be a string or a number, not %T", value)
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package gelflogger_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() gelflogger.Message {
+		return gelflogger.Message{
+			Version:      "1.1",
+			Host:         "billing",
+			ShortMessage: "order placed",
+			Timestamp:    1700000000.5,
+			Level:        6,
+			Additional:   map[string]interface{}{"order_id": 42, "customer": "c-1", "amount": 9.5},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(msg *gelflogger.Message)
+		want   []string
+	}{
+		{name: "Valid", modify: func(*gelflogger.Message) {}},
+		{
+			name:   "Missing required fields",
+			modify: func(msg *gelflogger.Message) { msg.Version, msg.Host, msg.ShortMessage = "1.0", "", "" },
+			want:   []string{"host", "short_message", "version"},
+		},
+		{
+			name:   "Level and timestamp out of range",
+			modify: func(msg *gelflogger.Message) { msg.Level, msg.Timestamp = 8, -1 },
+			want:   []string{"level", "timestamp"},
+		},
+		{
+			name: "Invalid field names",
+			modify: func(msg *gelflogger.Message) {
+				msg.Additional["id"] = "7"
+				msg.Additional["user name"] = "alice"
+			},
+			want: []string{"_id", "_user name"},
+		},
+		{
+			name: "Invalid field values",
+			modify: func(msg *gelflogger.Message) {
+				msg.Additional["paid"] = true
+				msg.Additional["ratio"] = math.Inf(1)
+				msg.Additional["body"] = strings.Repeat("x", gelflogger.MaxFieldValueSize+1)
+			},
+			want: []string{"_body", "_paid", "_ratio", ""},
+		},
+		{
+			name:   "Oversized message",
+			modify: func(msg *gelflogger.Message) { msg.FullMessage = strings.Repeat("x", gelflogger.MaxMessageSize) },
+			want:   []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := valid()
+			tt.modify(&msg)
+			var got []string
+			for _, violation := range gelflogger.Validate(&msg) {
+				if violation.Reason == "" {
+					t.Errorf("Validate() violation of %q without reason", violation.Field)
+				}
+				got = append(got, violation.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() violations of %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		fields  map[string]interface{}
+		wantErr bool
+	}{
+		{name: "Valid message", message: "order placed", fields: map[string]interface{}{"paid": true}},
+		{name: "Empty short message", message: "", fields: map[string]interface{}{}, wantErr: true},
+		{name: "Oversized field", message: "upload", fields: map[string]interface{}{"body": strings.Repeat("x", gelflogger.MaxFieldValueSize+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			logger := gelflogger.NewLoggerWithTransport(transport, processNothing, gelflogger.WithValidation())
+			err := logger.Log(tt.message, tt.fields)
+			if !tt.wantErr {
+				if err != nil || len(transport.messages) != 1 {
+					t.Fatalf("Log() error = %v, sent %d messages, want the message sent", err, len(transport.messages))
+				}
+				return
+			}
+			var validationErr *gelflogger.ValidationError
+			if !errors.As(err, &validationErr) || !errors.Is(err, gelflogger.ErrInvalidMessage) || !errors.Is(err, gelflogger.ErrPermanent) {
+				t.Fatalf("Log() error = %v, want a permanent ValidationError", err)
+			}
+			if len(transport.messages) != 0 || logger.Dropped() != 1 {
+				t.Errorf("sent %d messages, dropped %d, want the message dropped", len(transport.messages), logger.Dropped())
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package gelflogger_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+	"github.com/jame-developer/gelf-logger/pkg/helper"
+)
+
+func TestWriteWatchdog(t *testing.T) {
+	// The server accepts the connection, but never reads from it
+	server := helper.StartMockServer(t)
+	defer func() { _ = server.Close() }()
+	accepted := acceptConnections(server)
+	address := server.Addr().String()
+
+	fallback := &recordingTransport{}
+	logger, err := gelflogger.NewLogger(address, gelflogger.WithProcessor(processNothing),
+		gelflogger.WithWriteTimeout(50*time.Millisecond),
+		gelflogger.WithHealthCheckInterval(0),
+		gelflogger.WithFallback(fallback),
+	)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer func() { _ = logger.Close(context.Background()) }()
+	events := logger.Events()
+	stuck := <-accepted
+	defer func() { _ = stuck.Close() }()
+
+	// Fill the socket buffers until a write blocks and is aborted by the watchdog
+	large := strings.Repeat("x", 1<<20)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fallback.messages) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no write was aborted")
+		}
+		start := time.Now()
+		if err := logger.Log(large, map[string]interface{}{}); err != nil {
+			t.Fatalf("Log() error = %v, want the message handed to the fallback", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Log() blocked for %v", elapsed)
+		}
+	}
+
+	// The stuck connection is torn down and replaced by a new one
+	for _, want := range []gelflogger.EventType{gelflogger.EventDisconnected, gelflogger.EventReconnecting, gelflogger.EventConnected} {
+		select {
+		case event := <-events:
+			if event.Type != want {
+				t.Fatalf("event = %v, want %v", event.Type, want)
+			}
+			if want == gelflogger.EventDisconnected && !errors.Is(event.Reason, gelflogger.ErrWriteTimeout) {
+				t.Errorf("disconnect reason = %v, want ErrWriteTimeout", event.Reason)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %v event", want)
+		}
+	}
+	select {
+	case conn := <-accepted:
+		_ = conn.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("no new connection after the aborted write")
+	}
+}
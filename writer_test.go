@@ -0,0 +1,153 @@
+package gelflogger_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	gelflogger "github.com/jame-developer/gelf-logger"
+)
+
+func TestGelfWriterFullMessage(t *testing.T) {
+	const line = `{"level":"warn","time":1700000000500,"message":"disk almost full","free":"5%"}`
+
+	processed := 0
+	countingProcessor := func(fields map[string]interface{}) (int, float64, []byte, error) {
+		processed++
+		return gelflogger.ProcessFields(fields)
+	}
+
+	tests := []struct {
+		name            string
+		opts            []gelflogger.Option
+		wantFullMessage string
+		wantProcessed   int
+	}{
+		{
+			name:            "Field reader",
+			opts:            []gelflogger.Option{gelflogger.WithFieldReader(gelflogger.ReadFields)},
+			wantFullMessage: line,
+		},
+		{
+			name:            "Processor without field reader",
+			wantFullMessage: `{"free":"5%","level":"warn","message":"disk almost full","time":1700000000500}`,
+			wantProcessed:   1,
+		},
+		{
+			name:          "Field reader without full message",
+			opts:          []gelflogger.Option{gelflogger.WithFieldReader(gelflogger.ReadFields), gelflogger.WithFullMessage(gelflogger.FullMessageNone)},
+			wantProcessed: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed = 0
+			transport := &recordingTransport{}
+			writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(transport, countingProcessor, tt.opts...)}
+			if _, err := writer.Write([]byte(line + "\n")); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if processed != tt.wantProcessed {
+				t.Errorf("processor called %d times, want %d", processed, tt.wantProcessed)
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(transport.messages))
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			fullMessage, _ := gelfMsg["full_message"].(string)
+			if fullMessage != tt.wantFullMessage {
+				t.Errorf("full_message = %q, want %q", fullMessage, tt.wantFullMessage)
+			}
+			if gelfMsg["short_message"] != "disk almost full" || gelfMsg["level"] != float64(4) || gelfMsg["timestamp"] != 1700000000.5 || gelfMsg["_free"] != "5%" {
+				t.Errorf("sent %s, want the message, level, timestamp and fields of the JSON", transport.messages[0])
+			}
+			if _, ok := gelfMsg["_level"]; ok {
+				t.Errorf("sent %s, want the level field consumed", transport.messages[0])
+			}
+		})
+	}
+}
+
+func TestReadFields(t *testing.T) {
+	fields := map[string]interface{}{"level": "error", "time": 1700000000000.0, "message": "failed", "user": "42"}
+	level, timestamp, err := gelflogger.ReadFields(fields)
+	if err != nil {
+		t.Fatalf("ReadFields() error = %v", err)
+	}
+	if level != 3 || timestamp != 1700000000 {
+		t.Errorf("ReadFields() = %d, %v, want 3, 1700000000", level, timestamp)
+	}
+	if len(fields) != 1 || fields["user"] != "42" {
+		t.Errorf("fields after ReadFields() = %v, want only the additional fields", fields)
+	}
+	if _, _, err := gelflogger.ReadFields(map[string]interface{}{"time": "yesterday"}); err == nil {
+		t.Error("ReadFields() error = nil, want an error for an invalid time")
+	}
+}
+
+func BenchmarkGelfWriter(b *testing.B) {
+	line := []byte(`{"level":"info","time":1700000000500,"message":"order placed","request_id":"9b2c41e0","user_id":4711,"amount":99.95}` + "\n")
+	writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(discardTransport{}, gelflogger.ProcessFields,
+		gelflogger.WithFieldReader(gelflogger.ReadFields))}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := writer.Write(line); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGelfWriterReusesNoFields(t *testing.T) {
+	transport := &recordingTransport{}
+	writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(transport, gelflogger.ProcessFields,
+		gelflogger.WithFieldReader(gelflogger.ReadFields), gelflogger.WithProcessorChain(gelflogger.NewDefaultRedactor()))}
+	for _, line := range []string{`{"message":"first","order_id":"42"}`, `{"message":"second"}`} {
+		if _, err := writer.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if len(transport.messages) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(transport.messages))
+	}
+	var second map[string]interface{}
+	if err := json.Unmarshal([]byte(transport.messages[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := second["_order_id"]; ok {
+		t.Errorf("second message %s carries a field of the first one", transport.messages[1])
+	}
+}
+
+func TestGelfWriterWriteWithLevel(t *testing.T) {
+	const line = `{"level":"not a level","message":"native level","user":"42"}`
+	tests := []struct {
+		name string
+		opts []gelflogger.Option
+	}{
+		{name: "Field reader", opts: []gelflogger.Option{gelflogger.WithFieldReader(gelflogger.ReadFields)}},
+		{name: "Processor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			writer := &gelflogger.GelfWriter{Logger: gelflogger.NewLoggerWithTransport(transport, processNothing, tt.opts...)}
+			if _, err := writer.WriteWithLevel(gelflogger.Warning, []byte(line)); err != nil {
+				t.Fatalf("WriteWithLevel() error = %v", err)
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(transport.messages))
+			}
+			var gelfMsg map[string]interface{}
+			if err := json.Unmarshal([]byte(transport.messages[0]), &gelfMsg); err != nil {
+				t.Fatalf("invalid GELF message %s: %v", transport.messages[0], err)
+			}
+			if gelfMsg["level"] != float64(4) || gelfMsg["_user"] != "42" {
+				t.Errorf("sent %s, want level 4 and the fields of the JSON", transport.messages[0])
+			}
+		})
+	}
+}